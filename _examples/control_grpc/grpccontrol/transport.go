@@ -0,0 +1,361 @@
+// Package grpccontrol provides centrifuge.ControlTransport which sends control
+// messages between nodes over gRPC streams.
+package grpccontrol
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	metadataNodeID = "centrifuge-node-id"
+	metadataSecret = "centrifuge-control-secret"
+	peerQueueSize  = 4096
+)
+
+// Config of Transport.
+type Config struct {
+	// BindAddr is an address to listen for gRPC connections from other nodes. Required.
+	BindAddr string
+	// AdvertiseAddr is an address of this node as returned by Registry. By default,
+	// address of listener is used.
+	AdvertiseAddr string
+	// Registry provides addresses of other nodes. Required.
+	Registry centrifuge.ControlAddressRegistry
+	// RefreshInterval is an interval to refresh addresses from Registry. Zero value
+	// means 5 seconds.
+	RefreshInterval time.Duration
+	// ServerCredentials and ClientCredentials are used to protect connections between
+	// nodes – usually with mutual TLS (see centrifuge.MutualTLS).
+	ServerCredentials credentials.TransportCredentials
+	ClientCredentials credentials.TransportCredentials
+	// SharedSecret is used to authenticate streams when credentials are not set.
+	// Either credentials or SharedSecret must be set.
+	SharedSecret string
+}
+
+// Transport is a centrifuge.ControlTransport over gRPC streams. Every node opens a
+// client stream to every other node and writes control messages into it.
+type Transport struct {
+	node     *centrifuge.Node
+	config   Config
+	server   *grpc.Server
+	listener net.Listener
+	addr     string
+	handler  func(data []byte) error
+
+	mu    sync.RWMutex
+	peers map[string]*peer  // Address -> outgoing stream.
+	nodes map[string]string // Node ID -> address.
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+var _ centrifuge.ControlTransport = (*Transport)(nil)
+
+// ErrUnknownNode returned when control message is sent to node which is not
+// connected yet.
+var ErrUnknownNode = errors.New("grpc control: unknown node")
+
+// New creates Transport and starts listening on BindAddr.
+func New(node *centrifuge.Node, config Config) (*Transport, error) {
+	if config.BindAddr == "" || config.Registry == nil {
+		return nil, errors.New("grpc control: BindAddr and Registry required")
+	}
+	if (config.ServerCredentials == nil || config.ClientCredentials == nil) && config.SharedSecret == "" {
+		return nil, errors.New("grpc control: credentials or shared secret required")
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = 5 * time.Second
+	}
+	listener, err := net.Listen("tcp", config.BindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("grpc control: %w", err)
+	}
+	addr := config.AdvertiseAddr
+	if addr == "" {
+		addr = listener.Addr().String()
+	}
+	var opts []grpc.ServerOption
+	if config.ServerCredentials != nil {
+		opts = append(opts, grpc.Creds(config.ServerCredentials))
+	}
+	t := &Transport{
+		node:     node,
+		config:   config,
+		server:   grpc.NewServer(opts...),
+		listener: listener,
+		addr:     addr,
+		peers:    map[string]*peer{},
+		nodes:    map[string]string{},
+		closeCh:  make(chan struct{}),
+	}
+	t.server.RegisterService(&serviceDesc, t)
+	return t, nil
+}
+
+// Addr returns address of Transport listener.
+func (t *Transport) Addr() string {
+	return t.addr
+}
+
+// Run – see centrifuge.ControlTransport.
+func (t *Transport) Run(handler func(data []byte) error) error {
+	t.handler = handler
+	go func() { _ = t.server.Serve(t.listener) }()
+	go t.refreshLoop()
+	return nil
+}
+
+// Close stops server and closes streams to other nodes.
+func (t *Transport) Close(_ context.Context) error {
+	t.closeOnce.Do(func() {
+		close(t.closeCh)
+		t.server.Stop()
+		t.mu.Lock()
+		for _, p := range t.peers {
+			p.close()
+		}
+		t.mu.Unlock()
+	})
+	return nil
+}
+
+// PublishControl – see centrifuge.ControlTransport.
+func (t *Transport) PublishControl(data []byte, nodeID string) error {
+	if nodeID == t.node.ID() {
+		return t.handler(data)
+	}
+	t.mu.RLock()
+	if nodeID == "" {
+		for _, p := range t.peers {
+			p.send(data)
+		}
+		t.mu.RUnlock()
+		return t.handler(data)
+	}
+	defer t.mu.RUnlock()
+	addr, ok := t.nodes[nodeID]
+	if !ok {
+		return ErrUnknownNode
+	}
+	t.peers[addr].send(data)
+	return nil
+}
+
+// controlServer is a handler type of gRPC service.
+type controlServer interface {
+	stream(srv grpc.ServerStream) error
+}
+
+// serviceDesc describes gRPC service with one client stream method, messages are
+// well-known protobuf types so no code generation is needed.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "centrifuge.control.Control",
+	HandlerType: (*controlServer)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ClientStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(controlServer).stream(stream)
+		},
+	}},
+}
+
+var streamDesc = &serviceDesc.Streams[0]
+
+const streamMethod = "/centrifuge.control.Control/Stream"
+
+func (t *Transport) stream(stream grpc.ServerStream) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if t.config.SharedSecret != "" {
+		secret := md.Get(metadataSecret)
+		if len(secret) != 1 || subtle.ConstantTimeCompare([]byte(secret[0]), []byte(t.config.SharedSecret)) != 1 {
+			return status.Error(codes.Unauthenticated, "invalid secret")
+		}
+	}
+	if err := stream.SendHeader(metadata.Pairs(metadataNodeID, t.node.ID())); err != nil {
+		return err
+	}
+	for {
+		msg := &wrapperspb.BytesValue{}
+		if err := stream.RecvMsg(msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return stream.SendMsg(&emptypb.Empty{})
+			}
+			return err
+		}
+		if err := t.handler(msg.Value); err != nil {
+			t.node.Log(centrifuge.NewLogEntry(centrifuge.LogLevelError, "error handling control", map[string]any{"error": err.Error()}))
+		}
+	}
+}
+
+func (t *Transport) refreshLoop() {
+	ticker := time.NewTicker(t.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.refresh(); err != nil {
+			t.node.Log(centrifuge.NewLogEntry(centrifuge.LogLevelError, "error refreshing control addresses", map[string]any{"error": err.Error()}))
+		}
+		select {
+		case <-t.closeCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Transport) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.config.RefreshInterval)
+	defer cancel()
+	addrs, err := t.config.Registry.ControlAddresses(ctx)
+	if err != nil {
+		return err
+	}
+	actual := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		if addr != t.addr {
+			actual[addr] = struct{}{}
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.closeCh:
+		return nil
+	default:
+	}
+	for addr, p := range t.peers {
+		if _, ok := actual[addr]; !ok {
+			p.close()
+			delete(t.peers, addr)
+			for nodeID, nodeAddr := range t.nodes {
+				if nodeAddr == addr {
+					delete(t.nodes, nodeID)
+				}
+			}
+		}
+	}
+	for addr := range actual {
+		if _, ok := t.peers[addr]; ok {
+			continue
+		}
+		p, err := t.newPeer(addr)
+		if err != nil {
+			return err
+		}
+		t.peers[addr] = p
+		go p.run()
+	}
+	return nil
+}
+
+func (t *Transport) newPeer(addr string) (*peer, error) {
+	creds := t.config.ClientCredentials
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &peer{
+		transport: t,
+		addr:      addr,
+		conn:      conn,
+		queue:     make(chan []byte, peerQueueSize),
+		closeCh:   make(chan struct{}),
+	}, nil
+}
+
+// peer maintains client stream to another node.
+type peer struct {
+	transport *Transport
+	addr      string
+	conn      *grpc.ClientConn
+	queue     chan []byte
+	closeCh   chan struct{}
+	once      sync.Once
+}
+
+func (p *peer) close() {
+	p.once.Do(func() {
+		close(p.closeCh)
+		_ = p.conn.Close()
+	})
+}
+
+// send enqueues message, message is dropped if queue is full.
+func (p *peer) send(data []byte) {
+	select {
+	case p.queue <- data:
+	default:
+		p.transport.node.Log(centrifuge.NewLogEntry(centrifuge.LogLevelWarn, "control message dropped", map[string]any{"addr": p.addr}))
+	}
+}
+
+func (p *peer) run() {
+	for {
+		err := p.stream()
+		select {
+		case <-p.closeCh:
+			return
+		case <-time.After(p.transport.config.RefreshInterval):
+		}
+		if err != nil {
+			p.transport.node.Log(centrifuge.NewLogEntry(centrifuge.LogLevelDebug, "control stream error", map[string]any{"addr": p.addr, "error": err.Error()}))
+		}
+	}
+}
+
+func (p *peer) stream() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	md := metadata.Pairs(metadataNodeID, p.transport.node.ID())
+	if p.transport.config.SharedSecret != "" {
+		md.Set(metadataSecret, p.transport.config.SharedSecret)
+	}
+	stream, err := p.conn.NewStream(metadata.NewOutgoingContext(ctx, md), streamDesc, streamMethod)
+	if err != nil {
+		return err
+	}
+	header, err := stream.Header()
+	if err != nil {
+		return err
+	}
+	if ids := header.Get(metadataNodeID); len(ids) == 1 {
+		p.transport.mu.Lock()
+		if _, ok := p.transport.peers[p.addr]; ok {
+			p.transport.nodes[ids[0]] = p.addr
+		}
+		p.transport.mu.Unlock()
+	}
+	for {
+		select {
+		case <-p.closeCh:
+			return stream.CloseSend()
+		case data := <-p.queue:
+			if err := stream.SendMsg(&wrapperspb.BytesValue{Value: data}); err != nil {
+				return err
+			}
+		}
+	}
+}
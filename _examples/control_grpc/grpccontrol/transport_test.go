@@ -0,0 +1,69 @@
+package grpccontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	_, err := New(nil, Config{BindAddr: "127.0.0.1:0", Registry: centrifuge.StaticControlAddresses{}})
+	require.Error(t, err)
+
+	var addrs centrifuge.StaticControlAddresses
+	var nodes []*centrifuge.Node
+	var transports []*Transport
+	for i := 0; i < 2; i++ {
+		node, err := centrifuge.New(centrifuge.Config{})
+		require.NoError(t, err)
+		transport, err := New(node, Config{
+			BindAddr:        "127.0.0.1:0",
+			Registry:        &addrs,
+			RefreshInterval: 50 * time.Millisecond,
+			SharedSecret:    "secret",
+		})
+		require.NoError(t, err)
+		node.SetControlTransport(transport)
+		addrs = append(addrs, transport.Addr())
+		nodes = append(nodes, node)
+		transports = append(transports, transport)
+	}
+	for _, node := range nodes {
+		require.NoError(t, node.Run())
+		defer func(node *centrifuge.Node) { _ = node.Shutdown(context.Background()) }(node)
+	}
+	defer func() {
+		for _, transport := range transports {
+			_ = transport.Close(context.Background())
+		}
+	}()
+
+	require.Eventually(t, func() bool {
+		for _, node := range nodes {
+			info, err := node.Info()
+			require.NoError(t, err)
+			if len(info.Nodes) != 2 {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 50*time.Millisecond)
+
+	received := make(chan []byte, 1)
+	nodes[0].OnNotification(func(event centrifuge.NotificationEvent) {})
+	nodes[1].OnNotification(func(event centrifuge.NotificationEvent) {
+		received <- event.Data
+	})
+	require.Eventually(t, func() bool {
+		return nodes[0].Notify("op", []byte("data"), nodes[1].ID()) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	select {
+	case data := <-received:
+		require.Equal(t, []byte("data"), data)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for notification")
+	}
+}
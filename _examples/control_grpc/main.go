@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/centrifugal/centrifuge/_examples/control_grpc/grpccontrol"
+)
+
+var (
+	port        = flag.Int("port", 8000, "Port to bind app to")
+	controlAddr = flag.String("control", "127.0.0.1:9000", "Address to bind gRPC control server to")
+	peers       = flag.String("peers", "127.0.0.1:9000,127.0.0.1:9001", "Comma-separated control addresses of all nodes")
+	secret      = flag.String("secret", "secret", "Shared secret to authenticate nodes")
+)
+
+func handleLog(e centrifuge.LogEntry) {
+	log.Printf("[centrifuge] %s: %v", e.Message, e.Fields)
+}
+
+func waitExitSignal(n *centrifuge.Node, transport *grpccontrol.Transport) {
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan bool, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		_ = n.Shutdown(context.Background())
+		_ = transport.Close(context.Background())
+		done <- true
+	}()
+	<-done
+}
+
+func main() {
+	flag.Parse()
+
+	node, _ := centrifuge.New(centrifuge.Config{
+		LogLevel:   centrifuge.LogLevelInfo,
+		LogHandler: handleLog,
+	})
+
+	transport, err := grpccontrol.New(node, grpccontrol.Config{
+		BindAddr:      *controlAddr,
+		AdvertiseAddr: *controlAddr,
+		Registry:      centrifuge.StaticControlAddresses(strings.Split(*peers, ",")),
+		SharedSecret:  *secret,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	node.SetControlTransport(transport)
+
+	node.OnConnecting(func(ctx context.Context, e centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
+		return centrifuge.ConnectReply{
+			Credentials: &centrifuge.Credentials{UserID: e.ClientID},
+		}, nil
+	})
+
+	node.OnConnect(func(client *centrifuge.Client) {
+		client.OnSubscribe(func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
+			cb(centrifuge.SubscribeReply{}, nil)
+		})
+	})
+
+	if err := node.Run(); err != nil {
+		log.Fatal(err)
+	}
+
+	http.Handle("/connection/websocket", centrifuge.NewWebsocketHandler(node, centrifuge.WebsocketConfig{}))
+	http.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		info, err := node.Info()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("nodes: " + strconv.Itoa(len(info.Nodes)) + "\n"))
+	})
+
+	go func() {
+		if err := http.ListenAndServe(":"+strconv.Itoa(*port), nil); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	waitExitSignal(node, transport)
+	log.Println("bye!")
+}
@@ -0,0 +1,169 @@
+// Package ttlpresence contains in-memory centrifuge.PresenceManager implementation
+// which expires entries not updated during TTL. It shows how to plug custom presence
+// storage into Centrifuge.
+package ttlpresence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// DefaultTTL is a default time presence entry is considered valid after update.
+const DefaultTTL = 60 * time.Second
+
+// Config for PresenceManager.
+type Config struct {
+	// TTL is an interval how long to consider presence info valid after receiving
+	// presence update. Must be larger than centrifuge.Config.ClientPresenceUpdateInterval.
+	// Zero value means DefaultTTL.
+	TTL time.Duration
+	// SweepInterval is an interval to remove expired entries. Zero value means TTL / 2.
+	SweepInterval time.Duration
+}
+
+type entry struct {
+	info     *centrifuge.ClientInfo
+	expireAt time.Time
+}
+
+var _ centrifuge.PresenceManager = (*PresenceManager)(nil)
+var _ centrifuge.Closer = (*PresenceManager)(nil)
+
+// PresenceManager keeps presence in process memory and removes entries which
+// were not updated during TTL – so presence of connections from crashed code
+// paths never gets stuck.
+type PresenceManager struct {
+	config Config
+
+	mu       sync.RWMutex
+	channels map[string]map[string]entry
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	now       func() time.Time
+}
+
+// New creates PresenceManager and starts background sweeping of expired entries.
+// Call Close to stop it – Node calls Close automatically on Shutdown.
+func New(config Config) *PresenceManager {
+	if config.TTL == 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.SweepInterval == 0 {
+		config.SweepInterval = config.TTL / 2
+	}
+	m := &PresenceManager{
+		config:   config,
+		channels: map[string]map[string]entry{},
+		closeCh:  make(chan struct{}),
+		now:      time.Now,
+	}
+	go m.runSweep()
+	return m
+}
+
+// AddPresence - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) AddPresence(ch string, clientID string, info *centrifuge.ClientInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries, ok := m.channels[ch]
+	if !ok {
+		entries = map[string]entry{}
+		m.channels[ch] = entries
+	}
+	entries[clientID] = entry{info: info, expireAt: m.now().Add(m.config.TTL)}
+	return nil
+}
+
+// RemovePresence - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) RemovePresence(ch string, clientID string, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries, ok := m.channels[ch]
+	if !ok {
+		return nil
+	}
+	delete(entries, clientID)
+	if len(entries) == 0 {
+		delete(m.channels, ch)
+	}
+	return nil
+}
+
+// Presence - see centrifuge.PresenceManager interface description. Expired
+// entries not yet removed by sweeping are not returned.
+func (m *PresenceManager) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	now := m.now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := m.channels[ch]
+	presence := make(map[string]*centrifuge.ClientInfo, len(entries))
+	for clientID, e := range entries {
+		if now.After(e.expireAt) {
+			continue
+		}
+		presence[clientID] = e.info
+	}
+	return presence, nil
+}
+
+// PresenceStats - see centrifuge.PresenceManager interface description.
+func (m *PresenceManager) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	now := m.now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var stats centrifuge.PresenceStats
+	users := map[string]struct{}{}
+	for _, e := range m.channels[ch] {
+		if now.After(e.expireAt) {
+			continue
+		}
+		stats.NumClients++
+		if _, ok := users[e.info.UserID]; !ok {
+			users[e.info.UserID] = struct{}{}
+			stats.NumUsers++
+		}
+	}
+	return stats, nil
+}
+
+// Close stops sweeping expired entries.
+func (m *PresenceManager) Close(_ context.Context) error {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+	return nil
+}
+
+func (m *PresenceManager) runSweep() {
+	ticker := time.NewTicker(m.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.closeCh:
+			return
+		case <-ticker.C:
+			m.sweep()
+		}
+	}
+}
+
+// sweep removes expired entries and channels without entries.
+func (m *PresenceManager) sweep() {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for ch, entries := range m.channels {
+		for clientID, e := range entries {
+			if now.After(e.expireAt) {
+				delete(entries, clientID)
+			}
+		}
+		if len(entries) == 0 {
+			delete(m.channels, ch)
+		}
+	}
+}
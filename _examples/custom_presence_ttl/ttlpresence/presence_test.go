@@ -0,0 +1,84 @@
+package ttlpresence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+func TestPresenceManager(t *testing.T) {
+	m := New(Config{TTL: time.Minute, SweepInterval: time.Hour})
+	defer func() { _ = m.Close(context.Background()) }()
+
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	_ = m.AddPresence("ch", "1", &centrifuge.ClientInfo{ClientID: "1", UserID: "u1"})
+	_ = m.AddPresence("ch", "2", &centrifuge.ClientInfo{ClientID: "2", UserID: "u1"})
+	_ = m.AddPresence("ch", "3", &centrifuge.ClientInfo{ClientID: "3", UserID: "u2"})
+
+	presence, _ := m.Presence("ch")
+	if len(presence) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(presence))
+	}
+	stats, _ := m.PresenceStats("ch")
+	if stats.NumClients != 3 || stats.NumUsers != 2 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	_ = m.RemovePresence("ch", "3", "u2")
+	_ = m.RemovePresence("ch", "not_existing", "u2")
+	stats, _ = m.PresenceStats("ch")
+	if stats.NumClients != 2 || stats.NumUsers != 1 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	// Touch one entry and move time after TTL of another one.
+	now = now.Add(30 * time.Second)
+	_ = m.AddPresence("ch", "1", &centrifuge.ClientInfo{ClientID: "1", UserID: "u1"})
+	now = now.Add(45 * time.Second)
+
+	presence, _ = m.Presence("ch")
+	if len(presence) != 1 || presence["1"] == nil {
+		t.Fatalf("expected only touched entry, got %v", presence)
+	}
+
+	now = now.Add(time.Minute)
+	m.sweep()
+	if len(m.channels) != 0 {
+		t.Fatalf("expected empty state after sweep, got %d channels", len(m.channels))
+	}
+	presence, _ = m.Presence("ch")
+	if len(presence) != 0 {
+		t.Fatalf("expected empty presence, got %v", presence)
+	}
+}
+
+func TestPresenceManagerWithNode(t *testing.T) {
+	node, _ := centrifuge.New(centrifuge.Config{})
+	m := New(Config{})
+	node.SetPresenceManager(m)
+	if err := node.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_ = m.AddPresence("ch", "1", &centrifuge.ClientInfo{ClientID: "1", UserID: "u1"})
+	result, err := node.Presence("ch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Presence) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(result.Presence))
+	}
+	// Node falls back to in-memory pagination as PresencePager not implemented.
+	page, err := node.PresencePage("ch", centrifuge.WithPresenceLimit(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Presence) != 1 || page.Cursor != "" {
+		t.Fatalf("unexpected page: %#v", page)
+	}
+}
@@ -0,0 +1,87 @@
+package centrifuge
+
+import "sync"
+
+// BroadcastOverflowPolicy defines behaviour when broadcast worker pool queue is full.
+type BroadcastOverflowPolicy int
+
+const (
+	// BroadcastOverflowInline delivers chunk of subscribers in the goroutine which
+	// broadcasts publication when worker pool queue is full.
+	BroadcastOverflowInline BroadcastOverflowPolicy = iota
+	// BroadcastOverflowBlock waits for free space in worker pool queue.
+	BroadcastOverflowBlock
+)
+
+const (
+	defaultBroadcastWorkerQueueSize = 1024
+	defaultBroadcastChunkSize       = 512
+)
+
+// broadcastWorkerPool used to deliver publications to subscribers of large channels
+// in parallel.
+type broadcastWorkerPool struct {
+	chunkSize int
+	policy    BroadcastOverflowPolicy
+	mu        sync.RWMutex
+	closed    bool
+	tasks     chan func()
+}
+
+func newBroadcastWorkerPool(numWorkers int, queueSize int, chunkSize int, policy BroadcastOverflowPolicy) *broadcastWorkerPool {
+	if queueSize <= 0 {
+		queueSize = defaultBroadcastWorkerQueueSize
+	}
+	if chunkSize <= 0 {
+		chunkSize = defaultBroadcastChunkSize
+	}
+	p := &broadcastWorkerPool{
+		chunkSize: chunkSize,
+		policy:    policy,
+		tasks:     make(chan func(), queueSize),
+	}
+	for i := 0; i < numWorkers; i++ {
+		go p.runWorker()
+	}
+	return p
+}
+
+func (p *broadcastWorkerPool) runWorker() {
+	for task := range p.tasks {
+		task()
+	}
+}
+
+// submit runs task over worker pool. Task is executed in the calling goroutine
+// when pool is closed or when queue is full and BroadcastOverflowInline policy used.
+func (p *broadcastWorkerPool) submit(task func()) {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		task()
+		return
+	}
+	if p.policy == BroadcastOverflowBlock {
+		p.tasks <- task
+		p.mu.RUnlock()
+		return
+	}
+	select {
+	case p.tasks <- task:
+		p.mu.RUnlock()
+	default:
+		p.mu.RUnlock()
+		task()
+	}
+}
+
+// close stops workers after all queued tasks processed.
+func (p *broadcastWorkerPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+}
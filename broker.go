@@ -21,6 +21,9 @@ type Publication struct {
 	// we use it for calculating PUB/SUB time lag, it's not exposed to the client
 	// protocol.
 	Time int64
+
+	// delivery options of Publication set from PublishOptions.
+	delivery deliveryOptions
 }
 
 // ClientInfo contains information about client connection.
@@ -51,13 +54,16 @@ type BrokerEventHandler interface {
 // HistoryFilter allows filtering history according to fields set.
 type HistoryFilter struct {
 	// Since used to extract publications from stream since provided StreamPosition.
+	// Publications with offset greater than Since.Offset are returned in direct
+	// order, publications with offset less than Since.Offset – in reverse order
+	// (i.e. Reverse and Since allow loading last N publications before offset).
 	Since *StreamPosition
 	// Limit number of publications to return.
 	// -1 means no limit - i.e. return all publications currently in stream.
 	// 0 means that caller only interested in current stream top position so
 	// Broker should not return any publications.
 	Limit int
-	// Reverse direction.
+	// Reverse direction. Publications are returned from newest to oldest.
 	Reverse bool
 }
 
@@ -118,8 +124,53 @@ type PublishOptions struct {
 	IdempotentResultTTL time.Duration
 	// UseDelta enables using delta encoding for the publication.
 	UseDelta bool
+	// Priority is a delivery priority hint for the publication. Connection writer sends
+	// publications with PublicationPriorityHigh before queued publications of other
+	// channels – never before replies, other pushes or earlier publications of the same
+	// channel. Publications with PublicationPriorityLow are dropped from connection queue
+	// when it overflows (instead of disconnecting slow client). Since priorities reorder
+	// or drop messages in connection queue they can't be used together with history –
+	// Node.Publish returns ErrorBadRequest in this case (also when history is enabled by
+	// channel namespace).
+	Priority PublicationPriority
+	// FreshnessTTL if set makes publication stale after sitting in connection queue
+	// longer than FreshnessTTL. Stale publications are silently dropped instead of
+	// being sent to a slow client. Useful for data like live cursors or telemetry where
+	// old values are worthless. Like Priority, this can't be used together with history.
+	// Millisecond precision.
+	FreshnessTTL time.Duration
+	// CompressionThreshold if set enables compression of publication data with size
+	// not less than CompressionThreshold bytes for subscribers which negotiated
+	// compression (see SubscribeOptions.Compression). Compression is applied at the
+	// protocol level so works for all transports. Only applied to real-time
+	// publications – not to publications sent to clients upon recovery.
+	//
+	// Priority, FreshnessTTL and CompressionThreshold are not part of Publication – they
+	// are passed between nodes by built-in brokers (MemoryBroker, RedisBroker, GossipBroker)
+	// and never saved to history. Publications coming from other Broker implementations
+	// are delivered with default options.
+	CompressionThreshold int
+
+	// ctx of publish operation, passed to ContextBroker.PublishContext. May be nil
+	// if PublishOptions constructed by PublishMiddleware.
+	ctx context.Context
+	// withoutHistory is set by WithoutHistory to skip namespace history defaults.
+	withoutHistory bool
 }
 
+// PublicationPriority is a delivery priority hint for Publication.
+type PublicationPriority int8
+
+const (
+	// PublicationPriorityLow is for bulk data which may be dropped under pressure.
+	PublicationPriorityLow PublicationPriority = -1
+	// PublicationPriorityNormal is a default priority.
+	PublicationPriorityNormal PublicationPriority = 0
+	// PublicationPriorityHigh is for control/system messages which should overtake
+	// publications of other channels.
+	PublicationPriorityHigh PublicationPriority = 1
+)
+
 // Broker is responsible for PUB/SUB mechanics.
 type Broker interface {
 	// Run called once on start when broker already set to node. At
@@ -171,3 +222,15 @@ type Broker interface {
 	// but sometimes can be useful for application logic.
 	RemoveHistory(ch string) error
 }
+
+// ContextBroker is an optional Broker interface with context-aware methods. Node uses
+// it in Node.PublishContext, Node.HistoryContext and Node.RemoveHistoryContext, so
+// context cancellation and deadline are applied to in-flight Broker operations.
+type ContextBroker interface {
+	// PublishContext is the same as Broker.Publish but respects ctx.
+	PublishContext(ctx context.Context, ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error)
+	// HistoryContext is the same as Broker.History but respects ctx.
+	HistoryContext(ctx context.Context, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error)
+	// RemoveHistoryContext is the same as Broker.RemoveHistory but respects ctx.
+	RemoveHistoryContext(ctx context.Context, ch string) error
+}
@@ -0,0 +1,388 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/segmentio/encoding/json"
+)
+
+// GossipBrokerConfig is a config for GossipBroker.
+type GossipBrokerConfig struct {
+	// BindAddr is an address to listen for connections from other nodes, for
+	// example "0.0.0.0:7946". Required.
+	BindAddr string
+	// AdvertiseAddr is an address other nodes use to connect to this node. By
+	// default, address of listener is used, which is only correct when BindAddr
+	// contains routable host.
+	AdvertiseAddr string
+	// Seeds is a list of addresses of nodes to join cluster over. Other nodes are
+	// discovered over gossip. Seeds are redialed all the time, so it's fine to have
+	// node itself or not yet started nodes in the list.
+	Seeds []string
+	// GossipInterval is an interval to exchange member lists with other nodes.
+	// Zero value means 1 second.
+	GossipInterval time.Duration
+	// PeerTimeout is a time after which node is removed from member list if nothing
+	// was received from it. Zero value means 10 seconds.
+	PeerTimeout time.Duration
+	// TLS if set is used to protect connections between nodes with mutual TLS.
+	TLS *MutualTLS
+	// SharedSecret if set is used to authenticate connections between nodes when
+	// TLS is not used. All nodes must use the same secret. Either TLS or SharedSecret
+	// must be set.
+	SharedSecret string
+}
+
+// GossipBroker is a Broker which allows running several Centrifuge nodes without
+// external broker. Nodes discover each other over gossip (starting with Seeds) and
+// exchange publications, join/leave messages and control commands directly over TCP
+// connections.
+//
+// GossipBroker is suitable for PUB/SUB-only workloads. History is kept in memory of
+// the node publication was published on (see MemoryBroker), publications are sent to
+// other nodes without stream position – so positioning and recovery must not be used
+// with GossipBroker. Delivery is at most once: messages are dropped if peer connection
+// is not established or overflowed – drops are counted in mesh_dropped_frames_count
+// metric.
+type GossipBroker struct {
+	node         *Node
+	config       GossipBrokerConfig
+	memory       *MemoryBroker
+	eventHandler BrokerEventHandler
+	auth         meshAuth
+	drops        *meshDrops
+	server       *meshServer
+	addr         string
+
+	mu      sync.RWMutex
+	members map[string]*gossipMember // Node ID -> member.
+	peers   map[string]*meshPeer     // Address -> outgoing connection.
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+var _ Broker = (*GossipBroker)(nil)
+
+type gossipMember struct {
+	ID       string `json:"id"`
+	Addr     string `json:"addr"`
+	lastSeen time.Time
+	// direct is true when member was heard from directly, not over gossip of
+	// another node. Only such members are gossiped further.
+	direct bool
+}
+
+// gossipMessage is exchanged between nodes every GossipInterval.
+type gossipMessage struct {
+	ID      string          `json:"id"`
+	Addr    string          `json:"addr"`
+	Members []*gossipMember `json:"members,omitempty"`
+}
+
+const defaultGossipPeerTimeout = 10 * time.Second
+
+var errGossipUnknownNode = errors.New("gossip: unknown node")
+
+// NewGossipBroker creates GossipBroker and starts listening on BindAddr.
+func NewGossipBroker(n *Node, config GossipBrokerConfig) (*GossipBroker, error) {
+	if config.BindAddr == "" {
+		return nil, errors.New("gossip: BindAddr required")
+	}
+	auth, err := newMeshAuth(config.TLS, config.SharedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("gossip: %w", err)
+	}
+	if config.GossipInterval == 0 {
+		config.GossipInterval = time.Second
+	}
+	if config.PeerTimeout == 0 {
+		config.PeerTimeout = defaultGossipPeerTimeout
+	}
+	memory, err := NewMemoryBroker(n, MemoryBrokerConfig{})
+	if err != nil {
+		return nil, err
+	}
+	listener, err := listenMesh(config.BindAddr, auth)
+	if err != nil {
+		return nil, fmt.Errorf("gossip: %w", err)
+	}
+	addr := config.AdvertiseAddr
+	if addr == "" {
+		addr = listener.Addr().String()
+	}
+	b := &GossipBroker{
+		node:    n,
+		config:  config,
+		memory:  memory,
+		auth:    auth,
+		drops:   newMeshDrops(n, "gossip"),
+		addr:    addr,
+		members: map[string]*gossipMember{},
+		peers:   map[string]*meshPeer{},
+		closeCh: make(chan struct{}),
+	}
+	b.server = newMeshServer(n, listener, auth, b.handleFrame)
+	return b, nil
+}
+
+// Addr returns address other nodes use to connect to this node.
+func (b *GossipBroker) Addr() string {
+	return b.addr
+}
+
+// Members returns IDs of other nodes currently known to this node.
+func (b *GossipBroker) Members() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ids := make([]string, 0, len(b.members))
+	for id := range b.members {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Run runs broker – see Broker interface description.
+func (b *GossipBroker) Run(h BrokerEventHandler) error {
+	b.eventHandler = h
+	if err := b.memory.Run(h); err != nil {
+		return err
+	}
+	go b.server.serve()
+	go b.gossipLoop()
+	return nil
+}
+
+// Close stops listening and closes connections to other nodes.
+func (b *GossipBroker) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		close(b.closeCh)
+		b.server.close()
+		b.mu.Lock()
+		for _, p := range b.peers {
+			p.close()
+		}
+		b.mu.Unlock()
+	})
+	return b.memory.Close(ctx)
+}
+
+func (b *GossipBroker) handleFrame(frameType byte, payload []byte) error {
+	switch frameType {
+	case meshFrameGossip:
+		var msg gossipMessage
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+		b.handleGossip(msg)
+		return nil
+	case meshFrameControl:
+		return b.eventHandler.HandleControl(payload)
+	case meshFramePush:
+		var push protocol.Push
+		if err := push.UnmarshalVT(payload); err != nil {
+			return err
+		}
+		switch {
+		case push.Pub != nil:
+			return b.eventHandler.HandlePublication(push.Channel, pubFromProtoWithDelivery(push.Pub), StreamPosition{}, false, nil)
+		case push.Join != nil:
+			return b.eventHandler.HandleJoin(push.Channel, infoFromProto(push.Join.Info))
+		case push.Leave != nil:
+			return b.eventHandler.HandleLeave(push.Channel, infoFromProto(push.Leave.Info))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mesh frame type: %d", frameType)
+	}
+}
+
+// handleGossip refreshes sender and adds newly discovered members. Members known
+// indirectly are not refreshed and not gossiped further – so nodes which left are
+// removed from all member lists after PeerTimeout.
+func (b *GossipBroker) handleGossip(msg gossipMessage) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if msg.ID != b.node.ID() {
+		b.members[msg.ID] = &gossipMember{ID: msg.ID, Addr: msg.Addr, lastSeen: now, direct: true}
+		b.ensurePeerLocked(msg.Addr)
+	}
+	for _, m := range msg.Members {
+		if m.ID == b.node.ID() {
+			continue
+		}
+		if _, ok := b.members[m.ID]; !ok {
+			b.members[m.ID] = &gossipMember{ID: m.ID, Addr: m.Addr, lastSeen: now}
+			b.ensurePeerLocked(m.Addr)
+		}
+	}
+}
+
+// ensurePeerLocked starts connecting to address if not connected yet. Messages
+// sent to peer are queued till connection established.
+func (b *GossipBroker) ensurePeerLocked(addr string) {
+	if addr == b.addr {
+		return
+	}
+	if _, ok := b.peers[addr]; !ok {
+		p := newMeshPeer(b.node, addr, b.auth, b.config.GossipInterval, nil)
+		b.peers[addr] = p
+		go p.run()
+	}
+}
+
+func (b *GossipBroker) gossipLoop() {
+	ticker := time.NewTicker(b.config.GossipInterval)
+	defer ticker.Stop()
+	for {
+		b.gossip()
+		select {
+		case <-b.closeCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// gossip removes outdated members, maintains connections to members and seeds
+// and sends member list to all peers.
+func (b *GossipBroker) gossip() {
+	now := time.Now()
+	msg := gossipMessage{ID: b.node.ID(), Addr: b.addr}
+
+	b.mu.Lock()
+	addrs := make(map[string]struct{}, len(b.members)+len(b.config.Seeds))
+	for _, seed := range b.config.Seeds {
+		addrs[seed] = struct{}{}
+	}
+	for id, m := range b.members {
+		if now.Sub(m.lastSeen) > b.config.PeerTimeout {
+			delete(b.members, id)
+			continue
+		}
+		addrs[m.Addr] = struct{}{}
+		if m.direct {
+			msg.Members = append(msg.Members, m)
+		}
+	}
+	delete(addrs, b.addr)
+	for addr, p := range b.peers {
+		if _, ok := addrs[addr]; !ok {
+			p.close()
+			delete(b.peers, addr)
+		}
+	}
+	for addr := range addrs {
+		b.ensurePeerLocked(addr)
+	}
+	data, err := json.Marshal(msg)
+	if err == nil {
+		for addr, p := range b.peers {
+			if !p.send(meshFrameGossip, data) {
+				b.drops.dropped(meshDropReasonQueueFull, addr)
+			}
+		}
+	}
+	b.mu.Unlock()
+}
+
+// broadcast sends frame to all peers.
+func (b *GossipBroker) broadcast(frameType byte, data []byte) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for addr, p := range b.peers {
+		if !p.send(frameType, data) {
+			b.drops.dropped(meshDropReasonQueueFull, addr)
+		}
+	}
+}
+
+func (b *GossipBroker) broadcastPush(push *protocol.Push) error {
+	data, err := push.MarshalVT()
+	if err != nil {
+		return err
+	}
+	b.broadcast(meshFramePush, data)
+	return nil
+}
+
+// Publish - see Broker interface description.
+func (b *GossipBroker) Publish(ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
+	sp, fromCache, err := b.memory.Publish(ch, data, opts)
+	if err != nil || fromCache {
+		return sp, fromCache, err
+	}
+	pub := pubToProto(&Publication{Data: data, Info: opts.ClientInfo, Tags: opts.Tags})
+	pub.Time = time.Now().UnixMilli()
+	setProtoDelivery(pub, deliveryFromPublishOptions(opts))
+	return sp, false, b.broadcastPush(&protocol.Push{Channel: ch, Pub: pub})
+}
+
+// PublishJoin - see Broker interface description.
+func (b *GossipBroker) PublishJoin(ch string, info *ClientInfo) error {
+	if err := b.memory.PublishJoin(ch, info); err != nil {
+		return err
+	}
+	return b.broadcastPush(&protocol.Push{Channel: ch, Join: &protocol.Join{Info: infoToProto(info)}})
+}
+
+// PublishLeave - see Broker interface description.
+func (b *GossipBroker) PublishLeave(ch string, info *ClientInfo) error {
+	if err := b.memory.PublishLeave(ch, info); err != nil {
+		return err
+	}
+	return b.broadcastPush(&protocol.Push{Channel: ch, Leave: &protocol.Leave{Info: infoToProto(info)}})
+}
+
+// PublishControl - see Broker interface description.
+func (b *GossipBroker) PublishControl(data []byte, nodeID, _ string) error {
+	if nodeID == b.node.ID() {
+		return b.eventHandler.HandleControl(data)
+	}
+	if nodeID == "" {
+		b.broadcast(meshFrameControl, data)
+		return b.eventHandler.HandleControl(data)
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	m, ok := b.members[nodeID]
+	if !ok {
+		b.drops.dropped(meshDropReasonUnknownNode, nodeID)
+		return errGossipUnknownNode
+	}
+	p, ok := b.peers[m.Addr]
+	if !ok {
+		b.drops.dropped(meshDropReasonUnknownNode, nodeID)
+		return errGossipUnknownNode
+	}
+	if !p.send(meshFrameControl, data) {
+		b.drops.dropped(meshDropReasonQueueFull, m.Addr)
+	}
+	return nil
+}
+
+// Subscribe is noop here – messages are sent to all nodes.
+func (b *GossipBroker) Subscribe(_ string) error {
+	return nil
+}
+
+// Unsubscribe is noop here – messages are sent to all nodes.
+func (b *GossipBroker) Unsubscribe(_ string) error {
+	return nil
+}
+
+// History - see Broker interface description. History is local to node.
+func (b *GossipBroker) History(ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
+	return b.memory.History(ch, opts)
+}
+
+// RemoveHistory - see Broker interface description. History is local to node.
+func (b *GossipBroker) RemoveHistory(ch string) error {
+	return b.memory.RemoveHistory(ch)
+}
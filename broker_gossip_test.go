@@ -0,0 +1,151 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testGossipNode struct {
+	broker        *GossipBroker
+	publications  chan *Publication
+	joins         chan *ClientInfo
+	controls      chan []byte
+	controlsCount int
+}
+
+func newTestGossipNode(t *testing.T, seeds ...string) *testGossipNode {
+	node, err := New(Config{LogLevel: LogLevelError})
+	require.NoError(t, err)
+	broker, err := NewGossipBroker(node, GossipBrokerConfig{
+		BindAddr:       "127.0.0.1:0",
+		Seeds:          seeds,
+		GossipInterval: 50 * time.Millisecond,
+		PeerTimeout:    500 * time.Millisecond,
+		SharedSecret:   "secret",
+	})
+	require.NoError(t, err)
+	n := &testGossipNode{
+		broker:       broker,
+		publications: make(chan *Publication, 16),
+		joins:        make(chan *ClientInfo, 16),
+		controls:     make(chan []byte, 16),
+	}
+	require.NoError(t, broker.Run(&testBrokerEventHandler{
+		HandlePublicationFunc: func(ch string, pub *Publication, sp StreamPosition, delta bool, prevPub *Publication) error {
+			n.publications <- pub
+			return nil
+		},
+		HandleJoinFunc: func(ch string, info *ClientInfo) error {
+			n.joins <- info
+			return nil
+		},
+		HandleControlFunc: func(data []byte) error {
+			n.controls <- data
+			return nil
+		},
+	}))
+	t.Cleanup(func() { _ = broker.Close(context.Background()) })
+	return n
+}
+
+func waitGossipMembers(t *testing.T, num int, nodes ...*testGossipNode) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		for _, n := range nodes {
+			if len(n.broker.Members()) != num {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func waitGossipValue[T any](t *testing.T, ch chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for gossip message")
+	}
+	var zero T
+	return zero
+}
+
+func TestGossipBroker(t *testing.T) {
+	_, err := NewGossipBroker(defaultNodeNoHandlers(), GossipBrokerConfig{})
+	require.Error(t, err)
+	_, err = NewGossipBroker(defaultNodeNoHandlers(), GossipBrokerConfig{BindAddr: "127.0.0.1:0"})
+	require.ErrorIs(t, err, errMeshAuthRequired)
+
+	n1 := newTestGossipNode(t)
+	// Unavailable seeds are fine.
+	n2 := newTestGossipNode(t, n1.broker.Addr(), "127.0.0.1:1")
+	n3 := newTestGossipNode(t, n1.broker.Addr(), n1.broker.Addr())
+	// n2 and n3 discover each other over n1.
+	waitGossipMembers(t, 2, n1, n2, n3)
+
+	_, _, err = n3.broker.Publish("test", []byte(`{}`), PublishOptions{Tags: map[string]string{"k": "v"}})
+	require.NoError(t, err)
+	for _, n := range []*testGossipNode{n1, n2, n3} {
+		pub := waitGossipValue(t, n.publications)
+		require.Equal(t, []byte(`{}`), pub.Data)
+		require.Equal(t, map[string]string{"k": "v"}, pub.Tags)
+	}
+
+	require.NoError(t, n1.broker.PublishJoin("test", &ClientInfo{ClientID: "c", UserID: "u"}))
+	for _, n := range []*testGossipNode{n1, n2, n3} {
+		require.Equal(t, "u", waitGossipValue(t, n.joins).UserID)
+	}
+
+	// Control to all nodes.
+	require.NoError(t, n1.broker.PublishControl([]byte("all"), "", ""))
+	for _, n := range []*testGossipNode{n1, n2, n3} {
+		require.Equal(t, []byte("all"), waitGossipValue(t, n.controls))
+	}
+	// Control to specific node.
+	require.NoError(t, n1.broker.PublishControl([]byte("n2"), n2.broker.node.ID(), ""))
+	require.Equal(t, []byte("n2"), waitGossipValue(t, n2.controls))
+	require.ErrorIs(t, n1.broker.PublishControl([]byte("x"), "unknown", ""), errGossipUnknownNode)
+
+	// Stopped node removed from members.
+	require.NoError(t, n3.broker.Close(context.Background()))
+	waitGossipMembers(t, 1, n1, n2)
+
+	select {
+	case <-n1.controls:
+		require.Fail(t, "unexpected control message")
+	default:
+	}
+}
+
+func TestGossipBroker_NodeCluster(t *testing.T) {
+	newNode := func(seeds ...string) (*Node, *GossipBroker) {
+		node, err := New(Config{LogLevel: LogLevelError})
+		require.NoError(t, err)
+		broker, err := NewGossipBroker(node, GossipBrokerConfig{
+			BindAddr:       "127.0.0.1:0",
+			Seeds:          seeds,
+			GossipInterval: 50 * time.Millisecond,
+			SharedSecret:   "secret",
+		})
+		require.NoError(t, err)
+		node.SetBroker(broker)
+		require.NoError(t, node.Run())
+		t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+		return node, broker
+	}
+	node1, broker1 := newNode()
+	node2, _ := newNode(broker1.Addr())
+
+	require.Eventually(t, func() bool {
+		info1, err := node1.Info()
+		require.NoError(t, err)
+		info2, err := node2.Info()
+		require.NoError(t, err)
+		return len(info1.Nodes) == 2 && len(info2.Nodes) == 2
+	}, 10*time.Second, 50*time.Millisecond)
+}
@@ -21,6 +21,12 @@ import (
 // Running single node can be sufficient for many use cases especially when you
 // need maximum performance and not too many online clients. Consider configuring
 // your load balancer to have one backup Centrifuge node for HA in this case.
+//
+// Channel history streams have the same offset and epoch semantics as in RedisBroker:
+// offsets are monotonic per channel and survive history expiration and removal, new
+// epoch is only generated when stream meta information expires (see HistoryMetaTTL)
+// or when Node restarts – so recovery and positioning work the same way as in
+// production setups with RedisBroker.
 type MemoryBroker struct {
 	node         *Node
 	historyHub   *historyHub
@@ -39,9 +45,13 @@ type MemoryBroker struct {
 	resultExpireQueue priority.Queue
 	resultCache       map[string]StreamPosition
 	resultCacheMu     sync.RWMutex
+
+	patternsMu sync.RWMutex
+	patterns   map[string]struct{}
 }
 
 var _ Broker = (*MemoryBroker)(nil)
+var _ PatternSubscriber = (*MemoryBroker)(nil)
 
 // MemoryBrokerConfig is a memory broker config.
 type MemoryBrokerConfig struct{}
@@ -63,6 +73,7 @@ func NewMemoryBroker(n *Node, _ MemoryBrokerConfig) (*MemoryBroker, error) {
 		pubLocks:    pubLocks,
 		closeCh:     closeCh,
 		resultCache: map[string]StreamPosition{},
+		patterns:    map[string]struct{}{},
 	}
 	return b, nil
 }
@@ -106,6 +117,7 @@ func (b *MemoryBroker) Publish(ch string, data []byte, opts PublishOptions) (Str
 		Tags: opts.Tags,
 		Time: time.Now().UnixMilli(),
 	}
+	delivery := deliveryFromPublishOptions(opts)
 	var prevPub *Publication
 	if opts.HistorySize > 0 && opts.HistoryTTL > 0 {
 		var err error
@@ -122,7 +134,10 @@ func (b *MemoryBroker) Publish(ch string, data []byte, opts PublishOptions) (Str
 			}
 			b.saveResultToCache(ch, opts.IdempotencyKey, streamTop, resultExpireSeconds)
 		}
-		return streamTop, false, b.eventHandler.HandlePublication(ch, pub, streamTop, opts.UseDelta, prevPub)
+		pub = pubWithDelivery(pub, delivery)
+		err = b.eventHandler.HandlePublication(ch, pub, streamTop, opts.UseDelta, prevPub)
+		b.handlePatterns(ch, pub)
+		return streamTop, false, err
 	}
 	streamPosition := StreamPosition{}
 	if opts.IdempotencyKey != "" {
@@ -132,7 +147,37 @@ func (b *MemoryBroker) Publish(ch string, data []byte, opts PublishOptions) (Str
 		}
 		b.saveResultToCache(ch, opts.IdempotencyKey, streamPosition, resultExpireSeconds)
 	}
-	return streamPosition, false, b.eventHandler.HandlePublication(ch, pub, StreamPosition{}, opts.UseDelta, prevPub)
+	pub = pubWithDelivery(pub, delivery)
+	err := b.eventHandler.HandlePublication(ch, pub, StreamPosition{}, opts.UseDelta, prevPub)
+	b.handlePatterns(ch, pub)
+	return streamPosition, false, err
+}
+
+// handlePatterns delivers publication to patterns matching channel.
+func (b *MemoryBroker) handlePatterns(ch string, pub *Publication) {
+	b.patternsMu.RLock()
+	defer b.patternsMu.RUnlock()
+	for pattern := range b.patterns {
+		if MatchWildcard(pattern, ch) {
+			_ = b.eventHandler.HandlePublication(pattern, WildcardPublication(pub, ch), StreamPosition{}, false, nil)
+		}
+	}
+}
+
+// SubscribePattern - see PatternSubscriber.SubscribePattern.
+func (b *MemoryBroker) SubscribePattern(pattern string) error {
+	b.patternsMu.Lock()
+	defer b.patternsMu.Unlock()
+	b.patterns[pattern] = struct{}{}
+	return nil
+}
+
+// UnsubscribePattern - see PatternSubscriber.UnsubscribePattern.
+func (b *MemoryBroker) UnsubscribePattern(pattern string) error {
+	b.patternsMu.Lock()
+	defer b.patternsMu.Unlock()
+	delete(b.patterns, pattern)
+	return nil
 }
 
 func (b *MemoryBroker) getResultFromCache(ch string, key string) (StreamPosition, bool) {
@@ -340,6 +340,84 @@ func TestMemoryHistoryHubMetaTTL(t *testing.T) {
 	h.RUnlock()
 }
 
+func TestMemoryBrokerStreamPosition(t *testing.T) {
+	e := testMemoryBroker()
+	defer func() { _ = e.node.Shutdown(context.Background()) }()
+
+	const ch = "channel"
+	opts := PublishOptions{HistorySize: 2, HistoryTTL: time.Second}
+
+	sp, _, err := e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), sp.Offset)
+	require.NotEmpty(t, sp.Epoch)
+	epoch := sp.Epoch
+
+	// Offsets are per channel.
+	other, _, err := e.Publish("other", testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), other.Offset)
+
+	sp, _, err = e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, StreamPosition{Offset: 2, Epoch: epoch}, sp)
+
+	// Stream position survives history removal.
+	require.NoError(t, e.RemoveHistory(ch))
+	pubs, sp, err := e.History(ch, HistoryOptions{Filter: HistoryFilter{Limit: -1}})
+	require.NoError(t, err)
+	require.Empty(t, pubs)
+	require.Equal(t, StreamPosition{Offset: 2, Epoch: epoch}, sp)
+
+	// And history expiration.
+	sp, _, err = e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, StreamPosition{Offset: 3, Epoch: epoch}, sp)
+	time.Sleep(2 * time.Second)
+	pubs, sp, err = e.History(ch, HistoryOptions{Filter: HistoryFilter{Limit: -1}})
+	require.NoError(t, err)
+	require.Empty(t, pubs)
+	require.Equal(t, StreamPosition{Offset: 3, Epoch: epoch}, sp)
+
+	sp, _, err = e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, StreamPosition{Offset: 4, Epoch: epoch}, sp)
+
+	// Reverse history since position from another epoch starts from stream top.
+	pubs, _, err = e.History(ch, HistoryOptions{Filter: HistoryFilter{
+		Since:   &StreamPosition{Offset: 100, Epoch: "another"},
+		Limit:   10,
+		Reverse: true,
+	}})
+	require.NoError(t, err)
+	require.Len(t, pubs, 1)
+	require.Equal(t, uint64(4), pubs[0].Offset)
+}
+
+func TestMemoryBrokerNewEpochOnMetaExpiration(t *testing.T) {
+	e := testMemoryBroker()
+	defer func() { _ = e.node.Shutdown(context.Background()) }()
+
+	const ch = "channel"
+	opts := PublishOptions{HistorySize: 2, HistoryTTL: time.Second, HistoryMetaTTL: time.Second}
+	sp, _, err := e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), sp.Offset)
+	epoch := sp.Epoch
+
+	time.Sleep(2500 * time.Millisecond)
+
+	_, sp, err = e.History(ch, HistoryOptions{MetaTTL: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), sp.Offset)
+	require.NotEqual(t, epoch, sp.Epoch)
+	newEpoch := sp.Epoch
+
+	sp, _, err = e.Publish(ch, testPublicationData(), opts)
+	require.NoError(t, err)
+	require.Equal(t, StreamPosition{Offset: 1, Epoch: newEpoch}, sp)
+}
+
 func TestMemoryHistoryHubMetaTTLPerChannel(t *testing.T) {
 	h := newHistoryHub(300*time.Second, make(chan struct{}))
 	h.runCleanups()
@@ -757,6 +835,36 @@ func TestMemoryBrokerHistoryIterationReverse(t *testing.T) {
 	it.testHistoryIterationReverse(t, e.node, startPosition)
 }
 
+func TestMemoryBrokerHistoryBeforeOffset(t *testing.T) {
+	e := testMemoryBroker()
+	defer func() { _ = e.node.Shutdown(context.Background()) }()
+
+	var sp StreamPosition
+	for i := 0; i < 10; i++ {
+		res, err := e.node.Publish(historyIterationChannel, []byte(`{}`), WithHistory(10, time.Hour))
+		require.NoError(t, err)
+		sp = res.StreamPosition
+	}
+
+	res, err := e.node.History(historyIterationChannel,
+		WithSince(&StreamPosition{Offset: 6, Epoch: sp.Epoch}), WithLimit(3), WithReverse(true))
+	require.NoError(t, err)
+	require.Equal(t, sp, res.StreamPosition)
+	require.Len(t, res.Publications, 3)
+	for i, offset := range []uint64{5, 4, 3} {
+		require.Equal(t, offset, res.Publications[i].Offset)
+	}
+
+	res, err = e.node.History(historyIterationChannel,
+		WithSince(&StreamPosition{Offset: 1, Epoch: sp.Epoch}), WithLimit(3), WithReverse(true))
+	require.NoError(t, err)
+	require.Empty(t, res.Publications)
+
+	_, err = e.node.History(historyIterationChannel,
+		WithSince(&StreamPosition{Offset: 0, Epoch: sp.Epoch}), WithLimit(3), WithReverse(true))
+	require.ErrorIs(t, err, ErrorBadRequest)
+}
+
 func BenchmarkMemoryBrokerHistoryIteration(b *testing.B) {
 	e := testMemoryBroker()
 	defer func() { _ = e.node.Shutdown(context.Background()) }()
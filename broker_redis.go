@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	_ "embed"
 
 	"github.com/centrifugal/centrifuge/internal/convert"
+	"github.com/centrifugal/centrifuge/internal/timers"
 
 	"github.com/centrifugal/protocol"
 	"github.com/redis/rueidis"
@@ -56,6 +58,8 @@ type shardWrapper struct {
 	subClients          [][]rueidis.DedicatedClient
 	pubSubStartChannels [][]*pubSubStart
 	controlPubSubStart  *controlPubSubStart
+	subBatchersMu       sync.Mutex
+	subBatchers         map[subBatchKey]chan subBatchRequest
 }
 
 // RedisBroker uses Redis to implement Broker functionality. This broker allows
@@ -79,6 +83,7 @@ type RedisBroker struct {
 	addHistoryStreamScript  *rueidis.Lua
 	shardChannel            string
 	messagePrefix           string
+	payloadCipher           *payloadCipher
 	controlChannel          string
 	nodeChannel             string
 	closeOnce               sync.Once
@@ -107,6 +112,22 @@ type RedisBrokerConfig struct {
 	// publishing to channels and using PUB/SUB.
 	SkipPubSub bool
 
+	// SubscribeBatchDelay when set enables batching of channel SUBSCRIBE/UNSUBSCRIBE
+	// commands. Commands issued during delay window (up to 512 channels) are sent to Redis
+	// together over a single round trip. This helps to reduce load on Redis PUB/SUB
+	// connections during mass reconnects when many channels are subscribed at the same time,
+	// at the cost of adding up to SubscribeBatchDelay latency to subscribe. Usually a few
+	// milliseconds is enough. Zero value means no batching.
+	SubscribeBatchDelay time.Duration
+
+	// PayloadEncryptionKeys when set enable at-rest encryption of publication payloads
+	// kept in history. Payload is encrypted with the first key before being written to
+	// Redis and decrypted on read (and when received from PUB/SUB) with the key it was
+	// encrypted with. To rotate keys put a new key first and keep previous keys in the
+	// slice until history encrypted with them expires. Payloads written before encryption
+	// was enabled are returned as is. Publications without history are not encrypted.
+	PayloadEncryptionKeys []PayloadEncryptionKey
+
 	// numPubSubShards defines how many PUB/SUB shards will be used by Centrifuge.
 	// Each PUB/SUB shard uses dedicated connection to Redis. Zero value means 1.
 	numPubSubShards int
@@ -154,6 +175,11 @@ func NewRedisBroker(n *Node, config RedisBrokerConfig) (*RedisBroker, error) {
 		config.Prefix = "centrifuge"
 	}
 
+	payloadCipher, err := newPayloadCipher(config.PayloadEncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("broker: %w", err)
+	}
+
 	if config.numPubSubShards == 0 {
 		config.numPubSubShards = 1
 	}
@@ -187,6 +213,7 @@ func NewRedisBroker(n *Node, config RedisBrokerConfig) (*RedisBroker, error) {
 		historyListScript:       rueidis.NewLuaScript(historyListSource),
 		addHistoryStreamScript:  rueidis.NewLuaScript(addHistoryStreamSource),
 		addHistoryListScript:    rueidis.NewLuaScript(addHistoryListSource),
+		payloadCipher:           payloadCipher,
 		closeCh:                 make(chan struct{}),
 	}
 	b.shardChannel = config.Prefix + redisPubSubShardChannelSuffix
@@ -361,6 +388,17 @@ func (b *RedisBroker) Close(_ context.Context) error {
 	return nil
 }
 
+// CheckHealth pings all Redis shards. See HealthChecker.
+func (b *RedisBroker) CheckHealth(ctx context.Context) error {
+	for _, wrapper := range b.shards {
+		s := wrapper.shard
+		if err := s.ping(ctx); err != nil {
+			return fmt.Errorf("error ping Redis shard %s: %w", s.string(), err)
+		}
+	}
+	return nil
+}
+
 func (b *RedisBroker) runControlPubSub(s *RedisShard, eventHandler BrokerEventHandler, startOnce func(error)) {
 	b.node.Log(NewLogEntry(LogLevelDebug, "running Redis control PUB/SUB", map[string]any{"shard": s.string()}))
 	defer func() {
@@ -468,7 +506,12 @@ func (b *RedisBroker) runPubSub(s *shardWrapper, eventHandler BrokerEventHandler
 				case <-done:
 					return
 				case msg := <-ch:
-					err := b.handleRedisClientMessage(eventHandler, channelID(msg.Channel), convert.StringToBytes(msg.Message))
+					var err error
+					if msg.Pattern != "" {
+						err = b.handleRedisPatternMessage(eventHandler, msg.Pattern, channelID(msg.Channel), convert.StringToBytes(msg.Message))
+					} else {
+						err = b.handleRedisClientMessage(eventHandler, channelID(msg.Channel), convert.StringToBytes(msg.Message))
+					}
 					if err != nil {
 						b.node.Log(NewLogEntry(LogLevelError, "error handling client message", map[string]any{"error": err.Error()}))
 						continue
@@ -516,6 +559,23 @@ func (b *RedisBroker) runPubSub(s *shardWrapper, eventHandler BrokerEventHandler
 
 	channels := b.node.Hub().Channels()
 
+	if !useShardedPubSub {
+		var patterns []string
+		for _, ch := range channels {
+			if b.node.isWildcardChannel(ch) && index(ch, b.config.numPubSubShards) == psShardIndex {
+				patterns = append(patterns, b.redisPattern(ch))
+			}
+		}
+		if len(patterns) > 0 {
+			err = conn.Do(context.Background(), conn.B().Psubscribe().Pattern(patterns...).Build()).Error()
+			if err != nil {
+				startOnce(err)
+				b.node.Log(NewLogEntry(LogLevelError, "error subscribing to patterns", map[string]any{"error": err.Error()}))
+				return
+			}
+		}
+	}
+
 	var wg sync.WaitGroup
 	started := time.Now()
 
@@ -530,6 +590,9 @@ func (b *RedisBroker) runPubSub(s *shardWrapper, eventHandler BrokerEventHandler
 			chIDs := make([]channelID, 0, estimatedCap)
 
 			for _, ch := range channels {
+				if b.node.isWildcardChannel(ch) {
+					continue
+				}
 				if b.getShard(ch).shard == s.shard && ((useShardedPubSub && consistentIndex(ch, b.config.numClusterShards) == clusterShardIndex && index(ch, b.config.numPubSubShards) == psShardIndex && index(ch, b.config.numPubSubSubscribers) == subscriberIndex) || (index(ch, b.config.numPubSubShards) == psShardIndex && index(ch, b.config.numPubSubSubscribers) == subscriberIndex)) {
 					chIDs = append(chIDs, b.messageChannelID(s.shard, ch))
 				}
@@ -605,10 +668,23 @@ func (b *RedisBroker) useShardedPubSub(s *RedisShard) bool {
 
 // Publish - see Broker.Publish.
 func (b *RedisBroker) Publish(ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
-	return b.publish(b.getShard(ch), ch, data, opts)
+	return b.PublishContext(context.Background(), ch, data, opts)
+}
+
+// PublishContext - see ContextBroker.PublishContext.
+func (b *RedisBroker) PublishContext(ctx context.Context, ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
+	s := b.getShard(ch)
+	var sp StreamPosition
+	var fromCache bool
+	err := s.shard.retry(ctx, func() error {
+		var err error
+		sp, fromCache, err = b.publish(ctx, s, ch, data, opts)
+		return err
+	})
+	return sp, fromCache, err
 }
 
-func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
+func (b *RedisBroker) publish(ctx context.Context, s *shardWrapper, ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
 	protoPub := &protocol.Publication{
 		Data: data,
 		Info: infoToProto(opts.ClientInfo),
@@ -619,12 +695,20 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 		// In no history case we communicate delta flag over Publication field. This field is then
 		// cleaned up before passing to the Node layer when handling Redis message.
 		protoPub.Delta = opts.UseDelta
+	} else if b.payloadCipher != nil {
+		encrypted, err := b.payloadCipher.encrypt(ch, data)
+		if err != nil {
+			return StreamPosition{}, false, fmt.Errorf("error encrypting payload: %w", err)
+		}
+		protoPub.Data = encrypted
 	}
 
 	byteMessage, err := protoPub.MarshalVT()
 	if err != nil {
 		return StreamPosition{}, false, err
 	}
+	// Delivery options are sent over PUB/SUB only, they are not saved to history.
+	deliveryFields := appendDeliveryFields(nil, deliveryFromPublishOptions(opts))
 
 	publishChannel := b.messageChannelID(s.shard, ch)
 	useShardedPublish := b.useShardedPubSub(s.shard)
@@ -650,6 +734,7 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 	}
 
 	if opts.HistorySize <= 0 || opts.HistoryTTL <= 0 {
+		byteMessage = append(byteMessage, deliveryFields...)
 		var resp rueidis.RedisResult
 		if useShardedPublish {
 			if resultExpire == "" {
@@ -657,10 +742,10 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 					return StreamPosition{}, false, nil
 				}
 				cmd := s.shard.client.B().Spublish().Channel(string(publishChannel)).Message(convert.BytesToString(byteMessage)).Build()
-				resp = s.shard.client.Do(context.Background(), cmd)
+				resp = s.shard.client.Do(ctx, cmd)
 			} else {
 				resp = b.publishIdempotentScript.Exec(
-					context.Background(),
+					ctx,
 					s.shard.client,
 					[]string{string(resultKey)},
 					[]string{
@@ -677,10 +762,10 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 					return StreamPosition{}, false, nil
 				}
 				cmd := s.shard.client.B().Publish().Channel(string(publishChannel)).Message(convert.BytesToString(byteMessage)).Build()
-				resp = s.shard.client.Do(context.Background(), cmd)
+				resp = s.shard.client.Do(ctx, cmd)
 			} else {
 				resp = b.publishIdempotentScript.Exec(
-					context.Background(),
+					ctx,
 					s.shard.client,
 					[]string{string(resultKey)},
 					[]string{
@@ -723,7 +808,7 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 	}
 
 	replies, err := script.Exec(
-		context.Background(),
+		ctx,
 		s.shard.client,
 		[]string{string(streamKey), string(historyMetaKey), string(resultKey)},
 		[]string{
@@ -736,6 +821,7 @@ func (b *RedisBroker) publish(s *shardWrapper, ch string, data []byte, opts Publ
 			publishCommand,
 			resultExpire,
 			useDelta,
+			convert.BytesToString(deliveryFields),
 		},
 	).ToArray()
 	if err != nil {
@@ -841,27 +927,7 @@ func (b *RedisBroker) subscribe(s *shardWrapper, ch string) error {
 	if b.node.LogEnabled(LogLevelDebug) {
 		b.node.Log(NewLogEntry(LogLevelDebug, "subscribe node on channel", map[string]any{"channel": ch}))
 	}
-	psShardIndex := index(ch, b.config.numPubSubShards)
-	var clusterShardIndex int
-	if b.useShardedPubSub(s.shard) {
-		clusterShardIndex = consistentIndex(ch, b.config.numClusterShards)
-	}
-
-	s.subClientsMu.Lock()
-	conn := s.subClients[clusterShardIndex][psShardIndex]
-	if conn == nil {
-		s.subClientsMu.Unlock()
-		return errPubSubConnUnavailable
-	}
-	s.subClientsMu.Unlock()
-
-	var err error
-	if b.useShardedPubSub(s.shard) {
-		err = conn.Do(context.Background(), conn.B().Ssubscribe().Channel(string(b.messageChannelID(s.shard, ch))).Build()).Error()
-	} else {
-		err = conn.Do(context.Background(), conn.B().Subscribe().Channel(string(b.messageChannelID(s.shard, ch))).Build()).Error()
-	}
-	return err
+	return b.changeSubscription(s, ch, true)
 }
 
 // Unsubscribe - see Broker.Unsubscribe.
@@ -873,47 +939,254 @@ func (b *RedisBroker) unsubscribe(s *shardWrapper, ch string) error {
 	if b.node.LogEnabled(LogLevelDebug) {
 		b.node.Log(NewLogEntry(LogLevelDebug, "unsubscribe node from channel", map[string]any{"channel": ch}))
 	}
+	return b.changeSubscription(s, ch, false)
+}
+
+// SubscribePattern - see PatternSubscriber.SubscribePattern. Pattern is subscribed
+// with PSUBSCRIBE on every Redis shard. Not supported with sharded PUB/SUB.
+func (b *RedisBroker) SubscribePattern(pattern string) error {
+	return b.changePatternSubscription(pattern, true)
+}
+
+// UnsubscribePattern - see PatternSubscriber.UnsubscribePattern.
+func (b *RedisBroker) UnsubscribePattern(pattern string) error {
+	return b.changePatternSubscription(pattern, false)
+}
+
+var errPatternsNotSupported = errors.New("wildcard subscriptions not supported with sharded PUB/SUB")
+
+func (b *RedisBroker) changePatternSubscription(pattern string, subscribe bool) error {
+	if b.node.LogEnabled(LogLevelDebug) {
+		b.node.Log(NewLogEntry(LogLevelDebug, "change node pattern subscription", map[string]any{"pattern": pattern, "subscribe": subscribe}))
+	}
+	psShardIndex := index(pattern, b.config.numPubSubShards)
+	for _, s := range b.shards {
+		if b.useShardedPubSub(s.shard) {
+			return errPatternsNotSupported
+		}
+		s.subClientsMu.Lock()
+		conn := s.subClients[0][psShardIndex]
+		s.subClientsMu.Unlock()
+		if conn == nil {
+			return errPubSubConnUnavailable
+		}
+		var cmd rueidis.Completed
+		if subscribe {
+			cmd = conn.B().Psubscribe().Pattern(b.redisPattern(pattern)).Build()
+		} else {
+			cmd = conn.B().Punsubscribe().Pattern(b.redisPattern(pattern)).Build()
+		}
+		if err := conn.Do(context.Background(), cmd).Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redisPattern converts wildcard pattern to Redis glob-style pattern escaping
+// special characters other than "*" of pattern.
+func (b *RedisBroker) redisPattern(pattern string) string {
+	var sb strings.Builder
+	writeEscaped := func(s string, special string) {
+		for _, r := range s {
+			if strings.ContainsRune(special, r) {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		}
+	}
+	writeEscaped(b.messagePrefix, `?[]\*`)
+	writeEscaped(pattern, `?[]\`)
+	return sb.String()
+}
+
+func (b *RedisBroker) extractPattern(redisPattern string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range redisPattern {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return strings.TrimPrefix(sb.String(), b.messagePrefix)
+}
+
+type subBatchKey struct {
+	clusterShardIndex int
+	psShardIndex      int
+}
+
+type subBatchRequest struct {
+	channel   string
+	subscribe bool
+	errCh     chan error
+}
+
+func (b *RedisBroker) changeSubscription(s *shardWrapper, ch string, subscribe bool) error {
 	psShardIndex := index(ch, b.config.numPubSubShards)
 	var clusterShardIndex int
 	if b.useShardedPubSub(s.shard) {
 		clusterShardIndex = consistentIndex(ch, b.config.numClusterShards)
 	}
+	key := subBatchKey{clusterShardIndex: clusterShardIndex, psShardIndex: psShardIndex}
+	if b.config.SubscribeBatchDelay > 0 {
+		return b.batchSubscription(s, key, subBatchRequest{channel: ch, subscribe: subscribe})
+	}
+	errs := b.sendSubscriptions(s, key, []subBatchRequest{{channel: ch, subscribe: subscribe}})
+	return errs[0]
+}
+
+// sendSubscriptions sends SUBSCRIBE/UNSUBSCRIBE commands for requests over PUB/SUB connection
+// preserving requests order. Consecutive requests of the same type are grouped into a single
+// command. Returns error for each request.
+func (b *RedisBroker) sendSubscriptions(s *shardWrapper, key subBatchKey, requests []subBatchRequest) []error {
+	errs := make([]error, len(requests))
 
 	s.subClientsMu.Lock()
-	conn := s.subClients[clusterShardIndex][psShardIndex]
+	conn := s.subClients[key.clusterShardIndex][key.psShardIndex]
 	if conn == nil {
 		s.subClientsMu.Unlock()
-		return errPubSubConnUnavailable
+		for i := range errs {
+			errs[i] = errPubSubConnUnavailable
+		}
+		return errs
 	}
 	s.subClientsMu.Unlock()
 
-	var err error
-	if b.useShardedPubSub(s.shard) {
-		err = conn.Do(context.Background(), conn.B().Sunsubscribe().Channel(string(b.messageChannelID(s.shard, ch))).Build()).Error()
-	} else {
-		err = conn.Do(context.Background(), conn.B().Unsubscribe().Channel(string(b.messageChannelID(s.shard, ch))).Build()).Error()
+	useShardedPubSub := b.useShardedPubSub(s.shard)
+	var cmds rueidis.Commands
+	// cmdIndex contains index of command for each request.
+	cmdIndex := make([]int, len(requests))
+	for i := 0; i < len(requests); {
+		j := i
+		channels := make([]string, 0, 1)
+		for j < len(requests) && requests[j].subscribe == requests[i].subscribe {
+			channels = append(channels, string(b.messageChannelID(s.shard, requests[j].channel)))
+			cmdIndex[j] = len(cmds)
+			j++
+		}
+		if requests[i].subscribe {
+			if useShardedPubSub {
+				cmds = append(cmds, conn.B().Ssubscribe().Channel(channels...).Build())
+			} else {
+				cmds = append(cmds, conn.B().Subscribe().Channel(channels...).Build())
+			}
+		} else {
+			if useShardedPubSub {
+				cmds = append(cmds, conn.B().Sunsubscribe().Channel(channels...).Build())
+			} else {
+				cmds = append(cmds, conn.B().Unsubscribe().Channel(channels...).Build())
+			}
+		}
+		i = j
+	}
+
+	results := conn.DoMulti(context.Background(), cmds...)
+	for i := range requests {
+		errs[i] = results[cmdIndex[i]].Error()
+	}
+	return errs
+}
+
+func (b *RedisBroker) batchSubscription(s *shardWrapper, key subBatchKey, req subBatchRequest) error {
+	s.subBatchersMu.Lock()
+	if s.subBatchers == nil {
+		s.subBatchers = map[subBatchKey]chan subBatchRequest{}
+	}
+	reqCh, ok := s.subBatchers[key]
+	if !ok {
+		reqCh = make(chan subBatchRequest)
+		s.subBatchers[key] = reqCh
+		go b.runSubBatcher(s, key, reqCh)
+	}
+	s.subBatchersMu.Unlock()
+
+	req.errCh = make(chan error, 1)
+	select {
+	case reqCh <- req:
+	case <-b.closeCh:
+		return errPubSubConnUnavailable
+	}
+	return <-req.errCh
+}
+
+// runSubBatcher collects subscription requests during SubscribeBatchDelay and
+// sends them to Redis together.
+func (b *RedisBroker) runSubBatcher(s *shardWrapper, key subBatchKey, reqCh chan subBatchRequest) {
+	for {
+		var req subBatchRequest
+		select {
+		case <-b.closeCh:
+			return
+		case req = <-reqCh:
+		}
+		batch := []subBatchRequest{req}
+		tm := timers.AcquireTimer(b.config.SubscribeBatchDelay)
+	loop:
+		for len(batch) < redisSubscribeBatchLimit {
+			select {
+			case req := <-reqCh:
+				batch = append(batch, req)
+			case <-tm.C:
+				break loop
+			case <-b.closeCh:
+				timers.ReleaseTimer(tm)
+				for _, r := range batch {
+					r.errCh <- errPubSubConnUnavailable
+				}
+				return
+			}
+		}
+		timers.ReleaseTimer(tm)
+		errs := b.sendSubscriptions(s, key, batch)
+		for i, r := range batch {
+			r.errCh <- errs[i]
+		}
 	}
-	return err
 }
 
 // History - see Broker.History.
 func (b *RedisBroker) History(ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
-	return b.history(b.getShard(ch), ch, opts)
+	return b.HistoryContext(context.Background(), ch, opts)
+}
+
+// HistoryContext - see ContextBroker.HistoryContext.
+func (b *RedisBroker) HistoryContext(ctx context.Context, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
+	s := b.getShard(ch)
+	var pubs []*Publication
+	var sp StreamPosition
+	err := s.shard.retry(ctx, func() error {
+		var err error
+		pubs, sp, err = b.history(ctx, s, ch, opts)
+		return err
+	})
+	return pubs, sp, err
 }
 
-func (b *RedisBroker) history(s *shardWrapper, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
+func (b *RedisBroker) history(ctx context.Context, s *shardWrapper, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
 	if b.config.UseLists {
-		return b.historyList(s.shard, ch, opts.Filter)
+		return b.historyList(ctx, s.shard, ch, opts.Filter)
 	}
-	return b.historyStream(s.shard, ch, opts)
+	return b.historyStream(ctx, s.shard, ch, opts)
 }
 
 // RemoveHistory - see Broker.RemoveHistory.
 func (b *RedisBroker) RemoveHistory(ch string) error {
-	return b.removeHistory(b.getShard(ch), ch)
+	return b.RemoveHistoryContext(context.Background(), ch)
 }
 
-func (b *RedisBroker) removeHistory(s *shardWrapper, ch string) error {
+// RemoveHistoryContext - see ContextBroker.RemoveHistoryContext.
+func (b *RedisBroker) RemoveHistoryContext(ctx context.Context, ch string) error {
+	s := b.getShard(ch)
+	return s.shard.retry(ctx, func() error {
+		return b.removeHistory(ctx, s, ch)
+	})
+}
+
+func (b *RedisBroker) removeHistory(ctx context.Context, s *shardWrapper, ch string) error {
 	var key channelID
 	if b.config.UseLists {
 		key = b.historyListKey(s.shard, ch)
@@ -921,7 +1194,7 @@ func (b *RedisBroker) removeHistory(s *shardWrapper, ch string) error {
 		key = b.historyStreamKey(s.shard, ch)
 	}
 	cmd := s.shard.client.B().Del().Key(string(key)).Build()
-	resp := s.shard.client.Do(context.Background(), cmd)
+	resp := s.shard.client.Do(ctx, cmd)
 	return resp.Error()
 }
 
@@ -1020,6 +1293,9 @@ func (b *RedisBroker) handleRedisClientMessage(eventHandler BrokerEventHandler,
 		if err != nil {
 			return err
 		}
+		if err := b.decryptPublication(channel, &pub); err != nil {
+			return err
+		}
 		if pub.Offset == 0 {
 			// When adding to history and publishing happens atomically in Broker
 			// position info is prepended to Publication payload. In this case we should attach
@@ -1038,9 +1314,12 @@ func (b *RedisBroker) handleRedisClientMessage(eventHandler BrokerEventHandler,
 			if err != nil {
 				return err
 			}
-			_ = eventHandler.HandlePublication(channel, pubFromProto(&pub), sp, true, pubFromProto(&prevPub))
+			if err := b.decryptPublication(channel, &prevPub); err != nil {
+				return err
+			}
+			_ = eventHandler.HandlePublication(channel, pubFromProtoWithDelivery(&pub), sp, true, pubFromProto(&prevPub))
 		} else {
-			_ = eventHandler.HandlePublication(channel, pubFromProto(&pub), sp, delta, nil)
+			_ = eventHandler.HandlePublication(channel, pubFromProtoWithDelivery(&pub), sp, delta, nil)
 		}
 	} else if pushType == joinPushType {
 		var info protocol.ClientInfo
@@ -1060,7 +1339,43 @@ func (b *RedisBroker) handleRedisClientMessage(eventHandler BrokerEventHandler,
 	return nil
 }
 
-func (b *RedisBroker) historyStream(s *RedisShard, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
+// handleRedisPatternMessage handles publication received over pattern subscription.
+// Join and leave messages are not delivered over patterns.
+func (b *RedisBroker) handleRedisPatternMessage(eventHandler BrokerEventHandler, redisPattern string, chID channelID, data []byte) error {
+	pushData, pushType, _, _, _, ok := extractPushData(data)
+	if !ok {
+		return fmt.Errorf("malformed PUB/SUB data: %s", data)
+	}
+	if pushType != pubPushType {
+		return nil
+	}
+	var pub protocol.Publication
+	err := pub.UnmarshalVT(pushData)
+	if err != nil {
+		return err
+	}
+	channel := b.extractChannel(chID)
+	if err := b.decryptPublication(channel, &pub); err != nil {
+		return err
+	}
+	_ = eventHandler.HandlePublication(b.extractPattern(redisPattern), WildcardPublication(pubFromProtoWithDelivery(&pub), channel), StreamPosition{}, false, nil)
+	return nil
+}
+
+// decryptPublication decrypts publication payload if payload encryption enabled.
+func (b *RedisBroker) decryptPublication(ch string, pub *protocol.Publication) error {
+	if b.payloadCipher == nil {
+		return nil
+	}
+	data, err := b.payloadCipher.decrypt(ch, pub.Data)
+	if err != nil {
+		return fmt.Errorf("error decrypting payload: %w", err)
+	}
+	pub.Data = data
+	return nil
+}
+
+func (b *RedisBroker) historyStream(ctx context.Context, s *RedisShard, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
 	historyKey := b.historyStreamKey(s, ch)
 	historyMetaKey := b.historyMetaKey(s, ch)
 
@@ -1098,7 +1413,7 @@ func (b *RedisBroker) historyStream(s *RedisShard, ch string, opts HistoryOption
 
 	historyMetaTTLSeconds := int(historyMetaTTL.Seconds())
 
-	replies, err := b.historyStreamScript.Exec(context.Background(), s.client, []string{string(historyKey), string(historyMetaKey)}, []string{includePubs, strconv.FormatUint(offset, 10), strconv.Itoa(limit), reverse, strconv.Itoa(historyMetaTTLSeconds), strconv.FormatInt(time.Now().Unix(), 10)}).ToArray()
+	replies, err := b.historyStreamScript.Exec(ctx, s.client, []string{string(historyKey), string(historyMetaKey)}, []string{includePubs, strconv.FormatUint(offset, 10), strconv.Itoa(limit), reverse, strconv.Itoa(historyMetaTTLSeconds), strconv.FormatInt(time.Now().Unix(), 10)}).ToArray()
 	if err != nil {
 		return nil, StreamPosition{}, err
 	}
@@ -1167,6 +1482,9 @@ func (b *RedisBroker) historyStream(s *RedisShard, ch string, opts HistoryOption
 			if err != nil {
 				return nil, StreamPosition{}, fmt.Errorf("can not unmarshal value to Publication: %v", err)
 			}
+			if err := b.decryptPublication(ch, &pub); err != nil {
+				return nil, StreamPosition{}, err
+			}
 			pub.Offset = offset
 			publications = append(publications, pubFromProto(&pub))
 		}
@@ -1175,7 +1493,7 @@ func (b *RedisBroker) historyStream(s *RedisShard, ch string, opts HistoryOption
 	return nil, StreamPosition{Offset: uint64(offs), Epoch: epoch}, nil
 }
 
-func (b *RedisBroker) historyList(s *RedisShard, ch string, filter HistoryFilter) ([]*Publication, StreamPosition, error) {
+func (b *RedisBroker) historyList(ctx context.Context, s *RedisShard, ch string, filter HistoryFilter) ([]*Publication, StreamPosition, error) {
 	historyKey := b.historyListKey(s, ch)
 	historyMetaKey := b.historyMetaKey(s, ch)
 
@@ -1188,7 +1506,7 @@ func (b *RedisBroker) historyList(s *RedisShard, ch string, filter HistoryFilter
 
 	historyMetaTTLSeconds := int(b.node.config.HistoryMetaTTL.Seconds())
 
-	replies, err := b.historyListScript.Exec(context.Background(), s.client, []string{string(historyKey), string(historyMetaKey)}, []string{includePubs, rightBound, strconv.Itoa(historyMetaTTLSeconds), strconv.FormatInt(time.Now().Unix(), 10)}).ToArray()
+	replies, err := b.historyListScript.Exec(ctx, s.client, []string{string(historyKey), string(historyMetaKey)}, []string{includePubs, rightBound, strconv.Itoa(historyMetaTTLSeconds), strconv.FormatInt(time.Now().Unix(), 10)}).ToArray()
 	if err != nil {
 		return nil, StreamPosition{}, err
 	}
@@ -1237,10 +1555,17 @@ func (b *RedisBroker) historyList(s *RedisShard, ch string, filter HistoryFilter
 		if err != nil {
 			return nil, StreamPosition{}, fmt.Errorf("can not unmarshal value to Pub: %v", err)
 		}
+		if err := b.decryptPublication(ch, &pub); err != nil {
+			return nil, StreamPosition{}, err
+		}
 		pub.Offset = sp.Offset
 		publications = append(publications, pubFromProto(&pub))
 	}
 
+	if filter.Reverse {
+		return reverseHistoryList(publications, filter), latestPosition, nil
+	}
+
 	since := filter.Since
 	if since == nil {
 		if filter.Limit >= 0 && len(publications) >= filter.Limit {
@@ -1295,6 +1620,28 @@ func (b *RedisBroker) historyList(s *RedisShard, ch string, filter HistoryFilter
 	return publications, latestPosition, nil
 }
 
+// reverseHistoryList applies reverse filter to publications loaded from list in
+// chronological order. Semantics is the same as for streams: publications with
+// offset less than filter.Since.Offset (or latest publications if Since not set)
+// are returned from newest to oldest.
+func reverseHistoryList(publications []*Publication, filter HistoryFilter) []*Publication {
+	end := len(publications)
+	if filter.Since != nil {
+		end = sort.Search(len(publications), func(i int) bool {
+			return publications[i].Offset >= filter.Since.Offset
+		})
+	}
+	size := end
+	if filter.Limit >= 0 && filter.Limit < size {
+		size = filter.Limit
+	}
+	result := make([]*Publication, 0, size)
+	for i := end - 1; i >= 0 && len(result) < size; i-- {
+		result = append(result, publications[i])
+	}
+	return result
+}
+
 type pushType int
 
 const (
@@ -3,6 +3,7 @@
 package centrifuge
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/rand"
@@ -153,6 +154,14 @@ func stopRedisBroker(b *RedisBroker) {
 	}
 }
 
+func TestRedisBroker_CheckHealth(t *testing.T) {
+	node := testNode(t)
+	b := newTestRedisBroker(t, node, false, false, 0)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	defer stopRedisBroker(b)
+	require.NoError(t, b.CheckHealth(context.Background()))
+}
+
 func TestRedisBroker_NoShards(t *testing.T) {
 	n, _ := New(Config{})
 	_, err := NewRedisBroker(n, RedisBrokerConfig{})
@@ -491,6 +500,101 @@ func pubSubChannels(t *testing.T, e *RedisBroker) ([]string, error) {
 	return client.Do(context.Background(), client.B().PubsubChannels().Pattern(e.messagePrefix+"*").Build()).AsStrSlice()
 }
 
+func TestRedisBrokerSubscribeBatching(t *testing.T) {
+	node := testNode(t)
+	s, err := NewRedisShard(node, testSingleRedisConf(0))
+	require.NoError(t, err)
+	b, err := NewRedisBroker(node, RedisBrokerConfig{
+		Prefix:              getUniquePrefix(),
+		Shards:              []*RedisShard{s},
+		SubscribeBatchDelay: 5 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	node.SetBroker(b)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	defer stopRedisBroker(b)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, b.Subscribe("batch-"+strconv.Itoa(i)))
+		}(i)
+	}
+	wg.Wait()
+	channels, err := pubSubChannels(t, b)
+	require.NoError(t, err)
+	if len(channels) != 100 {
+		time.Sleep(2000 * time.Millisecond)
+		channels, err = pubSubChannels(t, b)
+		require.NoError(t, err)
+		require.Len(t, channels, 100)
+	}
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, b.Unsubscribe("batch-"+strconv.Itoa(i)))
+		}(i)
+	}
+	wg.Wait()
+	channels, err = pubSubChannels(t, b)
+	require.NoError(t, err)
+	if len(channels) != 0 {
+		time.Sleep(2000 * time.Millisecond)
+		channels, err = pubSubChannels(t, b)
+		require.NoError(t, err)
+		require.Len(t, channels, 0)
+	}
+}
+
+func TestRedisBrokerPayloadEncryption(t *testing.T) {
+	for _, useStreams := range []bool{true, false} {
+		t.Run(fmt.Sprintf("streams_%v", useStreams), func(t *testing.T) {
+			node := testNode(t)
+			s, err := NewRedisShard(node, testSingleRedisConf(0))
+			require.NoError(t, err)
+			key, err := NewAESGCMPayloadEncryptionKey("k1", bytes.Repeat([]byte{1}, 32))
+			require.NoError(t, err)
+			prefix := getUniquePrefix()
+			b, err := NewRedisBroker(node, RedisBrokerConfig{
+				Prefix:                prefix,
+				UseLists:              !useStreams,
+				Shards:                []*RedisShard{s},
+				PayloadEncryptionKeys: []PayloadEncryptionKey{key},
+			})
+			require.NoError(t, err)
+			node.SetBroker(b)
+			require.NoError(t, node.Run())
+			defer func() { _ = node.Shutdown(context.Background()) }()
+			defer stopRedisBroker(b)
+
+			_, _, err = b.Publish("channel", []byte(`{"secret":1}`), PublishOptions{HistorySize: 10, HistoryTTL: time.Minute})
+			require.NoError(t, err)
+			pubs, _, err := b.History("channel", HistoryOptions{Filter: HistoryFilter{Limit: -1}})
+			require.NoError(t, err)
+			require.Len(t, pubs, 1)
+			require.Equal(t, []byte(`{"secret":1}`), pubs[0].Data)
+
+			// Broker without keys sees encrypted payload.
+			plainBroker, err := NewRedisBroker(node, RedisBrokerConfig{
+				Prefix:   prefix,
+				UseLists: !useStreams,
+				Shards:   []*RedisShard{s},
+			})
+			require.NoError(t, err)
+			pubs, _, err = plainBroker.History("channel", HistoryOptions{Filter: HistoryFilter{Limit: -1}})
+			require.NoError(t, err)
+			require.Len(t, pubs, 1)
+			require.True(t, bytes.HasPrefix(pubs[0].Data, encryptedPayloadPrefix))
+			require.NotContains(t, string(pubs[0].Data), "secret")
+		})
+	}
+}
+
 func TestRedisBrokerSubscribeUnsubscribe(t *testing.T) {
 	for _, tt := range noHistoryRedisTests {
 		t.Run(tt.Name, func(t *testing.T) {
@@ -1738,7 +1842,7 @@ func TestRedisHistoryReversedNoMetaYet(t *testing.T) {
 func TestRedisHistoryIterationReverse(t *testing.T) {
 	for _, tt := range historyRedisTests {
 		t.Run(tt.Name, func(t *testing.T) {
-			if !tt.UseStreams || tt.UseCluster {
+			if tt.UseCluster {
 				t.Skip()
 			}
 			node := testNode(t)
@@ -2063,3 +2167,66 @@ func TestParseDeltaPush(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisBrokerPatternSubscription(t *testing.T) {
+	node, err := New(Config{
+		LogLevel:              LogLevelDebug,
+		LogHandler:            func(entry LogEntry) {},
+		WildcardSubscriptions: true,
+	})
+	require.NoError(t, err)
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(event SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	b := NewTestRedisBroker(t, node, getUniquePrefix(), false, 0)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	defer stopRedisBroker(b)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	transport := newTestTransport(cancelFn)
+	transport.sink = make(chan []byte, 100)
+	newTestSubscribedClientWithTransport(t, ctx, node, transport, "42", "stocks.*")
+
+	_, err = node.Publish("news.AAPL", []byte(`{"n":0}`))
+	require.NoError(t, err)
+	_, err = node.Publish("stocks.AAPL", []byte(`{"n":1}`), WithHistory(10, time.Minute))
+	require.NoError(t, err)
+
+	for {
+		select {
+		case data := <-transport.sink:
+			msg := string(data)
+			if !strings.Contains(msg, `"pub"`) {
+				continue
+			}
+			require.Contains(t, msg, `"channel":"stocks.*"`)
+			require.Contains(t, msg, `"_channel":"stocks.AAPL"`)
+			require.Contains(t, msg, `{"n":1}`)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for publication")
+		}
+	}
+}
+
+func TestReverseHistoryList(t *testing.T) {
+	var publications []*Publication
+	for i := 3; i <= 7; i++ {
+		publications = append(publications, &Publication{Offset: uint64(i)})
+	}
+	offsets := func(pubs []*Publication) []uint64 {
+		result := make([]uint64, 0, len(pubs))
+		for _, pub := range pubs {
+			result = append(result, pub.Offset)
+		}
+		return result
+	}
+	require.Equal(t, []uint64{7, 6, 5, 4, 3}, offsets(reverseHistoryList(publications, HistoryFilter{Limit: -1, Reverse: true})))
+	require.Equal(t, []uint64{7, 6}, offsets(reverseHistoryList(publications, HistoryFilter{Limit: 2, Reverse: true})))
+	require.Equal(t, []uint64{5, 4}, offsets(reverseHistoryList(publications, HistoryFilter{Since: &StreamPosition{Offset: 6}, Limit: 2, Reverse: true})))
+	require.Equal(t, []uint64{4, 3}, offsets(reverseHistoryList(publications, HistoryFilter{Since: &StreamPosition{Offset: 5}, Limit: -1, Reverse: true})))
+	require.Empty(t, reverseHistoryList(publications, HistoryFilter{Since: &StreamPosition{Offset: 3}, Limit: -1, Reverse: true}))
+	require.Equal(t, []uint64{7, 6}, offsets(reverseHistoryList(publications, HistoryFilter{Since: &StreamPosition{Offset: 100}, Limit: 2, Reverse: true})))
+}
@@ -0,0 +1,372 @@
+// Package centrifugetest provides utilities for unit testing applications built
+// on top of Centrifuge. It contains in-memory Transport and Client which drive
+// connect, subscribe, publish and RPC workflows of Node directly, without opening
+// sockets – so application OnConnecting, OnConnect, OnSubscribe and other handlers
+// can be tested in-process:
+//
+//	node, _ := centrifuge.New(centrifuge.Config{})
+//	setupHandlers(node)
+//	_ = node.Run()
+//	client, _, err := centrifugetest.Connect(ctx, node, centrifugetest.ConnectOptions{
+//		Credentials: &centrifuge.Credentials{UserID: "42"},
+//	})
+//	_, err = client.Subscribe(ctx, "chat:index")
+package centrifugetest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/centrifugal/centrifuge"
+
+	"github.com/centrifugal/protocol"
+)
+
+// ErrClosed returned when sending command or waiting for push of closed connection.
+var ErrClosed = errors.New("connection closed")
+
+// Transport is an in-memory bidirectional centrifuge.Transport working over JSON
+// protocol. Server-to-client pings are disabled. Messages written by Client are
+// available over Messages channel.
+type Transport struct {
+	mu         sync.Mutex
+	messages   chan []byte
+	closed     bool
+	closeCh    chan struct{}
+	disconnect centrifuge.Disconnect
+}
+
+var _ centrifuge.Transport = (*Transport)(nil)
+
+// NewTransport creates Transport. Messages channel has capacity bufferSize, when
+// it's full Client writes are blocked.
+func NewTransport(bufferSize int) *Transport {
+	return &Transport{
+		messages: make(chan []byte, bufferSize),
+		closeCh:  make(chan struct{}),
+	}
+}
+
+// Messages returns a channel with encoded replies written to the connection.
+func (t *Transport) Messages() <-chan []byte {
+	return t.messages
+}
+
+// Done returns a channel closed when transport is closed by server.
+func (t *Transport) Done() <-chan struct{} {
+	return t.closeCh
+}
+
+// Disconnect returns disconnect transport was closed with. Only valid after
+// Done channel closed.
+func (t *Transport) Disconnect() centrifuge.Disconnect {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.disconnect
+}
+
+// Name of transport.
+func (t *Transport) Name() string {
+	return "test"
+}
+
+// Protocol of transport.
+func (t *Transport) Protocol() centrifuge.ProtocolType {
+	return centrifuge.ProtocolTypeJSON
+}
+
+// ProtocolVersion of transport.
+func (t *Transport) ProtocolVersion() centrifuge.ProtocolVersion {
+	return centrifuge.ProtocolVersion2
+}
+
+// Unidirectional returns false – Transport is bidirectional.
+func (t *Transport) Unidirectional() bool {
+	return false
+}
+
+// Emulation returns false.
+func (t *Transport) Emulation() bool {
+	return false
+}
+
+// DisabledPushFlags returns PushFlagDisconnect as disconnect is available over
+// Transport.Disconnect.
+func (t *Transport) DisabledPushFlags() uint64 {
+	return centrifuge.PushFlagDisconnect
+}
+
+// PingPongConfig disables pings.
+func (t *Transport) PingPongConfig() centrifuge.PingPongConfig {
+	return centrifuge.PingPongConfig{PingInterval: -1, PongTimeout: -1}
+}
+
+// Write message to Messages channel.
+func (t *Transport) Write(message []byte) error {
+	return t.WriteMany(message)
+}
+
+// WriteMany messages to Messages channel.
+func (t *Transport) WriteMany(messages ...[]byte) error {
+	for _, message := range messages {
+		select {
+		case t.messages <- message:
+		case <-t.closeCh:
+			return io.EOF
+		}
+	}
+	return nil
+}
+
+// Close transport.
+func (t *Transport) Close(disconnect centrifuge.Disconnect) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	t.disconnect = disconnect
+	close(t.closeCh)
+	return nil
+}
+
+// ConnectOptions to connect Client with.
+type ConnectOptions struct {
+	// Credentials if set are attached to connection context with
+	// centrifuge.SetCredentials – as it's usually done by authentication
+	// middleware. Otherwise, Node.OnConnecting handler must authenticate
+	// connection.
+	Credentials *centrifuge.Credentials
+	// Request is a connect request sent by client, may be nil.
+	Request *protocol.ConnectRequest
+}
+
+// Client is an in-memory client connection to Node.
+type Client struct {
+	client    *centrifuge.Client
+	closeFn   centrifuge.ClientCloseFunc
+	transport *Transport
+
+	mu      sync.Mutex
+	nextID  uint32
+	pending map[uint32]chan *protocol.Reply
+	pushes  []*protocol.Push
+	pushCh  chan struct{}
+	closed  chan struct{}
+}
+
+// Connect creates Client connected to Node. Returns connect result or error
+// returned by Node.OnConnecting handler.
+func Connect(ctx context.Context, node *centrifuge.Node, opts ConnectOptions) (*Client, *protocol.ConnectResult, error) {
+	transport := NewTransport(128)
+	clientCtx := context.Background()
+	if opts.Credentials != nil {
+		clientCtx = centrifuge.SetCredentials(clientCtx, opts.Credentials)
+	}
+	c, closeFn, err := centrifuge.NewClient(clientCtx, node, transport)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := &Client{
+		client:    c,
+		closeFn:   closeFn,
+		transport: transport,
+		pending:   map[uint32]chan *protocol.Reply{},
+		pushCh:    make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+	}
+	go client.read()
+
+	req := opts.Request
+	if req == nil {
+		req = &protocol.ConnectRequest{}
+	}
+	reply, err := client.send(ctx, &protocol.Command{Connect: req})
+	if err != nil {
+		_ = client.Close()
+		return nil, nil, err
+	}
+	return client, reply.Connect, nil
+}
+
+// Client returns underlying centrifuge.Client.
+func (c *Client) Client() *centrifuge.Client {
+	return c.client
+}
+
+// Transport returns underlying Transport.
+func (c *Client) Transport() *Transport {
+	return c.transport
+}
+
+// Subscribe client to channel.
+func (c *Client) Subscribe(ctx context.Context, channel string) (*protocol.SubscribeResult, error) {
+	return c.SubscribeRequest(ctx, &protocol.SubscribeRequest{Channel: channel})
+}
+
+// SubscribeRequest sends subscribe request allowing to set token, data, recovery
+// fields, etc.
+func (c *Client) SubscribeRequest(ctx context.Context, req *protocol.SubscribeRequest) (*protocol.SubscribeResult, error) {
+	reply, err := c.send(ctx, &protocol.Command{Subscribe: req})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Subscribe, nil
+}
+
+// Unsubscribe client from channel.
+func (c *Client) Unsubscribe(ctx context.Context, channel string) error {
+	_, err := c.send(ctx, &protocol.Command{Unsubscribe: &protocol.UnsubscribeRequest{Channel: channel}})
+	return err
+}
+
+// Publish data into channel on behalf of client.
+func (c *Client) Publish(ctx context.Context, channel string, data []byte) error {
+	_, err := c.send(ctx, &protocol.Command{Publish: &protocol.PublishRequest{Channel: channel, Data: data}})
+	return err
+}
+
+// RPC sends RPC request and returns result data.
+func (c *Client) RPC(ctx context.Context, method string, data []byte) ([]byte, error) {
+	reply, err := c.send(ctx, &protocol.Command{Rpc: &protocol.RPCRequest{Method: method, Data: data}})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Rpc.Data, nil
+}
+
+// Send sends arbitrary command and waits for reply. Command ID is set
+// automatically. Reply error returned as *centrifuge.Error, if connection was
+// closed by server centrifuge.Disconnect returned.
+func (c *Client) Send(ctx context.Context, cmd *protocol.Command) (*protocol.Reply, error) {
+	return c.send(ctx, cmd)
+}
+
+// NextPush waits for the next asynchronous push (publication, join, leave,
+// unsubscribe, etc.) sent to client.
+func (c *Client) NextPush(ctx context.Context) (*protocol.Push, error) {
+	for {
+		c.mu.Lock()
+		if len(c.pushes) > 0 {
+			push := c.pushes[0]
+			c.pushes = c.pushes[1:]
+			c.mu.Unlock()
+			return push, nil
+		}
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, ErrClosed
+		case <-c.pushCh:
+		}
+	}
+}
+
+// Close client connection.
+func (c *Client) Close() error {
+	return c.closeFn()
+}
+
+func (c *Client) send(ctx context.Context, cmd *protocol.Command) (*protocol.Reply, error) {
+	c.mu.Lock()
+	c.nextID++
+	cmd.Id = c.nextID
+	replyCh := make(chan *protocol.Reply, 1)
+	c.pending[cmd.Id] = replyCh
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, cmd.Id)
+		c.mu.Unlock()
+	}()
+
+	if !c.client.HandleCommand(cmd, 0) {
+		// Client closes connection asynchronously.
+		select {
+		case <-ctx.Done():
+			return nil, ErrClosed
+		case <-c.transport.Done():
+			return nil, c.transport.Disconnect()
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		select {
+		case reply := <-replyCh:
+			return replyResult(reply)
+		default:
+		}
+		return nil, c.transport.Disconnect()
+	case reply := <-replyCh:
+		return replyResult(reply)
+	}
+}
+
+func replyResult(reply *protocol.Reply) (*protocol.Reply, error) {
+	if reply.Error != nil {
+		return nil, &centrifuge.Error{
+			Code:      reply.Error.Code,
+			Message:   reply.Error.Message,
+			Temporary: reply.Error.Temporary,
+		}
+	}
+	return reply, nil
+}
+
+func (c *Client) read() {
+	defer close(c.closed)
+	for {
+		select {
+		case <-c.transport.Done():
+			// Handle messages written before close.
+			for {
+				select {
+				case data := <-c.transport.Messages():
+					c.handleMessage(data)
+				default:
+					return
+				}
+			}
+		case data := <-c.transport.Messages():
+			c.handleMessage(data)
+		}
+	}
+}
+
+func (c *Client) handleMessage(data []byte) {
+	decoder := protocol.NewJSONReplyDecoder(data)
+	for {
+		reply, err := decoder.Decode()
+		if err != nil {
+			return
+		}
+		c.handleReply(reply)
+	}
+}
+
+func (c *Client) handleReply(reply *protocol.Reply) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reply.Id > 0 {
+		if ch, ok := c.pending[reply.Id]; ok {
+			ch <- reply
+		}
+		return
+	}
+	if reply.Push == nil {
+		// Ping.
+		return
+	}
+	c.pushes = append(c.pushes, reply.Push)
+	select {
+	case c.pushCh <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,104 @@
+package centrifugetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNode(t *testing.T) *centrifuge.Node {
+	node, err := centrifuge.New(centrifuge.Config{
+		LogLevel:   centrifuge.LogLevelTrace,
+		LogHandler: func(entry centrifuge.LogEntry) {},
+	})
+	require.NoError(t, err)
+	node.OnConnecting(func(ctx context.Context, e centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
+		if e.Token == "bad" {
+			return centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
+		}
+		if e.Token != "" {
+			return centrifuge.ConnectReply{Credentials: &centrifuge.Credentials{UserID: e.Token}}, nil
+		}
+		return centrifuge.ConnectReply{}, nil
+	})
+	node.OnConnect(func(client *centrifuge.Client) {
+		client.OnSubscribe(func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
+			if e.Channel == "forbidden" {
+				cb(centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied)
+				return
+			}
+			cb(centrifuge.SubscribeReply{}, nil)
+		})
+		client.OnPublish(func(e centrifuge.PublishEvent, cb centrifuge.PublishCallback) {
+			cb(centrifuge.PublishReply{}, nil)
+		})
+		client.OnRPC(func(e centrifuge.RPCEvent, cb centrifuge.RPCCallback) {
+			cb(centrifuge.RPCReply{Data: []byte(`"` + e.Method + `"`)}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+	return node
+}
+
+func TestConnect(t *testing.T) {
+	node := newTestNode(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, result, err := Connect(ctx, node, ConnectOptions{Credentials: &centrifuge.Credentials{UserID: "42"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Client)
+	require.Equal(t, "42", client.Client().UserID())
+	require.NoError(t, client.Close())
+
+	client, _, err = Connect(ctx, node, ConnectOptions{Request: &protocol.ConnectRequest{Token: "12"}})
+	require.NoError(t, err)
+	require.Equal(t, "12", client.Client().UserID())
+
+	_, _, err = Connect(ctx, node, ConnectOptions{Request: &protocol.ConnectRequest{Token: "bad"}})
+	var d centrifuge.Disconnect
+	require.True(t, errors.As(err, &d))
+	require.Equal(t, centrifuge.DisconnectInvalidToken.Code, d.Code)
+}
+
+func TestClient(t *testing.T) {
+	node := newTestNode(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, _, err := Connect(ctx, node, ConnectOptions{Credentials: &centrifuge.Credentials{UserID: "42"}})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	_, err = client.Subscribe(ctx, "forbidden")
+	var e *centrifuge.Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, centrifuge.ErrorPermissionDenied.Code, e.Code)
+
+	_, err = client.Subscribe(ctx, "test")
+	require.NoError(t, err)
+	require.NoError(t, client.Publish(ctx, "test", []byte(`{"n":1}`)))
+	push, err := client.NextPush(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "test", push.Channel)
+	require.Equal(t, []byte(`{"n":1}`), []byte(push.Pub.Data))
+
+	data, err := client.RPC(ctx, "m", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`"m"`), data)
+
+	require.NoError(t, client.Unsubscribe(ctx, "test"))
+	require.Zero(t, node.Hub().NumSubscribers("test"))
+
+	client.Client().Disconnect(centrifuge.DisconnectForceNoReconnect)
+	<-client.Transport().Done()
+	_, err = client.NextPush(ctx)
+	require.ErrorIs(t, err, ErrClosed)
+}
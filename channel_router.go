@@ -0,0 +1,208 @@
+package centrifuge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ChannelParams contains values of channel route parameters.
+type ChannelParams map[string]string
+
+// String returns parameter value, empty string if parameter not found.
+func (p ChannelParams) String(name string) string {
+	return p[name]
+}
+
+// Int returns parameter value parsed as integer. Parameters declared with int
+// type in route pattern are always valid integers.
+func (p ChannelParams) Int(name string) (int64, error) {
+	return strconv.ParseInt(p[name], 10, 64)
+}
+
+// ChannelSubscribeHandler handles subscribe event of channel matching route.
+type ChannelSubscribeHandler func(SubscribeEvent, ChannelParams, SubscribeCallback)
+
+// ChannelPublishHandler handles publish event of channel matching route.
+type ChannelPublishHandler func(PublishEvent, ChannelParams, PublishCallback)
+
+type channelRoute struct {
+	pattern   string
+	re        *regexp.Regexp
+	names     []string
+	subscribe ChannelSubscribeHandler
+	publish   ChannelPublishHandler
+}
+
+// ChannelRouter matches channels against route patterns and extracts parameters. Route
+// pattern consists of literal parts and parameters in curly braces, for example
+// "chat:{room_id}" or "user:{user_id:int}#{type}". Parameter matches one or more
+// characters up to the next literal part of pattern, parameter declared with int type
+// only matches integers. Routes are matched in order they were added.
+//
+// ChannelRouter may be used to dispatch client events:
+//
+//	router := centrifuge.NewChannelRouter()
+//	_ = router.OnSubscribe("chat:{room_id:int}", func(e centrifuge.SubscribeEvent, p centrifuge.ChannelParams, cb centrifuge.SubscribeCallback) {
+//		roomID, _ := p.Int("room_id")
+//		...
+//	})
+//	node.OnConnect(func(client *centrifuge.Client) {
+//		client.OnSubscribe(router.HandleSubscribe)
+//	})
+//
+// ChannelRouter must be configured before use, it's not safe to add routes concurrently
+// with matching.
+type ChannelRouter struct {
+	routes []*channelRoute
+}
+
+// NewChannelRouter creates ChannelRouter.
+func NewChannelRouter() *ChannelRouter {
+	return &ChannelRouter{}
+}
+
+var channelRouteParamRegexp = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)(?::([a-z]+))?}`)
+
+func compileChannelRoute(pattern string) (*channelRoute, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	var names []string
+	last := 0
+	for _, loc := range channelRouteParamRegexp.FindAllStringSubmatchIndex(pattern, -1) {
+		literal := pattern[last:loc[0]]
+		if strings.ContainsAny(literal, "{}") {
+			return nil, fmt.Errorf("malformed channel route pattern %q", pattern)
+		}
+		if last > 0 && literal == "" {
+			return nil, fmt.Errorf("channel route pattern %q has adjacent parameters", pattern)
+		}
+		sb.WriteString(regexp.QuoteMeta(literal))
+		name := pattern[loc[2]:loc[3]]
+		for _, n := range names {
+			if n == name {
+				return nil, fmt.Errorf("duplicate parameter %q in channel route pattern %q", name, pattern)
+			}
+		}
+		names = append(names, name)
+		paramType := ""
+		if loc[4] >= 0 {
+			paramType = pattern[loc[4]:loc[5]]
+		}
+		switch paramType {
+		case "", "string":
+			sb.WriteString("(.+?)")
+		case "int":
+			sb.WriteString("(-?[0-9]+)")
+		default:
+			return nil, fmt.Errorf("unknown parameter type %q in channel route pattern %q", paramType, pattern)
+		}
+		last = loc[1]
+	}
+	literal := pattern[last:]
+	if strings.ContainsAny(literal, "{}") {
+		return nil, fmt.Errorf("malformed channel route pattern %q", pattern)
+	}
+	sb.WriteString(regexp.QuoteMeta(literal))
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	return &channelRoute{pattern: pattern, re: re, names: names}, nil
+}
+
+func (r *ChannelRouter) route(pattern string) (*channelRoute, error) {
+	for _, route := range r.routes {
+		if route.pattern == pattern {
+			return route, nil
+		}
+	}
+	route, err := compileChannelRoute(pattern)
+	if err != nil {
+		return nil, err
+	}
+	r.routes = append(r.routes, route)
+	return route, nil
+}
+
+// Add adds route pattern without handlers. Useful when ChannelRouter is only used
+// for Match.
+func (r *ChannelRouter) Add(pattern string) error {
+	_, err := r.route(pattern)
+	return err
+}
+
+// OnSubscribe sets subscribe handler for channels matching pattern.
+func (r *ChannelRouter) OnSubscribe(pattern string, h ChannelSubscribeHandler) error {
+	route, err := r.route(pattern)
+	if err != nil {
+		return err
+	}
+	route.subscribe = h
+	return nil
+}
+
+// OnPublish sets publish handler for channels matching pattern.
+func (r *ChannelRouter) OnPublish(pattern string, h ChannelPublishHandler) error {
+	route, err := r.route(pattern)
+	if err != nil {
+		return err
+	}
+	route.publish = h
+	return nil
+}
+
+func (r *ChannelRouter) match(channel string) (*channelRoute, ChannelParams) {
+	for _, route := range r.routes {
+		matches := route.re.FindStringSubmatch(channel)
+		if matches == nil {
+			continue
+		}
+		params := make(ChannelParams, len(route.names))
+		for i, name := range route.names {
+			params[name] = matches[i+1]
+		}
+		return route, params
+	}
+	return nil, nil
+}
+
+// Match returns pattern of the first route matching channel and extracted
+// parameters. The last return value is false if no route matches channel.
+func (r *ChannelRouter) Match(channel string) (string, ChannelParams, bool) {
+	route, params := r.match(channel)
+	if route == nil {
+		return "", nil, false
+	}
+	return route.pattern, params, true
+}
+
+// HandleSubscribe is a SubscribeHandler dispatching event to subscribe handler of
+// the first route matching channel. If no route matches or matching route has no
+// subscribe handler then ErrorUnknownChannel returned to client.
+func (r *ChannelRouter) HandleSubscribe(e SubscribeEvent, cb SubscribeCallback) {
+	route, params := r.match(e.Channel)
+	if route == nil || route.subscribe == nil {
+		cb(SubscribeReply{}, ErrorUnknownChannel)
+		return
+	}
+	route.subscribe(e, params, cb)
+}
+
+// HandlePublish is a PublishHandler dispatching event to publish handler of the first
+// route matching channel. If no route matches then ErrorUnknownChannel returned to
+// client, if matching route has no publish handler then ErrorPermissionDenied returned.
+func (r *ChannelRouter) HandlePublish(e PublishEvent, cb PublishCallback) {
+	route, params := r.match(e.Channel)
+	if route == nil {
+		cb(PublishReply{}, ErrorUnknownChannel)
+		return
+	}
+	if route.publish == nil {
+		cb(PublishReply{}, ErrorPermissionDenied)
+		return
+	}
+	route.publish(e, params, cb)
+}
@@ -0,0 +1,99 @@
+package centrifuge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelRouter_Add(t *testing.T) {
+	r := NewChannelRouter()
+	for _, pattern := range []string{
+		"chat:{",
+		"chat:{room}}",
+		"chat:{room:float}",
+		"chat:{a}{b}",
+		"chat:{a}:{a}",
+	} {
+		require.Error(t, r.Add(pattern), pattern)
+	}
+	require.Empty(t, r.routes)
+	require.NoError(t, r.Add("chat:{room}"))
+	require.NoError(t, r.Add("chat:{room}"))
+	require.Len(t, r.routes, 1)
+}
+
+func TestChannelRouter_Match(t *testing.T) {
+	r := NewChannelRouter()
+	require.NoError(t, r.Add("user:{user_id:int}#{type}"))
+	require.NoError(t, r.Add("chat:{room_id}"))
+	require.NoError(t, r.Add("chat:{room_id}.typing"))
+	require.NoError(t, r.Add("news"))
+
+	pattern, params, ok := r.Match("user:42#notifications")
+	require.True(t, ok)
+	require.Equal(t, "user:{user_id:int}#{type}", pattern)
+	userID, err := params.Int("user_id")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), userID)
+	require.Equal(t, "notifications", params.String("type"))
+
+	_, _, ok = r.Match("user:abc#notifications")
+	require.False(t, ok)
+
+	pattern, params, ok = r.Match("chat:index")
+	require.True(t, ok)
+	require.Equal(t, "chat:{room_id}", pattern)
+	require.Equal(t, ChannelParams{"room_id": "index"}, params)
+
+	// Routes are matched in order of adding.
+	pattern, params, ok = r.Match("chat:index.typing")
+	require.True(t, ok)
+	require.Equal(t, "chat:{room_id}", pattern)
+	require.Equal(t, "index.typing", params.String("room_id"))
+
+	pattern, params, ok = r.Match("news")
+	require.True(t, ok)
+	require.Equal(t, "news", pattern)
+	require.Empty(t, params)
+
+	_, _, ok = r.Match("chat:")
+	require.False(t, ok)
+	_, _, ok = r.Match("unknown")
+	require.False(t, ok)
+}
+
+func TestChannelRouter_Handlers(t *testing.T) {
+	r := NewChannelRouter()
+	require.NoError(t, r.OnSubscribe("chat:{room_id:int}", func(e SubscribeEvent, p ChannelParams, cb SubscribeCallback) {
+		roomID, err := p.Int("room_id")
+		if err != nil || roomID != 1 {
+			cb(SubscribeReply{}, ErrorPermissionDenied)
+			return
+		}
+		cb(SubscribeReply{}, nil)
+	}))
+	require.NoError(t, r.Add("news"))
+
+	var subscribeErr error
+	subscribeCb := func(reply SubscribeReply, err error) { subscribeErr = err }
+	r.HandleSubscribe(SubscribeEvent{Channel: "chat:1"}, subscribeCb)
+	require.NoError(t, subscribeErr)
+	r.HandleSubscribe(SubscribeEvent{Channel: "chat:2"}, subscribeCb)
+	require.Equal(t, ErrorPermissionDenied, subscribeErr)
+	r.HandleSubscribe(SubscribeEvent{Channel: "news"}, subscribeCb)
+	require.Equal(t, ErrorUnknownChannel, subscribeErr)
+
+	var publishErr error
+	publishCb := func(reply PublishReply, err error) { publishErr = err }
+	r.HandlePublish(PublishEvent{Channel: "chat:1"}, publishCb)
+	require.Equal(t, ErrorPermissionDenied, publishErr)
+	r.HandlePublish(PublishEvent{Channel: "unknown"}, publishCb)
+	require.Equal(t, ErrorUnknownChannel, publishErr)
+
+	require.NoError(t, r.OnPublish("chat:{room_id:int}", func(e PublishEvent, p ChannelParams, cb PublishCallback) {
+		cb(PublishReply{}, nil)
+	}))
+	r.HandlePublish(PublishEvent{Channel: "chat:1"}, publishCb)
+	require.NoError(t, publishErr)
+}
@@ -0,0 +1,37 @@
+package centrifuge
+
+import (
+	"strings"
+)
+
+// userAllowedInChannel checks whether user is allowed to subscribe to channel when
+// Config.UserLimitedChannels is on. Channels without user boundary are not limited.
+func (n *Node) userAllowedInChannel(ch string, user string) bool {
+	if !n.config.UserLimitedChannels {
+		return true
+	}
+	boundary := n.config.UserChannelBoundary
+	if boundary == "" {
+		boundary = "#"
+	}
+	i := strings.LastIndex(ch, boundary)
+	if i < 0 {
+		return true
+	}
+	if user == "" {
+		return false
+	}
+	separator := n.config.UserChannelSeparator
+	if separator == "" {
+		separator = ","
+	}
+	users := ch[i+len(boundary):]
+	for users != "" {
+		var allowed string
+		allowed, users, _ = strings.Cut(users, separator)
+		if allowed == user {
+			return true
+		}
+	}
+	return false
+}
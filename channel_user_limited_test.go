@@ -0,0 +1,60 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_userAllowedInChannel(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	require.True(t, node.userAllowedInChannel("dialog#42,43", "1"))
+
+	node.config.UserLimitedChannels = true
+	require.True(t, node.userAllowedInChannel("dialog", "1"))
+	require.True(t, node.userAllowedInChannel("dialog#42,43", "42"))
+	require.True(t, node.userAllowedInChannel("dialog#42,43", "43"))
+	require.False(t, node.userAllowedInChannel("dialog#42,43", "4"))
+	require.False(t, node.userAllowedInChannel("dialog#42,43", ""))
+	require.False(t, node.userAllowedInChannel("dialog#", "42"))
+	require.True(t, node.userAllowedInChannel("a#b#42", "42"))
+
+	node.config.UserChannelBoundary = "$"
+	node.config.UserChannelSeparator = ";"
+	require.True(t, node.userAllowedInChannel("dialog$42;43", "43"))
+	require.True(t, node.userAllowedInChannel("dialog#42,43", "1"))
+}
+
+func TestClientSubscribe_UserLimitedChannel(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	node.config.UserLimitedChannels = true
+	handlerCalled := false
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(event SubscribeEvent, cb SubscribeCallback) {
+			handlerCalled = true
+			cb(SubscribeReply{}, nil)
+		})
+	})
+
+	client := newTestConnectedClientV2(t, node, "44")
+	rwWrapper := testReplyWriterWrapper()
+	err := client.handleSubscribe(&protocol.SubscribeRequest{Channel: "dialog#42,43"}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorPermissionDenied, err)
+	require.False(t, handlerCalled)
+	require.NotContains(t, client.channels, "dialog#42,43")
+
+	// Subscribe request validated before user-limited channel check.
+	node.config.ChannelMaxLength = 12
+	err = client.handleSubscribe(&protocol.SubscribeRequest{Channel: "dialog#42,430"}, &protocol.Command{Id: 2}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorBadRequest, err)
+	node.config.ChannelMaxLength = 0
+
+	client = newTestConnectedClientV2(t, node, "42")
+	subscribeClientV2(t, client, "dialog#42,43")
+	require.True(t, handlerCalled)
+}
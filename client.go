@@ -243,6 +243,8 @@ type Client struct {
 	session           string
 	user              string
 	info              []byte
+	tokenID           string
+	issuedAt          int64
 	storage           map[string]any
 	storageMu         sync.Mutex
 	authenticated     bool
@@ -255,6 +257,7 @@ type Client struct {
 	nextPong          int64
 	lastSeen          int64
 	lastPing          int64
+	connectedAt       time.Time
 	pingInterval      time.Duration
 	pongTimeout       time.Duration
 	eventHub          *clientEventHub
@@ -314,19 +317,16 @@ var uniErrorCodeToDisconnect = map[uint32]Disconnect{
 }
 
 func extractUnidirectionalDisconnect(err error) Disconnect {
-	switch t := err.(type) {
-	case *Disconnect:
-		return *t
-	case Disconnect:
-		return t
-	case *Error:
-		if d, ok := uniErrorCodeToDisconnect[t.Code]; ok {
+	if d, ok := asDisconnect(err); ok {
+		return d
+	}
+	var clientErr *Error
+	if errors.As(err, &clientErr) {
+		if d, ok := uniErrorCodeToDisconnect[clientErr.Code]; ok {
 			return d
 		}
-		return DisconnectServerError
-	default:
-		return DisconnectServerError
 	}
+	return DisconnectServerError
 }
 
 // Connect supposed to be called from unidirectional transport layer to pass
@@ -576,14 +576,40 @@ func (c *Client) closeStale() {
 }
 
 func (c *Client) transportEnqueue(data []byte, ch string, frameType protocol.FrameType) error {
+	return c.transportEnqueueItem(queue.Item{Data: data, FrameType: frameType}, ch, c.node.clientQueueCounters(ch))
+}
+
+// transportEnqueuePublication enqueues publication data taking into account
+// delivery options of publication.
+func (c *Client) transportEnqueuePublication(data []byte, ch string, prep preparedData) error {
 	item := queue.Item{
 		Data:      data,
-		FrameType: frameType,
+		Channel:   ch, // Queue needs channel to order publications with priority.
+		FrameType: protocol.FrameTypePushPublication,
+		Priority:  prep.priority,
+	}
+	if prep.freshnessTTL > 0 {
+		item.ExpireAt = time.Now().Add(prep.freshnessTTL).UnixNano()
 	}
+	queueCounters := prep.queueCounters
+	if queueCounters.enqueued == nil {
+		// Not resolved upon broadcast.
+		queueCounters = c.node.clientQueueCounters(ch)
+	}
+	return c.transportEnqueueItem(item, ch, queueCounters)
+}
+
+func (c *Client) transportEnqueueItem(item queue.Item, ch string, queueCounters clientQueueCounters) error {
 	if c.node.config.GetChannelNamespaceLabel != nil {
 		item.Channel = ch
 	}
 	disconnect := c.messageWriter.enqueue(item)
+	if queueCounters.enqueued != nil {
+		queueCounters.enqueued.Inc()
+		if disconnect != nil && disconnect.Code == DisconnectSlow.Code {
+			queueCounters.overflow.Inc()
+		}
+	}
 	if disconnect != nil {
 		// close in goroutine to not block message broadcast.
 		go func() { _ = c.close(*disconnect) }()
@@ -604,7 +630,7 @@ func (c *Client) updateChannelPresence(ch string, chCtx ChannelContext) error {
 		return nil
 	}
 	c.mu.RUnlock()
-	return c.node.addPresence(ch, c.uid, &ClientInfo{
+	return c.node.refreshPresence(ch, c.uid, &ClientInfo{
 		ClientID: c.uid,
 		UserID:   c.user,
 		ConnInfo: c.info,
@@ -947,7 +973,7 @@ func (c *Client) Disconnect(disconnect ...Disconnect) {
 }
 
 func (c *Client) close(disconnect Disconnect) error {
-	c.startWriter(0, 0, 0)
+	c.startWriter(0, 0, 0, 0)
 	c.presenceMu.Lock()
 	defer c.presenceMu.Unlock()
 	c.connectMu.Lock()
@@ -981,17 +1007,22 @@ func (c *Client) close(disconnect Disconnect) error {
 
 	c.mu.RLock()
 	authenticated := c.authenticated
+	connectedAt := c.connectedAt
 	c.mu.RUnlock()
 
 	if authenticated {
+		c.node.metrics.observeConnectionDuration(time.Since(connectedAt))
 		err := c.node.removeClient(c)
 		if err != nil {
 			c.node.logger.log(newLogEntry(LogLevelError, "error removing client", map[string]any{"user": c.user, "client": c.uid, "error": err.Error()}))
 		}
 	}
 
+	// Disconnect sent to a client may contain reconnect advice.
+	clientDisconnect := c.node.withReconnectAdvice(disconnect)
+
 	if disconnect.Code != DisconnectConnectionClosed.Code && !hasFlag(c.transport.DisabledPushFlags(), PushFlagDisconnect) {
-		if replyData, err := c.getDisconnectPushReply(disconnect); err == nil {
+		if replyData, err := c.getDisconnectPushReply(clientDisconnect); err == nil {
 			_ = c.transportEnqueue(replyData, "", protocol.FrameTypePushDisconnect)
 		}
 	}
@@ -999,7 +1030,7 @@ func (c *Client) close(disconnect Disconnect) error {
 	// close writer and send messages remaining in writer queue if any.
 	_ = c.messageWriter.close(disconnect != DisconnectConnectionClosed && disconnect != DisconnectSlow)
 
-	_ = c.transport.Close(disconnect)
+	_ = c.transport.Close(clientDisconnect)
 
 	if disconnect.Code != DisconnectConnectionClosed.Code {
 		c.node.logger.log(newLogEntry(LogLevelDebug, "closing client connection", map[string]any{"client": c.uid, "user": c.user, "reason": disconnect.Reason}))
@@ -1007,6 +1038,12 @@ func (c *Client) close(disconnect Disconnect) error {
 	if disconnect.Code != DisconnectConnectionClosed.Code {
 		c.node.metrics.incServerDisconnect(disconnect.Code)
 	}
+	if c.node.debugEventsEnabled() {
+		c.node.emitDebugEvent(DebugEvent{Type: DebugEventDisconnect, Client: c.uid, User: c.user, Code: disconnect.Code, Reason: disconnect.Reason})
+	}
+	if prevStatus == statusConnected {
+		c.node.emitWebhookEvent(WebhookEvent{Type: WebhookEventDisconnect, User: c.user, Client: c.uid, Code: disconnect.Code, Reason: disconnect.Reason})
+	}
 	if c.eventHub.disconnectHandler != nil && prevStatus == statusConnected {
 		c.eventHub.disconnectHandler(DisconnectEvent{
 			Disconnect: disconnect,
@@ -1103,9 +1140,19 @@ func isPong(cmd *protocol.Command) bool {
 	return cmd.Id == 0 && cmd.Send == nil
 }
 
+// observeCommandDuration records command duration metric and logs command if it
+// took longer than Config.SlowCommandThreshold.
+func (c *Client) observeCommandDuration(frameType protocol.FrameType, started time.Time) {
+	duration := time.Since(started)
+	c.node.metrics.observeCommandDuration(frameType, duration)
+	if threshold := c.node.reloadableConfig().slowCommandThreshold; threshold > 0 && duration >= threshold {
+		c.node.logger.log(newLogEntry(LogLevelWarn, "slow command", map[string]any{"command": frameType.String(), "client": c.ID(), "user": c.UserID(), "duration": duration.String()}))
+	}
+}
+
 func (c *Client) handleCommandFinished(cmd *protocol.Command, frameType protocol.FrameType, disconnect *Disconnect, reply *protocol.Reply, started time.Time) {
 	defer func() {
-		c.node.metrics.observeCommandDuration(frameType, time.Since(started))
+		c.observeCommandDuration(frameType, started)
 	}()
 	if c.node.clientEvents.commandProcessedHandler != nil {
 		event := newCommandProcessedEvent(cmd, disconnect, reply, started)
@@ -1115,35 +1162,27 @@ func (c *Client) handleCommandFinished(cmd *protocol.Command, frameType protocol
 
 func (c *Client) handleCommandDispatchError(ch string, cmd *protocol.Command, frameType protocol.FrameType, err error, started time.Time) (*Disconnect, bool) {
 	defer func() {
-		c.node.metrics.observeCommandDuration(frameType, time.Since(started))
+		c.observeCommandDuration(frameType, started)
 	}()
-	switch t := err.(type) {
-	case *Disconnect:
-		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, t, nil, started)
-			c.issueCommandProcessedEvent(event)
-		}
-		return t, false
-	case Disconnect:
-		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, &t, nil, started)
-			c.issueCommandProcessedEvent(event)
-		}
-		return &t, false
-	default:
-		if cmd.Connect != nil {
-			c.mu.Lock()
-			c.unusable = true
-			c.mu.Unlock()
-		}
-		errorReply := &protocol.Reply{Error: toClientErr(err).toProto()}
-		c.writeError(ch, frameType, cmd, errorReply, nil)
+	if d, ok := asDisconnect(err); ok {
 		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, nil, errorReply, started)
+			event := newCommandProcessedEvent(cmd, &d, nil, started)
 			c.issueCommandProcessedEvent(event)
 		}
-		return nil, cmd.Connect == nil
+		return &d, false
 	}
+	if cmd.Connect != nil {
+		c.mu.Lock()
+		c.unusable = true
+		c.mu.Unlock()
+	}
+	errorReply := &protocol.Reply{Error: toClientErr(err).toProto()}
+	c.writeError(ch, frameType, cmd, errorReply, nil)
+	if c.node.clientEvents.commandProcessedHandler != nil {
+		event := newCommandProcessedEvent(cmd, nil, errorReply, started)
+		c.issueCommandProcessedEvent(event)
+	}
+	return nil, cmd.Connect == nil
 }
 
 func (c *Client) dispatchCommand(cmd *protocol.Command, cmdSize int) (*Disconnect, bool) {
@@ -1162,8 +1201,8 @@ func (c *Client) dispatchCommand(cmd *protocol.Command, cmdSize int) (*Disconnec
 	var frameType protocol.FrameType
 	defer func() {
 		channelGroup := "_"
-		if metricChannel != "" && c.node.config.GetChannelNamespaceLabel != nil && c.node.config.ChannelNamespaceLabelForTransportMessagesReceived {
-			channelGroup = c.node.config.GetChannelNamespaceLabel(metricChannel)
+		if c.node.config.ChannelNamespaceLabelForTransportMessagesReceived {
+			channelGroup = c.node.channelNamespaceLabel(metricChannel)
 		}
 		c.node.metrics.incTransportMessagesReceived(c.transport.Name(), frameType, channelGroup, cmdSize)
 	}()
@@ -1235,37 +1274,50 @@ func (c *Client) dispatchCommand(cmd *protocol.Command, cmdSize int) (*Disconnec
 		return c.handleCommandDispatchError(metricChannel, cmd, frameType, handleErr, started)
 	}
 
+	if c.node.commandFunc != nil {
+		handleErr = c.node.commandFunc(c, CommandEvent{
+			Command:     cmd,
+			CommandSize: cmdSize,
+			FrameType:   frameType,
+			started:     started,
+		})
+	} else {
+		handleErr = c.handleCommand(cmd, started)
+	}
+	if handleErr != nil {
+		return c.handleCommandDispatchError(metricChannel, cmd, frameType, handleErr, started)
+	}
+	return nil, true
+}
+
+// handleCommand calls command handler according to command type.
+func (c *Client) handleCommand(cmd *protocol.Command, started time.Time) error {
 	if cmd.Connect != nil {
-		handleErr = c.handleConnect(cmd.Connect, cmd, started, nil)
+		return c.handleConnect(cmd.Connect, cmd, started, nil)
 	} else if cmd.Ping != nil {
-		handleErr = c.handlePing(cmd, started, nil)
+		return c.handlePing(cmd, started, nil)
 	} else if cmd.Subscribe != nil {
-		handleErr = c.handleSubscribe(cmd.Subscribe, cmd, started, nil)
+		return c.handleSubscribe(cmd.Subscribe, cmd, started, nil)
 	} else if cmd.Unsubscribe != nil {
-		handleErr = c.handleUnsubscribe(cmd.Unsubscribe, cmd, started, nil)
+		return c.handleUnsubscribe(cmd.Unsubscribe, cmd, started, nil)
 	} else if cmd.Publish != nil {
-		handleErr = c.handlePublish(cmd.Publish, cmd, started, nil)
+		return c.handlePublish(cmd.Publish, cmd, started, nil)
 	} else if cmd.Presence != nil {
-		handleErr = c.handlePresence(cmd.Presence, cmd, started, nil)
+		return c.handlePresence(cmd.Presence, cmd, started, nil)
 	} else if cmd.PresenceStats != nil {
-		handleErr = c.handlePresenceStats(cmd.PresenceStats, cmd, started, nil)
+		return c.handlePresenceStats(cmd.PresenceStats, cmd, started, nil)
 	} else if cmd.History != nil {
-		handleErr = c.handleHistory(cmd.History, cmd, started, nil)
+		return c.handleHistory(cmd.History, cmd, started, nil)
 	} else if cmd.Rpc != nil {
-		handleErr = c.handleRPC(cmd.Rpc, cmd, started, nil)
+		return c.handleRPC(cmd.Rpc, cmd, started, nil)
 	} else if cmd.Send != nil {
-		handleErr = c.handleSend(cmd.Send, cmd, started)
+		return c.handleSend(cmd.Send, cmd, started)
 	} else if cmd.Refresh != nil {
-		handleErr = c.handleRefresh(cmd.Refresh, cmd, started, nil)
+		return c.handleRefresh(cmd.Refresh, cmd, started, nil)
 	} else if cmd.SubRefresh != nil {
-		handleErr = c.handleSubRefresh(cmd.SubRefresh, cmd, started, nil)
-	} else {
-		return &DisconnectBadRequest, false
-	}
-	if handleErr != nil {
-		return c.handleCommandDispatchError(metricChannel, cmd, frameType, handleErr, started)
+		return c.handleSubRefresh(cmd.SubRefresh, cmd, started, nil)
 	}
-	return nil, true
+	return DisconnectBadRequest
 }
 
 func (c *Client) writeEncodedPush(rep *protocol.Reply, rw *replyWriter, ch string, frameType protocol.FrameType) {
@@ -1368,22 +1420,22 @@ func (c *Client) expire() {
 	if !clientSideRefresh && c.eventHub.refreshHandler != nil {
 		cb := func(reply RefreshReply, err error) {
 			if err != nil {
-				switch t := err.(type) {
-				case *Disconnect:
-					_ = c.close(*t)
-					return
-				case Disconnect:
-					_ = c.close(t)
-					return
-				default:
-					_ = c.close(DisconnectServerError)
+				if d, ok := asDisconnect(err); ok {
+					_ = c.close(d)
 					return
 				}
+				_ = c.close(DisconnectServerError)
+				return
 			}
 			if reply.Expired {
 				_ = c.close(DisconnectExpired)
 				return
 			}
+			c.applyRefreshToken(reply)
+			if c.tokenRevoked() {
+				_ = c.close(DisconnectInvalidToken)
+				return
+			}
 			if reply.ExpireAt > 0 {
 				c.mu.Lock()
 				c.exp = reply.ExpireAt
@@ -1496,6 +1548,53 @@ func (c *Client) Refresh(opts ...RefreshOption) error {
 	return c.transportEnqueue(replyData, "", protocol.FrameTypePushRefresh)
 }
 
+// UpdatePresence updates ClientInfo attached to client presence in channel without
+// resubscribing. If channel has join/leave messages enabled then join message with
+// updated ClientInfo is sent to channel – clients may treat it as presence update.
+// This is useful for things like typing indicators or user status. Connection info
+// change affects all client channels, presence in other channels is updated on next
+// periodic presence update. Does nothing if client is not subscribed to channel.
+func (c *Client) UpdatePresence(ch string, opts ...UpdatePresenceOption) error {
+	updateOpts := &UpdatePresenceOptions{}
+	for _, opt := range opts {
+		opt(updateOpts)
+	}
+
+	// Sync with periodic presence updates so they won't overwrite new info.
+	c.presenceMu.Lock()
+	defer c.presenceMu.Unlock()
+
+	c.mu.Lock()
+	if c.status == statusClosed {
+		c.mu.Unlock()
+		return io.EOF
+	}
+	chCtx, ok := c.channels[ch]
+	if !ok || !channelHasFlag(chCtx.flags, flagSubscribed) {
+		c.mu.Unlock()
+		return nil
+	}
+	if updateOpts.ConnInfo != nil {
+		c.info = updateOpts.ConnInfo
+	}
+	if updateOpts.ChanInfo != nil {
+		chCtx.info = updateOpts.ChanInfo
+		c.channels[ch] = chCtx
+	}
+	info := c.clientInfo(ch)
+	c.mu.Unlock()
+
+	if channelHasFlag(chCtx.flags, flagEmitPresence) {
+		if err := c.node.addPresence(ch, c.uid, info); err != nil {
+			return err
+		}
+	}
+	if channelHasFlag(chCtx.flags, flagEmitJoinLeave) {
+		return c.node.publishJoin(ch, info)
+	}
+	return nil
+}
+
 func (c *Client) getRefreshPushReply(res *protocol.Refresh) ([]byte, error) {
 	return c.encodeReply(&protocol.Reply{
 		Push: &protocol.Push{
@@ -1546,6 +1645,12 @@ func (c *Client) handleRefresh(req *protocol.RefreshRequest, cmd *protocol.Comma
 			return
 		}
 
+		c.applyRefreshToken(reply)
+		if c.tokenRevoked() {
+			c.writeDisconnectOrErrorFlush("", protocol.FrameTypeRefresh, cmd, DisconnectInvalidToken, started, rw)
+			return
+		}
+
 		expireAt := reply.ExpireAt
 		info := reply.Info
 
@@ -1631,6 +1736,24 @@ func (c *Client) handleSubscribe(req *protocol.SubscribeRequest, cmd *protocol.C
 		return replyError
 	}
 
+	chOpts, ok := c.node.ChannelOptions(req.Channel)
+	if !ok {
+		c.onSubscribeError(req.Channel)
+		c.node.logger.log(newLogEntry(LogLevelInfo, "subscription to channel of unknown namespace", map[string]any{"channel": req.Channel, "user": c.user, "client": c.uid}))
+		return ErrorUnknownChannel
+	}
+	if chOpts.MaxSubscribers > 0 && c.node.hub.NumSubscribers(req.Channel) >= chOpts.MaxSubscribers {
+		c.onSubscribeError(req.Channel)
+		c.node.logger.log(newLogEntry(LogLevelInfo, "maximum limit of channel subscribers reached", map[string]any{"channel": req.Channel, "limit": chOpts.MaxSubscribers, "user": c.user, "client": c.uid}))
+		return ErrorLimitExceeded
+	}
+
+	if !c.node.userAllowedInChannel(req.Channel, c.user) {
+		c.onSubscribeError(req.Channel)
+		c.node.logger.log(newLogEntry(LogLevelInfo, "user not allowed in user-limited channel", map[string]any{"channel": req.Channel, "user": c.user, "client": c.uid}))
+		return ErrorPermissionDenied
+	}
+
 	event := SubscribeEvent{
 		Channel:     req.Channel,
 		Token:       req.Token,
@@ -1651,6 +1774,19 @@ func (c *Client) handleSubscribe(req *protocol.SubscribeRequest, cmd *protocol.C
 			return
 		}
 
+		if c.node.isWildcardChannel(req.Channel) {
+			// Pattern is not a stream.
+			reply.Options.EnablePositioning = false
+			reply.Options.EnableRecovery = false
+		}
+		if chOpts.Presence {
+			reply.Options.EmitPresence = true
+		}
+		if chOpts.JoinLeave {
+			reply.Options.EmitJoinLeave = true
+			reply.Options.PushJoinLeave = true
+		}
+
 		ctx := c.subscribeCmd(req, reply, cmd, false, started, rw)
 
 		if ctx.disconnect != nil {
@@ -1796,7 +1932,8 @@ func (c *Client) getUnsubscribeCommandReply(res *protocol.UnsubscribeResult) (*p
 }
 
 func (c *Client) handlePublish(req *protocol.PublishRequest, cmd *protocol.Command, started time.Time, rw *replyWriter) error {
-	if c.eventHub.publishHandler == nil {
+	chOpts, ok := c.node.ChannelOptions(req.Channel)
+	if c.eventHub.publishHandler == nil && !(ok && chOpts.AllowPublishForClient) {
 		return ErrorNotAvailable
 	}
 
@@ -1807,6 +1944,15 @@ func (c *Client) handlePublish(req *protocol.PublishRequest, cmd *protocol.Comma
 		return c.logDisconnectBadRequest("channel and data required for publish")
 	}
 
+	if !ok {
+		c.node.logger.log(newLogEntry(LogLevelInfo, "publication to channel of unknown namespace", map[string]any{"channel": channel, "user": c.user, "client": c.uid}))
+		return ErrorUnknownChannel
+	}
+	if maxSize := c.node.publicationMaxSize(channel); maxSize > 0 && len(data) > maxSize {
+		c.node.logger.log(newLogEntry(LogLevelInfo, "publication too large", map[string]any{"channel": channel, "size": len(data), "max": maxSize, "user": c.user, "client": c.uid}))
+		return ErrorPublicationTooLarge
+	}
+
 	c.mu.RLock()
 	info := c.clientInfo(channel)
 	c.mu.RUnlock()
@@ -1830,6 +1976,12 @@ func (c *Client) handlePublish(req *protocol.PublishRequest, cmd *protocol.Comma
 				WithClientInfo(reply.Options.ClientInfo),
 			)
 			if err != nil {
+				var clientErr *Error
+				var disconnect Disconnect
+				if errors.As(err, &clientErr) || errors.As(err, &disconnect) {
+					c.writeDisconnectOrErrorFlush(channel, protocol.FrameTypePublish, cmd, err, started, rw)
+					return
+				}
 				c.logWriteInternalErrorFlush(channel, protocol.FrameTypePublish, cmd, err, "error publish", started, rw)
 				return
 			}
@@ -1845,6 +1997,11 @@ func (c *Client) handlePublish(req *protocol.PublishRequest, cmd *protocol.Comma
 		c.releasePublishCommandReply(protoReply)
 	}
 
+	if c.eventHub.publishHandler == nil {
+		// Allowed by channel namespace options.
+		cb(PublishReply{}, nil)
+		return nil
+	}
 	c.eventHub.publishHandler(event, cb)
 	return nil
 }
@@ -2078,30 +2235,21 @@ func (c *Client) writeError(ch string, frameType protocol.FrameType, cmd *protoc
 
 func (c *Client) writeDisconnectOrErrorFlush(ch string, frameType protocol.FrameType, cmd *protocol.Command, replyError error, started time.Time, rw *replyWriter) {
 	defer func() {
-		c.node.metrics.observeCommandDuration(frameType, time.Since(started))
+		c.observeCommandDuration(frameType, started)
 	}()
-	switch t := replyError.(type) {
-	case *Disconnect:
-		go func() { _ = c.close(*t) }()
-		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, t, nil, started)
-			c.issueCommandProcessedEvent(event)
-		}
-		return
-	case Disconnect:
-		go func() { _ = c.close(t) }()
+	if d, ok := asDisconnect(replyError); ok {
+		go func() { _ = c.close(d) }()
 		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, &t, nil, started)
+			event := newCommandProcessedEvent(cmd, &d, nil, started)
 			c.issueCommandProcessedEvent(event)
 		}
 		return
-	default:
-		errorReply := &protocol.Reply{Error: toClientErr(replyError).toProto()}
-		c.writeError(ch, frameType, cmd, errorReply, rw)
-		if c.node.clientEvents.commandProcessedHandler != nil {
-			event := newCommandProcessedEvent(cmd, nil, errorReply, started)
-			c.issueCommandProcessedEvent(event)
-		}
+	}
+	errorReply := &protocol.Reply{Error: toClientErr(replyError).toProto()}
+	c.writeError(ch, frameType, cmd, errorReply, rw)
+	if c.node.clientEvents.commandProcessedHandler != nil {
+		event := newCommandProcessedEvent(cmd, nil, errorReply, started)
+		c.issueCommandProcessedEvent(event)
 	}
 }
 
@@ -2152,7 +2300,7 @@ func (c *Client) getRPCCommandReply(res *protocol.RPCResult) (*protocol.Reply, e
 func (c *Client) handleSend(req *protocol.SendRequest, cmd *protocol.Command, started time.Time) error {
 	// Send handler is a bit special since it's a one way command: client does not expect any reply.
 	if c.eventHub.messageHandler == nil {
-		c.node.metrics.observeCommandDuration(protocol.FrameTypeSend, time.Since(started))
+		c.observeCommandDuration(protocol.FrameTypeSend, started)
 		// Return DisconnectNotAvailable here since otherwise client won't even know
 		// server does not have asynchronous message handler set.
 		return DisconnectNotAvailable
@@ -2216,7 +2364,7 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 		}
 		reply, err := c.node.clientEvents.connectingHandler(c.ctx, e)
 		if err != nil {
-			c.startWriter(0, 0, 0)
+			c.startWriter(0, 0, 0, 0)
 			return nil, err
 		}
 		if reply.PingPongConfig != nil {
@@ -2225,7 +2373,7 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 			c.pingInterval, c.pongTimeout = getPingPongPeriodValues(c.transport.PingPongConfig())
 		}
 		c.replyWithoutQueue = reply.ReplyWithoutQueue
-		c.startWriter(reply.WriteDelay, reply.MaxMessagesInFrame, reply.QueueInitialCap)
+		c.startWriter(reply.WriteDelay, reply.MaxMessagesInFrame, reply.MaxFrameSize, reply.QueueInitialCap)
 
 		if reply.Credentials != nil {
 			credentials = reply.Credentials
@@ -2250,7 +2398,7 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 			}
 		}
 	} else {
-		c.startWriter(0, 0, 0)
+		c.startWriter(0, 0, 0, 0)
 		c.pingInterval, c.pongTimeout = getPingPongPeriodValues(c.transport.PingPongConfig())
 	}
 
@@ -2282,6 +2430,8 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 	c.user = credentials.UserID
 	c.info = credentials.Info
 	c.exp = credentials.ExpireAt
+	c.tokenID = credentials.TokenID
+	c.issuedAt = credentials.IssuedAt
 
 	user := c.user
 	exp := c.exp
@@ -2292,6 +2442,11 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 		return nil, DisconnectConnectionClosed
 	}
 
+	if c.tokenRevoked() {
+		c.node.logger.log(newLogEntry(LogLevelInfo, "connection token revoked", map[string]any{"client": c.uid, "user": user}))
+		return nil, DisconnectInvalidToken
+	}
+
 	if c.node.LogEnabled(LogLevelDebug) {
 		c.node.logger.log(newLogEntry(LogLevelDebug, "client authenticated", map[string]any{"client": c.uid, "user": c.user}))
 	}
@@ -2330,7 +2485,7 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 	if c.transport.Unidirectional() || c.transport.Emulation() {
 		res.Session = c.session
 	}
-	if c.transport.Emulation() {
+	if c.transport.Emulation() || c.node.config.ClientConnectIncludeNodeID {
 		res.Node = c.node.ID()
 	}
 	if c.node.config.ClientConnectIncludeServerTime {
@@ -2340,6 +2495,7 @@ func (c *Client) connectCmd(req *protocol.ConnectRequest, cmd *protocol.Command,
 	// Client successfully connected.
 	c.mu.Lock()
 	c.authenticated = true
+	c.connectedAt = time.Now()
 	c.mu.Unlock()
 
 	err := c.node.addClient(c)
@@ -2472,20 +2628,21 @@ func (c *Client) getConnectPushReply(res *protocol.ConnectResult) (*protocol.Rep
 	}, nil
 }
 
-func (c *Client) startWriter(batchDelay time.Duration, maxMessagesInFrame int, queueInitialCap int) {
+func (c *Client) startWriter(batchDelay time.Duration, maxMessagesInFrame int, maxFrameSize int, queueInitialCap int) {
 	c.startWriterOnce.Do(func() {
 		var writeMu sync.Mutex
 		messageWriterConf := writerConfig{
-			MaxQueueSize: c.node.config.ClientQueueMaxSize,
+			MaxQueueSize: c.node.reloadableConfig().clientQueueMaxSize,
+			MaxFrameSize: maxFrameSize,
 			WriteFn: func(item queue.Item) error {
 				channelGroup := "_"
-				if item.Channel != "" && c.node.config.GetChannelNamespaceLabel != nil && c.node.config.ChannelNamespaceLabelForTransportMessagesSent {
-					channelGroup = c.node.config.GetChannelNamespaceLabel(item.Channel)
+				if c.node.config.ChannelNamespaceLabelForTransportMessagesSent {
+					channelGroup = c.node.channelNamespaceLabel(item.Channel)
 				}
 				c.node.metrics.incTransportMessagesSent(c.transport.Name(), item.FrameType, channelGroup, len(item.Data))
 
 				if c.node.clientEvents.transportWriteHandler != nil {
-					pass := c.node.clientEvents.transportWriteHandler(c, TransportWriteEvent(item))
+					pass := c.node.clientEvents.transportWriteHandler(c, TransportWriteEvent{Data: item.Data, Channel: item.Channel, FrameType: item.FrameType})
 					if !pass {
 						return nil
 					}
@@ -2509,15 +2666,15 @@ func (c *Client) startWriter(batchDelay time.Duration, maxMessagesInFrame int, q
 				messages := make([][]byte, 0, len(items))
 				for i := 0; i < len(items); i++ {
 					if c.node.clientEvents.transportWriteHandler != nil {
-						pass := c.node.clientEvents.transportWriteHandler(c, TransportWriteEvent(items[i]))
+						pass := c.node.clientEvents.transportWriteHandler(c, TransportWriteEvent{Data: items[i].Data, Channel: items[i].Channel, FrameType: items[i].FrameType})
 						if !pass {
 							continue
 						}
 					}
 					messages = append(messages, items[i].Data)
 					channelGroup := "_"
-					if items[i].Channel != "" && c.node.config.GetChannelNamespaceLabel != nil && c.node.config.ChannelNamespaceLabelForTransportMessagesSent {
-						channelGroup = c.node.config.GetChannelNamespaceLabel(items[i].Channel)
+					if c.node.config.ChannelNamespaceLabelForTransportMessagesSent {
+						channelGroup = c.node.channelNamespaceLabel(items[i].Channel)
 					}
 					c.node.metrics.incTransportMessagesSent(c.transport.Name(), items[i].FrameType, channelGroup, len(items[i].Data))
 				}
@@ -2773,11 +2930,15 @@ func (c *Client) subscribeCmd(req *protocol.SubscribeRequest, reply SubscribeRep
 			sub.deltaType = dt
 		}
 	}
+	if sub.deltaType == deltaTypeNone {
+		sub.compression = reply.Options.Compression
+	}
 	err := c.node.addSubscription(channel, sub)
 	if err != nil {
 		c.node.logger.log(newLogEntry(LogLevelError, "error adding subscription", map[string]any{"channel": channel, "user": c.user, "client": c.uid, "error": err.Error()}))
 		c.pubSubSync.StopBuffering(channel)
-		if clientErr, ok := err.(*Error); ok && clientErr != ErrorInternal {
+		var clientErr *Error
+		if errors.As(err, &clientErr) && clientErr != ErrorInternal {
 			return errorDisconnectContext(clientErr, nil)
 		}
 		ctx.disconnect = &DisconnectServerError
@@ -3128,7 +3289,7 @@ func (c *Client) writePublicationUpdatePosition(ch string, pub *protocol.Publica
 		}
 		if prep.deltaSub {
 			if deltaAllowed {
-				return c.transportEnqueue(prep.localDeltaData, ch, protocol.FrameTypePushPublication)
+				return c.transportEnqueuePublication(prep.localDeltaData, ch, prep)
 			}
 			c.mu.Lock()
 			if chCtx, chCtxOK := c.channels[ch]; chCtxOK {
@@ -3137,7 +3298,7 @@ func (c *Client) writePublicationUpdatePosition(ch string, pub *protocol.Publica
 			}
 			c.mu.Unlock()
 		}
-		return c.transportEnqueue(prep.fullData, ch, protocol.FrameTypePushPublication)
+		return c.transportEnqueuePublication(prep.fullData, ch, prep)
 	}
 	serverSide := channelHasFlag(channelContext.flags, flagServerSide)
 	currentPositionOffset := channelContext.streamPosition.Offset
@@ -3200,7 +3361,7 @@ func (c *Client) writePublicationUpdatePosition(ch string, pub *protocol.Publica
 	}
 	if prep.deltaSub {
 		if deltaAllowed {
-			return c.transportEnqueue(prep.brokerDeltaData, ch, protocol.FrameTypePushPublication)
+			return c.transportEnqueuePublication(prep.brokerDeltaData, ch, prep)
 		}
 		c.mu.Lock()
 		if chCtx, chCtxOK := c.channels[ch]; chCtxOK {
@@ -3209,7 +3370,7 @@ func (c *Client) writePublicationUpdatePosition(ch string, pub *protocol.Publica
 		}
 		c.mu.Unlock()
 	}
-	return c.transportEnqueue(prep.fullData, ch, protocol.FrameTypePushPublication)
+	return c.transportEnqueuePublication(prep.fullData, ch, prep)
 }
 
 func (c *Client) writePublicationNoDelta(ch string, pub *protocol.Publication, data []byte, sp StreamPosition) error {
@@ -3238,7 +3399,7 @@ func (c *Client) writePublication(ch string, pub *protocol.Publication, prep pre
 			c.mu.RUnlock()
 
 			if deltaAllowed {
-				return c.transportEnqueue(prep.localDeltaData, ch, protocol.FrameTypePushPublication)
+				return c.transportEnqueuePublication(prep.localDeltaData, ch, prep)
 			}
 			c.mu.Lock()
 			if chCtx, chCtxOK := c.channels[ch]; chCtxOK {
@@ -3247,7 +3408,7 @@ func (c *Client) writePublication(ch string, pub *protocol.Publication, prep pre
 			}
 			c.mu.Unlock()
 		}
-		return c.transportEnqueue(prep.fullData, ch, protocol.FrameTypePushPublication)
+		return c.transportEnqueuePublication(prep.fullData, ch, prep)
 	}
 	c.pubSubSync.SyncPublication(ch, pub, func() {
 		_ = c.writePublicationUpdatePosition(ch, pub, prep, sp, maxLagExceeded)
@@ -3349,6 +3510,7 @@ func (c *Client) unsubscribe(channel string, unsubscribe Unsubscribe, disconnect
 	}
 
 	if channelHasFlag(chCtx.flags, flagSubscribed) {
+		c.node.emitWebhookEvent(WebhookEvent{Type: WebhookEventUnsubscribe, User: c.user, Client: c.uid, Channel: channel, Code: unsubscribe.Code, Reason: unsubscribe.Reason})
 		if c.eventHub.unsubscribeHandler != nil {
 			c.eventHub.unsubscribeHandler(UnsubscribeEvent{
 				Channel:     channel,
@@ -3373,9 +3535,10 @@ func (c *Client) logDisconnectBadRequest(message string) error {
 
 func (c *Client) logWriteInternalErrorFlush(ch string, frameType protocol.FrameType, cmd *protocol.Command, err error, message string, started time.Time, rw *replyWriter) {
 	defer func() {
-		c.node.metrics.observeCommandDuration(frameType, time.Since(started))
+		c.observeCommandDuration(frameType, started)
 	}()
-	if clientErr, ok := err.(*Error); ok {
+	var clientErr *Error
+	if errors.As(err, &clientErr) {
 		errorReply := &protocol.Reply{Error: clientErr.toProto()}
 		c.writeError(ch, frameType, cmd, errorReply, rw)
 		return
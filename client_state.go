@@ -0,0 +1,178 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// ErrClientNotFound returned by Node.ClientState when client with provided ID
+// is not connected to any node.
+var ErrClientNotFound = errors.New("client not found")
+
+// ClientState is a snapshot of client connection state for support tooling.
+type ClientState struct {
+	// NodeID is an ID of node client connected to.
+	NodeID string `json:"node_id"`
+	// ClientID is a unique client connection ID.
+	ClientID string `json:"client_id"`
+	// UserID of connection, empty for anonymous connection.
+	UserID string `json:"user_id"`
+	// Session is a session ID of unidirectional and emulation connections.
+	Session string `json:"session,omitempty"`
+	// Authenticated is true when client successfully sent connect command.
+	Authenticated bool `json:"authenticated"`
+	// ConnectedAt is a time when connection was authenticated.
+	ConnectedAt time.Time `json:"connected_at"`
+	// ExpireAt is a time when connection credentials expire, zero if credentials do
+	// not expire.
+	ExpireAt time.Time `json:"expire_at"`
+	// Transport details of connection.
+	Transport ClientTransportState `json:"transport"`
+	// Queue contains stats of connection message queue.
+	Queue ClientQueueState `json:"queue"`
+	// Channels connection subscribed to (or currently subscribing to).
+	Channels map[string]ClientChannelState `json:"channels,omitempty"`
+}
+
+// ClientTransportState contains transport details of connection.
+type ClientTransportState struct {
+	Name            string          `json:"name"`
+	Protocol        ProtocolType    `json:"protocol"`
+	ProtocolVersion ProtocolVersion `json:"protocol_version"`
+	Unidirectional  bool            `json:"unidirectional"`
+	Emulation       bool            `json:"emulation"`
+}
+
+// ClientQueueState contains stats of connection message queue.
+type ClientQueueState struct {
+	// Len is a number of messages waiting in queue.
+	Len int `json:"len"`
+	// Size is a total size of messages in queue in bytes.
+	Size int `json:"size"`
+	// MaxSize is a max size of queue in bytes, connection is disconnected when exceeded.
+	MaxSize int `json:"max_size"`
+}
+
+// ClientChannelState contains state of connection subscription.
+type ClientChannelState struct {
+	// Subscribed is false while subscription is still in progress.
+	Subscribed bool `json:"subscribed"`
+	// ServerSide is true for server-side subscriptions.
+	ServerSide bool `json:"server_side"`
+	// Positioned is true when position of client in channel stream is tracked.
+	Positioned bool `json:"positioned"`
+	// Offset and Epoch of the last publication client received in positioned channel.
+	Offset uint64 `json:"offset,omitempty"`
+	Epoch  string `json:"epoch,omitempty"`
+	// ExpireAt is a time when subscription expires, zero if subscription does not expire.
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+type clientStateRequest struct {
+	Client string `json:"client"`
+}
+
+type clientStateResponse struct {
+	State *ClientState `json:"state,omitempty"`
+}
+
+// ClientState returns a snapshot of client connection with provided ID: subscribed
+// channels with stream positions, message queue stats, transport details and credentials
+// expiration. If client is not connected to the current Node then it's searched on all
+// nodes using Survey. ErrClientNotFound returned if client was not found.
+func (n *Node) ClientState(ctx context.Context, clientID string) (ClientState, error) {
+	if c, ok := n.hub.clientByID(clientID); ok {
+		return c.state(), nil
+	}
+	data, err := json.Marshal(clientStateRequest{Client: clientID})
+	if err != nil {
+		return ClientState{}, err
+	}
+	results, err := n.Survey(ctx, clientStateOp, data, "")
+	if err != nil {
+		return ClientState{}, err
+	}
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return ClientState{}, fmt.Errorf("unexpected client state survey code from node %s: %d", nodeID, result.Code)
+		}
+		var resp clientStateResponse
+		if err := json.Unmarshal(result.Data, &resp); err != nil {
+			return ClientState{}, err
+		}
+		if resp.State != nil {
+			return *resp.State, nil
+		}
+	}
+	return ClientState{}, ErrClientNotFound
+}
+
+func (n *Node) handleClientStateSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req clientStateRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error unmarshal client state request", map[string]any{"error": err.Error()}))
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	var resp clientStateResponse
+	if c, ok := n.hub.clientByID(req.Client); ok {
+		state := c.state()
+		resp.State = &state
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+func (c *Client) state() ClientState {
+	c.mu.RLock()
+	state := ClientState{
+		NodeID:        c.node.ID(),
+		ClientID:      c.uid,
+		UserID:        c.user,
+		Session:       c.session,
+		Authenticated: c.authenticated,
+		ConnectedAt:   c.connectedAt,
+		Transport: ClientTransportState{
+			Name:            c.transport.Name(),
+			Protocol:        c.transport.Protocol(),
+			ProtocolVersion: c.transport.ProtocolVersion(),
+			Unidirectional:  c.transport.Unidirectional(),
+			Emulation:       c.transport.Emulation(),
+		},
+		Channels: make(map[string]ClientChannelState, len(c.channels)),
+	}
+	if c.exp > 0 {
+		state.ExpireAt = time.Unix(c.exp, 0)
+	}
+	for ch, chCtx := range c.channels {
+		chState := ClientChannelState{
+			Subscribed: channelHasFlag(chCtx.flags, flagSubscribed),
+			ServerSide: channelHasFlag(chCtx.flags, flagServerSide),
+			Positioned: channelHasFlag(chCtx.flags, flagPositioning),
+			Offset:     chCtx.streamPosition.Offset,
+			Epoch:      chCtx.streamPosition.Epoch,
+		}
+		if chCtx.expireAt > 0 {
+			chState.ExpireAt = time.Unix(chCtx.expireAt, 0)
+		}
+		state.Channels[ch] = chState
+	}
+	messageWriter := c.messageWriter
+	c.mu.RUnlock()
+	if messageWriter != nil {
+		state.Queue = ClientQueueState{
+			Len:     messageWriter.messages.Len(),
+			Size:    messageWriter.messages.Size(),
+			MaxSize: int(messageWriter.maxQueueSize.Load()),
+		}
+	}
+	return state
+}
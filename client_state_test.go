@@ -0,0 +1,71 @@
+package centrifuge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_ClientState(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client := newTestSubscribedClientV2(t, node, "42", "test")
+
+	state, err := node.ClientState(context.Background(), client.ID())
+	require.NoError(t, err)
+	require.Equal(t, node.ID(), state.NodeID)
+	require.Equal(t, client.ID(), state.ClientID)
+	require.Equal(t, "42", state.UserID)
+	require.True(t, state.Authenticated)
+	require.Equal(t, transportWebsocket, state.Transport.Name)
+	require.Contains(t, state.Channels, "test")
+	require.True(t, state.Channels["test"].Subscribed)
+	require.Equal(t, node.config.ClientQueueMaxSize, state.Queue.MaxSize)
+
+	_, err = node.ClientState(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrClientNotFound)
+}
+
+func TestNode_ClientStateSurvey(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	client := newTestSubscribedClientV2(t, node, "42", "test")
+
+	var reply SurveyReply
+	node.handleClientStateSurvey(SurveyEvent{Op: clientStateOp, Data: []byte(`{"client":"` + client.ID() + `"}`)}, func(r SurveyReply) {
+		reply = r
+	})
+	require.Zero(t, reply.Code)
+	var resp clientStateResponse
+	require.NoError(t, json.Unmarshal(reply.Data, &resp))
+	require.NotNil(t, resp.State)
+	require.Equal(t, client.ID(), resp.State.ClientID)
+}
+
+func TestDebugHandler_ClientState(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	client := newTestSubscribedClientV2(t, node, "42", "test")
+	h, err := NewDebugHandler(DebugConfig{Token: "secret", Node: node})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/clients/"+client.ID(), nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var state ClientState
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	require.Equal(t, client.ID(), state.ClientID)
+
+	req = httptest.NewRequest(http.MethodGet, "/debug/clients/unknown", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -286,10 +286,33 @@ func TestClientConnectContextCredentials(t *testing.T) {
 	require.Equal(t, false, result.Expires)
 	require.Equal(t, uint32(0), result.Ttl)
 	require.NotZero(t, result.Time)
+	require.Empty(t, result.Node)
 	require.True(t, client.authenticated)
 	require.Equal(t, "42", client.UserID())
 }
 
+func TestClientConnectIncludeNodeID(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	node.config.ClientConnectIncludeNodeID = true
+
+	client := newTestClient(t, node, "42")
+	rwWrapper := testReplyWriterWrapper()
+	_, err := client.connectCmd(&protocol.ConnectRequest{}, &protocol.Command{}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	result := extractConnectReply(rwWrapper.replies)
+	require.Equal(t, node.ID(), result.Node)
+	require.Zero(t, result.Time)
+}
+
+func TestNodeServerFeatures(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	features := node.ServerFeatures()
+	require.True(t, features.Has(ServerFeatureDelta|ServerFeatureRecovery))
+	require.True(t, features.Has(ServerFeatureBatching))
+}
+
 func TestClientRefreshHandlerClosingExpiredClient(t *testing.T) {
 	node := defaultTestNode()
 	defer func() { _ = node.Shutdown(context.Background()) }()
@@ -1705,6 +1728,34 @@ func TestClientPublishError(t *testing.T) {
 	require.Equal(t, ErrorInternal.toProto(), rwWrapper.replies[0].Error)
 }
 
+func TestClientPublishMiddlewareError(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	node.UsePublishMiddleware(func(next PublishFunc) PublishFunc {
+		return func(channel string, data []byte, opts PublishOptions) (PublishResult, error) {
+			return PublishResult{}, ErrorLimitExceeded
+		}
+	})
+
+	node.OnConnect(func(client *Client) {
+		client.OnPublish(func(event PublishEvent, cb PublishCallback) {
+			cb(PublishReply{}, nil)
+		})
+	})
+
+	client := newTestClient(t, node, "42")
+	connectClientV2(t, client)
+
+	rwWrapper := testReplyWriterWrapper()
+	err := client.handlePublish(&protocol.PublishRequest{
+		Channel: "test",
+		Data:    []byte(`{}`),
+	}, &protocol.Command{}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	require.Equal(t, ErrorLimitExceeded.toProto(), rwWrapper.replies[0].Error)
+}
+
 func TestClientPing(t *testing.T) {
 	node := defaultTestNode()
 	defer func() { _ = node.Shutdown(context.Background()) }()
@@ -3573,7 +3624,7 @@ func TestClient_HandleCommandV2_Pong(t *testing.T) {
 	defer func() { _ = node.Shutdown(context.Background()) }()
 	clientV2 := newTestClientV2(t, node, "42")
 
-	clientV2.startWriter(0, 0, 0)
+	clientV2.startWriter(0, 0, 0, 0)
 	clientV2.sendPing()
 	ok := clientV2.HandleCommand(&protocol.Command{
 		Connect: &protocol.ConnectRequest{},
@@ -3871,3 +3922,64 @@ func TestClientUnsubscribeDuringSubscribeWithError(t *testing.T) {
 	err := client.close(DisconnectForceNoReconnect)
 	require.NoError(t, err)
 }
+
+func TestClient_UpdatePresence(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(e SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{
+				Options: SubscribeOptions{
+					EmitPresence:  true,
+					EmitJoinLeave: true,
+					PushJoinLeave: true,
+					ChannelInfo:   []byte(`{"typing":false}`),
+				},
+			}, nil)
+		})
+	})
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	transport := newTestTransport(cancelFn)
+	transport.sink = make(chan []byte, 100)
+	client := newTestSubscribedClientWithTransport(t, ctx, node, transport, "42", "test")
+
+	waitJoin := func(substr string) {
+		t.Helper()
+		for {
+			select {
+			case data := <-transport.sink:
+				if strings.Contains(string(data), `"join"`) && strings.Contains(string(data), substr) {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("no join with updated info")
+			}
+		}
+	}
+
+	require.NoError(t, client.UpdatePresence("test", WithPresenceChanInfo([]byte(`{"typing":true}`))))
+	result, err := node.Presence("test")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"typing":true}`), result.Presence[client.ID()].ChanInfo)
+	waitJoin(`"typing":true`)
+
+	// Not subscribed channel is ignored.
+	require.NoError(t, client.UpdatePresence("not_subscribed", WithPresenceChanInfo([]byte(`{}`))))
+
+	// Update over Node API.
+	require.NoError(t, node.UpdatePresence("42", "test", WithPresenceConnInfo([]byte(`{"status":"away"}`)), WithUpdatePresenceClient(client.ID())))
+	result, err = node.Presence("test")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"status":"away"}`), result.Presence[client.ID()].ConnInfo)
+	require.Equal(t, []byte(`{"typing":true}`), result.Presence[client.ID()].ChanInfo)
+	waitJoin(`"status":"away"`)
+
+	// Periodic presence update keeps new info.
+	client.updatePresence()
+	result, err = node.Presence("test")
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"status":"away"}`), result.Presence[client.ID()].ConnInfo)
+}
@@ -0,0 +1,647 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/hll"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// Survey ops used by Centrifuge library to collect cluster-wide information
+// and to apply changes to connections on all nodes.
+const (
+	channelsOp           = "centrifuge_channels"
+	connectionsOp        = "centrifuge_connections"
+	runtimeStatsOp       = "centrifuge_runtime_stats"
+	updatePresenceOp     = "centrifuge_update_presence"
+	onlineUsersOp        = "centrifuge_online_users"
+	channelSubscribersOp = "centrifuge_channel_subscribers"
+	clientStateOp        = "centrifuge_client_state"
+)
+
+// ChannelInfo contains aggregated information about channel.
+type ChannelInfo struct {
+	// NumSubscribers is a total number of channel subscribers on all nodes.
+	NumSubscribers int
+	// PublicationRate is a number of publications per second delivered to channel
+	// subscribers. It's calculated over the last 10 seconds, so newly
+	// active channels may have zero rate for some time.
+	PublicationRate float64
+}
+
+type channelsRequest struct {
+	Pattern string `json:"pattern,omitempty"`
+	// TopLimit if set makes node reply only with TopLimit channels ordered by TopOrder.
+	TopLimit int              `json:"top_limit,omitempty"`
+	TopOrder TopChannelsOrder `json:"top_order,omitempty"`
+}
+
+type channelStatsItem struct {
+	NumSubscribers  int     `json:"n"`
+	PublicationRate float64 `json:"r,omitempty"`
+}
+
+type channelsResponse struct {
+	Channels map[string]channelStatsItem `json:"channels,omitempty"`
+}
+
+// Channels returns active channels in a cluster with number of subscribers in each
+// channel. Information is collected from all running nodes using Survey, so the
+// same considerations about Survey scalability apply here. For channels of the
+// current Node only see Hub.Channels.
+func (n *Node) Channels(ctx context.Context, opts ...ChannelsOption) (map[string]ChannelInfo, error) {
+	channelsOpts := &ChannelsOptions{}
+	for _, opt := range opts {
+		opt(channelsOpts)
+	}
+	if channelsOpts.Pattern != "" {
+		if _, err := path.Match(channelsOpts.Pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	channels, err := n.collectChannels(ctx, channelsRequest{Pattern: channelsOpts.Pattern})
+	if err != nil {
+		return nil, err
+	}
+	if channelsOpts.Limit > 0 && len(channels) > channelsOpts.Limit {
+		names := make([]string, 0, len(channels))
+		for ch := range channels {
+			names = append(names, ch)
+		}
+		sort.Strings(names)
+		for _, ch := range names[channelsOpts.Limit:] {
+			delete(channels, ch)
+		}
+	}
+	return channels, nil
+}
+
+func (n *Node) collectChannels(ctx context.Context, req channelsRequest) (map[string]ChannelInfo, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	results, err := n.Survey(ctx, channelsOp, data, "")
+	if err != nil {
+		return nil, err
+	}
+	channels := map[string]ChannelInfo{}
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return nil, fmt.Errorf("unexpected channels survey code from node %s: %d", nodeID, result.Code)
+		}
+		var resp channelsResponse
+		if err := json.Unmarshal(result.Data, &resp); err != nil {
+			return nil, err
+		}
+		for ch, item := range resp.Channels {
+			info := channels[ch]
+			info.NumSubscribers += item.NumSubscribers
+			// Each node receives all channel publications, so we are not summing rates.
+			if item.PublicationRate > info.PublicationRate {
+				info.PublicationRate = item.PublicationRate
+			}
+			channels[ch] = info
+		}
+	}
+	return channels, nil
+}
+
+// TopChannelsOrder defines how to order channels in TopChannels result.
+type TopChannelsOrder int
+
+const (
+	// TopChannelsBySubscribers orders channels by number of subscribers.
+	TopChannelsBySubscribers TopChannelsOrder = iota
+	// TopChannelsByPublicationRate orders channels by publication rate.
+	TopChannelsByPublicationRate
+)
+
+// TopChannel is an item of TopChannels result.
+type TopChannel struct {
+	Channel string
+	ChannelInfo
+}
+
+// TopChannels returns up to limit channels in a cluster with the largest number of
+// subscribers or publication rate – depending on order. Useful to detect hot channels.
+// Publication rate is only tracked for channels which have subscribers. If limit set
+// each node replies only with its own top channels, so a channel which is not in top
+// on any node is not returned even if it's in top of a cluster in total.
+func (n *Node) TopChannels(ctx context.Context, limit int, order TopChannelsOrder) ([]TopChannel, error) {
+	channels, err := n.collectChannels(ctx, channelsRequest{TopLimit: max(limit, 0), TopOrder: order})
+	if err != nil {
+		return nil, err
+	}
+	return topChannels(channels, limit, order), nil
+}
+
+// topChannels returns up to limit channels ordered by order, limit <= 0 means no limit.
+func topChannels(channels map[string]ChannelInfo, limit int, order TopChannelsOrder) []TopChannel {
+	top := make([]TopChannel, 0, len(channels))
+	for ch, info := range channels {
+		top = append(top, TopChannel{Channel: ch, ChannelInfo: info})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if order == TopChannelsByPublicationRate && top[i].PublicationRate != top[j].PublicationRate {
+			return top[i].PublicationRate > top[j].PublicationRate
+		}
+		if top[i].NumSubscribers != top[j].NumSubscribers {
+			return top[i].NumSubscribers > top[j].NumSubscribers
+		}
+		return top[i].Channel < top[j].Channel
+	})
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+func (n *Node) handleChannelsSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req channelsRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error unmarshal channels request", map[string]any{"error": err.Error()}))
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	channels, err := n.hub.channelStatsSnapshot(req.Pattern)
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	if req.TopLimit > 0 && len(channels) > req.TopLimit {
+		infos := make(map[string]ChannelInfo, len(channels))
+		for ch, item := range channels {
+			infos[ch] = ChannelInfo{NumSubscribers: item.NumSubscribers, PublicationRate: item.PublicationRate}
+		}
+		channels = make(map[string]channelStatsItem, req.TopLimit)
+		for _, item := range topChannels(infos, req.TopLimit, req.TopOrder) {
+			channels[item.Channel] = channelStatsItem{NumSubscribers: item.NumSubscribers, PublicationRate: item.PublicationRate}
+		}
+	}
+	data, err := json.Marshal(channelsResponse{Channels: channels})
+	if err != nil {
+		cb(SurveyReply{Code: 3})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+type channelSubscribersRequest struct {
+	Channel string `json:"channel"`
+}
+
+type channelSubscribersResponse struct {
+	NumSubscribers int `json:"n,omitempty"`
+}
+
+// ChannelSubscribersByNode returns number of channel subscribers on each running node
+// keyed by node ID, nodes without channel subscribers have zero value. This helps to
+// diagnose uneven distribution of subscribers across a cluster. Information is collected
+// from all running nodes using Survey.
+func (n *Node) ChannelSubscribersByNode(ctx context.Context, ch string) (map[string]int, error) {
+	data, err := json.Marshal(channelSubscribersRequest{Channel: ch})
+	if err != nil {
+		return nil, err
+	}
+	results, err := n.Survey(ctx, channelSubscribersOp, data, "")
+	if err != nil {
+		return nil, err
+	}
+	byNode := make(map[string]int, len(results))
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return nil, fmt.Errorf("unexpected channel subscribers survey code from node %s: %d", nodeID, result.Code)
+		}
+		var resp channelSubscribersResponse
+		if err := json.Unmarshal(result.Data, &resp); err != nil {
+			return nil, err
+		}
+		byNode[nodeID] = resp.NumSubscribers
+	}
+	return byNode, nil
+}
+
+func (n *Node) handleChannelSubscribersSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req channelSubscribersRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error unmarshal channel subscribers request", map[string]any{"error": err.Error()}))
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	data, err := json.Marshal(channelSubscribersResponse{NumSubscribers: n.hub.NumSubscribers(req.Channel)})
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+// OnlineUsers contains information about users connected to a cluster.
+type OnlineUsers struct {
+	// NumUsers is an estimated number of distinct users connected to all nodes.
+	NumUsers int
+	// Users contains up to OnlineUsersOptions.SampleSize user IDs sorted
+	// lexicographically.
+	Users []string
+}
+
+// maxOnlineUsersSampleSize is a maximum number of user IDs each node sends
+// in reply to online users survey.
+const maxOnlineUsersSampleSize = 1000
+
+type onlineUsersRequest struct {
+	SampleSize int `json:"sample_size,omitempty"`
+}
+
+type onlineUsersResponse struct {
+	Sketch []byte   `json:"sketch"`
+	Users  []string `json:"users,omitempty"`
+}
+
+// OnlineUsers returns an estimated number of distinct users connected to all nodes
+// of a cluster, connections of the same user to different nodes counted once. Anonymous
+// users are not counted. Each node sends a HyperLogLog sketch of its users instead of
+// user IDs, so NumUsers has about 2% error for large numbers of users. Use
+// WithOnlineUsersSample to also get some user IDs. Information is collected from all
+// running nodes using Survey.
+func (n *Node) OnlineUsers(ctx context.Context, opts ...OnlineUsersOption) (OnlineUsers, error) {
+	onlineUsersOpts := &OnlineUsersOptions{}
+	for _, opt := range opts {
+		opt(onlineUsersOpts)
+	}
+	sampleSize := min(max(onlineUsersOpts.SampleSize, 0), maxOnlineUsersSampleSize)
+	data, err := json.Marshal(onlineUsersRequest{SampleSize: sampleSize})
+	if err != nil {
+		return OnlineUsers{}, err
+	}
+	results, err := n.Survey(ctx, onlineUsersOp, data, "")
+	if err != nil {
+		return OnlineUsers{}, err
+	}
+	sketch := hll.New()
+	users := map[string]struct{}{}
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return OnlineUsers{}, fmt.Errorf("unexpected online users survey code from node %s: %d", nodeID, result.Code)
+		}
+		var resp onlineUsersResponse
+		if err := json.Unmarshal(result.Data, &resp); err != nil {
+			return OnlineUsers{}, err
+		}
+		nodeSketch, err := hll.FromBytes(resp.Sketch)
+		if err != nil {
+			return OnlineUsers{}, err
+		}
+		sketch.Merge(nodeSketch)
+		for _, user := range resp.Users {
+			users[user] = struct{}{}
+		}
+	}
+	online := OnlineUsers{NumUsers: sketch.Count()}
+	if sampleSize > 0 {
+		// Each node sends its smallest user IDs, so their union contains the
+		// smallest user IDs of a cluster.
+		online.Users = make([]string, 0, len(users))
+		for user := range users {
+			online.Users = append(online.Users, user)
+		}
+		sort.Strings(online.Users)
+		if len(online.Users) > sampleSize {
+			online.Users = online.Users[:sampleSize]
+		}
+	}
+	return online, nil
+}
+
+func (n *Node) handleOnlineUsersSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req onlineUsersRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error unmarshal online users request", map[string]any{"error": err.Error()}))
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	users := n.hub.userIDs()
+	sketch := hll.New()
+	for _, user := range users {
+		sketch.Add(user)
+	}
+	resp := onlineUsersResponse{Sketch: sketch.Bytes()}
+	if sampleSize := min(req.SampleSize, maxOnlineUsersSampleSize); sampleSize > 0 {
+		sort.Strings(users)
+		resp.Users = users[:min(sampleSize, len(users))]
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+// ConnectionInfo contains information about client connection.
+type ConnectionInfo struct {
+	// NodeID is an ID of node client connected to.
+	NodeID string
+	// ClientID is a unique client connection ID.
+	ClientID string
+	// UserID of connection.
+	UserID string
+	// Transport is a name of connection transport.
+	Transport string
+	// ConnectedAt is a time when connection was authenticated.
+	ConnectedAt time.Time
+	// Channels connection currently subscribed to.
+	Channels []string
+}
+
+type connectionsRequest struct {
+	User    string `json:"user,omitempty"`
+	AnyUser bool   `json:"any_user,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+type connectionInfo struct {
+	Client      string   `json:"client"`
+	User        string   `json:"user"`
+	Transport   string   `json:"transport"`
+	ConnectedAt int64    `json:"connected_at"`
+	Channels    []string `json:"channels,omitempty"`
+}
+
+type connectionsResponse struct {
+	Connections []connectionInfo `json:"connections,omitempty"`
+}
+
+// Connections returns all active connections of a user in a cluster keyed by client ID.
+// Information is collected from all running nodes using Survey. For connections of the
+// current Node only see Hub.UserConnections. To find connections by other criteria see
+// FindConnections.
+func (n *Node) Connections(ctx context.Context, userID string) (map[string]ConnectionInfo, error) {
+	return n.FindConnections(ctx, WithConnectionsUser(userID))
+}
+
+var errConnectionsLimitRequired = errors.New("limit required to find connections without user or channel filter")
+
+// FindConnections returns active connections in a cluster keyed by client ID. Use
+// WithConnectionsUser and WithConnectionsChannel options to filter connections. Without
+// filters WithConnectionsLimit must be set since all connections on every node are
+// visited. With limit each node sends up to limit connections with the smallest client
+// IDs, and the result contains up to limit connections with the smallest client IDs in
+// a cluster. Information is collected from all running nodes using Survey. For
+// connections of the current Node only see Hub.Connections and Hub.UserConnections.
+func (n *Node) FindConnections(ctx context.Context, opts ...ConnectionsOption) (map[string]ConnectionInfo, error) {
+	connectionsOpts := &ConnectionsOptions{}
+	for _, opt := range opts {
+		opt(connectionsOpts)
+	}
+	if !connectionsOpts.userSet && connectionsOpts.Channel == "" && connectionsOpts.Limit <= 0 {
+		return nil, errConnectionsLimitRequired
+	}
+	data, err := json.Marshal(connectionsRequest{
+		User:    connectionsOpts.User,
+		AnyUser: !connectionsOpts.userSet,
+		Channel: connectionsOpts.Channel,
+		Limit:   max(connectionsOpts.Limit, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, err := n.Survey(ctx, connectionsOp, data, "")
+	if err != nil {
+		return nil, err
+	}
+	connections := map[string]ConnectionInfo{}
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return nil, fmt.Errorf("unexpected connections survey code from node %s: %d", nodeID, result.Code)
+		}
+		var resp connectionsResponse
+		if err := json.Unmarshal(result.Data, &resp); err != nil {
+			return nil, err
+		}
+		for _, info := range resp.Connections {
+			connections[info.Client] = ConnectionInfo{
+				NodeID:      nodeID,
+				ClientID:    info.Client,
+				UserID:      info.User,
+				Transport:   info.Transport,
+				ConnectedAt: time.UnixMilli(info.ConnectedAt),
+				Channels:    info.Channels,
+			}
+		}
+	}
+	if connectionsOpts.Limit > 0 && len(connections) > connectionsOpts.Limit {
+		clientIDs := make([]string, 0, len(connections))
+		for clientID := range connections {
+			clientIDs = append(clientIDs, clientID)
+		}
+		sort.Strings(clientIDs)
+		for _, clientID := range clientIDs[connectionsOpts.Limit:] {
+			delete(connections, clientID)
+		}
+	}
+	return connections, nil
+}
+
+// UserChannels returns channels user is currently subscribed to on all nodes with
+// a number of user connections subscribed to each channel. Information is collected
+// from all running nodes using Survey. Useful for moderation tooling and debugging.
+func (n *Node) UserChannels(ctx context.Context, userID string) (map[string]int, error) {
+	connections, err := n.Connections(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	channels := map[string]int{}
+	for _, info := range connections {
+		for _, ch := range info.Channels {
+			channels[ch]++
+		}
+	}
+	return channels, nil
+}
+
+func (n *Node) handleConnectionsSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req connectionsRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error unmarshal connections request", map[string]any{"error": err.Error()}))
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	var clients map[string]*Client
+	if !req.AnyUser {
+		clients = n.hub.UserConnections(req.User)
+	} else {
+		clients = n.hub.Connections()
+	}
+	clientIDs := make([]string, 0, len(clients))
+	for clientID := range clients {
+		clientIDs = append(clientIDs, clientID)
+	}
+	if req.Limit > 0 {
+		// Sort to return the same connections as FindConnections keeps after merging.
+		sort.Strings(clientIDs)
+	}
+	var resp connectionsResponse
+	for _, clientID := range clientIDs {
+		if req.Limit > 0 && len(resp.Connections) >= req.Limit {
+			break
+		}
+		c := clients[clientID]
+		channels := c.Channels()
+		if req.Channel != "" && !stringInSlice(req.Channel, channels) {
+			continue
+		}
+		c.mu.RLock()
+		connectedAt := c.connectedAt
+		c.mu.RUnlock()
+		resp.Connections = append(resp.Connections, connectionInfo{
+			Client:      c.ID(),
+			User:        c.UserID(),
+			Transport:   c.Transport().Name(),
+			ConnectedAt: connectedAt.UnixMilli(),
+			Channels:    channels,
+		})
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+// RuntimeStats contains Go runtime statistics of a node.
+type RuntimeStats struct {
+	// NumGoroutine is a number of goroutines running on node.
+	NumGoroutine int `json:"num_goroutine"`
+	// NumCPU is a number of logical CPUs usable by node process.
+	NumCPU int `json:"num_cpu"`
+	// HeapAlloc is bytes of allocated heap objects.
+	HeapAlloc uint64 `json:"heap_alloc"`
+	// HeapInuse is bytes in in-use heap spans.
+	HeapInuse uint64 `json:"heap_inuse"`
+	// Sys is the total bytes of memory obtained from the OS.
+	Sys uint64 `json:"sys"`
+	// NumGC is the number of completed GC cycles.
+	NumGC uint32 `json:"num_gc"`
+	// PauseTotalNs is the cumulative nanoseconds in GC stop-the-world pauses.
+	PauseTotalNs uint64 `json:"pause_total_ns"`
+	// LastGC is the time the last garbage collection finished as Unix nanoseconds.
+	LastGC uint64 `json:"last_gc"`
+}
+
+// RuntimeStats returns Go runtime statistics of all running nodes keyed by node ID.
+// This allows spotting a node leaking goroutines or memory. Note that collecting
+// memory statistics stops the world for a short time on every node, so avoid
+// calling this method too often.
+func (n *Node) RuntimeStats(ctx context.Context) (map[string]RuntimeStats, error) {
+	results, err := n.Survey(ctx, runtimeStatsOp, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]RuntimeStats, len(results))
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return nil, fmt.Errorf("unexpected runtime stats survey code from node %s: %d", nodeID, result.Code)
+		}
+		var s RuntimeStats
+		if err := json.Unmarshal(result.Data, &s); err != nil {
+			return nil, err
+		}
+		stats[nodeID] = s
+	}
+	return stats, nil
+}
+
+func (n *Node) handleRuntimeStatsSurvey(_ SurveyEvent, cb SurveyCallback) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	data, err := json.Marshal(RuntimeStats{
+		NumGoroutine: runtime.NumGoroutine(),
+		NumCPU:       runtime.NumCPU(),
+		HeapAlloc:    memStats.HeapAlloc,
+		HeapInuse:    memStats.HeapInuse,
+		Sys:          memStats.Sys,
+		NumGC:        memStats.NumGC,
+		PauseTotalNs: memStats.PauseTotalNs,
+		LastGC:       memStats.LastGC,
+	})
+	if err != nil {
+		cb(SurveyReply{Code: 2})
+		return
+	}
+	cb(SurveyReply{Data: data})
+}
+
+type updatePresenceRequest struct {
+	User     string `json:"user"`
+	Channel  string `json:"channel"`
+	Client   string `json:"client,omitempty"`
+	Session  string `json:"session,omitempty"`
+	ConnInfo []byte `json:"conn_info,omitempty"`
+	ChanInfo []byte `json:"chan_info,omitempty"`
+}
+
+// UpdatePresence updates ClientInfo attached to presence of user connections subscribed
+// to channel on all nodes without resubscribing. See Client.UpdatePresence for details.
+// Use WithUpdatePresenceClient or WithUpdatePresenceSession to update concrete
+// connection only.
+func (n *Node) UpdatePresence(userID string, ch string, opts ...UpdatePresenceOption) error {
+	updateOpts := &UpdatePresenceOptions{}
+	for _, opt := range opts {
+		opt(updateOpts)
+	}
+	data, err := json.Marshal(updatePresenceRequest{
+		User:     userID,
+		Channel:  ch,
+		Client:   updateOpts.clientID,
+		Session:  updateOpts.sessionID,
+		ConnInfo: updateOpts.ConnInfo,
+		ChanInfo: updateOpts.ChanInfo,
+	})
+	if err != nil {
+		return err
+	}
+	results, err := n.Survey(context.Background(), updatePresenceOp, data, "")
+	if err != nil {
+		return err
+	}
+	for nodeID, result := range results {
+		if result.Code != 0 {
+			return fmt.Errorf("unexpected update presence survey code from node %s: %d", nodeID, result.Code)
+		}
+	}
+	return nil
+}
+
+func (n *Node) handleUpdatePresenceSurvey(e SurveyEvent, cb SurveyCallback) {
+	var req updatePresenceRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		cb(SurveyReply{Code: 1})
+		return
+	}
+	var opts []UpdatePresenceOption
+	if req.ConnInfo != nil {
+		opts = append(opts, WithPresenceConnInfo(req.ConnInfo))
+	}
+	if req.ChanInfo != nil {
+		opts = append(opts, WithPresenceChanInfo(req.ChanInfo))
+	}
+	go func() {
+		if err := n.hub.updatePresence(req.User, req.Channel, req.Client, req.Session, opts...); err != nil {
+			n.logger.log(newLogEntry(LogLevelError, "error updating presence", map[string]any{"user": req.User, "channel": req.Channel, "error": err.Error()}))
+			cb(SurveyReply{Code: 2})
+			return
+		}
+		cb(SurveyReply{})
+	}()
+}
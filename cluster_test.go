@@ -0,0 +1,287 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_Channels(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	newTestSubscribedClientV2(t, node, "42", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:2")
+	newTestSubscribedClientV2(t, node, "44", "news")
+
+	channels, err := node.Channels(context.Background())
+	require.NoError(t, err)
+	require.Len(t, channels, 3)
+	require.Equal(t, 2, channels["chat:1"].NumSubscribers)
+	require.Equal(t, 1, channels["chat:2"].NumSubscribers)
+	require.Equal(t, 1, channels["news"].NumSubscribers)
+
+	channels, err = node.Channels(context.Background(), WithChannelsPattern("chat:*"))
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	require.NotContains(t, channels, "news")
+
+	channels, err = node.Channels(context.Background(), WithChannelsPattern("chat:*"), WithChannelsLimit(1))
+	require.NoError(t, err)
+	require.Len(t, channels, 1)
+	require.Contains(t, channels, "chat:1")
+}
+
+func TestNode_Channels_BadPattern(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err := node.Channels(context.Background(), WithChannelsPattern("chat:["))
+	require.Error(t, err)
+}
+
+func TestNode_handleChannelsSurvey(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	newTestSubscribedClientV2(t, node, "42", "chat:1")
+
+	done := make(chan SurveyReply, 1)
+	node.handleChannelsSurvey(SurveyEvent{Op: channelsOp, Data: []byte(`{"pattern":"chat:*"}`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply := <-done
+	require.Zero(t, reply.Code)
+	require.Equal(t, `{"channels":{"chat:1":{"n":1}}}`, string(reply.Data))
+
+	// Node replies only with its top channels.
+	newTestSubscribedClientV2(t, node, "43", "chat:2")
+	newTestSubscribedClientV2(t, node, "44", "chat:2")
+	node.handleChannelsSurvey(SurveyEvent{Op: channelsOp, Data: []byte(`{"top_limit":1}`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply = <-done
+	require.Zero(t, reply.Code)
+	require.Equal(t, `{"channels":{"chat:2":{"n":2}}}`, string(reply.Data))
+
+	node.handleChannelsSurvey(SurveyEvent{Op: channelsOp, Data: []byte(`{`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply = <-done
+	require.Equal(t, uint32(1), reply.Code)
+}
+
+func TestNode_Connections(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client1 := newTestSubscribedClientV2(t, node, "42", "chat:1")
+	client2 := newTestConnectedClientV2(t, node, "42")
+	newTestConnectedClientV2(t, node, "43")
+
+	connections, err := node.Connections(context.Background(), "42")
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+
+	info, ok := connections[client1.ID()]
+	require.True(t, ok)
+	require.Equal(t, node.ID(), info.NodeID)
+	require.Equal(t, "42", info.UserID)
+	require.Equal(t, client1.ID(), info.ClientID)
+	require.Equal(t, transportWebsocket, info.Transport)
+	require.False(t, info.ConnectedAt.IsZero())
+	require.Equal(t, []string{"chat:1"}, info.Channels)
+
+	info, ok = connections[client2.ID()]
+	require.True(t, ok)
+	require.Empty(t, info.Channels)
+
+	connections, err = node.Connections(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Empty(t, connections)
+}
+
+func TestNode_FindConnections(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client1 := newTestSubscribedClientV2(t, node, "42", "chat:1")
+	newTestConnectedClientV2(t, node, "42")
+	newTestConnectedClientV2(t, node, "43")
+
+	_, err := node.FindConnections(context.Background())
+	require.ErrorIs(t, err, errConnectionsLimitRequired)
+
+	connections, err := node.FindConnections(context.Background(), WithConnectionsLimit(10))
+	require.NoError(t, err)
+	require.Len(t, connections, 3)
+
+	connections, err = node.FindConnections(context.Background(), WithConnectionsLimit(2))
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+
+	connections, err = node.FindConnections(context.Background(), WithConnectionsUser("42"), WithConnectionsLimit(1))
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+
+	connections, err = node.FindConnections(context.Background(), WithConnectionsChannel("chat:1"))
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+	require.Contains(t, connections, client1.ID())
+
+	connections, err = node.FindConnections(context.Background(), WithConnectionsUser("43"), WithConnectionsChannel("chat:1"))
+	require.NoError(t, err)
+	require.Empty(t, connections)
+
+	// Empty user matches anonymous connections only.
+	connections, err = node.FindConnections(context.Background(), WithConnectionsUser(""))
+	require.NoError(t, err)
+	require.Empty(t, connections)
+}
+
+func TestNode_TopChannels(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	newTestSubscribedClientV2(t, node, "42", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:2")
+	newTestSubscribedClientV2(t, node, "44", "news")
+
+	for i := 0; i < 3; i++ {
+		_, err := node.Publish("chat:2", []byte(`{}`))
+		require.NoError(t, err)
+	}
+	_, err := node.Publish("news", []byte(`{}`))
+	require.NoError(t, err)
+	node.hub.updateChannelStats(time.Second)
+
+	top, err := node.TopChannels(context.Background(), 2, TopChannelsBySubscribers)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	require.Equal(t, "chat:1", top[0].Channel)
+	require.Equal(t, 2, top[0].NumSubscribers)
+	require.Equal(t, "chat:2", top[1].Channel)
+
+	top, err = node.TopChannels(context.Background(), 0, TopChannelsByPublicationRate)
+	require.NoError(t, err)
+	require.Len(t, top, 3)
+	require.Equal(t, "chat:2", top[0].Channel)
+	require.Equal(t, float64(3), top[0].PublicationRate)
+	require.Equal(t, "news", top[1].Channel)
+	require.Equal(t, float64(1), top[1].PublicationRate)
+	require.Equal(t, "chat:1", top[2].Channel)
+	require.Zero(t, top[2].PublicationRate)
+}
+
+func TestNode_RuntimeStats(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	stats, err := node.RuntimeStats(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	s, ok := stats[node.ID()]
+	require.True(t, ok)
+	require.Positive(t, s.NumGoroutine)
+	require.Positive(t, s.NumCPU)
+	require.NotZero(t, s.HeapAlloc)
+	require.NotZero(t, s.Sys)
+}
+
+func TestNode_OnlineUsers(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	online, err := node.OnlineUsers(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, online.NumUsers)
+
+	newTestConnectedClientV2(t, node, "42")
+	newTestConnectedClientV2(t, node, "42")
+	newTestConnectedClientV2(t, node, "43")
+	newTestConnectedClientV2(t, node, "44")
+	newTestConnectedClientV2(t, node, "")
+
+	online, err = node.OnlineUsers(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, online.NumUsers)
+	require.Empty(t, online.Users)
+
+	online, err = node.OnlineUsers(context.Background(), WithOnlineUsersSample(2))
+	require.NoError(t, err)
+	require.Equal(t, 3, online.NumUsers)
+	require.Equal(t, []string{"42", "43"}, online.Users)
+}
+
+func TestNode_handleOnlineUsersSurvey(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	newTestConnectedClientV2(t, node, "42")
+	newTestConnectedClientV2(t, node, "43")
+
+	done := make(chan SurveyReply, 1)
+	node.handleOnlineUsersSurvey(SurveyEvent{Op: onlineUsersOp, Data: []byte(`{}`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply := <-done
+	require.Zero(t, reply.Code)
+	var resp onlineUsersResponse
+	require.NoError(t, json.Unmarshal(reply.Data, &resp))
+	require.Empty(t, resp.Users)
+	require.NotEmpty(t, resp.Sketch)
+
+	node.handleOnlineUsersSurvey(SurveyEvent{Op: onlineUsersOp, Data: []byte(`{"sample_size":1}`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply = <-done
+	require.Zero(t, reply.Code)
+	resp = onlineUsersResponse{}
+	require.NoError(t, json.Unmarshal(reply.Data, &resp))
+	require.Equal(t, []string{"42"}, resp.Users)
+
+	node.handleOnlineUsersSurvey(SurveyEvent{Op: onlineUsersOp, Data: []byte(`{`)}, func(reply SurveyReply) {
+		done <- reply
+	})
+	reply = <-done
+	require.Equal(t, uint32(1), reply.Code)
+}
+
+func TestNode_UserChannels(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client1 := newTestSubscribedClientV2(t, node, "42", "chat:1")
+	subscribeClientV2(t, client1, "chat:2")
+	newTestSubscribedClientV2(t, node, "42", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:3")
+
+	channels, err := node.UserChannels(context.Background(), "42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"chat:1": 2, "chat:2": 1}, channels)
+
+	channels, err = node.UserChannels(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Empty(t, channels)
+}
+
+func TestNode_ChannelSubscribersByNode(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	newTestSubscribedClientV2(t, node, "42", "chat:1")
+	newTestSubscribedClientV2(t, node, "43", "chat:1")
+
+	byNode, err := node.ChannelSubscribersByNode(context.Background(), "chat:1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{node.ID(): 2}, byNode)
+
+	byNode, err = node.ChannelSubscribersByNode(context.Background(), "chat:2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{node.ID(): 0}, byNode)
+}
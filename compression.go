@@ -0,0 +1,81 @@
+package centrifuge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"sync"
+
+	"github.com/centrifugal/protocol"
+)
+
+// PublicationCompression is a compression algorithm applied to publication data
+// sent to subscribers. See PublishOptions.CompressionThreshold and
+// SubscribeOptions.Compression.
+type PublicationCompression string
+
+const (
+	publicationCompressionNone PublicationCompression = ""
+	// PublicationCompressionGzip compresses publication data with gzip.
+	PublicationCompressionGzip PublicationCompression = "gzip"
+)
+
+// PublicationCompressionTag is a tag set to publications with compressed data, the
+// value of tag is PublicationCompression used. With Protobuf protocol compressed data
+// is sent as is, with JSON protocol it's sent as a JSON string with base64 encoded
+// compressed data. Client must decompress data when the tag is present.
+const PublicationCompressionTag = "_compression"
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzipWriterPool.Get().(*gzip.Writer)
+	defer gzipWriterPool.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// getCompressedPub returns publication with data compressed according to key. Original
+// publication returned if compression does not reduce data size.
+func getCompressedPub(fullPub *protocol.Publication, key preparedKey) (*protocol.Publication, error) {
+	if key.Compression != PublicationCompressionGzip {
+		return fullPub, nil
+	}
+	compressed, err := gzipData(fullPub.Data)
+	if err != nil {
+		return nil, err
+	}
+	if key.ProtocolType == protocol.TypeJSON {
+		encoded := make([]byte, base64.StdEncoding.EncodedLen(len(compressed))+2)
+		encoded[0] = '"'
+		base64.StdEncoding.Encode(encoded[1:], compressed)
+		encoded[len(encoded)-1] = '"'
+		compressed = encoded
+	}
+	if len(compressed) >= len(fullPub.Data) {
+		return fullPub, nil
+	}
+	tags := make(map[string]string, len(fullPub.Tags)+1)
+	for k, v := range fullPub.Tags {
+		tags[k] = v
+	}
+	tags[PublicationCompressionTag] = string(key.Compression)
+	return &protocol.Publication{
+		Offset: fullPub.Offset,
+		Data:   compressed,
+		Info:   fullPub.Info,
+		Tags:   tags,
+		Time:   fullPub.Time,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package centrifuge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func gunzipTestData(t *testing.T, data []byte) []byte {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	result, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return result
+}
+
+func TestGetCompressedPub(t *testing.T) {
+	data := []byte(`{"text": "` + strings.Repeat("a", 1000) + `"}`)
+	fullPub := &protocol.Publication{Offset: 1, Data: data, Tags: map[string]string{"k": "v"}}
+
+	pub, err := getCompressedPub(fullPub, preparedKey{ProtocolType: protocol.TypeProtobuf})
+	require.NoError(t, err)
+	require.Same(t, fullPub, pub)
+
+	pub, err = getCompressedPub(fullPub, preparedKey{ProtocolType: protocol.TypeProtobuf, Compression: PublicationCompressionGzip})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"k": "v", PublicationCompressionTag: "gzip"}, pub.Tags)
+	require.Len(t, fullPub.Tags, 1, "original publication must not be modified")
+	require.Equal(t, uint64(1), pub.Offset)
+	require.Equal(t, data, gunzipTestData(t, pub.Data))
+
+	pub, err = getCompressedPub(fullPub, preparedKey{ProtocolType: protocol.TypeJSON, Compression: PublicationCompressionGzip})
+	require.NoError(t, err)
+	var encoded string
+	require.NoError(t, json.Unmarshal(pub.Data, &encoded))
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	require.Equal(t, data, gunzipTestData(t, compressed))
+
+	// Not compressed when compression does not reduce size.
+	smallPub := &protocol.Publication{Data: []byte(`{}`)}
+	pub, err = getCompressedPub(smallPub, preparedKey{ProtocolType: protocol.TypeProtobuf, Compression: PublicationCompressionGzip})
+	require.NoError(t, err)
+	require.Same(t, smallPub, pub)
+}
+
+func TestNode_PublishCompression(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	subscribe := func(compression PublicationCompression) *testTransport {
+		transport := newTestTransport(func() {})
+		transport.sink = make(chan []byte, 100)
+		client, err := newClient(SetCredentials(context.Background(), &Credentials{UserID: "42"}), node, transport)
+		require.NoError(t, err)
+		connectClientV2(t, client)
+		rwWrapper := testReplyWriterWrapper()
+		subCtx := client.subscribeCmd(&protocol.SubscribeRequest{
+			Channel: "test",
+		}, SubscribeReply{Options: SubscribeOptions{Compression: compression}}, &protocol.Command{}, false, time.Now(), rwWrapper.rw)
+		require.Nil(t, subCtx.disconnect)
+		return transport
+	}
+	compressing := subscribe(PublicationCompressionGzip)
+	plain := subscribe("")
+
+	largeData := []byte(`{"text": "` + strings.Repeat("a", 1000) + `"}`)
+	_, err := node.Publish("test", []byte(`{"text": "small"}`), WithCompressionThreshold(100))
+	require.NoError(t, err)
+	_, err = node.Publish("test", largeData, WithCompressionThreshold(100))
+	require.NoError(t, err)
+
+	type pushReply struct {
+		Push struct {
+			Pub struct {
+				Data json.RawMessage   `json:"data"`
+				Tags map[string]string `json:"tags"`
+			} `json:"pub"`
+		} `json:"push"`
+	}
+	readPubs := func(transport *testTransport) []pushReply {
+		var pubs []pushReply
+		timeout := time.After(5 * time.Second)
+		for len(pubs) < 2 {
+			select {
+			case data := <-transport.sink:
+				for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+					var reply pushReply
+					require.NoError(t, json.Unmarshal([]byte(line), &reply))
+					if reply.Push.Pub.Data != nil {
+						pubs = append(pubs, reply)
+					}
+				}
+			case <-timeout:
+				require.Fail(t, "timeout receiving publications")
+			}
+		}
+		return pubs
+	}
+
+	pubs := readPubs(compressing)
+	require.JSONEq(t, `{"text": "small"}`, string(pubs[0].Push.Pub.Data))
+	require.Empty(t, pubs[0].Push.Pub.Tags)
+	require.Equal(t, "gzip", pubs[1].Push.Pub.Tags[PublicationCompressionTag])
+	require.Len(t, pubs[1].Push.Pub.Tags, 1)
+	var encoded string
+	require.NoError(t, json.Unmarshal(pubs[1].Push.Pub.Data, &encoded))
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	require.Equal(t, largeData, gunzipTestData(t, compressed))
+
+	pubs = readPubs(plain)
+	require.Empty(t, pubs[1].Push.Pub.Tags)
+	require.JSONEq(t, string(largeData), string(pubs[1].Push.Pub.Data))
+}
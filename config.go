@@ -1,7 +1,11 @@
 package centrifuge
 
 import (
+	"errors"
+	"fmt"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Config contains Node configuration options.
@@ -14,16 +18,36 @@ type Config struct {
 	Name string
 	// LogLevel is a log level. By default, nothing will be logged by Centrifuge.
 	LogLevel LogLevel
-	// LogHandler is a handler function Node will send logs to.
+	// LogHandler is a handler function Node will send logs to. See NewSlogLogHandler
+	// to send logs into slog.Logger.
 	LogHandler LogHandler
+	// SlowOperationThreshold if set enables warn logs about Broker, PresenceManager and
+	// history operations which took longer than this threshold. Log entry contains
+	// operation type, channel and duration. Zero value means no slow operation logging.
+	SlowOperationThreshold time.Duration
+	// SlowCommandThreshold if set enables warn logs about client commands which took
+	// longer than this threshold to process. Zero value means no slow command logging.
+	SlowCommandThreshold time.Duration
 	// NodeInfoMetricsAggregateInterval sets interval for automatic metrics
 	// aggregation. It's not reasonable to have it less than one second.
 	// Zero value means 60 * time.Second.
 	NodeInfoMetricsAggregateInterval time.Duration
+	// NodeHeartbeatInterval sets how often node publishes information about itself
+	// to other nodes over control protocol. Zero value means 3 * time.Second.
+	NodeHeartbeatInterval time.Duration
+	// NodeHeartbeatExpiration sets how long information about other node is considered
+	// actual since last heartbeat received from it. Nodes which left gracefully are
+	// removed immediately, expiration is only required for nodes which died. Must be
+	// greater than NodeHeartbeatInterval. Zero value means 2 * NodeHeartbeatInterval + 1s.
+	NodeHeartbeatExpiration time.Duration
 	// ClientConnectIncludeServerTime tells Centrifuge to append `time` field to Connect result of client protocol.
 	// This field contains Unix timestamp in milliseconds and represents current server time. By default, server time
 	// is not included.
 	ClientConnectIncludeServerTime bool
+	// ClientConnectIncludeNodeID tells Centrifuge to append `node` field with ID of Node
+	// to Connect result of client protocol for all transports. By default, node ID is only
+	// sent to clients of emulation transports which require it to send commands.
+	ClientConnectIncludeNodeID bool
 	// ClientPresenceUpdateInterval sets an interval how often connected
 	// clients update presence information.
 	// Zero value means 25 * time.Second.
@@ -58,6 +82,24 @@ type Config struct {
 	// bytes. After this queue size exceeded Centrifuge closes client's connection.
 	// Zero value means 1048576 bytes (1MB).
 	ClientQueueMaxSize int
+	// ClientReconnectAdvice allows advising clients about reconnect backoff per
+	// disconnect code. Advice is passed to Transport.Close in Disconnect.ReconnectAdvice,
+	// see ReconnectAdvice for limitations. May be changed at runtime with
+	// Node.ReloadConfig to slow down reconnect storms after mass disconnects.
+	ClientReconnectAdvice map[uint32]ReconnectAdvice
+	// SessionResumptionSecret if set enables session resumption: on graceful shutdown
+	// Node sends short-lived resumption token to each authenticated client in async
+	// message right before disconnecting it. Token is signed with this secret (HMAC
+	// SHA-256) and encodes connection credentials and subscribed channels with stream
+	// positions. When client reconnects to another node with resumption token, use
+	// Node.ResumeSession in ConnectingHandler to restore connection state. Secret must
+	// be the same on all nodes.
+	SessionResumptionSecret []byte
+	// SessionResumptionTTL is a lifetime of resumption token. Zero value means 1 minute.
+	SessionResumptionTTL time.Duration
+	// SessionResumptionMessage allows customizing data of async message with resumption
+	// token. By default, message data is {"resumption_token":"<token>"}.
+	SessionResumptionMessage func(token string) []byte
 	// ClientChannelLimit sets upper limit of client-side channels each client
 	// can subscribe to. Client-side subscriptions attempts will get an ErrorLimitExceeded
 	// in subscribe reply. Server-side subscriptions above limit will result into
@@ -85,6 +127,66 @@ type Config struct {
 	// UseSingleFlight allows turning on mode where singleflight will be automatically used
 	// for Node.History (including recovery) and Node.Presence/Node.PresenceStats calls.
 	UseSingleFlight bool
+	// HistoryCacheSize if set enables keeping up to HistoryCacheSize latest publications
+	// for each channel this Node is subscribed to in Node memory. Cache is filled with
+	// publications received from Broker and used to serve history requests with Since
+	// position (including recovery) when requested position is within cached tail – so
+	// recovery after mass reconnect does not hit Broker for small gaps. Deeper gaps are
+	// served by Broker. Zero value means no cache.
+	HistoryCacheSize int
+	// HistoryCacheTTL limits how long publications are kept in history cache after the
+	// last publication in a channel – like HistoryTTL of a history stream in Broker. It
+	// must not be greater than HistoryTTL used for channels, otherwise cache may serve
+	// publications which already expired in Broker. For channels of namespace with
+	// HistoryTTL set the smaller value is used. Zero value means 1 minute.
+	HistoryCacheTTL time.Duration
+	// PresenceCacheTTL if set enables node-local cache of Node.Presence and Node.PresenceStats
+	// results for the provided time. Useful for channels where presence is requested often
+	// to reduce load on PresenceManager. Cached values are invalidated on presence changes
+	// made by this Node, changes made by other nodes become visible after TTL expiration.
+	// So keep it small – usually a few hundred milliseconds. Zero value means no cache.
+	PresenceCacheTTL time.Duration
+	// JoinLeaveAggregationInterval if set enables aggregation of join and leave messages
+	// received by this Node. Instead of sending each join/leave to subscribers immediately
+	// Node collects them and sends net changes once per interval: join and leave of the
+	// same client within interval cancel each other out. This allows subscribers of large
+	// channels with high churn to keep member lists current with incremental updates
+	// without full presence fetches. Zero value means join/leave messages are delivered
+	// without delay.
+	JoinLeaveAggregationInterval time.Duration
+	// PublicationMaxSize if set limits size of publication data in bytes for publications
+	// made with Node.Publish, including publications from clients. Publications over limit
+	// are rejected with ErrorPublicationTooLarge. May be overridden per namespace with
+	// ChannelOptions.PublicationMaxSize. Zero value means no limit.
+	PublicationMaxSize int
+	// Namespaces allows configuring options of channels by namespace – see ChannelNamespace
+	// and ChannelOptions. If set, clients can only subscribe and publish to channels of
+	// configured namespaces. Options of channel may be resolved with Node.ChannelOptions.
+	Namespaces []ChannelNamespace
+	// WildcardSubscriptions enables subscriptions to channel patterns. If enabled, channel
+	// containing "*" is a pattern where "*" matches any sequence of characters – for example
+	// client subscribed to "stocks.*" receives publications of "stocks.AAPL" and "stocks.GOOG".
+	// Original channel of publication is sent to client in WildcardChannelTag tag. Broker must
+	// implement PatternSubscriber. Publishing into patterns is not allowed, subscriptions to
+	// patterns do not support positioning and recovery. If Namespaces set, only publications
+	// of channels from namespace of pattern are delivered. With UserLimitedChannels publications
+	// of user-limited channels are only delivered to users allowed in channel.
+	WildcardSubscriptions bool
+	// UserLimitedChannels enables user-limited channels. Part of channel after the last
+	// UserChannelBoundary contains a list of user IDs separated by UserChannelSeparator –
+	// for example "dialog#42,43". Only listed users can subscribe to such channel, Node
+	// checks this before calling Client.OnSubscribe handler and rejects other users with
+	// ErrorPermissionDenied.
+	UserLimitedChannels bool
+	// UserChannelBoundary is a boundary of user IDs part in user-limited channel.
+	// Zero value means "#".
+	UserChannelBoundary string
+	// UserChannelSeparator separates user IDs in user-limited channel. Zero value
+	// means ",".
+	UserChannelSeparator string
+	// ChannelNamespaceBoundary is a string separating namespace name from the rest of
+	// channel. Zero value means ":".
+	ChannelNamespaceBoundary string
 	// HistoryMetaTTL sets a time of stream meta key expiration in Redis. Stream
 	// meta key is a Redis HASH that contains top offset in channel and epoch value.
 	// In some cases – when channels created for а short time and then
@@ -103,12 +205,23 @@ type Config struct {
 	// MetricsNamespace is a Prometheus metrics namespace to use for internal metrics.
 	// If not set then the default namespace name "centrifuge" will be used.
 	MetricsNamespace string
+	// MetricsRegisterer is a Prometheus registerer to register internal metrics in.
+	// If not set then prometheus.DefaultRegisterer is used. Using a separate registry
+	// allows running several nodes in one process without metrics collision.
+	MetricsRegisterer prometheus.Registerer
+	// MetricsGatherer is a Prometheus gatherer used to aggregate metrics for NodeInfo
+	// (see NodeInfoMetricsAggregateInterval). If not set then MetricsRegisterer is used
+	// when it also implements prometheus.Gatherer (like *prometheus.Registry does),
+	// otherwise prometheus.DefaultGatherer is used.
+	MetricsGatherer prometheus.Gatherer
 	// GetChannelNamespaceLabel if set will be used by Centrifuge to extract channel_namespace
 	// label for some channel related metrics. Make sure to maintain low cardinality of returned
 	// values to avoid issues with Prometheus performance. This function may introduce sufficient
 	// overhead since it's called in hot paths - so it should be fast. Usage of this function for
-	// specific metrics must be enabled over ChannelNamespaceLabelForTransportMessagesSent and
-	// ChannelNamespaceLabelForTransportMessagesReceived options.
+	// specific metrics must be enabled over ChannelNamespaceLabelForTransportMessagesSent,
+	// ChannelNamespaceLabelForTransportMessagesReceived, ChannelNamespaceLabelForPublish,
+	// ChannelNamespaceLabelForSubscribe and ChannelNamespaceLabelForClientQueue options. See
+	// also ChannelNamespaceLabelMaxCardinality.
 	GetChannelNamespaceLabel func(channel string) string
 	// ChannelNamespaceLabelForTransportMessagesSent enables using GetChannelNamespaceLabel
 	// function for extracting channel_namespace label for transport_messages_sent and
@@ -118,6 +231,38 @@ type Config struct {
 	// function for extracting channel_namespace label for transport_messages_received and
 	// transport_messages_received_size.
 	ChannelNamespaceLabelForTransportMessagesReceived bool
+	// ChannelNamespaceLabelForPublish enables node_publish_count metric with channel_namespace
+	// label extracted using GetChannelNamespaceLabel function.
+	ChannelNamespaceLabelForPublish bool
+	// ChannelNamespaceLabelForSubscribe enables node_subscribe_count metric with channel_namespace
+	// label extracted using GetChannelNamespaceLabel function.
+	ChannelNamespaceLabelForSubscribe bool
+	// ChannelNamespaceLabelForClientQueue enables client_queue_enqueued_count and
+	// client_queue_overflow_count metrics with channel_namespace label extracted using
+	// GetChannelNamespaceLabel function. Overflow is counted for a message which caused
+	// connection queue to exceed ClientQueueMaxSize.
+	ChannelNamespaceLabelForClientQueue bool
+	// ChannelNamespaceLabelMaxCardinality limits the number of unique channel_namespace label
+	// values. When limit reached all new values are reported as "_other". Zero value means
+	// no limit.
+	ChannelNamespaceLabelMaxCardinality int
+
+	// BroadcastWorkerPoolSize if set enables a pool of worker goroutines used to deliver
+	// publications to local subscribers of large channels in parallel. Channel subscribers
+	// are split into chunks of BroadcastChunkSize, so a single channel with an enormous
+	// number of subscribers does not serialize delivery. Channels with less than two chunks
+	// of subscribers are always delivered in the goroutine which received publication.
+	// Publication order for each subscriber is preserved. Zero value means no worker pool.
+	BroadcastWorkerPoolSize int
+	// BroadcastWorkerQueueSize is a maximum number of chunks waiting for a free worker.
+	// Zero value means 1024.
+	BroadcastWorkerQueueSize int
+	// BroadcastChunkSize is a number of subscribers processed by one worker task.
+	// Zero value means 512.
+	BroadcastChunkSize int
+	// BroadcastOverflowPolicy defines what to do when worker pool queue is full. By default,
+	// BroadcastOverflowInline used – chunk delivered in the goroutine which broadcasts publication.
+	BroadcastOverflowPolicy BroadcastOverflowPolicy
 
 	// GetChannelMediumOptions is a way to provide ChannelMediumOptions for specific channel.
 	// This function is called each time new channel appears on the Node.
@@ -125,17 +270,9 @@ type Config struct {
 	GetChannelMediumOptions func(channel string) ChannelMediumOptions
 }
 
-const (
-	// nodeInfoPublishInterval is an interval how often node must publish
-	// node control message.
-	nodeInfoPublishInterval = 3 * time.Second
-	// nodeInfoCleanInterval is an interval in seconds, how often node must
-	// clean information about other running nodes.
-	nodeInfoCleanInterval = nodeInfoPublishInterval * 3
-	// nodeInfoMaxDelay is an interval in seconds how long node info is
-	// considered actual.
-	nodeInfoMaxDelay = nodeInfoPublishInterval*2 + time.Second
-)
+// nodeInfoPublishInterval is a default interval how often node must publish
+// node control message – see Config.NodeHeartbeatInterval.
+const nodeInfoPublishInterval = 3 * time.Second
 
 // PingPongConfig allows configuring application level ping-pong behavior.
 // Note that in current implementation PingPongConfig.PingInterval must be greater than PingPongConfig.PongTimeout.
@@ -164,3 +301,120 @@ func getPingPongPeriodValues(config PingPongConfig) (time.Duration, time.Duratio
 	}
 	return pingInterval, pongTimeout
 }
+
+// Validate checks PingPongConfig for invalid and conflicting values.
+func (c PingPongConfig) Validate() error {
+	var errs []error
+	if c.PingInterval < -1 {
+		errs = append(errs, fmt.Errorf("PingInterval must be -1 (disabled), 0 (default) or positive, got %s", c.PingInterval))
+	}
+	if c.PongTimeout < -1 {
+		errs = append(errs, fmt.Errorf("PongTimeout must be -1 (disabled), 0 (default) or positive, got %s", c.PongTimeout))
+	}
+	pingInterval, pongTimeout := getPingPongPeriodValues(c)
+	if pingInterval > 0 && pongTimeout > 0 && pongTimeout >= pingInterval {
+		errs = append(errs, fmt.Errorf("PongTimeout (%s) must be less than PingInterval (%s)", pongTimeout, pingInterval))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks Config for invalid and conflicting values. It returns an error
+// listing all problems found (see errors.Join), or nil if Config is valid. Validate
+// is called by New, so usually there is no need to call it explicitly.
+func (c Config) Validate() error {
+	var errs []error
+	nonNegativeDurations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SlowOperationThreshold", c.SlowOperationThreshold},
+		{"SlowCommandThreshold", c.SlowCommandThreshold},
+		{"NodeInfoMetricsAggregateInterval", c.NodeInfoMetricsAggregateInterval},
+		{"NodeHeartbeatInterval", c.NodeHeartbeatInterval},
+		{"NodeHeartbeatExpiration", c.NodeHeartbeatExpiration},
+		{"ClientPresenceUpdateInterval", c.ClientPresenceUpdateInterval},
+		{"ClientExpiredCloseDelay", c.ClientExpiredCloseDelay},
+		{"ClientExpiredSubCloseDelay", c.ClientExpiredSubCloseDelay},
+		{"ClientStaleCloseDelay", c.ClientStaleCloseDelay},
+		{"ClientChannelPositionCheckDelay", c.ClientChannelPositionCheckDelay},
+		{"ClientChannelPositionMaxTimeLag", c.ClientChannelPositionMaxTimeLag},
+		{"HistoryCacheTTL", c.HistoryCacheTTL},
+		{"PresenceCacheTTL", c.PresenceCacheTTL},
+		{"JoinLeaveAggregationInterval", c.JoinLeaveAggregationInterval},
+		{"HistoryMetaTTL", c.HistoryMetaTTL},
+		{"SessionResumptionTTL", c.SessionResumptionTTL},
+	}
+	for _, d := range nonNegativeDurations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", d.name, d.value))
+		}
+	}
+	nonNegativeInts := []struct {
+		name  string
+		value int
+	}{
+		{"ClientQueueMaxSize", c.ClientQueueMaxSize},
+		{"ClientChannelLimit", c.ClientChannelLimit},
+		{"UserConnectionLimit", c.UserConnectionLimit},
+		{"ChannelMaxLength", c.ChannelMaxLength},
+		{"HistoryMaxPublicationLimit", c.HistoryMaxPublicationLimit},
+		{"RecoveryMaxPublicationLimit", c.RecoveryMaxPublicationLimit},
+		{"HistoryCacheSize", c.HistoryCacheSize},
+		{"PublicationMaxSize", c.PublicationMaxSize},
+		{"ChannelNamespaceLabelMaxCardinality", c.ChannelNamespaceLabelMaxCardinality},
+		{"BroadcastWorkerPoolSize", c.BroadcastWorkerPoolSize},
+		{"BroadcastWorkerQueueSize", c.BroadcastWorkerQueueSize},
+		{"BroadcastChunkSize", c.BroadcastChunkSize},
+	}
+	for _, i := range nonNegativeInts {
+		if i.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", i.name, i.value))
+		}
+	}
+	if c.NodeInfoMetricsAggregateInterval > 0 && c.NodeInfoMetricsAggregateInterval < time.Second {
+		errs = append(errs, fmt.Errorf("NodeInfoMetricsAggregateInterval must be at least 1s, got %s", c.NodeInfoMetricsAggregateInterval))
+	}
+	if c.NodeHeartbeatExpiration > 0 {
+		heartbeatInterval := c.NodeHeartbeatInterval
+		if heartbeatInterval == 0 {
+			heartbeatInterval = nodeInfoPublishInterval
+		}
+		if c.NodeHeartbeatExpiration <= heartbeatInterval {
+			errs = append(errs, fmt.Errorf("NodeHeartbeatExpiration must be greater than NodeHeartbeatInterval (%s), got %s", heartbeatInterval, c.NodeHeartbeatExpiration))
+		}
+	}
+	clientQueueMaxSize := c.ClientQueueMaxSize
+	if clientQueueMaxSize == 0 {
+		clientQueueMaxSize = 1048576
+	}
+	if c.PublicationMaxSize > clientQueueMaxSize {
+		errs = append(errs, fmt.Errorf("PublicationMaxSize (%d) exceeds ClientQueueMaxSize (%d): clients receiving such publications would be disconnected", c.PublicationMaxSize, clientQueueMaxSize))
+	}
+	if c.BroadcastWorkerPoolSize == 0 && (c.BroadcastWorkerQueueSize > 0 || c.BroadcastChunkSize > 0 || c.BroadcastOverflowPolicy != BroadcastOverflowInline) {
+		errs = append(errs, errors.New("BroadcastWorkerQueueSize, BroadcastChunkSize and BroadcastOverflowPolicy require BroadcastWorkerPoolSize to be set"))
+	}
+	if c.GetChannelNamespaceLabel == nil && (c.ChannelNamespaceLabelForTransportMessagesSent || c.ChannelNamespaceLabelForTransportMessagesReceived || c.ChannelNamespaceLabelForPublish || c.ChannelNamespaceLabelForSubscribe || c.ChannelNamespaceLabelForClientQueue) {
+		errs = append(errs, errors.New("ChannelNamespaceLabelFor* options require GetChannelNamespaceLabel to be set"))
+	}
+	if c.UserLimitedChannels {
+		boundary, separator := c.UserChannelBoundary, c.UserChannelSeparator
+		if boundary == "" {
+			boundary = "#"
+		}
+		if separator == "" {
+			separator = ","
+		}
+		if boundary == separator {
+			errs = append(errs, fmt.Errorf("UserChannelBoundary and UserChannelSeparator must differ, both are %q", boundary))
+		}
+	}
+	for code, advice := range c.ClientReconnectAdvice {
+		if err := advice.validate(code); err != nil {
+			errs = append(errs, fmt.Errorf("invalid ClientReconnectAdvice: %w", err))
+		}
+	}
+	if _, err := newChannelNamespaces(c); err != nil {
+		errs = append(errs, fmt.Errorf("invalid Namespaces: %w", err))
+	}
+	return errors.Join(errs...)
+}
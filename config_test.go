@@ -0,0 +1,54 @@
+package centrifuge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+
+	err := Config{
+		ClientQueueMaxSize:              -1,
+		ClientStaleCloseDelay:           -time.Second,
+		PublicationMaxSize:              2048,
+		ChannelNamespaceLabelForPublish: true,
+		BroadcastChunkSize:              10,
+		UserLimitedChannels:             true,
+		UserChannelBoundary:             ",",
+		Namespaces: []ChannelNamespace{
+			{Name: "chat"},
+			{Name: "chat"},
+		},
+	}.Validate()
+	require.Error(t, err)
+	msg := err.Error()
+	require.Contains(t, msg, "ClientQueueMaxSize must not be negative")
+	require.Contains(t, msg, "ClientStaleCloseDelay must not be negative")
+	require.Contains(t, msg, "GetChannelNamespaceLabel")
+	require.Contains(t, msg, "BroadcastWorkerPoolSize")
+	require.Contains(t, msg, "UserChannelBoundary and UserChannelSeparator must differ")
+	require.Contains(t, msg, "duplicate namespace name")
+
+	err = Config{ClientQueueMaxSize: 1024, PublicationMaxSize: 2048}.Validate()
+	require.ErrorContains(t, err, "PublicationMaxSize (2048) exceeds ClientQueueMaxSize (1024)")
+
+	err = Config{NodeHeartbeatExpiration: 2 * time.Second}.Validate()
+	require.ErrorContains(t, err, "NodeHeartbeatExpiration must be greater than NodeHeartbeatInterval (3s), got 2s")
+	require.NoError(t, Config{NodeHeartbeatInterval: time.Second, NodeHeartbeatExpiration: 2 * time.Second}.Validate())
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(Config{ClientChannelLimit: -1})
+	require.ErrorContains(t, err, "ClientChannelLimit must not be negative")
+}
+
+func TestPingPongConfig_Validate(t *testing.T) {
+	require.NoError(t, PingPongConfig{}.Validate())
+	require.NoError(t, PingPongConfig{PingInterval: -1, PongTimeout: -1}.Validate())
+	require.NoError(t, PingPongConfig{PingInterval: 5 * time.Second, PongTimeout: -1}.Validate())
+	require.ErrorContains(t, PingPongConfig{PingInterval: 5 * time.Second}.Validate(), "PongTimeout (10s) must be less than PingInterval (5s)")
+	require.ErrorContains(t, PingPongConfig{PingInterval: -2}.Validate(), "PingInterval must be -1")
+}
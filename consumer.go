@@ -0,0 +1,229 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ConsumerMessage is a message fetched by ConsumerSource from external system
+// like Kafka topic or database outbox table.
+type ConsumerMessage struct {
+	// Topic (or queue, table) message was consumed from.
+	Topic string
+	// Key of message, for Kafka this is a message key.
+	Key []byte
+	// Data is a message payload.
+	Data []byte
+	// Headers of message.
+	Headers map[string]string
+	// Ack is an optional source specific value (ex. partition and offset in Kafka)
+	// which ConsumerSource may use to commit message.
+	Ack any
+}
+
+// ConsumerSource is a source of messages for Consumer. Centrifuge does not
+// depend on clients to external systems – ConsumerSource is an adapter to
+// a client library of your choice (Kafka consumer group, SQS queue, etc.).
+type ConsumerSource interface {
+	// Fetch blocks until a batch of messages is available or ctx is done.
+	Fetch(ctx context.Context) ([]ConsumerMessage, error)
+	// Commit is called after all messages of batch returned by Fetch were
+	// published into channels – i.e. for Kafka this commits offsets.
+	Commit(ctx context.Context, messages []ConsumerMessage) error
+}
+
+// ConsumerReleaser may be implemented by ConsumerSource which holds resources for
+// fetched batch until it's committed – like open transaction with locked rows. For
+// such sources Consumer limits retries of batch with ConsumerConfig.MaxBatchRetries.
+type ConsumerReleaser interface {
+	// Release is called instead of Commit when batch was not published and committed
+	// within ConsumerConfig.MaxBatchRetries retries. Messages of released batch must
+	// be returned by Fetch again.
+	Release(ctx context.Context, messages []ConsumerMessage) error
+}
+
+// ConsumerPublication describes publication Consumer should make for
+// a consumed message.
+type ConsumerPublication struct {
+	// Channel to publish into.
+	Channel string
+	// Data to publish.
+	Data []byte
+	// Options of publication. Since messages are delivered at least once
+	// consider using WithIdempotencyKey.
+	Options []PublishOption
+}
+
+// ConsumerRouteFunc returns publications for consumed message. Returning no
+// publications skips the message. Returning error skips the message too and
+// logs the error – consuming is not blocked by malformed messages.
+type ConsumerRouteFunc func(msg ConsumerMessage) ([]ConsumerPublication, error)
+
+// ConsumerConfig is a config for Consumer.
+type ConsumerConfig struct {
+	// Name of consumer used in logs. Zero value means "consumer".
+	Name string
+	// Source of messages. Must be set.
+	Source ConsumerSource
+	// Route maps consumed message to publications. Must be set.
+	Route ConsumerRouteFunc
+	// RetryBackoff is an initial delay between retries of failed fetch, publish
+	// or commit operations, delay is doubled on every next attempt up to
+	// MaxRetryBackoff. Zero value means 100ms.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff is a maximum delay between retries. Zero value means 10s.
+	MaxRetryBackoff time.Duration
+	// MaxBatchRetries limits the total number of publish and commit retries for one
+	// fetched batch when Source implements ConsumerReleaser. After that batch is
+	// released and fetched again. Zero value means no limit.
+	MaxBatchRetries int
+}
+
+// errConsumerBatchRetries returned when batch was not processed in MaxBatchRetries.
+var errConsumerBatchRetries = errors.New("consumer: batch retries exceeded")
+
+// Consumer reads messages from ConsumerSource and publishes them into channels
+// using Node.Publish. Source messages are committed only after successful publish
+// into Broker, failed publications are retried – so delivery into channels is at
+// least once. Publications rejected with non-temporary *Error (like ErrorBadRequest,
+// ErrorPublicationTooLarge or ErrorInvalidPayload) are not retried: they are logged
+// and skipped, so one bad message does not block consuming. This allows streaming
+// backend events to clients without writing a custom relay service.
+type Consumer struct {
+	node   *Node
+	config ConsumerConfig
+}
+
+// NewConsumer creates Consumer. Call Consumer.Run to start consuming.
+func NewConsumer(n *Node, config ConsumerConfig) (*Consumer, error) {
+	if config.Source == nil {
+		return nil, errors.New("consumer: source required")
+	}
+	if config.Route == nil {
+		return nil, errors.New("consumer: route func required")
+	}
+	if config.Name == "" {
+		config.Name = "consumer"
+	}
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = 100 * time.Millisecond
+	}
+	if config.MaxRetryBackoff == 0 {
+		config.MaxRetryBackoff = 10 * time.Second
+	}
+	return &Consumer{node: n, config: config}, nil
+}
+
+// Run consumes messages until ctx is done. It returns ctx error.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		var messages []ConsumerMessage
+		err := c.retry(ctx, "fetch", nil, func() error {
+			var err error
+			messages, err = c.config.Source.Fetch(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		err = c.processBatch(ctx, messages)
+		if errors.Is(err, errConsumerBatchRetries) {
+			c.node.logger.log(newLogEntry(LogLevelError, "consumer batch retries exceeded, releasing batch", map[string]any{"consumer": c.config.Name, "retries": c.config.MaxBatchRetries}))
+			if err := c.config.Source.(ConsumerReleaser).Release(ctx, messages); err != nil {
+				c.node.logger.log(newLogEntry(LogLevelError, "error releasing consumer batch", map[string]any{"consumer": c.config.Name, "error": err.Error()}))
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// processBatch publishes messages and commits them. Returns errConsumerBatchRetries
+// if batch must be released.
+func (c *Consumer) processBatch(ctx context.Context, messages []ConsumerMessage) error {
+	var budget *int
+	if _, ok := c.config.Source.(ConsumerReleaser); ok && c.config.MaxBatchRetries > 0 {
+		retries := c.config.MaxBatchRetries
+		budget = &retries
+	}
+	for _, msg := range messages {
+		if err := c.process(ctx, msg, budget); err != nil {
+			return err
+		}
+	}
+	return c.retry(ctx, "commit", budget, func() error {
+		return c.config.Source.Commit(ctx, messages)
+	})
+}
+
+func (c *Consumer) process(ctx context.Context, msg ConsumerMessage, budget *int) error {
+	publications, err := c.config.Route(msg)
+	if err != nil {
+		c.node.logger.log(newLogEntry(LogLevelError, "error routing consumed message, skipping", map[string]any{"consumer": c.config.Name, "topic": msg.Topic, "error": err.Error()}))
+		c.node.metrics.incConsumerSkipped(c.config.Name, "route")
+		return nil
+	}
+	for _, pub := range publications {
+		var permanentErr error
+		err := c.retry(ctx, "publish", budget, func() error {
+			_, err := c.node.Publish(pub.Channel, pub.Data, pub.Options...)
+			var clientErr *Error
+			if errors.As(err, &clientErr) && !clientErr.Temporary {
+				// Publication rejected, retrying won't help.
+				permanentErr = err
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if permanentErr != nil {
+			c.node.logger.log(newLogEntry(LogLevelError, "publication of consumed message rejected, skipping", map[string]any{"consumer": c.config.Name, "topic": msg.Topic, "channel": pub.Channel, "error": permanentErr.Error()}))
+			c.node.metrics.incConsumerSkipped(c.config.Name, "publish")
+		}
+	}
+	return nil
+}
+
+// retry calls fn until it succeeds or ctx is done. If budget is not nil then every
+// retry decrements it, errConsumerBatchRetries returned when budget is exhausted.
+// Otherwise, returns ctx error only.
+func (c *Consumer) retry(ctx context.Context, op string, budget *int, fn func() error) error {
+	backoff := c.config.RetryBackoff
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if budget != nil {
+			if *budget <= 0 {
+				c.node.logger.log(newLogEntry(LogLevelError, "consumer operation failed", map[string]any{"consumer": c.config.Name, "op": op, "error": err.Error()}))
+				return errConsumerBatchRetries
+			}
+			*budget--
+		}
+		c.node.logger.log(newLogEntry(LogLevelError, "consumer operation failed, retrying", map[string]any{"consumer": c.config.Name, "op": op, "error": err.Error(), "backoff": backoff.String()}))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > c.config.MaxRetryBackoff {
+			backoff = c.config.MaxRetryBackoff
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// KafkaMessage is a message fetched from Kafka topic partition.
+type KafkaMessage struct {
+	// Topic of message.
+	Topic string
+	// Partition of topic.
+	Partition int32
+	// Offset of message in partition.
+	Offset int64
+	// Key of message.
+	Key []byte
+	// Value of message.
+	Value []byte
+	// Headers of message.
+	Headers map[string]string
+}
+
+// KafkaOffset is a position in topic partition.
+type KafkaOffset struct {
+	Topic     string
+	Partition int32
+	// Offset is an offset of the next message to consume from partition – i.e.
+	// offset of the last processed message plus one, as Kafka expects on commit.
+	Offset int64
+}
+
+// KafkaClient is a minimal Kafka consumer group API used by Kafka consumer. Centrifuge
+// does not depend on Kafka client libraries – implement KafkaClient with library of
+// your choice with auto commit disabled. For example, with github.com/twmb/franz-go
+// FetchMessages calls PollRecords, CommitOffsets calls CommitOffsetsSync.
+type KafkaClient interface {
+	// FetchMessages blocks until messages of partitions assigned to consumer are
+	// available or ctx is done. Returns up to maxMessages messages, messages of each
+	// partition must be in offset order.
+	FetchMessages(ctx context.Context, maxMessages int) ([]KafkaMessage, error)
+	// CommitOffsets commits consumer group offsets of partitions.
+	CommitOffsets(ctx context.Context, offsets []KafkaOffset) error
+}
+
+// KafkaConsumerConfig is a config for Kafka consumer.
+type KafkaConsumerConfig struct {
+	// Client to Kafka. Must be set.
+	Client KafkaClient
+	// MaxMessages fetched at once. Zero value means 100.
+	MaxMessages int
+	// ChannelHeader is a name of message header containing channel to publish into.
+	// Zero value means "channel".
+	ChannelHeader string
+	// Route maps message to publications. Message headers are available in
+	// ConsumerMessage.Headers, key in ConsumerMessage.Key. By default, message value
+	// is published into channel from ChannelHeader header.
+	Route ConsumerRouteFunc
+}
+
+// NewKafkaConsumer creates Consumer fetching messages from Kafka topics and publishing
+// them into channels. Offsets are committed after all messages of fetched batch were
+// published: for each partition offset of the last message in batch plus one is
+// committed. Messages of each partition are published in offset order. Messages which
+// were not committed before partition reassignment or node failure are consumed again
+// by the next partition owner – use WithIdempotencyKey in Route to avoid duplicate
+// publications. Call Consumer.Run to start consuming.
+func NewKafkaConsumer(n *Node, config KafkaConsumerConfig) (*Consumer, error) {
+	if config.Client == nil {
+		return nil, errors.New("kafka consumer: client required")
+	}
+	if config.MaxMessages == 0 {
+		config.MaxMessages = 100
+	}
+	if config.ChannelHeader == "" {
+		config.ChannelHeader = "channel"
+	}
+	route := config.Route
+	if route == nil {
+		channelHeader := config.ChannelHeader
+		route = func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			channel := msg.Headers[channelHeader]
+			if channel == "" {
+				return nil, errors.New("no " + channelHeader + " header in message")
+			}
+			return []ConsumerPublication{{Channel: channel, Data: msg.Data}}, nil
+		}
+	}
+	return NewConsumer(n, ConsumerConfig{
+		Name:   "kafka",
+		Source: &kafkaSource{config: config},
+		Route:  route,
+	})
+}
+
+type kafkaSource struct {
+	config KafkaConsumerConfig
+}
+
+func (s *kafkaSource) Fetch(ctx context.Context) ([]ConsumerMessage, error) {
+	fetched, err := s.config.Client.FetchMessages(ctx, s.config.MaxMessages)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]ConsumerMessage, 0, len(fetched))
+	for _, m := range fetched {
+		messages = append(messages, ConsumerMessage{
+			Topic:   m.Topic,
+			Key:     m.Key,
+			Data:    m.Value,
+			Headers: m.Headers,
+			Ack:     KafkaOffset{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset},
+		})
+	}
+	return messages, nil
+}
+
+func (s *kafkaSource) Commit(ctx context.Context, messages []ConsumerMessage) error {
+	offsets := kafkaCommitOffsets(messages)
+	if len(offsets) == 0 {
+		return nil
+	}
+	return s.config.Client.CommitOffsets(ctx, offsets)
+}
+
+type kafkaPartition struct {
+	topic     string
+	partition int32
+}
+
+// kafkaCommitOffsets returns offsets to commit for messages – next offset after
+// the greatest one of each partition, sorted by topic and partition.
+func kafkaCommitOffsets(messages []ConsumerMessage) []KafkaOffset {
+	next := make(map[kafkaPartition]int64)
+	for _, msg := range messages {
+		o, ok := msg.Ack.(KafkaOffset)
+		if !ok {
+			continue
+		}
+		key := kafkaPartition{topic: o.Topic, partition: o.Partition}
+		if offset, ok := next[key]; !ok || o.Offset+1 > offset {
+			next[key] = o.Offset + 1
+		}
+	}
+	offsets := make([]KafkaOffset, 0, len(next))
+	for key, offset := range next {
+		offsets = append(offsets, KafkaOffset{Topic: key.topic, Partition: key.partition, Offset: offset})
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+	return offsets
+}
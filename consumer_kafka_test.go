@@ -0,0 +1,108 @@
+package centrifuge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testKafkaClient struct {
+	mu        sync.Mutex
+	messages  []KafkaMessage
+	committed [][]KafkaOffset
+}
+
+func (c *testKafkaClient) FetchMessages(ctx context.Context, maxMessages int) ([]KafkaMessage, error) {
+	c.mu.Lock()
+	if len(c.messages) == 0 {
+		c.mu.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	n := min(maxMessages, len(c.messages))
+	messages := c.messages[:n]
+	c.messages = c.messages[n:]
+	c.mu.Unlock()
+	return messages, nil
+}
+
+func (c *testKafkaClient) CommitOffsets(_ context.Context, offsets []KafkaOffset) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.committed = append(c.committed, offsets)
+	return nil
+}
+
+func TestNewKafkaConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	_, err := NewKafkaConsumer(node, KafkaConsumerConfig{})
+	require.Error(t, err)
+}
+
+func TestKafkaCommitOffsets(t *testing.T) {
+	offsets := kafkaCommitOffsets([]ConsumerMessage{
+		{Ack: KafkaOffset{Topic: "b", Partition: 0, Offset: 5}},
+		{Ack: KafkaOffset{Topic: "a", Partition: 1, Offset: 10}},
+		{Ack: KafkaOffset{Topic: "a", Partition: 0, Offset: 3}},
+		{Ack: KafkaOffset{Topic: "a", Partition: 1, Offset: 11}},
+		{Ack: KafkaOffset{Topic: "a", Partition: 0, Offset: 4}},
+	})
+	require.Equal(t, []KafkaOffset{
+		{Topic: "a", Partition: 0, Offset: 5},
+		{Topic: "a", Partition: 1, Offset: 12},
+		{Topic: "b", Partition: 0, Offset: 6},
+	}, offsets)
+	require.Empty(t, kafkaCommitOffsets(nil))
+}
+
+func TestKafkaConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client := &testKafkaClient{messages: []KafkaMessage{
+		{Topic: "events", Partition: 0, Offset: 7, Value: []byte(`{"n":1}`), Headers: map[string]string{"channel": "a"}},
+		{Topic: "events", Partition: 1, Offset: 3, Value: []byte(`{"n":2}`), Headers: map[string]string{"channel": "b"}},
+		{Topic: "events", Partition: 0, Offset: 8, Value: []byte(`{"n":3}`), Headers: map[string]string{"channel": "a"}},
+		{Topic: "events", Partition: 1, Offset: 4, Value: []byte(`{}`)},
+	}}
+
+	consumer, err := NewKafkaConsumer(node, KafkaConsumerConfig{
+		Client:      client,
+		MaxMessages: 3,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			return []ConsumerPublication{{
+				Channel: msg.Headers["channel"],
+				Data:    msg.Data,
+				Options: []PublishOption{WithHistory(10, time.Minute)},
+			}}, nil
+		},
+	})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		return len(client.committed) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	client.mu.Lock()
+	require.Equal(t, []KafkaOffset{{Topic: "events", Partition: 0, Offset: 9}, {Topic: "events", Partition: 1, Offset: 4}}, client.committed[0])
+	// Message without channel skipped but committed.
+	require.Equal(t, []KafkaOffset{{Topic: "events", Partition: 1, Offset: 5}}, client.committed[1])
+	client.mu.Unlock()
+
+	result, err := node.History("a", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 2)
+	require.Equal(t, []byte(`{"n":1}`), result.Publications[0].Data)
+	require.Equal(t, []byte(`{"n":3}`), result.Publications[1].Data)
+}
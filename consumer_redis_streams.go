@@ -0,0 +1,231 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/rueidis"
+)
+
+// RedisStreamsConsumerConfig is a config for Redis Streams consumer.
+type RedisStreamsConsumerConfig struct {
+	// Shard is a Redis shard to consume streams from. Must be set.
+	Shard *RedisShard
+	// Streams to consume. Must be set. In Redis Cluster all streams must belong
+	// to the same hash slot – use hash tags like {events}.users.
+	Streams []string
+	// Group is a consumer group name. Group is created (together with stream)
+	// if not exists. Must be set.
+	Group string
+	// Consumer is a consumer name inside group. Zero value means Node ID.
+	Consumer string
+	// BatchSize is a max number of entries read at once. Zero value means 100.
+	BatchSize int
+	// Block is a max time to wait for new entries. Zero value means 1 second.
+	Block time.Duration
+	// ClaimMinIdle is a time after which pending entries of other consumers in
+	// group (ex. of crashed node) are claimed by this consumer. Zero value means
+	// 30 seconds.
+	ClaimMinIdle time.Duration
+	// Route maps stream entry to publications. Entry fields are available in
+	// ConsumerMessage.Headers, value of "data" field is also set to ConsumerMessage.Data.
+	// By default, entry is published into channel from "channel" field.
+	Route ConsumerRouteFunc
+}
+
+// NewRedisStreamsConsumer creates Consumer reading entries of Redis Streams using
+// consumer group and publishing them into channels. Entries are acknowledged with
+// XACK after successful publish. Pending entries not acknowledged during
+// ClaimMinIdle are claimed with XAUTOCLAIM (requires Redis >= 6.2) – so entries
+// of crashed consumers are eventually published. This allows other services to feed
+// realtime data with XADD only. Call Consumer.Run to start consuming.
+func NewRedisStreamsConsumer(n *Node, config RedisStreamsConsumerConfig) (*Consumer, error) {
+	if config.Shard == nil {
+		return nil, errors.New("redis streams consumer: shard required")
+	}
+	if len(config.Streams) == 0 {
+		return nil, errors.New("redis streams consumer: streams required")
+	}
+	if config.Group == "" {
+		return nil, errors.New("redis streams consumer: group required")
+	}
+	if config.Consumer == "" {
+		config.Consumer = n.ID()
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = 100
+	}
+	if config.Block == 0 {
+		config.Block = time.Second
+	}
+	if config.ClaimMinIdle == 0 {
+		config.ClaimMinIdle = 30 * time.Second
+	}
+	route := config.Route
+	if route == nil {
+		route = redisStreamsDefaultRoute
+	}
+	return NewConsumer(n, ConsumerConfig{
+		Name:   "redis_streams",
+		Source: &redisStreamsSource{config: config},
+		Route:  route,
+	})
+}
+
+func redisStreamsDefaultRoute(msg ConsumerMessage) ([]ConsumerPublication, error) {
+	channel := msg.Headers["channel"]
+	if channel == "" {
+		return nil, errors.New("no channel field in stream entry")
+	}
+	return []ConsumerPublication{{Channel: channel, Data: msg.Data}}, nil
+}
+
+type redisStreamsSource struct {
+	config RedisStreamsConsumerConfig
+
+	createdGroups bool
+	lastClaim     time.Time
+	// claim cursors by stream.
+	claimCursors map[string]string
+}
+
+func (s *redisStreamsSource) client() rueidis.Client {
+	return s.config.Shard.client
+}
+
+func (s *redisStreamsSource) createGroups(ctx context.Context) error {
+	for _, stream := range s.config.Streams {
+		cmd := s.client().B().XgroupCreate().Key(stream).Group(s.config.Group).Id("$").Mkstream().Build()
+		err := s.client().Do(ctx, cmd).Error()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *redisStreamsSource) Fetch(ctx context.Context) ([]ConsumerMessage, error) {
+	if !s.createdGroups {
+		if err := s.createGroups(ctx); err != nil {
+			return nil, err
+		}
+		s.createdGroups = true
+	}
+	if time.Since(s.lastClaim) >= s.config.ClaimMinIdle {
+		messages, err := s.claim(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 {
+			return messages, nil
+		}
+		s.lastClaim = time.Now()
+	}
+
+	ids := make([]string, len(s.config.Streams))
+	for i := range ids {
+		ids[i] = ">"
+	}
+	cmd := s.client().B().Xreadgroup().Group(s.config.Group, s.config.Consumer).
+		Count(int64(s.config.BatchSize)).Block(s.config.Block.Milliseconds()).
+		Streams().Key(s.config.Streams...).Id(ids...).Build()
+	result, err := s.client().Do(ctx, cmd).AsXRead()
+	if err != nil {
+		if rueidis.IsRedisNil(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var messages []ConsumerMessage
+	for _, stream := range s.config.Streams {
+		for _, entry := range result[stream] {
+			messages = append(messages, redisStreamEntryToMessage(stream, entry))
+		}
+	}
+	return messages, nil
+}
+
+// claim takes pending entries idle for more than ClaimMinIdle. Called until
+// there are no more entries to claim in all streams.
+func (s *redisStreamsSource) claim(ctx context.Context) ([]ConsumerMessage, error) {
+	if s.claimCursors == nil {
+		s.claimCursors = map[string]string{}
+	}
+	var messages []ConsumerMessage
+	for _, stream := range s.config.Streams {
+		cursor, ok := s.claimCursors[stream]
+		if !ok {
+			cursor = "0-0"
+		}
+		if cursor == "" {
+			// Claiming in this stream finished in current round.
+			continue
+		}
+		cmd := s.client().B().Xautoclaim().Key(stream).Group(s.config.Group).Consumer(s.config.Consumer).
+			MinIdleTime(strconv.FormatInt(s.config.ClaimMinIdle.Milliseconds(), 10)).
+			Start(cursor).Count(int64(s.config.BatchSize)).Build()
+		values, err := s.client().Do(ctx, cmd).ToArray()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) < 2 {
+			return nil, errors.New("redis streams consumer: unexpected XAUTOCLAIM reply")
+		}
+		nextCursor, err := values[0].ToString()
+		if err != nil {
+			return nil, err
+		}
+		entries, err := values[1].AsXRange()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.FieldValues == nil {
+				// Entry deleted from stream but still pending.
+				continue
+			}
+			messages = append(messages, redisStreamEntryToMessage(stream, entry))
+		}
+		if nextCursor == "0-0" {
+			nextCursor = ""
+		}
+		s.claimCursors[stream] = nextCursor
+	}
+	if len(messages) == 0 {
+		// Start from the beginning on next claim round.
+		s.claimCursors = nil
+	}
+	return messages, nil
+}
+
+func redisStreamEntryToMessage(stream string, entry rueidis.XRangeEntry) ConsumerMessage {
+	headers := make(map[string]string, len(entry.FieldValues)+1)
+	for k, v := range entry.FieldValues {
+		headers[k] = v
+	}
+	headers["id"] = entry.ID
+	return ConsumerMessage{
+		Topic:   stream,
+		Key:     []byte(entry.ID),
+		Data:    []byte(entry.FieldValues["data"]),
+		Headers: headers,
+		Ack:     entry.ID,
+	}
+}
+
+func (s *redisStreamsSource) Commit(ctx context.Context, messages []ConsumerMessage) error {
+	idsByStream := map[string][]string{}
+	for _, msg := range messages {
+		idsByStream[msg.Topic] = append(idsByStream[msg.Topic], msg.Ack.(string))
+	}
+	for stream, ids := range idsByStream {
+		cmd := s.client().B().Xack().Key(stream).Group(s.config.Group).Id(ids...).Build()
+		if err := s.client().Do(ctx, cmd).Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build integration
+
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStreamsConsumer(t *testing.T) {
+	node := testNode(t)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	s, err := NewRedisShard(node, testSingleRedisConf(0))
+	require.NoError(t, err)
+
+	stream := getUniquePrefix() + ".events"
+	client := s.client
+	ctx := context.Background()
+	require.NoError(t, client.Do(ctx, client.B().XgroupCreate().Key(stream).Group("g").Id("$").Mkstream().Build()).Error())
+
+	// Entry read by crashed consumer and left pending.
+	require.NoError(t, client.Do(ctx, client.B().Xadd().Key(stream).Id("*").FieldValue().
+		FieldValue("channel", "a").FieldValue("data", `{"n":1}`).Build()).Error())
+	require.NoError(t, client.Do(ctx, client.B().Xreadgroup().Group("g", "crashed").Count(10).
+		Streams().Key(stream).Id(">").Build()).Error())
+	require.NoError(t, client.Do(ctx, client.B().Xadd().Key(stream).Id("*").FieldValue().
+		FieldValue("channel", "b").FieldValue("data", `{"n":2}`).Build()).Error())
+
+	consumer, err := NewRedisStreamsConsumer(node, RedisStreamsConsumerConfig{
+		Shard:        s,
+		Streams:      []string{stream},
+		Group:        "g",
+		Block:        50 * time.Millisecond,
+		ClaimMinIdle: 100 * time.Millisecond,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			return []ConsumerPublication{{
+				Channel: msg.Headers["channel"],
+				Data:    msg.Data,
+				Options: []PublishOption{WithHistory(10, time.Minute)},
+			}}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() { _ = consumer.Run(runCtx) }()
+
+	require.Eventually(t, func() bool {
+		pending, err := client.Do(ctx, client.B().Xpending().Key(stream).Group("g").Build()).ToArray()
+		if err != nil || len(pending) == 0 {
+			return false
+		}
+		numPending, _ := pending[0].AsInt64()
+		if numPending != 0 {
+			return false
+		}
+		for _, ch := range []string{"a", "b"} {
+			result, err := node.History(ch, WithLimit(NoLimit))
+			if err != nil || len(result.Publications) != 1 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,36 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRedisStreamsConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err := NewRedisStreamsConsumer(node, RedisStreamsConsumerConfig{})
+	require.Error(t, err)
+	_, err = NewRedisStreamsConsumer(node, RedisStreamsConsumerConfig{Shard: &RedisShard{}})
+	require.Error(t, err)
+	_, err = NewRedisStreamsConsumer(node, RedisStreamsConsumerConfig{Shard: &RedisShard{}, Streams: []string{"events"}})
+	require.Error(t, err)
+	consumer, err := NewRedisStreamsConsumer(node, RedisStreamsConsumerConfig{Shard: &RedisShard{}, Streams: []string{"events"}, Group: "centrifuge"})
+	require.NoError(t, err)
+	source := consumer.config.Source.(*redisStreamsSource)
+	require.Equal(t, node.ID(), source.config.Consumer)
+	require.Equal(t, 100, source.config.BatchSize)
+}
+
+func TestRedisStreamsDefaultRoute(t *testing.T) {
+	_, err := redisStreamsDefaultRoute(ConsumerMessage{Headers: map[string]string{"data": "{}"}})
+	require.Error(t, err)
+	publications, err := redisStreamsDefaultRoute(ConsumerMessage{
+		Data:    []byte(`{}`),
+		Headers: map[string]string{"channel": "news", "data": "{}"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []ConsumerPublication{{Channel: "news", Data: []byte(`{}`)}}, publications)
+}
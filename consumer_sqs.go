@@ -0,0 +1,164 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// SQSMessage is a message received from SQS queue.
+type SQSMessage struct {
+	// MessageID of message.
+	MessageID string
+	// ReceiptHandle required to delete message from queue.
+	ReceiptHandle string
+	// Body of message.
+	Body string
+	// Attributes are string message attributes.
+	Attributes map[string]string
+}
+
+// SQSClient is a minimal SQS API used by SQS consumer. Centrifuge does not depend
+// on AWS SDK – implement SQSClient with SDK of your choice, for aws-sdk-go-v2
+// ReceiveMessages calls ReceiveMessage with MessageAttributeNames set to "All",
+// DeleteMessages calls DeleteMessageBatch.
+type SQSClient interface {
+	// ReceiveMessages receives up to maxMessages messages waiting up to waitTime
+	// for messages to arrive (long polling).
+	ReceiveMessages(ctx context.Context, queueURL string, maxMessages int, waitTime time.Duration) ([]SQSMessage, error)
+	// DeleteMessages deletes messages with provided receipt handles from queue.
+	// Called with at most 10 receipt handles.
+	DeleteMessages(ctx context.Context, queueURL string, receiptHandles []string) error
+}
+
+// SQSConsumerConfig is a config for SQS consumer.
+type SQSConsumerConfig struct {
+	// Client to SQS. Must be set.
+	Client SQSClient
+	// QueueURL to consume. Must be set.
+	QueueURL string
+	// MaxMessages received at once, SQS allows up to 10. Zero value means 10.
+	MaxMessages int
+	// WaitTime for long polling, SQS allows up to 20 seconds. Zero value means
+	// 20 seconds.
+	WaitTime time.Duration
+	// ChannelAttribute is a name of message attribute containing channel to publish
+	// into. Zero value means "channel".
+	ChannelAttribute string
+	// Route maps message to publications. Message attributes are available in
+	// ConsumerMessage.Headers. By default, message body is published into channel
+	// from ChannelAttribute attribute.
+	Route ConsumerRouteFunc
+}
+
+// NewSQSConsumer creates Consumer receiving messages from SQS queue and publishing
+// them into channels. Messages are deleted from queue after successful publish, so
+// messages of failed nodes are received again after visibility timeout. If queue is
+// subscribed to SNS topic without raw message delivery then SNS envelope is unwrapped:
+// ConsumerMessage.Data contains SNS message and Headers contain SNS message attributes.
+// Call Consumer.Run to start consuming.
+func NewSQSConsumer(n *Node, config SQSConsumerConfig) (*Consumer, error) {
+	if config.Client == nil {
+		return nil, errors.New("sqs consumer: client required")
+	}
+	if config.QueueURL == "" {
+		return nil, errors.New("sqs consumer: queue URL required")
+	}
+	if config.MaxMessages == 0 {
+		config.MaxMessages = 10
+	}
+	if config.WaitTime == 0 {
+		config.WaitTime = 20 * time.Second
+	}
+	if config.ChannelAttribute == "" {
+		config.ChannelAttribute = "channel"
+	}
+	route := config.Route
+	if route == nil {
+		channelAttribute := config.ChannelAttribute
+		route = func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			channel := msg.Headers[channelAttribute]
+			if channel == "" {
+				return nil, errors.New("no " + channelAttribute + " attribute in message")
+			}
+			return []ConsumerPublication{{Channel: channel, Data: msg.Data}}, nil
+		}
+	}
+	return NewConsumer(n, ConsumerConfig{
+		Name:   "sqs",
+		Source: &sqsSource{config: config},
+		Route:  route,
+	})
+}
+
+type sqsSource struct {
+	config SQSConsumerConfig
+}
+
+func (s *sqsSource) Fetch(ctx context.Context) ([]ConsumerMessage, error) {
+	received, err := s.config.Client.ReceiveMessages(ctx, s.config.QueueURL, s.config.MaxMessages, s.config.WaitTime)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]ConsumerMessage, 0, len(received))
+	for _, m := range received {
+		messages = append(messages, sqsToConsumerMessage(s.config.QueueURL, m))
+	}
+	return messages, nil
+}
+
+const sqsDeleteBatchSize = 10
+
+func (s *sqsSource) Commit(ctx context.Context, messages []ConsumerMessage) error {
+	for len(messages) > 0 {
+		batch := messages[:min(len(messages), sqsDeleteBatchSize)]
+		receiptHandles := make([]string, len(batch))
+		for i, msg := range batch {
+			receiptHandles[i] = msg.Ack.(string)
+		}
+		if err := s.config.Client.DeleteMessages(ctx, s.config.QueueURL, receiptHandles); err != nil {
+			return err
+		}
+		messages = messages[len(batch):]
+	}
+	return nil
+}
+
+type snsNotification struct {
+	Type              string `json:"Type"`
+	MessageID         string `json:"MessageId"`
+	TopicArn          string `json:"TopicArn"`
+	Message           string `json:"Message"`
+	MessageAttributes map[string]struct {
+		Type  string `json:"Type"`
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+func sqsToConsumerMessage(queueURL string, m SQSMessage) ConsumerMessage {
+	headers := make(map[string]string, len(m.Attributes))
+	for k, v := range m.Attributes {
+		headers[k] = v
+	}
+	msg := ConsumerMessage{
+		Topic:   queueURL,
+		Key:     []byte(m.MessageID),
+		Data:    []byte(m.Body),
+		Headers: headers,
+		Ack:     m.ReceiptHandle,
+	}
+	var notification snsNotification
+	if len(m.Body) > 0 && m.Body[0] == '{' && json.Unmarshal([]byte(m.Body), &notification) == nil &&
+		notification.Type == "Notification" && notification.TopicArn != "" {
+		msg.Topic = notification.TopicArn
+		msg.Data = []byte(notification.Message)
+		for k, v := range notification.MessageAttributes {
+			if v.Type == "String" || v.Type == "Number" {
+				msg.Headers[k] = v.Value
+			}
+		}
+	}
+	return msg
+}
@@ -0,0 +1,103 @@
+package centrifuge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testSQSClient struct {
+	mu       sync.Mutex
+	messages []SQSMessage
+	deleted  [][]string
+}
+
+func (c *testSQSClient) ReceiveMessages(ctx context.Context, _ string, maxMessages int, _ time.Duration) ([]SQSMessage, error) {
+	c.mu.Lock()
+	if len(c.messages) == 0 {
+		c.mu.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	n := min(maxMessages, len(c.messages))
+	messages := c.messages[:n]
+	c.messages = c.messages[n:]
+	c.mu.Unlock()
+	return messages, nil
+}
+
+func (c *testSQSClient) DeleteMessages(_ context.Context, _ string, receiptHandles []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, receiptHandles)
+	return nil
+}
+
+func TestNewSQSConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	_, err := NewSQSConsumer(node, SQSConsumerConfig{})
+	require.Error(t, err)
+	_, err = NewSQSConsumer(node, SQSConsumerConfig{Client: &testSQSClient{}})
+	require.Error(t, err)
+}
+
+func TestSQSToConsumerMessage(t *testing.T) {
+	msg := sqsToConsumerMessage("queue", SQSMessage{
+		MessageID:     "1",
+		ReceiptHandle: "r1",
+		Body:          `{"n":1}`,
+		Attributes:    map[string]string{"channel": "a"},
+	})
+	require.Equal(t, "queue", msg.Topic)
+	require.Equal(t, []byte(`{"n":1}`), msg.Data)
+	require.Equal(t, "a", msg.Headers["channel"])
+	require.Equal(t, "r1", msg.Ack)
+
+	msg = sqsToConsumerMessage("queue", SQSMessage{
+		MessageID:     "2",
+		ReceiptHandle: "r2",
+		Body:          `{"Type":"Notification","MessageId":"x","TopicArn":"arn:aws:sns:topic","Message":"{\"n\":2}","MessageAttributes":{"channel":{"Type":"String","Value":"b"}}}`,
+	})
+	require.Equal(t, "arn:aws:sns:topic", msg.Topic)
+	require.Equal(t, []byte(`{"n":2}`), msg.Data)
+	require.Equal(t, "b", msg.Headers["channel"])
+}
+
+func TestSQSConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client := &testSQSClient{}
+	for i := 0; i < 12; i++ {
+		client.messages = append(client.messages, SQSMessage{
+			ReceiptHandle: "r",
+			Body:          `{}`,
+			Attributes:    map[string]string{"channel": "test"},
+		})
+	}
+	client.messages = append(client.messages, SQSMessage{ReceiptHandle: "no_channel", Body: `{}`})
+
+	consumer, err := NewSQSConsumer(node, SQSConsumerConfig{Client: client, QueueURL: "queue", MaxMessages: 13})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		return len(client.deleted) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	require.Len(t, client.deleted[0], 10)
+	// Message without channel skipped but deleted.
+	require.Len(t, client.deleted[1], 3)
+}
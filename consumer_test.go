@@ -0,0 +1,251 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testConsumerSource struct {
+	mu         sync.Mutex
+	batches    [][]ConsumerMessage
+	fetchErrs  int
+	commitErrs int
+	committed  []ConsumerMessage
+	commitCh   chan struct{}
+}
+
+func (s *testConsumerSource) Fetch(ctx context.Context) ([]ConsumerMessage, error) {
+	s.mu.Lock()
+	if s.fetchErrs > 0 {
+		s.fetchErrs--
+		s.mu.Unlock()
+		return nil, errors.New("fetch boom")
+	}
+	if len(s.batches) > 0 {
+		batch := s.batches[0]
+		s.batches = s.batches[1:]
+		s.mu.Unlock()
+		return batch, nil
+	}
+	s.mu.Unlock()
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *testConsumerSource) Commit(_ context.Context, messages []ConsumerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.commitErrs > 0 {
+		s.commitErrs--
+		return errors.New("commit boom")
+	}
+	s.committed = append(s.committed, messages...)
+	s.commitCh <- struct{}{}
+	return nil
+}
+
+func TestNewConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	_, err := NewConsumer(node, ConsumerConfig{})
+	require.Error(t, err)
+	_, err = NewConsumer(node, ConsumerConfig{Source: &testConsumerSource{}})
+	require.Error(t, err)
+}
+
+func TestConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	source := &testConsumerSource{
+		batches: [][]ConsumerMessage{
+			{{Topic: "events", Key: []byte("a"), Data: []byte(`{"n":1}`)}, {Topic: "events", Key: []byte("bad")}},
+			{{Topic: "events", Key: []byte("b"), Data: []byte(`{"n":2}`)}},
+		},
+		fetchErrs:  1,
+		commitErrs: 1,
+		commitCh:   make(chan struct{}, 2),
+	}
+
+	consumer, err := NewConsumer(node, ConsumerConfig{
+		Source: source,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			if len(msg.Data) == 0 {
+				return nil, errors.New("empty data")
+			}
+			return []ConsumerPublication{{
+				Channel: "user:" + string(msg.Key),
+				Data:    msg.Data,
+				Options: []PublishOption{WithHistory(10, time.Minute)},
+			}}, nil
+		},
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-source.commitCh:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for commit")
+		}
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	source.mu.Lock()
+	require.Len(t, source.committed, 3)
+	source.mu.Unlock()
+
+	for _, key := range []string{"a", "b"} {
+		result, err := node.History("user:"+key, WithLimit(NoLimit))
+		require.NoError(t, err)
+		require.Len(t, result.Publications, 1)
+	}
+}
+
+func TestConsumer_PublishRetry(t *testing.T) {
+	broker := NewTestBroker()
+	broker.errorOnPublish = true
+	node := nodeWithBroker(broker)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	source := &testConsumerSource{
+		batches:  [][]ConsumerMessage{{{Data: []byte(`{}`)}}},
+		commitCh: make(chan struct{}, 1),
+	}
+	consumer, err := NewConsumer(node, ConsumerConfig{
+		Source: source,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			return []ConsumerPublication{{Channel: "test", Data: msg.Data}}, nil
+		},
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: 2 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, consumer.Run(ctx), context.DeadlineExceeded)
+	// Publish retried and message not committed.
+	require.Greater(t, atomic.LoadInt32(&broker.publishCount), int32(1))
+	require.Empty(t, source.committed)
+}
+
+// testReleasingConsumerSource returns released batches from Fetch again.
+type testReleasingConsumerSource struct {
+	testConsumerSource
+	released int
+}
+
+func (s *testReleasingConsumerSource) Release(_ context.Context, messages []ConsumerMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released++
+	s.batches = append(s.batches, messages)
+	return nil
+}
+
+func TestConsumer_MaxBatchRetries(t *testing.T) {
+	broker := NewTestBroker()
+	broker.errorOnPublish = true
+	node := nodeWithBroker(broker)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	source := &testReleasingConsumerSource{testConsumerSource: testConsumerSource{
+		batches:  [][]ConsumerMessage{{{Data: []byte(`{}`)}}},
+		commitCh: make(chan struct{}, 1),
+	}}
+	consumer, err := NewConsumer(node, ConsumerConfig{
+		Source: source,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			return []ConsumerPublication{{Channel: "test", Data: msg.Data}}, nil
+		},
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+		MaxBatchRetries: 2,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.released >= 2
+	}, 5*time.Second, time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	// Batch released after first attempt and two retries.
+	publishCount := atomic.LoadInt32(&broker.publishCount)
+	source.mu.Lock()
+	require.GreaterOrEqual(t, publishCount, int32(3*source.released))
+	require.LessOrEqual(t, publishCount, int32(3*(source.released+1)))
+	source.mu.Unlock()
+	require.Empty(t, source.committed)
+}
+
+func TestConsumer_PermanentPublishError(t *testing.T) {
+	node, err := New(Config{
+		LogLevel:           LogLevelTrace,
+		LogHandler:         func(entry LogEntry) {},
+		PublicationMaxSize: 8,
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	source := &testConsumerSource{
+		batches: [][]ConsumerMessage{{
+			{Key: []byte("a"), Data: []byte(`{"too":"large"}`)},
+			{Key: []byte("b"), Data: []byte(`{}`)},
+		}},
+		commitCh: make(chan struct{}, 1),
+	}
+	consumer, err := NewConsumer(node, ConsumerConfig{
+		Source: source,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			return []ConsumerPublication{{
+				Channel: "user:" + string(msg.Key),
+				Data:    msg.Data,
+				Options: []PublishOption{WithHistory(10, time.Minute)},
+			}}, nil
+		},
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	select {
+	case <-source.commitCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for commit")
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// Rejected message skipped and committed, next message published.
+	source.mu.Lock()
+	require.Len(t, source.committed, 2)
+	source.mu.Unlock()
+	result, err := node.History("user:b", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 1)
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.consumerSkippedCount.WithLabelValues("consumer", "publish")))
+}
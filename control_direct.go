@@ -0,0 +1,273 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ControlTransport delivers control messages between nodes. By default, control
+// messages are sent over Broker, ControlTransport set with Node.SetControlTransport
+// allows sending them directly between nodes instead – see DirectControl.
+type ControlTransport interface {
+	// Run called once on Node start. Handler must be called for control data
+	// received from other nodes.
+	Run(handler func(data []byte) error) error
+	// PublishControl sends control data. If nodeID is empty string then data
+	// should be delivered to all running nodes (including this node), if nodeID
+	// is set then data should be delivered only to node with specified ID.
+	PublishControl(data []byte, nodeID string) error
+}
+
+// ControlAddressRegistry provides addresses of DirectControl listeners of all nodes.
+type ControlAddressRegistry interface {
+	// ControlAddresses returns addresses of nodes, it may contain address of
+	// this node too.
+	ControlAddresses(ctx context.Context) ([]string, error)
+}
+
+// StaticControlAddresses is a ControlAddressRegistry with fixed list of addresses.
+type StaticControlAddresses []string
+
+// ControlAddresses – see ControlAddressRegistry.
+func (a StaticControlAddresses) ControlAddresses(_ context.Context) ([]string, error) {
+	return a, nil
+}
+
+// DNSControlAddresses is a ControlAddressRegistry which resolves Host to IP addresses
+// of nodes – for example, headless service in Kubernetes.
+type DNSControlAddresses struct {
+	Host string
+	Port int
+}
+
+// ControlAddresses – see ControlAddressRegistry.
+func (a DNSControlAddresses) ControlAddresses(ctx context.Context) ([]string, error) {
+	ips, err := net.DefaultResolver.LookupHost(ctx, a.Host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.JoinHostPort(ip, strconv.Itoa(a.Port)))
+	}
+	return addrs, nil
+}
+
+// DirectControlConfig is a config for DirectControl.
+type DirectControlConfig struct {
+	// BindAddr is an address to listen for connections from other nodes. Required.
+	BindAddr string
+	// AdvertiseAddr is an address of this node as returned by Registry. Used to not
+	// connect to itself, by default address of listener is used.
+	AdvertiseAddr string
+	// Registry provides addresses of other nodes. Required.
+	Registry ControlAddressRegistry
+	// RefreshInterval is an interval to refresh addresses from Registry. Also used
+	// as an interval between reconnect attempts. Zero value means 5 seconds.
+	RefreshInterval time.Duration
+	// TLS if set is used to protect connections between nodes with mutual TLS.
+	TLS *MutualTLS
+	// SharedSecret if set is used to authenticate connections between nodes when
+	// TLS is not used. All nodes must use the same secret. Note, that without TLS
+	// control messages are sent unencrypted. Either TLS or SharedSecret must be set
+	// since control messages allow disconnecting clients, sending data to them and
+	// so on.
+	SharedSecret string
+}
+
+// DirectControl is a ControlTransport which sends control messages over direct
+// TCP connections between nodes. Addresses of nodes are loaded from registry. This
+// reduces broker load and control latency in large clusters. Control messages sent
+// when connection to node is not established yet, or when connection is overflowed,
+// are dropped – drops are counted in mesh_dropped_frames_count metric.
+//
+// DirectControl uses simple length-prefixed framing over TCP (optionally with mutual
+// TLS) instead of gRPC streams to not bring gRPC dependency into the library. Since
+// ControlTransport is an interface, transport over gRPC streams may be implemented
+// outside – see _examples/control_grpc.
+type DirectControl struct {
+	node    *Node
+	config  DirectControlConfig
+	auth    meshAuth
+	drops   *meshDrops
+	server  *meshServer
+	addr    string
+	handler func(data []byte) error
+
+	mu    sync.RWMutex
+	peers map[string]*meshPeer // Address -> outgoing connection.
+	nodes map[string]string    // Node ID -> address.
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+var _ ControlTransport = (*DirectControl)(nil)
+
+var errDirectControlUnknownNode = errors.New("direct control: unknown node")
+
+// NewDirectControl creates DirectControl and starts listening on BindAddr.
+func NewDirectControl(n *Node, config DirectControlConfig) (*DirectControl, error) {
+	if config.BindAddr == "" || config.Registry == nil {
+		return nil, errors.New("direct control: BindAddr and Registry required")
+	}
+	auth, err := newMeshAuth(config.TLS, config.SharedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("direct control: %w", err)
+	}
+	if config.RefreshInterval == 0 {
+		config.RefreshInterval = 5 * time.Second
+	}
+	listener, err := listenMesh(config.BindAddr, auth)
+	if err != nil {
+		return nil, fmt.Errorf("direct control: %w", err)
+	}
+	addr := config.AdvertiseAddr
+	if addr == "" {
+		addr = listener.Addr().String()
+	}
+	c := &DirectControl{
+		node:    n,
+		config:  config,
+		auth:    auth,
+		drops:   newMeshDrops(n, "control"),
+		addr:    addr,
+		peers:   map[string]*meshPeer{},
+		nodes:   map[string]string{},
+		closeCh: make(chan struct{}),
+	}
+	c.server = newMeshServer(n, listener, auth, c.handleFrame)
+	return c, nil
+}
+
+// Addr returns address of DirectControl listener.
+func (c *DirectControl) Addr() string {
+	return c.addr
+}
+
+// Run – see ControlTransport.
+func (c *DirectControl) Run(handler func(data []byte) error) error {
+	c.handler = handler
+	go c.server.serve()
+	go c.refreshLoop()
+	return nil
+}
+
+// Close stops listening and closes connections to other nodes.
+func (c *DirectControl) Close(_ context.Context) error {
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+		c.server.close()
+		c.mu.Lock()
+		for _, p := range c.peers {
+			p.close()
+		}
+		c.mu.Unlock()
+	})
+	return nil
+}
+
+func (c *DirectControl) handleFrame(frameType byte, payload []byte) error {
+	if frameType != meshFrameControl {
+		return fmt.Errorf("unexpected mesh frame type: %d", frameType)
+	}
+	return c.handler(payload)
+}
+
+func (c *DirectControl) refreshLoop() {
+	ticker := time.NewTicker(c.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.refresh(); err != nil {
+			c.node.logger.log(newLogEntry(LogLevelError, "error refreshing control addresses", map[string]any{"error": err.Error()}))
+		}
+		select {
+		case <-c.closeCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh connects to new nodes from registry and disconnects from nodes which
+// are not in registry anymore.
+func (c *DirectControl) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.RefreshInterval)
+	defer cancel()
+	addrs, err := c.config.Registry.ControlAddresses(ctx)
+	if err != nil {
+		return err
+	}
+	actual := make(map[string]struct{}, len(addrs))
+	for _, addr := range addrs {
+		if addr != c.addr {
+			actual[addr] = struct{}{}
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.closeCh:
+		return nil
+	default:
+	}
+	for addr, p := range c.peers {
+		if _, ok := actual[addr]; !ok {
+			p.close()
+			delete(c.peers, addr)
+			for nodeID, nodeAddr := range c.nodes {
+				if nodeAddr == addr {
+					delete(c.nodes, nodeID)
+				}
+			}
+		}
+	}
+	for addr := range actual {
+		if _, ok := c.peers[addr]; ok {
+			continue
+		}
+		addr := addr
+		p := newMeshPeer(c.node, addr, c.auth, c.config.RefreshInterval, func(nodeID string) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if _, ok := c.peers[addr]; ok {
+				c.nodes[nodeID] = addr
+			}
+		})
+		c.peers[addr] = p
+		go p.run()
+	}
+	return nil
+}
+
+// PublishControl – see ControlTransport.
+func (c *DirectControl) PublishControl(data []byte, nodeID string) error {
+	if nodeID == c.node.ID() {
+		return c.handler(data)
+	}
+	c.mu.RLock()
+	if nodeID == "" {
+		for addr, p := range c.peers {
+			if !p.send(meshFrameControl, data) {
+				c.drops.dropped(meshDropReasonQueueFull, addr)
+			}
+		}
+		c.mu.RUnlock()
+		return c.handler(data)
+	}
+	defer c.mu.RUnlock()
+	addr, ok := c.nodes[nodeID]
+	if !ok {
+		c.drops.dropped(meshDropReasonUnknownNode, nodeID)
+		return errDirectControlUnknownNode
+	}
+	if !c.peers[addr].send(meshFrameControl, data) {
+		c.drops.dropped(meshDropReasonQueueFull, addr)
+	}
+	return nil
+}
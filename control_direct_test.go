@@ -0,0 +1,158 @@
+package centrifuge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testControlRegistry struct {
+	mu    sync.Mutex
+	addrs []string
+}
+
+func (r *testControlRegistry) ControlAddresses(_ context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.addrs, nil
+}
+
+func (r *testControlRegistry) set(addrs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addrs = addrs
+}
+
+func TestDirectControl(t *testing.T) {
+	_, err := NewDirectControl(defaultNodeNoHandlers(), DirectControlConfig{BindAddr: "127.0.0.1:0"})
+	require.Error(t, err)
+	_, err = NewDirectControl(defaultNodeNoHandlers(), DirectControlConfig{BindAddr: "127.0.0.1:0", Registry: StaticControlAddresses{}})
+	require.ErrorIs(t, err, errMeshAuthRequired)
+
+	registry := &testControlRegistry{}
+	newNode := func() (*Node, *DirectControl) {
+		node, err := New(Config{LogLevel: LogLevelError})
+		require.NoError(t, err)
+		control, err := NewDirectControl(node, DirectControlConfig{
+			BindAddr:        "127.0.0.1:0",
+			Registry:        registry,
+			RefreshInterval: 50 * time.Millisecond,
+			SharedSecret:    "secret",
+		})
+		require.NoError(t, err)
+		node.SetControlTransport(control)
+		registry.set(append(registry.addrs, control.Addr())...)
+		return node, control
+	}
+	node1, control1 := newNode()
+	node2, _ := newNode()
+	require.NoError(t, node1.Run())
+	defer func() { _ = node1.Shutdown(context.Background()) }()
+	require.NoError(t, node2.Run())
+	defer func() { _ = node2.Shutdown(context.Background()) }()
+
+	// Nodes know each other over control messages sent directly.
+	require.Eventually(t, func() bool {
+		info1, err := node1.Info()
+		require.NoError(t, err)
+		info2, err := node2.Info()
+		require.NoError(t, err)
+		return len(info1.Nodes) == 2 && len(info2.Nodes) == 2
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// Control message to specific node.
+	require.Eventually(t, func() bool {
+		control1.mu.RLock()
+		defer control1.mu.RUnlock()
+		_, ok := control1.nodes[node2.ID()]
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+	received := make(chan []byte, 1)
+	node1.OnNotification(func(event NotificationEvent) {})
+	node2.OnNotification(func(event NotificationEvent) {
+		received <- event.Data
+	})
+	require.NoError(t, node1.Notify("op", []byte("data"), node2.ID()))
+	select {
+	case data := <-received:
+		require.Equal(t, []byte("data"), data)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for notification")
+	}
+	require.ErrorIs(t, control1.PublishControl([]byte("x"), "unknown"), errDirectControlUnknownNode)
+
+	// Node removed from registry is disconnected.
+	registry.set(control1.Addr())
+	require.Eventually(t, func() bool {
+		control1.mu.RLock()
+		defer control1.mu.RUnlock()
+		return len(control1.peers) == 0 && len(control1.nodes) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDirectControl_SharedSecret(t *testing.T) {
+	node, err := New(Config{LogLevel: LogLevelError})
+	require.NoError(t, err)
+	received := make(chan []byte, 1)
+	control, err := NewDirectControl(node, DirectControlConfig{
+		BindAddr:     "127.0.0.1:0",
+		Registry:     StaticControlAddresses{},
+		SharedSecret: "secret",
+	})
+	require.NoError(t, err)
+	require.NoError(t, control.Run(func(data []byte) error {
+		received <- data
+		return nil
+	}))
+	defer func() { _ = control.Close(context.Background()) }()
+
+	other, err := New(Config{LogLevel: LogLevelError})
+	require.NoError(t, err)
+	send := func(secret string) bool {
+		p := newMeshPeer(other, control.Addr(), meshAuth{secret: []byte(secret)}, time.Second, nil)
+		defer p.close()
+		require.True(t, p.send(meshFrameControl, []byte(secret)))
+		go p.run()
+		select {
+		case data := <-received:
+			require.Equal(t, []byte(secret), data)
+			return true
+		case <-time.After(500 * time.Millisecond):
+			return false
+		}
+	}
+	require.False(t, send("wrong"))
+	require.True(t, send("secret"))
+}
+
+func TestDirectControl_Drops(t *testing.T) {
+	node, err := New(Config{LogLevel: LogLevelError})
+	require.NoError(t, err)
+	control, err := NewDirectControl(node, DirectControlConfig{
+		BindAddr:     "127.0.0.1:0",
+		Registry:     StaticControlAddresses{},
+		SharedSecret: "secret",
+	})
+	require.NoError(t, err)
+	defer func() { _ = control.Close(context.Background()) }()
+
+	require.ErrorIs(t, control.PublishControl([]byte("x"), "unknown"), errDirectControlUnknownNode)
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.meshDroppedCount.WithLabelValues("control", meshDropReasonUnknownNode)))
+
+	// Peer which is never connected overflows.
+	p := newMeshPeer(node, "127.0.0.1:1", control.auth, time.Second, nil)
+	control.mu.Lock()
+	control.peers[p.addr] = p
+	control.nodes["node"] = p.addr
+	control.mu.Unlock()
+	for i := 0; i < meshPeerQueueSize; i++ {
+		require.NoError(t, control.PublishControl([]byte("x"), "node"))
+	}
+	require.Equal(t, float64(0), testutil.ToFloat64(node.metrics.meshDroppedCount.WithLabelValues("control", meshDropReasonQueueFull)))
+	require.NoError(t, control.PublishControl([]byte("x"), "node"))
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.meshDroppedCount.WithLabelValues("control", meshDropReasonQueueFull)))
+}
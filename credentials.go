@@ -17,6 +17,12 @@ type Credentials struct {
 	// In some cases having additional info can be an undesired overhead – but
 	// you are simply free to not use this field at all.
 	Info []byte
+	// TokenID is an optional unique ID of token connection authenticated with (i.e. jti
+	// claim of JWT). Allows revoking token with Node.RevokeToken.
+	TokenID string
+	// IssuedAt is an optional Unix time (seconds) when connection token was issued (i.e.
+	// iat claim of JWT). Allows revoking tokens with Node.RevokeUserTokens.
+	IssuedAt int64
 }
 
 // credentialsContextKeyType is special type to safely use context for setting
@@ -0,0 +1,164 @@
+package centrifuge
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+)
+
+// DebugEventType is a type of DebugEvent.
+type DebugEventType uint8
+
+const (
+	// DebugEventSubscribe emitted when client subscribed to channel on this Node.
+	DebugEventSubscribe DebugEventType = iota + 1
+	// DebugEventUnsubscribe emitted when client unsubscribed from channel on this Node.
+	DebugEventUnsubscribe
+	// DebugEventPublish emitted when publication successfully published over this Node.
+	DebugEventPublish
+	// DebugEventPublicationReceived emitted when publication received from Broker.
+	// DebugEvent.NumSubscribers contains number of local subscribers at the moment.
+	DebugEventPublicationReceived
+	// DebugEventDisconnect emitted when client connection closed.
+	DebugEventDisconnect
+	// DebugEventControl emitted when control command received from another Node.
+	DebugEventControl
+)
+
+// String returns a name of DebugEventType.
+func (t DebugEventType) String() string {
+	switch t {
+	case DebugEventSubscribe:
+		return "subscribe"
+	case DebugEventUnsubscribe:
+		return "unsubscribe"
+	case DebugEventPublish:
+		return "publish"
+	case DebugEventPublicationReceived:
+		return "publication_received"
+	case DebugEventDisconnect:
+		return "disconnect"
+	case DebugEventControl:
+		return "control"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes DebugEventType as its name.
+func (t DebugEventType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// DebugEvent describes internal Node event. Only fields relevant for event Type are set.
+type DebugEvent struct {
+	Type DebugEventType `json:"type"`
+	Time time.Time      `json:"time"`
+	// Channel for subscribe, unsubscribe and publication events.
+	Channel string `json:"channel,omitempty"`
+	// Client and User for subscribe, unsubscribe and disconnect events.
+	Client string `json:"client,omitempty"`
+	User   string `json:"user,omitempty"`
+	// Offset and Epoch of publication if history used.
+	Offset uint64 `json:"offset,omitempty"`
+	Epoch  string `json:"epoch,omitempty"`
+	// NumSubscribers is a number of local channel subscribers for DebugEventPublicationReceived.
+	NumSubscribers int `json:"num_subscribers,omitempty"`
+	// Code and Reason of DebugEventDisconnect.
+	Code   uint32 `json:"code,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Command is a control command name and Node is an ID of sender for DebugEventControl.
+	Command string `json:"command,omitempty"`
+	Node    string `json:"node,omitempty"`
+}
+
+// debugEvents keeps debug event listeners. Events are only built when there is at
+// least one listener, so hot paths only pay for an atomic load when nobody listens.
+type debugEvents struct {
+	mu        sync.RWMutex
+	listeners map[chan DebugEvent]struct{}
+	active    atomic.Int32
+}
+
+// SubscribeDebugEvents starts listening to internal events of this Node – subscriptions,
+// publications, disconnects and received control commands. This is useful when
+// diagnosing message loss. Events are sent to returned channel with buffer of bufferSize
+// (256 if zero), events not fitting into buffer are dropped – so a slow listener never
+// blocks Node. Returned function must be called to stop listening, it closes the channel.
+// See also DebugConfig.Node to stream events over DebugHandler.
+func (n *Node) SubscribeDebugEvents(bufferSize int) (<-chan DebugEvent, func()) {
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+	ch := make(chan DebugEvent, bufferSize)
+	d := &n.debugEvents
+	d.mu.Lock()
+	if d.listeners == nil {
+		d.listeners = map[chan DebugEvent]struct{}{}
+	}
+	d.listeners[ch] = struct{}{}
+	d.active.Store(int32(len(d.listeners)))
+	d.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			d.mu.Lock()
+			delete(d.listeners, ch)
+			d.active.Store(int32(len(d.listeners)))
+			close(ch)
+			d.mu.Unlock()
+		})
+	}
+}
+
+func (n *Node) debugEventsEnabled() bool {
+	return n.debugEvents.active.Load() > 0
+}
+
+func (n *Node) emitDebugEvent(event DebugEvent) {
+	event.Time = time.Now()
+	d := &n.debugEvents
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for ch := range d.listeners {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func controlCommandName(cmd *controlpb.Command) string {
+	switch {
+	case cmd.Node != nil:
+		return "node"
+	case cmd.Shutdown != nil:
+		return "shutdown"
+	case cmd.Unsubscribe != nil:
+		return "unsubscribe"
+	case cmd.Subscribe != nil:
+		return "subscribe"
+	case cmd.Disconnect != nil:
+		return "disconnect"
+	case cmd.SurveyRequest != nil:
+		return "survey_request"
+	case cmd.SurveyResponse != nil:
+		return "survey_response"
+	case cmd.Notification != nil:
+		return "notification"
+	case cmd.Refresh != nil:
+		return "refresh"
+	case cmd.Send != nil:
+		return "send"
+	case cmd.ShutdownRequest != nil:
+		return "shutdown_request"
+	case cmd.Revoke != nil:
+		return "revoke"
+	case cmd.HistoryRemove != nil:
+		return "history_remove"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,106 @@
+package centrifuge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+	"github.com/stretchr/testify/require"
+)
+
+func waitDebugEvent(t *testing.T, events <-chan DebugEvent, eventType DebugEventType) DebugEvent {
+	t.Helper()
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for debug event", eventType.String())
+		}
+	}
+}
+
+func TestNode_SubscribeDebugEvents(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	require.False(t, node.debugEventsEnabled())
+
+	events, stop := node.SubscribeDebugEvents(0)
+	require.True(t, node.debugEventsEnabled())
+
+	client := newTestSubscribedClientV2(t, node, "42", "test")
+	event := waitDebugEvent(t, events, DebugEventSubscribe)
+	require.Equal(t, "test", event.Channel)
+	require.Equal(t, client.ID(), event.Client)
+	require.Equal(t, "42", event.User)
+
+	_, err := node.Publish("test", []byte(`{}`))
+	require.NoError(t, err)
+	// Memory Broker delivers publication before Publish returns, so order of
+	// these two events is not defined.
+	received := map[DebugEventType]DebugEvent{}
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received[event.Type] = event
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for publication debug events")
+		}
+	}
+	require.Equal(t, "test", received[DebugEventPublish].Channel)
+	require.Equal(t, 1, received[DebugEventPublicationReceived].NumSubscribers)
+
+	client.Disconnect(DisconnectForceNoReconnect)
+	event = waitDebugEvent(t, events, DebugEventUnsubscribe)
+	require.Equal(t, "test", event.Channel)
+	event = waitDebugEvent(t, events, DebugEventDisconnect)
+	require.Equal(t, DisconnectForceNoReconnect.Code, event.Code)
+
+	stop()
+	stop()
+	require.False(t, node.debugEventsEnabled())
+	_, ok := <-events
+	require.False(t, ok)
+}
+
+func TestControlCommandName(t *testing.T) {
+	require.Equal(t, "node", controlCommandName(&controlpb.Command{Node: &controlpb.Node{}}))
+	require.Equal(t, "refresh", controlCommandName(&controlpb.Command{Refresh: &controlpb.Refresh{}}))
+	require.Equal(t, "unknown", controlCommandName(&controlpb.Command{}))
+}
+
+func TestDebugHandler_Events(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	h, err := NewDebugHandler(DebugConfig{Token: "secret", Node: node})
+	require.NoError(t, err)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/debug/events?channel=test", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = node.Publish("other", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = node.Publish("test", []byte(`{}`))
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	var event map[string]any
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+	require.Contains(t, []string{"publish", "publication_received"}, event["type"])
+	require.Equal(t, "test", event["channel"])
+}
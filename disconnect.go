@@ -1,7 +1,9 @@
 package centrifuge
 
 import (
+	"errors"
 	"fmt"
+	"time"
 )
 
 // Disconnect allows configuring how client will be disconnected from a server.
@@ -33,6 +35,9 @@ type Disconnect struct {
 	Code uint32 `json:"code,omitempty"`
 	// Reason is a short description of disconnect code for humans.
 	Reason string `json:"reason"`
+	// ReconnectAdvice is set to Disconnect passed to Transport.Close when
+	// Config.ClientReconnectAdvice contains advice for Code – see ReconnectAdvice.
+	ReconnectAdvice *ReconnectAdvice `json:"-"`
 }
 
 // String representation.
@@ -46,6 +51,32 @@ func (d Disconnect) Error() string {
 	return d.String()
 }
 
+// Is reports whether target is Disconnect (or *Disconnect) with the same Code, so
+// errors.Is may be used to check for predefined disconnects.
+func (d Disconnect) Is(target error) bool {
+	switch t := target.(type) {
+	case Disconnect:
+		return d.Code == t.Code
+	case *Disconnect:
+		return t != nil && d.Code == t.Code
+	}
+	return false
+}
+
+// asDisconnect extracts Disconnect from err, err may be Disconnect or *Disconnect
+// possibly wrapped.
+func asDisconnect(err error) (Disconnect, bool) {
+	var d Disconnect
+	if errors.As(err, &d) {
+		return d, true
+	}
+	var dPtr *Disconnect
+	if errors.As(err, &dPtr) && dPtr != nil {
+		return *dPtr, true
+	}
+	return Disconnect{}, false
+}
+
 // DisconnectConnectionClosed is a special Disconnect object used when
 // client connection was closed without any advice from a server side.
 // This can be a clean disconnect, or temporary disconnect of the client
@@ -179,3 +210,39 @@ var (
 		Reason: "too many errors",
 	}
 )
+
+// ReconnectAdvice is a reconnect backoff advice for a client. Advice is set to
+// Disconnect.ReconnectAdvice of Disconnect passed to Transport.Close, Disconnect.Reason
+// is never modified. Note, client protocol and WebSocket close frame have no field
+// for reconnect backoff, so built-in transports do not send advice to clients at the
+// moment – advice may be delivered by custom Transport implementations which control
+// their wire format.
+type ReconnectAdvice struct {
+	// MinDelay is a minimal delay before reconnect.
+	MinDelay time.Duration
+	// MaxDelay is a maximal delay before reconnect.
+	MaxDelay time.Duration
+}
+
+func (a ReconnectAdvice) validate(code uint32) error {
+	if (code >= 3500 && code < 4000) || (code >= 4500 && code < 5000) {
+		return fmt.Errorf("code %d is terminal, client does not reconnect", code)
+	}
+	if a.MinDelay < 0 || a.MaxDelay < a.MinDelay {
+		return fmt.Errorf("code %d: MinDelay must be non-negative and not exceed MaxDelay", code)
+	}
+	return nil
+}
+
+func (a ReconnectAdvice) apply(d Disconnect) Disconnect {
+	d.ReconnectAdvice = &a
+	return d
+}
+
+// withReconnectAdvice returns Disconnect with reconnect advice configured for its code.
+func (n *Node) withReconnectAdvice(d Disconnect) Disconnect {
+	if advice, ok := n.reloadableConfig().reconnectAdvice[d.Code]; ok {
+		return advice.apply(d)
+	}
+	return d
+}
@@ -1,7 +1,11 @@
 package centrifuge
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -23,3 +27,57 @@ func TestDisconnect_Error(t *testing.T) {
 	errorText := d.Error()
 	require.Equal(t, "code: 42, reason: reason", errorText)
 }
+
+func TestDisconnect_Is(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", DisconnectForceNoReconnect)
+	require.ErrorIs(t, err, DisconnectForceNoReconnect)
+	require.ErrorIs(t, err, &DisconnectForceNoReconnect)
+	require.NotErrorIs(t, err, DisconnectServerError)
+	require.ErrorIs(t, &Disconnect{Code: DisconnectExpired.Code}, DisconnectExpired)
+	require.NotErrorIs(t, err, ErrorInternal)
+
+	d, ok := asDisconnect(err)
+	require.True(t, ok)
+	require.Equal(t, DisconnectForceNoReconnect, d)
+	d, ok = asDisconnect(fmt.Errorf("wrapped: %w", &DisconnectExpired))
+	require.True(t, ok)
+	require.Equal(t, DisconnectExpired, d)
+	_, ok = asDisconnect(errors.New("boom"))
+	require.False(t, ok)
+}
+
+func TestReconnectAdvice(t *testing.T) {
+	advice := ReconnectAdvice{MinDelay: time.Second, MaxDelay: 30 * time.Second}
+	require.NoError(t, advice.validate(DisconnectShutdown.Code))
+	require.Error(t, advice.validate(DisconnectForceNoReconnect.Code))
+	require.Error(t, ReconnectAdvice{MinDelay: time.Second}.validate(DisconnectShutdown.Code))
+
+	d := advice.apply(DisconnectShutdown)
+	require.Equal(t, DisconnectShutdown.Code, d.Code)
+	require.Equal(t, DisconnectShutdown.Reason, d.Reason)
+	require.Equal(t, &advice, d.ReconnectAdvice)
+	require.Nil(t, DisconnectShutdown.ReconnectAdvice)
+
+	require.Error(t, Config{ClientReconnectAdvice: map[uint32]ReconnectAdvice{3500: advice}}.Validate())
+}
+
+func TestClientCloseReconnectAdvice(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	require.NoError(t, node.ReloadConfig(Config{
+		LogLevel: LogLevelError,
+		ClientReconnectAdvice: map[uint32]ReconnectAdvice{
+			DisconnectShutdown.Code: {MinDelay: time.Second, MaxDelay: 5 * time.Second},
+		},
+	}))
+
+	client := newTestClientV2(t, node, "42")
+	connectClientV2(t, client)
+	require.NoError(t, client.close(DisconnectShutdown))
+	transport := client.transport.(*testTransport)
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	require.Equal(t, DisconnectShutdown.Code, transport.disconnect.Code)
+	require.Equal(t, DisconnectShutdown.Reason, transport.disconnect.Reason)
+	require.Equal(t, &ReconnectAdvice{MinDelay: time.Second, MaxDelay: 5 * time.Second}, transport.disconnect.ReconnectAdvice)
+}
@@ -0,0 +1,146 @@
+package centrifuge
+
+import (
+	"context"
+	"time"
+)
+
+// defaultEphemeralGracePeriod is used when ChannelOptions.EphemeralGracePeriod
+// is not set.
+const defaultEphemeralGracePeriod = 30 * time.Second
+
+const ephemeralCleanupTimeout = 5 * time.Second
+
+// scheduleEphemeralCleanup starts grace period timer for channel of ephemeral
+// namespace which has no subscribers on this Node anymore.
+func (n *Node) scheduleEphemeralCleanup(ch string) {
+	if n.isWildcardChannel(ch) {
+		return
+	}
+	opts, ok := n.ChannelOptions(ch)
+	if !ok || !opts.Ephemeral {
+		return
+	}
+	gracePeriod := opts.EphemeralGracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = defaultEphemeralGracePeriod
+	}
+	n.ephemeralMu.Lock()
+	defer n.ephemeralMu.Unlock()
+	if n.ephemeralTimers == nil {
+		// Node is shut down.
+		return
+	}
+	if t, ok := n.ephemeralTimers[ch]; ok {
+		t.Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(gracePeriod, func() {
+		n.ephemeralMu.Lock()
+		if n.ephemeralTimers[ch] != t {
+			// Timer was cancelled or rescheduled.
+			n.ephemeralMu.Unlock()
+			return
+		}
+		delete(n.ephemeralTimers, ch)
+		n.ephemeralMu.Unlock()
+		n.cleanupEphemeralChannel(ch)
+	})
+	n.ephemeralTimers[ch] = t
+}
+
+// cancelEphemeralCleanup stops grace period timer of channel, called when
+// channel gets a subscriber on this Node.
+func (n *Node) cancelEphemeralCleanup(ch string) {
+	n.ephemeralMu.Lock()
+	defer n.ephemeralMu.Unlock()
+	if t, ok := n.ephemeralTimers[ch]; ok {
+		t.Stop()
+		delete(n.ephemeralTimers, ch)
+	}
+}
+
+func (n *Node) stopEphemeralCleanup() {
+	n.ephemeralMu.Lock()
+	defer n.ephemeralMu.Unlock()
+	for _, t := range n.ephemeralTimers {
+		t.Stop()
+	}
+	n.ephemeralTimers = nil
+}
+
+// cleanupEphemeralChannel removes history, presence and cached state of channel
+// if it has no subscribers on all nodes of a cluster. Cluster survey is made
+// without holding channel subscription lock – lock is only taken to re-check local
+// subscribers and remove history, so subscriptions to other channels hashed to the
+// same lock are not blocked by a survey.
+func (n *Node) cleanupEphemeralChannel(ch string) {
+	if n.hub.NumSubscribers(ch) > 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ephemeralCleanupTimeout)
+	defer cancel()
+	numSubscribers, err := n.clusterChannelSubscribers(ctx, ch)
+	if err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error checking ephemeral channel subscribers", map[string]any{"channel": ch, "error": err.Error()}))
+		return
+	}
+	if numSubscribers > 0 {
+		return
+	}
+	if !n.removeEphemeralHistory(ch) {
+		return
+	}
+	if n.presenceManager != nil {
+		presence, err := n.presenceManager.Presence(ch)
+		if err != nil {
+			n.logger.log(newLogEntry(LogLevelError, "error getting ephemeral channel presence", map[string]any{"channel": ch, "error": err.Error()}))
+		}
+		for clientID, info := range presence {
+			if n.hub.NumSubscribers(ch) > 0 {
+				// Channel got a subscriber during cleanup.
+				return
+			}
+			if err := n.presenceManager.RemovePresence(ch, clientID, info.UserID); err != nil {
+				n.logger.log(newLogEntry(LogLevelError, "error removing ephemeral channel presence", map[string]any{"channel": ch, "error": err.Error()}))
+				break
+			}
+		}
+	}
+	if n.presenceCache != nil {
+		n.presenceCache.invalidate(ch)
+	}
+	if n.LogEnabled(LogLevelDebug) {
+		n.logger.log(newLogEntry(LogLevelDebug, "ephemeral channel state removed", map[string]any{"channel": ch}))
+	}
+}
+
+// removeEphemeralHistory removes channel history under channel subscription lock
+// if channel still has no subscribers on this Node. Returns false if channel got
+// a subscriber.
+func (n *Node) removeEphemeralHistory(ch string) bool {
+	mu := n.subLock(ch)
+	mu.Lock()
+	defer mu.Unlock()
+	if n.hub.NumSubscribers(ch) > 0 {
+		return false
+	}
+	if err := n.broker.RemoveHistory(ch); err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error removing ephemeral channel history", map[string]any{"channel": ch, "error": err.Error()}))
+	}
+	n.handleHistoryRemove(ch)
+	return true
+}
+
+// clusterChannelSubscribers returns number of channel subscribers on all nodes.
+func (n *Node) clusterChannelSubscribers(ctx context.Context, ch string) (int, error) {
+	byNode, err := n.ChannelSubscribersByNode(ctx, ch)
+	if err != nil {
+		return 0, err
+	}
+	var numSubscribers int
+	for _, num := range byNode {
+		numSubscribers += num
+	}
+	return numSubscribers, nil
+}
@@ -0,0 +1,81 @@
+package centrifuge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEphemeralChannel_Cleanup(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "room", ChannelOptions: ChannelOptions{
+			Presence:             true,
+			HistorySize:          10,
+			HistoryTTL:           time.Minute,
+			Ephemeral:            true,
+			EphemeralGracePeriod: 100 * time.Millisecond,
+		}},
+		{Name: "chat", ChannelOptions: ChannelOptions{HistorySize: 10, HistoryTTL: time.Minute}},
+	})
+
+	for _, ch := range []string{"room:1", "chat:1"} {
+		client := newTestSubscribedClientV2(t, node, "42", ch)
+		_, err := node.Publish(ch, []byte(`{}`))
+		require.NoError(t, err)
+		// Presence entry left by connection on a dead node.
+		require.NoError(t, node.addPresence(ch, "stale", &ClientInfo{ClientID: "stale", UserID: "43"}))
+		client.Unsubscribe(ch)
+	}
+
+	require.Eventually(t, func() bool {
+		result, err := node.History("room:1", WithLimit(NoLimit))
+		require.NoError(t, err)
+		presence, err := node.Presence("room:1")
+		require.NoError(t, err)
+		return len(result.Publications) == 0 && len(presence.Presence) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// State of non-ephemeral channel kept.
+	result, err := node.History("chat:1", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 1)
+	presence, err := node.Presence("chat:1")
+	require.NoError(t, err)
+	require.Len(t, presence.Presence, 1)
+}
+
+func TestEphemeralChannel_ResubscribeWithinGracePeriod(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "room", ChannelOptions: ChannelOptions{
+			HistorySize:          10,
+			HistoryTTL:           time.Minute,
+			Ephemeral:            true,
+			EphemeralGracePeriod: time.Hour,
+		}},
+	})
+
+	client := newTestSubscribedClientV2(t, node, "42", "room:1")
+	_, err := node.Publish("room:1", []byte(`{}`))
+	require.NoError(t, err)
+	client.Unsubscribe("room:1")
+
+	require.Eventually(t, func() bool {
+		node.ephemeralMu.Lock()
+		defer node.ephemeralMu.Unlock()
+		_, ok := node.ephemeralTimers["room:1"]
+		return ok
+	}, 5*time.Second, 50*time.Millisecond)
+
+	subscribeClientV2(t, client, "room:1")
+	node.ephemeralMu.Lock()
+	_, ok := node.ephemeralTimers["room:1"]
+	node.ephemeralMu.Unlock()
+	require.False(t, ok)
+
+	// Subscriber appeared before cleanup started – state kept.
+	node.cleanupEphemeralChannel("room:1")
+	result, err := node.History("room:1", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 1)
+}
@@ -30,6 +30,21 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%d: %s", e.Code, e.Message)
 }
 
+// Is reports whether target is *Error with the same Code. This allows using
+// errors.Is with well-known errors defined below even if error was re-created
+// (for example, decoded from a client protocol reply) or wrapped:
+//
+//	if errors.Is(err, centrifuge.ErrorLimitExceeded) {
+//		...
+//	}
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	return e.Code == t.Code
+}
+
 // Here we define well-known errors that can be used in client protocol replies.
 var (
 	// ErrorInternal means server error, if returned this is a signal
@@ -109,4 +124,16 @@ var (
 		Code:    112,
 		Message: "unrecoverable position",
 	}
+	// ErrorPublicationTooLarge means that publication data exceeds size limit
+	// configured over Config.PublicationMaxSize or ChannelOptions.PublicationMaxSize.
+	ErrorPublicationTooLarge = &Error{
+		Code:    113,
+		Message: "publication too large",
+	}
+	// ErrorInvalidPayload means that publication data was rejected by
+	// ChannelOptions.ValidatePayload.
+	ErrorInvalidPayload = &Error{
+		Code:    114,
+		Message: "invalid payload",
+	}
 )
@@ -55,6 +55,12 @@ type ConnectReply struct {
 	// Centrifuge Client message writer will collect from the client's queue before sending
 	// to the connection. By default, it's 16. Use -1 to disable the limit.
 	MaxMessagesInFrame int
+	// MaxFrameSize is the maximum total size in bytes of messages which Centrifuge Client
+	// message writer collects into a single frame. A message larger than MaxFrameSize is
+	// still sent, but in a separate frame. Together with MaxMessagesInFrame this allows
+	// tuning write coalescing for different transports and networks – for example, smaller
+	// frames for mobile clients. By default, frame size is only limited by MaxMessagesInFrame.
+	MaxFrameSize int
 	// WriteDelay is a time Centrifuge will try to collect messages inside message writer loop
 	// before sending them towards this connection. Enabling WriteDelay may reduce CPU usage of
 	// both server and client in case of high message rate inside individual connections. The
@@ -102,6 +108,11 @@ type RefreshReply struct {
 	// Info allows modifying connection information,
 	// zero value means no modification of current connection Info.
 	Info []byte
+	// TokenID and IssuedAt describe a new token of connection, see Credentials.TokenID
+	// and Credentials.IssuedAt. Zero values mean no modification of current connection
+	// token credentials.
+	TokenID  string
+	IssuedAt int64
 }
 
 // RefreshCallback should be called as soon as handler decides what to do
@@ -419,6 +430,49 @@ type NodeInfoSendReply struct {
 // arbitrary data to it. See NodeInfoSendReply.
 type NodeInfoSendHandler func() NodeInfoSendReply
 
+// NodeJoinEvent contains information about a node which joined a cluster.
+type NodeJoinEvent struct {
+	Node NodeInfo
+}
+
+// NodeJoinHandler called when a new node discovered in a cluster. It's not called
+// for the current Node.
+type NodeJoinHandler func(NodeJoinEvent)
+
+// NodeLeaveEvent contains information about a node which left a cluster.
+type NodeLeaveEvent struct {
+	Node NodeInfo
+	// Expired is true when node was removed since it has not sent control
+	// node frames for a long time, false when node sent shutdown command.
+	Expired bool
+}
+
+// NodeLeaveHandler called when a node left a cluster.
+type NodeLeaveHandler func(NodeLeaveEvent)
+
+// ShutdownRequestEvent contains details of remote shutdown request.
+type ShutdownRequestEvent struct {
+	// FromNodeID is an ID of node sent request.
+	FromNodeID string
+	// Reason passed to Node.RequestNodeShutdown.
+	Reason string
+}
+
+// ShutdownRequestHandler called when shutdown of the current Node requested with
+// Node.RequestNodeShutdown. Handler is called in a separate goroutine, usually it
+// should stop accepting new connections and call Node.Shutdown.
+type ShutdownRequestHandler func(ShutdownRequestEvent)
+
+// StartHandler called by Node.Run after Node started and connected to Broker, but
+// before Node.Run returns. Returning error from StartHandler aborts Node.Run with
+// this error.
+type StartHandler func(ctx context.Context) error
+
+// ShutdownHandler called by Node.Shutdown before clients are disconnected and
+// Broker and PresenceManager are closed. Context passed is the one provided to
+// Node.Shutdown, so handler should respect its deadline.
+type ShutdownHandler func(ctx context.Context) error
+
 // TransportWriteEvent called just before sending data into the client connection. The
 // event is triggered from inside each client's message queue consumer – so it should
 // not directly affect Hub broadcast latencies.
@@ -456,6 +510,19 @@ type CommandReadEvent struct {
 // Also, carefully read docs for CommandReadEvent to avoid possible bugs.
 type CommandReadHandler func(*Client, CommandReadEvent) error
 
+// CommandEvent contains protocol.Command passed through CommandMiddleware chain.
+// Same pooling considerations as for CommandReadEvent apply.
+type CommandEvent struct {
+	// Command to handle.
+	Command *protocol.Command
+	// CommandSize is a size of command in bytes in its protocol representation.
+	CommandSize int
+	// FrameType of command.
+	FrameType protocol.FrameType
+
+	started time.Time
+}
+
 // CommandProcessedEvent contains protocol.Command processed by Client. Command and
 // Reply types and their fields in the event MAY BE POOLED by Centrifuge, so code
 // which wants to use them AFTER CommandProcessedHandler handler returns MUST MAKE A
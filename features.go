@@ -0,0 +1,29 @@
+package centrifuge
+
+// ServerFeature is a bitmap of client protocol features supported by server.
+type ServerFeature uint64
+
+const (
+	// ServerFeatureDelta means server supports delta compression of publications
+	// in subscriptions – see SubscribeOptions.AllowedDeltaTypes.
+	ServerFeatureDelta ServerFeature = 1 << iota
+	// ServerFeatureRecovery means server supports recovery of missed publications
+	// in positioned and recoverable subscriptions.
+	ServerFeatureRecovery
+	// ServerFeatureBatching means server accepts several commands in one frame
+	// and may send several replies and pushes in one frame.
+	ServerFeatureBatching
+)
+
+// Has reports whether all features of f2 are set in f.
+func (f ServerFeature) Has(f2 ServerFeature) bool {
+	return f&f2 == f2
+}
+
+// ServerFeatures returns a bitmap of client protocol features supported by Node.
+// Client protocol connect result has no dedicated field for features, so it's not
+// sent automatically – applications which need clients to adapt behavior may pass
+// it to clients over ConnectReply.Data.
+func (n *Node) ServerFeatures() ServerFeature {
+	return ServerFeatureDelta | ServerFeatureRecovery | ServerFeatureBatching
+}
@@ -0,0 +1,155 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// FirehosePublication is a publication passed to FirehoseSink.
+type FirehosePublication struct {
+	// Channel publication was published into.
+	Channel string
+	// Data of publication.
+	Data []byte
+	// Offset in channel history stream, zero if history is not used.
+	Offset uint64
+	// Epoch of channel history stream, empty if history is not used.
+	Epoch string
+	// Tags of publication.
+	Tags map[string]string
+	// Time publication was made by this node.
+	Time time.Time
+}
+
+// FirehoseSink receives all publications made over Node.Publish on this node.
+// This allows analytics or archival pipelines to tap all traffic without
+// subscribing to every channel. Each Node has its own firehose, so sink of every
+// node should be registered to receive traffic of the whole cluster.
+type FirehoseSink interface {
+	// ConsumePublications is called from a single goroutine with a batch of
+	// publications in the order they were made. Returned error is logged, batch
+	// is not retried – sink should handle retries itself if required. Slice
+	// is reused after call returns, so it must not be retained by Sink.
+	ConsumePublications(ctx context.Context, publications []FirehosePublication) error
+}
+
+// FirehoseConfig is a config for firehose.
+type FirehoseConfig struct {
+	// Sink to pass publications to. Must be set.
+	Sink FirehoseSink
+	// QueueSize is a max number of publications buffered for Sink. Zero value
+	// means 4096.
+	QueueSize int
+	// BatchSize is a max number of publications in one ConsumePublications call.
+	// Zero value means 256.
+	BatchSize int
+	// DropOnOverflow changes behaviour when queue is full. By default, Publish
+	// blocks until Sink catches up – so slow Sink slows down publishers. With
+	// DropOnOverflow publications not fitting into queue are not passed to Sink
+	// and publishers are never blocked.
+	DropOnOverflow bool
+}
+
+type firehose struct {
+	config  FirehoseConfig
+	queue   chan FirehosePublication
+	done    chan struct{}
+	running atomic.Bool
+	dropped atomic.Int64
+}
+
+// SetFirehose registers FirehoseSink receiving all publications made over this Node.
+// Publications are passed to Sink asynchronously after successful publish to Broker.
+// This should be done before Node.Run called.
+func (n *Node) SetFirehose(config FirehoseConfig) error {
+	if config.Sink == nil {
+		return errors.New("firehose: sink required")
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = 4096
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = 256
+	}
+	n.firehose = &firehose{
+		config: config,
+		queue:  make(chan FirehosePublication, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+	return nil
+}
+
+func (n *Node) sendToFirehose(ch string, data []byte, opts PublishOptions, sp StreamPosition) {
+	pub := FirehosePublication{
+		Channel: ch,
+		Data:    data,
+		Offset:  sp.Offset,
+		Epoch:   sp.Epoch,
+		Tags:    opts.Tags,
+		Time:    time.Now(),
+	}
+	if n.firehose.config.DropOnOverflow {
+		select {
+		case n.firehose.queue <- pub:
+		default:
+			n.firehose.dropped.Add(1)
+		}
+		return
+	}
+	select {
+	case n.firehose.queue <- pub:
+	case <-n.shutdownCh:
+	}
+}
+
+func (n *Node) runFirehose() {
+	defer close(n.firehose.done)
+	batch := make([]FirehosePublication, 0, n.firehose.config.BatchSize)
+	for {
+		select {
+		case <-n.shutdownCh:
+			// Pass publications left in queue.
+			for {
+				select {
+				case pub := <-n.firehose.queue:
+					batch = append(batch, pub)
+					if len(batch) == n.firehose.config.BatchSize {
+						n.consumeFirehoseBatch(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						n.consumeFirehoseBatch(batch)
+					}
+					return
+				}
+			}
+		case pub := <-n.firehose.queue:
+			batch = append(batch, pub)
+		loop:
+			for len(batch) < n.firehose.config.BatchSize {
+				select {
+				case pub := <-n.firehose.queue:
+					batch = append(batch, pub)
+				default:
+					break loop
+				}
+			}
+			n.consumeFirehoseBatch(batch)
+			clear(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (n *Node) consumeFirehoseBatch(batch []FirehosePublication) {
+	if dropped := n.firehose.dropped.Swap(0); dropped > 0 {
+		n.logger.log(newLogEntry(LogLevelWarn, "firehose queue overflow, publications dropped", map[string]any{"dropped": dropped}))
+	}
+	err := n.firehose.config.Sink.ConsumePublications(context.Background(), batch)
+	if err != nil {
+		n.logger.log(newLogEntry(LogLevelError, "error consuming firehose publications", map[string]any{"error": err.Error(), "num": len(batch)}))
+	}
+}
@@ -0,0 +1,96 @@
+package centrifuge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testFirehoseSink struct {
+	mu           sync.Mutex
+	publications []FirehosePublication
+	block        chan struct{}
+}
+
+func (s *testFirehoseSink) ConsumePublications(_ context.Context, publications []FirehosePublication) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publications = append(s.publications, publications...)
+	return nil
+}
+
+func (s *testFirehoseSink) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.publications)
+}
+
+func newTestFirehoseNode(t *testing.T) *Node {
+	node, err := New(Config{
+		LogLevel:   LogLevelTrace,
+		LogHandler: func(entry LogEntry) {},
+	})
+	require.NoError(t, err)
+	return node
+}
+
+func TestNode_SetFirehose(t *testing.T) {
+	node := newTestFirehoseNode(t)
+	require.Error(t, node.SetFirehose(FirehoseConfig{}))
+
+	sink := &testFirehoseSink{}
+	require.NoError(t, node.SetFirehose(FirehoseConfig{Sink: sink}))
+	require.NoError(t, node.Run())
+
+	_, err := node.Publish("test", []byte(`{}`), WithTags(map[string]string{"k": "v"}))
+	require.NoError(t, err)
+	_, err = node.Publish("test", []byte(`{}`), WithHistory(10, time.Minute))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return sink.len() == 2 }, 5*time.Second, 10*time.Millisecond)
+	sink.mu.Lock()
+	require.Equal(t, "test", sink.publications[0].Channel)
+	require.Equal(t, map[string]string{"k": "v"}, sink.publications[0].Tags)
+	require.Zero(t, sink.publications[0].Offset)
+	require.Equal(t, uint64(1), sink.publications[1].Offset)
+	require.NotEmpty(t, sink.publications[1].Epoch)
+	sink.mu.Unlock()
+	require.NoError(t, node.Shutdown(context.Background()))
+}
+
+func TestNode_SetFirehose_DropOnOverflow(t *testing.T) {
+	node := newTestFirehoseNode(t)
+	sink := &testFirehoseSink{block: make(chan struct{})}
+	require.NoError(t, node.SetFirehose(FirehoseConfig{Sink: sink, QueueSize: 1, BatchSize: 1, DropOnOverflow: true}))
+	require.NoError(t, node.Run())
+
+	for i := 0; i < 10; i++ {
+		_, err := node.Publish("test", []byte(`{}`))
+		require.NoError(t, err)
+	}
+	close(sink.block)
+	// At most one publication is consumed by blocked sink and one is in queue.
+	require.NoError(t, node.Shutdown(context.Background()))
+	require.LessOrEqual(t, sink.len(), 2)
+	require.Greater(t, sink.len(), 0)
+}
+
+func TestNode_SetFirehose_Shutdown(t *testing.T) {
+	node := newTestFirehoseNode(t)
+	sink := &testFirehoseSink{}
+	require.NoError(t, node.SetFirehose(FirehoseConfig{Sink: sink, BatchSize: 2}))
+	require.NoError(t, node.Run())
+	for i := 0; i < 5; i++ {
+		_, err := node.Publish("test", []byte(`{}`))
+		require.NoError(t, err)
+	}
+	// All queued publications passed to sink upon shutdown.
+	require.NoError(t, node.Shutdown(context.Background()))
+	require.Equal(t, 5, sink.len())
+}
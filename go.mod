@@ -7,6 +7,7 @@ require (
 	github.com/Yiling-J/theine-go v0.3.2
 	github.com/centrifugal/protocol v0.13.3
 	github.com/google/uuid v1.6.0
+	github.com/planetscale/vtprotobuf v0.6.0
 	github.com/prometheus/client_golang v1.19.1
 	github.com/redis/rueidis v1.0.39
 	github.com/segmentio/encoding v0.4.0
@@ -24,7 +25,6 @@ require (
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/planetscale/vtprotobuf v0.6.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/prometheus/client_model v0.5.0 // indirect
 	github.com/prometheus/common v0.48.0 // indirect
@@ -0,0 +1,185 @@
+package centrifuge
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"sync/atomic"
+)
+
+// DebugConfig represents config for DebugHandler.
+type DebugConfig struct {
+	// Prefix is a URL path prefix DebugHandler is mounted on. Zero value means "/debug".
+	Prefix string
+	// Token is a secret which must be passed in Authorization header as "Bearer <Token>"
+	// to access debug endpoints. If empty then Authorize func must be set.
+	Token string
+	// Tokens are additional valid tokens. Together with Token this allows rotating
+	// secret without dropping requests: configure old and new tokens, move clients
+	// to the new one and then remove the old one – see also DebugHandler.SetTokens.
+	Tokens []string
+	// Authorize allows setting custom authorization logic. If set then Token is not used.
+	Authorize func(r *http.Request) bool
+	// Node if set enables streaming of Node debug events under <Prefix>/events as
+	// newline-delimited JSON – see Node.SubscribeDebugEvents. Optional "channel"
+	// URL query parameter limits stream to events of one channel. Also enables
+	// client state snapshots under <Prefix>/clients/<client ID> – see Node.ClientState.
+	Node *Node
+}
+
+// DebugHandler exposes pprof profiles under <Prefix>/pprof/, expvar variables
+// under <Prefix>/vars and Node debug events under <Prefix>/events (if
+// DebugConfig.Node set). All requests must be authorized – see DebugConfig. Do
+// not expose DebugHandler to the public internet even with authorization enabled.
+type DebugHandler struct {
+	config DebugConfig
+	mux    *http.ServeMux
+	tokens atomic.Pointer[[]string]
+}
+
+// NewDebugHandler creates new DebugHandler. Returns an error if none of
+// DebugConfig.Token, DebugConfig.Tokens or DebugConfig.Authorize set since debug
+// endpoints must never be left unprotected.
+func NewDebugHandler(config DebugConfig) (*DebugHandler, error) {
+	tokens := debugTokens(config.Token, config.Tokens)
+	if len(tokens) == 0 && config.Authorize == nil {
+		return nil, errors.New("debug handler requires Token or Authorize")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "/debug"
+	}
+	config.Prefix = strings.TrimSuffix(config.Prefix, "/")
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.Prefix+"/pprof/", func(w http.ResponseWriter, r *http.Request) {
+		// pprof.Index resolves named profiles only under /debug/pprof/, so resolve
+		// profile name here to support custom prefix.
+		name := strings.TrimPrefix(r.URL.Path, config.Prefix+"/pprof/")
+		if name != "" {
+			pprof.Handler(name).ServeHTTP(w, r)
+			return
+		}
+		pprof.Index(w, r)
+	})
+	mux.HandleFunc(config.Prefix+"/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(config.Prefix+"/pprof/profile", pprof.Profile)
+	mux.HandleFunc(config.Prefix+"/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(config.Prefix+"/pprof/trace", pprof.Trace)
+	mux.Handle(config.Prefix+"/vars", expvar.Handler())
+	if config.Node != nil {
+		mux.HandleFunc(config.Prefix+"/events", debugEventsHandler(config.Node))
+		mux.HandleFunc(config.Prefix+"/clients/", debugClientStateHandler(config.Node, config.Prefix+"/clients/"))
+	}
+	h := &DebugHandler{
+		config: config,
+		mux:    mux,
+	}
+	h.tokens.Store(&tokens)
+	return h, nil
+}
+
+func debugTokens(token string, extra []string) []string {
+	tokens := make([]string, 0, len(extra)+1)
+	if token != "" {
+		tokens = append(tokens, token)
+	}
+	for _, t := range extra {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// SetTokens replaces a set of valid tokens at runtime, so tokens can be rotated
+// without restarting the process. Returns an error if tokens contain no non-empty
+// token while DebugConfig.Authorize not set.
+func (h *DebugHandler) SetTokens(tokens ...string) error {
+	tokens = debugTokens("", tokens)
+	if len(tokens) == 0 && h.config.Authorize == nil {
+		return errors.New("at least one token required")
+	}
+	h.tokens.Store(&tokens)
+	return nil
+}
+
+func (h *DebugHandler) authorized(r *http.Request) bool {
+	if h.config.Authorize != nil {
+		return h.config.Authorize(r)
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	// Check all tokens without early exit to not reveal which one matched.
+	var match int
+	for _, t := range *h.tokens.Load() {
+		match |= subtle.ConstantTimeCompare([]byte(token), []byte(t))
+	}
+	return match == 1
+}
+
+func (h *DebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func debugEventsHandler(node *Node) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		channel := r.URL.Query().Get("channel")
+		events, stop := node.SubscribeDebugEvents(0)
+		defer stop()
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		encoder := json.NewEncoder(w)
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-node.NotifyShutdown():
+				return
+			case event := <-events:
+				if channel != "" && event.Channel != channel {
+					continue
+				}
+				if err := encoder.Encode(event); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func debugClientStateHandler(node *Node, prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		clientID := strings.TrimPrefix(r.URL.Path, prefix)
+		if clientID == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		state, err := node.ClientState(r.Context(), clientID)
+		if err != nil {
+			if errors.Is(err, ErrClientNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	}
+}
@@ -0,0 +1,69 @@
+package centrifuge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	h, err := NewDebugHandler(DebugConfig{Token: "secret"})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/debug/vars", "/debug/pprof/", "/debug/pprof/goroutine"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusUnauthorized, rec.Code, path)
+
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		require.NotZero(t, rec.Body.Len(), path)
+	}
+}
+
+func TestDebugHandler_Authorize(t *testing.T) {
+	h, err := NewDebugHandler(DebugConfig{Prefix: "/internal/debug/", Authorize: func(r *http.Request) bool {
+		return r.Header.Get("X-Admin") == "1"
+	}})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/internal/debug/vars", nil)
+	req.Header.Set("X-Admin", "1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNewDebugHandler_NoAuth(t *testing.T) {
+	_, err := NewDebugHandler(DebugConfig{})
+	require.Error(t, err)
+}
+
+func TestDebugHandler_TokenRotation(t *testing.T) {
+	h, err := NewDebugHandler(DebugConfig{Token: "old", Tokens: []string{"new"}})
+	require.NoError(t, err)
+
+	check := func(token string, code int) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, code, rec.Code, token)
+	}
+	check("old", http.StatusOK)
+	check("new", http.StatusOK)
+	check("other", http.StatusUnauthorized)
+
+	require.NoError(t, h.SetTokens("new"))
+	check("old", http.StatusUnauthorized)
+	check("new", http.StatusOK)
+
+	require.Error(t, h.SetTokens(""))
+	check("new", http.StatusOK)
+}
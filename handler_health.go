@@ -0,0 +1,111 @@
+package centrifuge
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// HealthChecker may be implemented by Broker or PresenceManager to report its health
+// to HealthHandler. RedisBroker and RedisPresenceManager implement it by pinging Redis
+// shards. Broker and PresenceManager which do not implement HealthChecker are always
+// considered healthy.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthConfig represents config for HealthHandler.
+type HealthConfig struct {
+	// CheckTimeout is a timeout for readiness checks. Zero value means 5 seconds.
+	CheckTimeout time.Duration
+}
+
+// HealthHandler serves liveness and readiness probes. Requests with URL path ending
+// with "/readyz" are processed as readiness probe: Node must not be shutting down,
+// Broker and PresenceManager must be reachable. All other requests are processed as
+// liveness probe which only tells that process is alive. So the same HealthHandler
+// may be mounted on both "/healthz" and "/readyz" paths. Response is a JSON object
+// with status and checks, status code 503 returned when not ready.
+type HealthHandler struct {
+	node   *Node
+	config HealthConfig
+}
+
+// NewHealthHandler creates new HealthHandler.
+func NewHealthHandler(node *Node, config HealthConfig) *HealthHandler {
+	if config.CheckTimeout == 0 {
+		config.CheckTimeout = 5 * time.Second
+	}
+	return &HealthHandler{
+		node:   node,
+		config: config,
+	}
+}
+
+type healthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type healthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+const (
+	healthStatusOK    = "ok"
+	healthStatusError = "error"
+)
+
+func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := healthResponse{Status: healthStatusOK}
+	if strings.HasSuffix(r.URL.Path, "/readyz") {
+		resp = h.checkReadiness(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *HealthHandler) checkReadiness(ctx context.Context) healthResponse {
+	ctx, cancel := context.WithTimeout(ctx, h.config.CheckTimeout)
+	defer cancel()
+
+	resp := healthResponse{Status: healthStatusOK, Checks: map[string]healthCheckResult{}}
+
+	h.node.mu.RLock()
+	shutdown := h.node.shutdown
+	h.node.mu.RUnlock()
+	if shutdown {
+		resp.Checks["node"] = healthCheckResult{Status: healthStatusError, Error: "shutting down"}
+	} else {
+		resp.Checks["node"] = healthCheckResult{Status: healthStatusOK}
+	}
+	resp.Checks["broker"] = checkHealth(ctx, h.node.broker)
+	if h.node.presenceManager != nil {
+		resp.Checks["presence_manager"] = checkHealth(ctx, h.node.presenceManager)
+	}
+	for _, check := range resp.Checks {
+		if check.Status != healthStatusOK {
+			resp.Status = healthStatusError
+			break
+		}
+	}
+	return resp
+}
+
+func checkHealth(ctx context.Context, component any) healthCheckResult {
+	checker, ok := component.(HealthChecker)
+	if !ok {
+		return healthCheckResult{Status: healthStatusOK}
+	}
+	if err := checker.CheckHealth(ctx); err != nil {
+		return healthCheckResult{Status: healthStatusError, Error: err.Error()}
+	}
+	return healthCheckResult{Status: healthStatusOK}
+}
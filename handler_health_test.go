@@ -0,0 +1,66 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+type unhealthyTestBroker struct {
+	*TestBroker
+}
+
+func (b *unhealthyTestBroker) CheckHealth(_ context.Context) error {
+	return errors.New("boom")
+}
+
+func serveHealth(t *testing.T, h *HealthHandler, path string) (int, healthResponse) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var resp healthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestHealthHandler(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	h := NewHealthHandler(node, HealthConfig{})
+
+	code, resp := serveHealth(t, h, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, healthStatusOK, resp.Status)
+	require.Empty(t, resp.Checks)
+
+	code, resp = serveHealth(t, h, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, healthStatusOK, resp.Status)
+	require.Equal(t, healthStatusOK, resp.Checks["broker"].Status)
+	require.Equal(t, healthStatusOK, resp.Checks["presence_manager"].Status)
+
+	require.NoError(t, node.Shutdown(context.Background()))
+	code, resp = serveHealth(t, h, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, healthStatusError, resp.Status)
+	require.Equal(t, "shutting down", resp.Checks["node"].Error)
+
+	// Liveness still OK.
+	code, _ = serveHealth(t, h, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestHealthHandler_BrokerUnhealthy(t *testing.T) {
+	node := nodeWithBroker(&unhealthyTestBroker{NewTestBroker()})
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	h := NewHealthHandler(node, HealthConfig{})
+
+	code, resp := serveHealth(t, h, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, healthStatusError, resp.Status)
+	require.Equal(t, "boom", resp.Checks["broker"].Error)
+	require.Equal(t, healthStatusOK, resp.Checks["node"].Status)
+}
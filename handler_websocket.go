@@ -1,6 +1,8 @@
 package centrifuge
 
 import (
+	"compress/flate"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -70,9 +72,54 @@ type WebsocketConfig struct {
 	// works well for your use case, and you want to enable it in production.
 	CompressionPreparedMessageCacheSize int64
 
+	// ConnectLimiter if set is checked before WebSocket upgrade. Connection attempts
+	// from banned IP addresses or exceeding configured rate are rejected with 429 status
+	// code. See IPConnectLimiter.
+	ConnectLimiter *IPConnectLimiter
+
 	PingPongConfig
 }
 
+// Validate checks WebsocketConfig for invalid and conflicting values. It returns
+// an error listing all problems found, or nil if config is valid. NewWebsocketHandler
+// does not validate config, so call Validate explicitly to catch configuration
+// mistakes on start.
+func (c WebsocketConfig) Validate() error {
+	errs := []error{c.PingPongConfig.Validate()}
+	if c.ReadBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("ReadBufferSize must not be negative, got %d", c.ReadBufferSize))
+	}
+	if c.WriteBufferSize < 0 {
+		errs = append(errs, fmt.Errorf("WriteBufferSize must not be negative, got %d", c.WriteBufferSize))
+	}
+	if c.UseWriteBufferPool && c.WriteBufferSize > 0 {
+		errs = append(errs, errors.New("WriteBufferSize is ignored when UseWriteBufferPool is enabled, set only one of them"))
+	}
+	if c.MessageSizeLimit < 0 {
+		errs = append(errs, fmt.Errorf("MessageSizeLimit must not be negative, got %d", c.MessageSizeLimit))
+	}
+	if c.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("WriteTimeout must not be negative, got %s", c.WriteTimeout))
+	}
+	writeTimeout := c.WriteTimeout
+	if writeTimeout == 0 {
+		writeTimeout = time.Second
+	}
+	if pingInterval, _ := getPingPongPeriodValues(c.PingPongConfig); pingInterval > 0 && writeTimeout >= pingInterval {
+		errs = append(errs, fmt.Errorf("WriteTimeout (%s) must be less than PingInterval (%s)", writeTimeout, pingInterval))
+	}
+	if c.CompressionLevel < flate.HuffmanOnly || c.CompressionLevel > flate.BestCompression {
+		errs = append(errs, fmt.Errorf("CompressionLevel must be in range [%d, %d], got %d", flate.HuffmanOnly, flate.BestCompression, c.CompressionLevel))
+	}
+	if c.CompressionMinSize < 0 {
+		errs = append(errs, fmt.Errorf("CompressionMinSize must not be negative, got %d", c.CompressionMinSize))
+	}
+	if !c.Compression && c.CompressionPreparedMessageCacheSize > 0 {
+		errs = append(errs, errors.New("CompressionPreparedMessageCacheSize requires Compression to be enabled"))
+	}
+	return errors.Join(errs...)
+}
+
 // WebsocketHandler handles WebSocket client connections. WebSocket protocol
 // is a bidirectional connection between a client and a server for low-latency
 // communication.
@@ -117,6 +164,11 @@ func NewWebsocketHandler(node *Node, config WebsocketConfig) *WebsocketHandler {
 func (s *WebsocketHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	s.node.metrics.incTransportConnect(transportWebsocket)
 
+	if s.config.ConnectLimiter != nil && !s.config.ConnectLimiter.allowRequest(rw, r) {
+		s.node.logger.log(newLogEntry(LogLevelDebug, "websocket connection rejected by limiter", map[string]any{"remote_addr": r.RemoteAddr}))
+		return
+	}
+
 	var protoType = ProtocolTypeJSON
 	var useFramePingPong bool
 
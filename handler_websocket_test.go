@@ -1233,3 +1233,46 @@ func BenchmarkWsCommandReplyV2MultipleParallel(b *testing.B) {
 		})
 	}
 }
+
+func TestWebsocketConfig_Validate(t *testing.T) {
+	require.NoError(t, WebsocketConfig{}.Validate())
+	err := WebsocketConfig{
+		WriteTimeout:                        30 * time.Second,
+		CompressionLevel:                    10,
+		CompressionPreparedMessageCacheSize: 100,
+	}.Validate()
+	require.Error(t, err)
+	msg := err.Error()
+	require.Contains(t, msg, "WriteTimeout (30s) must be less than PingInterval (25s)")
+	require.Contains(t, msg, "CompressionLevel must be in range")
+	require.Contains(t, msg, "CompressionPreparedMessageCacheSize requires Compression")
+}
+
+func TestWebsocketHandlerConnectLimiter(t *testing.T) {
+	n, _ := New(Config{})
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	limiter := NewIPConnectLimiter(IPConnectLimiterConfig{})
+	mux := http.NewServeMux()
+	mux.Handle("/connection/websocket", NewWebsocketHandler(n, WebsocketConfig{
+		ConnectLimiter: limiter,
+	}))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	url := "ws" + server.URL[4:]
+	dialer := &websocket.Dialer{}
+
+	limiter.Ban("127.0.0.1", 0)
+	_, resp, _, err := dialer.Dial(url+"/connection/websocket", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	_ = resp.Body.Close()
+
+	limiter.Unban("127.0.0.1")
+	conn, resp, _, err := dialer.Dial(url+"/connection/websocket", nil)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	_ = conn.Close()
+}
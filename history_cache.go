@@ -0,0 +1,128 @@
+package centrifuge
+
+import (
+	"sync"
+	"time"
+)
+
+// historyCache keeps the tail of recent publications for channels the Node is
+// subscribed to. It's filled with publications coming from Broker, so recovery
+// requests for small gaps (which is usual after mass reconnect) may be served
+// without Broker round trip. Publications in cache are always contiguous by
+// offset: on a gap cache for channel is reset. Like history streams in Broker,
+// channel cache expires after TTL since the last added publication.
+type historyCache struct {
+	size     int
+	mu       sync.RWMutex
+	channels map[string]*historyCacheChannel
+}
+
+type historyCacheChannel struct {
+	mu    sync.RWMutex
+	epoch string
+	// expireAt is Unix nanoseconds time when cached publications expire.
+	expireAt int64
+	// pubs is a ring buffer of publications with contiguous offsets.
+	pubs  []*Publication
+	start int
+	count int
+}
+
+func newHistoryCache(size int) *historyCache {
+	return &historyCache{
+		size:     size,
+		channels: map[string]*historyCacheChannel{},
+	}
+}
+
+// add appends publication to channel cache, channel cache expires after ttl.
+func (c *historyCache) add(ch string, pub *Publication, sp StreamPosition, ttl time.Duration) {
+	if pub.Offset == 0 {
+		// Publication without history, nothing to keep.
+		return
+	}
+	c.mu.RLock()
+	cc, ok := c.channels[ch]
+	c.mu.RUnlock()
+	if !ok {
+		c.mu.Lock()
+		cc, ok = c.channels[ch]
+		if !ok {
+			cc = &historyCacheChannel{pubs: make([]*Publication, c.size)}
+			c.channels[ch] = cc
+		}
+		c.mu.Unlock()
+	}
+	cc.add(pub, sp.Epoch, time.Now().Add(ttl).UnixNano())
+}
+
+func (cc *historyCacheChannel) add(pub *Publication, epoch string, expireAt int64) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.count > 0 && (cc.epoch != epoch || cc.top() != pub.Offset-1 || cc.expired()) {
+		// Reset cache on epoch change, offset gap or expiration.
+		cc.count = 0
+		cc.start = 0
+	}
+	cc.epoch = epoch
+	cc.expireAt = expireAt
+	if cc.count < len(cc.pubs) {
+		cc.pubs[(cc.start+cc.count)%len(cc.pubs)] = pub
+		cc.count++
+		return
+	}
+	cc.pubs[cc.start] = pub
+	cc.start = (cc.start + 1) % len(cc.pubs)
+}
+
+// Lock must be held outside.
+func (cc *historyCacheChannel) top() uint64 {
+	return cc.pubs[(cc.start+cc.count-1)%len(cc.pubs)].Offset
+}
+
+// Lock must be held outside.
+func (cc *historyCacheChannel) expired() bool {
+	return time.Now().UnixNano() >= cc.expireAt
+}
+
+// history returns publications since provided position if cache can serve
+// the request.
+func (c *historyCache) history(ch string, since StreamPosition, limit int) (HistoryResult, bool) {
+	c.mu.RLock()
+	cc, ok := c.channels[ch]
+	c.mu.RUnlock()
+	if !ok {
+		return HistoryResult{}, false
+	}
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	if cc.count == 0 || cc.epoch != since.Epoch || cc.expired() {
+		return HistoryResult{}, false
+	}
+	first := cc.pubs[cc.start].Offset
+	top := cc.top()
+	if since.Offset+1 < first || since.Offset > top {
+		return HistoryResult{}, false
+	}
+	num := int(top - since.Offset)
+	if limit >= 0 && num > limit {
+		num = limit
+	}
+	pubs := make([]*Publication, 0, num)
+	for i := int(since.Offset + 1 - first); len(pubs) < num; i++ {
+		pubs = append(pubs, cc.pubs[(cc.start+i)%len(cc.pubs)])
+	}
+	return HistoryResult{
+		StreamPosition: StreamPosition{Offset: top, Epoch: cc.epoch},
+		Publications:   pubs,
+	}, true
+}
+
+// remove drops channel cache, must be called when Node unsubscribes from
+// channel since publications are not received anymore, and when channel
+// history is removed.
+func (c *historyCache) remove(ch string) {
+	c.mu.Lock()
+	delete(c.channels, ch)
+	c.mu.Unlock()
+}
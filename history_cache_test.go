@@ -0,0 +1,163 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+	"github.com/centrifugal/centrifuge/internal/controlproto"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func testHistoryCachePub(offset uint64) *Publication {
+	return &Publication{Offset: offset, Data: []byte(`{}`)}
+}
+
+func TestHistoryCache(t *testing.T) {
+	c := newHistoryCache(3)
+	sp := func(offset uint64) StreamPosition {
+		return StreamPosition{Offset: offset, Epoch: "e"}
+	}
+	for i := uint64(1); i <= 5; i++ {
+		c.add("test", testHistoryCachePub(i), sp(i), time.Minute)
+	}
+
+	// Only 3 latest publications kept.
+	_, ok := c.history("test", sp(1), -1)
+	require.False(t, ok)
+
+	result, ok := c.history("test", sp(2), -1)
+	require.True(t, ok)
+	require.Equal(t, sp(5), result.StreamPosition)
+	require.Len(t, result.Publications, 3)
+	require.Equal(t, uint64(3), result.Publications[0].Offset)
+	require.Equal(t, uint64(5), result.Publications[2].Offset)
+
+	result, ok = c.history("test", sp(3), 1)
+	require.True(t, ok)
+	require.Len(t, result.Publications, 1)
+	require.Equal(t, uint64(4), result.Publications[0].Offset)
+
+	result, ok = c.history("test", sp(5), -1)
+	require.True(t, ok)
+	require.Empty(t, result.Publications)
+
+	// Client ahead of cache or with another epoch.
+	_, ok = c.history("test", sp(6), -1)
+	require.False(t, ok)
+	_, ok = c.history("test", StreamPosition{Offset: 4, Epoch: "other"}, -1)
+	require.False(t, ok)
+
+	// Gap resets cache.
+	c.add("test", testHistoryCachePub(7), sp(7), time.Minute)
+	_, ok = c.history("test", sp(4), -1)
+	require.False(t, ok)
+	result, ok = c.history("test", sp(6), -1)
+	require.True(t, ok)
+	require.Len(t, result.Publications, 1)
+
+	c.remove("test")
+	_, ok = c.history("test", sp(6), -1)
+	require.False(t, ok)
+}
+
+func TestNode_HistoryCache(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:         LogLevelTrace,
+		LogHandler:       func(entry LogEntry) {},
+		HistoryCacheSize: 10,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	n.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(e SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	newTestSubscribedClientV2(t, n, "42", "test")
+
+	var sp StreamPosition
+	for i := 0; i < 3; i++ {
+		result, err := n.Publish("test", []byte(`{}`), WithHistory(10, time.Minute))
+		require.NoError(t, err)
+		sp = result.StreamPosition
+	}
+
+	hits := testutil.ToFloat64(n.metrics.actionCountHistoryCacheHit)
+	result, err := n.History("test", WithSince(&StreamPosition{Offset: 1, Epoch: sp.Epoch}), WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Equal(t, sp, result.StreamPosition)
+	require.Len(t, result.Publications, 2)
+	require.Equal(t, hits+1, testutil.ToFloat64(n.metrics.actionCountHistoryCacheHit))
+}
+
+func TestHistoryCache_TTL(t *testing.T) {
+	c := newHistoryCache(3)
+	sp := StreamPosition{Offset: 1, Epoch: "e"}
+	c.add("test", testHistoryCachePub(1), sp, time.Minute)
+	_, ok := c.history("test", StreamPosition{Epoch: "e"}, -1)
+	require.True(t, ok)
+
+	c.add("test", testHistoryCachePub(2), StreamPosition{Offset: 2, Epoch: "e"}, -time.Second)
+	_, ok = c.history("test", StreamPosition{Epoch: "e"}, -1)
+	require.False(t, ok)
+}
+
+func TestNode_HistoryCacheRemoveHistory(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:         LogLevelTrace,
+		LogHandler:       func(entry LogEntry) {},
+		HistoryCacheSize: 10,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	n.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(e SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	newTestSubscribedClientV2(t, n, "42", "test")
+
+	var sp StreamPosition
+	for i := 0; i < 3; i++ {
+		result, err := n.Publish("test", []byte(`{}`), WithHistory(10, time.Minute))
+		require.NoError(t, err)
+		sp = result.StreamPosition
+	}
+	require.NoError(t, n.RemoveHistory("test"))
+
+	hits := testutil.ToFloat64(n.metrics.actionCountHistoryCacheHit)
+	result, err := n.History("test", WithSince(&StreamPosition{Offset: 1, Epoch: sp.Epoch}), WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Empty(t, result.Publications)
+	require.Equal(t, hits, testutil.ToFloat64(n.metrics.actionCountHistoryCacheHit))
+}
+
+func TestNode_HistoryCacheRemoveControl(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:         LogLevelTrace,
+		LogHandler:       func(entry LogEntry) {},
+		HistoryCacheSize: 10,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	sp := StreamPosition{Offset: 1, Epoch: "e"}
+	n.historyCache.add("test", testHistoryCachePub(1), sp, time.Minute)
+
+	// History removed on another node.
+	cmdBytes, err := controlproto.NewProtobufEncoder().EncodeCommand(&controlpb.Command{
+		Uid:           "other",
+		HistoryRemove: &controlpb.HistoryRemove{Channel: "test"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.handleControl(cmdBytes))
+	_, ok := n.historyCache.history("test", StreamPosition{Epoch: "e"}, -1)
+	require.False(t, ok)
+}
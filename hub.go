@@ -3,10 +3,13 @@ package centrifuge
 import (
 	"context"
 	"io"
+	"path"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/centrifugal/centrifuge/internal/convert"
+	"github.com/centrifugal/centrifuge/internal/queue"
 
 	"github.com/centrifugal/protocol"
 	"github.com/segmentio/encoding/json"
@@ -35,6 +38,14 @@ func newHub(logger *logger, metrics *metrics, maxTimeLagMilli int64) *Hub {
 	return h
 }
 
+// setBroadcastWorkerPool sets worker pool to deliver publications to subscribers
+// of large channels in parallel. Must be called before Hub is used.
+func (h *Hub) setBroadcastWorkerPool(pool *broadcastWorkerPool) {
+	for i := 0; i < numHubShards; i++ {
+		h.subShards[i].broadcastPool = pool
+	}
+}
+
 func (h *Hub) clientBySession(session string) (*Client, bool) {
 	h.sessionsMu.RLock()
 	defer h.sessionsMu.RUnlock()
@@ -102,6 +113,20 @@ func (h *Hub) Connections() map[string]*Client {
 	return connections
 }
 
+// clientByID finds client connection by ID. Connections are sharded by user ID,
+// so all shards are checked.
+func (h *Hub) clientByID(clientID string) (*Client, bool) {
+	for _, shard := range h.connShards {
+		shard.mu.RLock()
+		c, ok := shard.clients[clientID]
+		shard.mu.RUnlock()
+		if ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
 // UserConnections returns all user connections to the current Node.
 func (h *Hub) UserConnections(userID string) map[string]*Client {
 	return h.connShards[index(userID, numHubShards)].userConnections(userID)
@@ -123,6 +148,14 @@ func (h *Hub) disconnect(userID string, disconnect Disconnect, clientID, session
 	return h.connShards[index(userID, numHubShards)].disconnect(userID, disconnect, clientID, sessionID, whitelist)
 }
 
+func (h *Hub) updatePresence(userID string, ch string, clientID, sessionID string, opts ...UpdatePresenceOption) error {
+	return h.connShards[index(userID, numHubShards)].updatePresence(userID, ch, clientID, sessionID, opts...)
+}
+
+func (h *Hub) send(userID string, data []byte, clientID, sessionID string) error {
+	return h.connShards[index(userID, numHubShards)].send(userID, data, clientID, sessionID)
+}
+
 func (h *Hub) addSub(ch string, sub subInfo) (bool, error) {
 	return h.subShards[index(ch, numHubShards)].addSub(ch, sub)
 }
@@ -168,6 +201,25 @@ func (h *Hub) Channels() []string {
 	return channels
 }
 
+// channelStatsSnapshot returns stats for each channel matching pattern. Empty
+// pattern matches all channels.
+func (h *Hub) channelStatsSnapshot(pattern string) (map[string]channelStatsItem, error) {
+	result := map[string]channelStatsItem{}
+	for i := 0; i < numHubShards; i++ {
+		if err := h.subShards[i].channelStatsSnapshot(pattern, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// updateChannelStats calculates channel publication rates over elapsed interval.
+func (h *Hub) updateChannelStats(elapsed time.Duration) {
+	for i := 0; i < numHubShards; i++ {
+		h.subShards[i].updateChannelStats(elapsed)
+	}
+}
+
 // NumClients returns total number of client connections.
 func (h *Hub) NumClients() int {
 	var total int
@@ -187,6 +239,16 @@ func (h *Hub) NumUsers() int {
 	return total
 }
 
+// userIDs returns IDs of users connected to the current node. Anonymous
+// users are not included.
+func (h *Hub) userIDs() []string {
+	var users []string
+	for i := 0; i < numHubShards; i++ {
+		users = h.connShards[i].appendUserIDs(users)
+	}
+	return users
+}
+
 // NumSubscriptions returns a total number of subscriptions.
 func (h *Hub) NumSubscriptions() int {
 	var total int
@@ -247,6 +309,8 @@ func (h *connShard) shutdown(ctx context.Context, sem chan struct{}) error {
 		return nil
 	}
 
+	sessionResumption := len(clients[0].node.config.SessionResumptionSecret) > 0
+
 	for _, client := range clients {
 		select {
 		case sem <- struct{}{}:
@@ -256,6 +320,9 @@ func (h *connShard) shutdown(ctx context.Context, sem chan struct{}) error {
 		go func(cc *Client) {
 			defer func() { <-sem }()
 			defer func() { closeFinishedCh <- struct{}{} }()
+			if sessionResumption {
+				cc.sendResumptionToken()
+			}
 			_ = cc.close(advice)
 		}(client)
 	}
@@ -340,6 +407,44 @@ func (h *connShard) refresh(user string, clientID string, sessionID string, opts
 	return firstErr
 }
 
+func (h *connShard) updatePresence(user string, ch string, clientID string, sessionID string, opts ...UpdatePresenceOption) error {
+	userConnections := h.userConnections(user)
+
+	var firstErr error
+	for _, c := range userConnections {
+		if clientID != "" && c.ID() != clientID {
+			continue
+		}
+		if sessionID != "" && c.sessionID() != sessionID {
+			continue
+		}
+		err := c.UpdatePresence(ch, opts...)
+		if err != nil && err != io.EOF && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h *connShard) send(user string, data []byte, clientID string, sessionID string) error {
+	userConnections := h.userConnections(user)
+
+	var firstErr error
+	for _, c := range userConnections {
+		if clientID != "" && c.ID() != clientID {
+			continue
+		}
+		if sessionID != "" && c.sessionID() != sessionID {
+			continue
+		}
+		err := c.Send(data)
+		if err != nil && err != io.EOF && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 func (h *connShard) unsubscribe(user string, ch string, unsubscribe Unsubscribe, clientID string, sessionID string) error {
 	userConnections := h.userConnections(user)
 
@@ -472,6 +577,18 @@ func (h *connShard) NumClients() int {
 	return total
 }
 
+func (h *connShard) appendUserIDs(users []string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for user := range h.users {
+		if user == "" {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
 // NumUsers returns a number of unique users connected.
 func (h *connShard) NumUsers() int {
 	h.mu.RLock()
@@ -492,22 +609,36 @@ var stringToDeltaType = map[string]DeltaType{
 }
 
 type subInfo struct {
-	client    *Client
-	deltaType DeltaType
+	client      *Client
+	deltaType   DeltaType
+	compression PublicationCompression
+}
+
+// channelStats contains channel statistics on the current node.
+type channelStats struct {
+	// numPublications since last stats update.
+	numPublications atomic.Uint64
+	// publicationRate is a number of publications per second calculated
+	// over the last stats update interval.
+	publicationRate float64
 }
 
 type subShard struct {
 	mu sync.RWMutex
 	// registry to hold active subscriptions of clients to channels with some additional info.
 	subs            map[string]map[string]subInfo
+	stats           map[string]*channelStats
 	maxTimeLagMilli int64
 	logger          *logger
 	metrics         *metrics
+	// broadcastPool is optional, set when Config.BroadcastWorkerPoolSize > 0.
+	broadcastPool *broadcastWorkerPool
 }
 
 func newSubShard(logger *logger, metrics *metrics, maxTimeLagMilli int64) *subShard {
 	return &subShard{
 		subs:            make(map[string]map[string]subInfo),
+		stats:           make(map[string]*channelStats),
 		logger:          logger,
 		metrics:         metrics,
 		maxTimeLagMilli: maxTimeLagMilli,
@@ -524,6 +655,7 @@ func (h *subShard) addSub(ch string, sub subInfo) (bool, error) {
 	_, ok := h.subs[ch]
 	if !ok {
 		h.subs[ch] = make(map[string]subInfo)
+		h.stats[ch] = &channelStats{}
 	}
 	h.subs[ch][uid] = sub
 	if !ok {
@@ -553,6 +685,7 @@ func (h *subShard) removeSub(ch string, c *Client) (bool, error) {
 	// clean up subs map if it's needed.
 	if len(h.subs[ch]) == 0 {
 		delete(h.subs, ch)
+		delete(h.stats, ch)
 		return true, nil
 	}
 
@@ -569,6 +702,7 @@ type preparedKey struct {
 	ProtocolType   protocol.Type
 	Unidirectional bool
 	DeltaType      DeltaType
+	Compression    PublicationCompression
 }
 
 type preparedData struct {
@@ -576,8 +710,77 @@ type preparedData struct {
 	brokerDeltaData []byte
 	localDeltaData  []byte
 	deltaSub        bool
+	priority        queue.Priority
+	freshnessTTL    time.Duration
+	queueCounters   clientQueueCounters
+}
+
+// protoPubPool and protoInfoPool reuse protocol publications built upon broadcast.
+// Publication is owned by broadcastPublication and returned to pool when delivery
+// to all subscribers finished – code which keeps it after writing to connection
+// (like PubSubSync buffer) must make a copy.
+var (
+	protoPubPool  sync.Pool
+	protoInfoPool sync.Pool
+)
+
+func acquireProtoPub() *protocol.Publication {
+	if v := protoPubPool.Get(); v != nil {
+		return v.(*protocol.Publication)
+	}
+	return &protocol.Publication{}
+}
+
+// releaseProtoPub returns Publication to pool. Publication Info is not released since
+// it may be shared with other publications.
+func releaseProtoPub(p *protocol.Publication) {
+	p.Reset()
+	protoPubPool.Put(p)
+}
+
+func acquireProtoInfo() *protocol.ClientInfo {
+	if v := protoInfoPool.Get(); v != nil {
+		return v.(*protocol.ClientInfo)
+	}
+	return &protocol.ClientInfo{}
+}
+
+func releaseProtoInfo(info *protocol.ClientInfo) {
+	info.Reset()
+	protoInfoPool.Put(info)
 }
 
+// acquireBroadcastPub is the same as pubToProto but takes Publication and ClientInfo
+// from pool. Must be released with releaseBroadcastPub.
+func acquireBroadcastPub(pub *Publication) *protocol.Publication {
+	p := acquireProtoPub()
+	p.Offset = pub.Offset
+	p.Data = pub.Data
+	p.Tags = pub.Tags
+	if pub.Info != nil {
+		info := acquireProtoInfo()
+		info.Client = pub.Info.ClientID
+		info.User = pub.Info.UserID
+		if len(pub.Info.ConnInfo) > 0 {
+			info.ConnInfo = pub.Info.ConnInfo
+		}
+		if len(pub.Info.ChanInfo) > 0 {
+			info.ChanInfo = pub.Info.ChanInfo
+		}
+		p.Info = info
+	}
+	return p
+}
+
+func releaseBroadcastPub(p *protocol.Publication) {
+	if p.Info != nil {
+		releaseProtoInfo(p.Info)
+	}
+	releaseProtoPub(p)
+}
+
+// getDeltaPub returns publication to send to delta subscribers. If returned publication
+// is not fullPub it's taken from pool and must be released with releaseProtoPub.
 func getDeltaPub(prevPub *Publication, fullPub *protocol.Publication, key preparedKey) *protocol.Publication {
 	deltaPub := fullPub
 	if prevPub != nil && key.DeltaType == DeltaTypeFossil {
@@ -591,21 +794,19 @@ func getDeltaPub(prevPub *Publication, fullPub *protocol.Publication, key prepar
 		if key.ProtocolType == protocol.TypeJSON {
 			deltaData = json.Escape(convert.BytesToString(deltaData))
 		}
-		deltaPub = &protocol.Publication{
-			Offset: fullPub.Offset,
-			Data:   deltaData,
-			Info:   fullPub.Info,
-			Tags:   fullPub.Tags,
-			Delta:  delta,
-		}
+		deltaPub = acquireProtoPub()
+		deltaPub.Offset = fullPub.Offset
+		deltaPub.Data = deltaData
+		deltaPub.Info = fullPub.Info
+		deltaPub.Tags = fullPub.Tags
+		deltaPub.Delta = delta
 	} else if prevPub == nil && key.ProtocolType == protocol.TypeJSON && key.DeltaType == DeltaTypeFossil {
 		// In JSON and Fossil case we need to send full state in JSON string format.
-		deltaPub = &protocol.Publication{
-			Offset: fullPub.Offset,
-			Data:   json.Escape(convert.BytesToString(fullPub.Data)),
-			Info:   fullPub.Info,
-			Tags:   fullPub.Tags,
-		}
+		deltaPub = acquireProtoPub()
+		deltaPub.Offset = fullPub.Offset
+		deltaPub.Data = json.Escape(convert.BytesToString(fullPub.Data))
+		deltaPub.Info = fullPub.Info
+		deltaPub.Tags = fullPub.Tags
 	}
 	return deltaPub
 }
@@ -663,132 +864,228 @@ func (h *subShard) broadcastPublication(channel string, sp StreamPosition, pub,
 		h.metrics.observePubSubDeliveryLag(timeLagMilli)
 	}
 
-	fullPub := pubToProto(pub)
+	fullPub := acquireBroadcastPub(pub)
+	defer releaseBroadcastPub(fullPub)
 	preparedDataByKey := make(map[preparedKey]preparedData)
 
 	h.mu.RLock()
-	defer h.mu.RUnlock()
-
 	channelSubscribers, ok := h.subs[channel]
 	if !ok {
+		h.mu.RUnlock()
 		return nil
 	}
+	if stats, ok := h.stats[channel]; ok {
+		stats.numPublications.Add(1)
+	}
 
 	var (
 		jsonEncodeErr *encodeError
 	)
 
-	for _, sub := range channelSubscribers {
-		key := preparedKey{
-			ProtocolType:   sub.client.Transport().Protocol().toProto(),
-			Unidirectional: sub.client.transport.Unidirectional(),
-			DeltaType:      sub.deltaType,
-		}
-		prepValue, prepDataFound := preparedDataByKey[key]
-		if !prepDataFound {
-			var brokerDeltaPub *protocol.Publication
-			if fullPub.Offset > 0 {
-				brokerDeltaPub = getDeltaPub(prevPub, fullPub, key)
+	if h.broadcastPool != nil && len(channelSubscribers) >= h.broadcastPool.chunkSize*2 {
+		// Copy subscribers to not hold shard lock while waiting for broadcast workers –
+		// this would block subscribe/unsubscribe in the shard till broadcast is finished.
+		subs := make([]subInfo, 0, len(channelSubscribers))
+		for _, sub := range channelSubscribers {
+			subs = append(subs, sub)
+		}
+		h.mu.RUnlock()
+		var err error
+		jsonEncodeErr, err = h.broadcastPublicationParallel(subs, channel, sp, fullPub, prevPub, localPrevPub, maxLagExceeded, pub.delivery)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, sub := range channelSubscribers {
+			err := h.deliverPublication(sub, channel, sp, fullPub, prevPub, localPrevPub, maxLagExceeded, pub.delivery, preparedDataByKey, &jsonEncodeErr)
+			if err != nil {
+				h.mu.RUnlock()
+				return err
 			}
-			localDeltaPub := getDeltaPub(localPrevPub, fullPub, key)
+		}
+		h.mu.RUnlock()
+	}
+	if jsonEncodeErr != nil && h.logger.enabled(LogLevelWarn) {
+		// Log that we had clients with inappropriate protocol, and point to the first such client.
+		h.logger.log(NewLogEntry(LogLevelWarn, "inappropriate protocol publication", map[string]any{
+			"channel": channel,
+			"user":    jsonEncodeErr.user,
+			"client":  jsonEncodeErr.client,
+			"error":   jsonEncodeErr.error,
+		}))
+	}
+
+	h.metrics.observeBroadcastDuration(now)
+	return nil
+}
+
+// deliverPublication prepares publication data for subscriber (using preparedDataByKey
+// as a cache of already encoded data) and writes it to subscriber connection.
+func (h *subShard) deliverPublication(
+	sub subInfo, channel string, sp StreamPosition, fullPub *protocol.Publication, prevPub, localPrevPub *Publication,
+	maxLagExceeded bool, delivery deliveryOptions, preparedDataByKey map[preparedKey]preparedData, jsonEncodeErr **encodeError,
+) error {
+	if delivery.userAllowed != nil && !delivery.userAllowed(sub.client.user) {
+		return nil
+	}
+	key := preparedKey{
+		ProtocolType:   sub.client.Transport().Protocol().toProto(),
+		Unidirectional: sub.client.transport.Unidirectional(),
+		DeltaType:      sub.deltaType,
+	}
+	if delivery.compressionThreshold > 0 && len(fullPub.Data) >= delivery.compressionThreshold {
+		key.Compression = sub.compression
+	}
+	prepValue, prepDataFound := preparedDataByKey[key]
+	if !prepDataFound {
+		var brokerDeltaPub *protocol.Publication
+		if fullPub.Offset > 0 {
+			brokerDeltaPub = getDeltaPub(prevPub, fullPub, key)
+		}
+		localDeltaPub := getDeltaPub(localPrevPub, fullPub, key)
 
-			var brokerDeltaData []byte
-			var localDeltaData []byte
-			if key.DeltaType != deltaTypeNone {
+		var brokerDeltaData []byte
+		var localDeltaData []byte
+		if key.DeltaType != deltaTypeNone {
+			var err error
+			brokerDeltaData, err = getDeltaData(sub, key, channel, brokerDeltaPub, *jsonEncodeErr)
+			if err != nil {
+				return err
+			}
+			localDeltaData, err = getDeltaData(sub, key, channel, localDeltaPub, *jsonEncodeErr)
+			if err != nil {
+				return err
+			}
+		}
+		if brokerDeltaPub != nil && brokerDeltaPub != fullPub {
+			releaseProtoPub(brokerDeltaPub)
+		}
+		if localDeltaPub != fullPub {
+			releaseProtoPub(localDeltaPub)
+		}
+
+		var fullData []byte
+
+		compressedPub, err := getCompressedPub(fullPub, key)
+		if err != nil {
+			return err
+		}
+
+		if key.ProtocolType == protocol.TypeJSON {
+			if sub.client.transport.Unidirectional() {
+				pubToUse := compressedPub
+				if key.ProtocolType == protocol.TypeJSON && key.DeltaType == DeltaTypeFossil {
+					pubToUse = &protocol.Publication{
+						Offset: fullPub.Offset,
+						Data:   json.Escape(convert.BytesToString(fullPub.Data)),
+						Info:   fullPub.Info,
+						Tags:   fullPub.Tags,
+					}
+				}
+				push := &protocol.Push{Channel: channel, Pub: pubToUse}
 				var err error
-				brokerDeltaData, err = getDeltaData(sub, key, channel, brokerDeltaPub, jsonEncodeErr)
+				fullData, err = protocol.DefaultJsonPushEncoder.Encode(push)
 				if err != nil {
-					return err
+					*jsonEncodeErr = &encodeError{client: sub.client.ID(), user: sub.client.UserID(), error: err}
+				}
+			} else {
+				pubToUse := compressedPub
+				if key.ProtocolType == protocol.TypeJSON && key.DeltaType == DeltaTypeFossil {
+					pubToUse = &protocol.Publication{
+						Offset: fullPub.Offset,
+						Data:   json.Escape(convert.BytesToString(fullPub.Data)),
+						Info:   fullPub.Info,
+						Tags:   fullPub.Tags,
+					}
 				}
-				localDeltaData, err = getDeltaData(sub, key, channel, localDeltaPub, jsonEncodeErr)
+				push := &protocol.Push{Channel: channel, Pub: pubToUse}
+				var err error
+				fullData, err = protocol.DefaultJsonReplyEncoder.Encode(&protocol.Reply{Push: push})
 				if err != nil {
-					return err
+					*jsonEncodeErr = &encodeError{client: sub.client.ID(), user: sub.client.UserID(), error: err}
 				}
 			}
-
-			var fullData []byte
-
-			if key.ProtocolType == protocol.TypeJSON {
-				if sub.client.transport.Unidirectional() {
-					pubToUse := fullPub
-					if key.ProtocolType == protocol.TypeJSON && key.DeltaType == DeltaTypeFossil {
-						pubToUse = &protocol.Publication{
-							Offset: fullPub.Offset,
-							Data:   json.Escape(convert.BytesToString(fullPub.Data)),
-							Info:   fullPub.Info,
-							Tags:   fullPub.Tags,
-						}
-					}
-					push := &protocol.Push{Channel: channel, Pub: pubToUse}
-					var err error
-					fullData, err = protocol.DefaultJsonPushEncoder.Encode(push)
-					if err != nil {
-						jsonEncodeErr = &encodeError{client: sub.client.ID(), user: sub.client.UserID(), error: err}
-					}
-				} else {
-					pubToUse := fullPub
-					if key.ProtocolType == protocol.TypeJSON && key.DeltaType == DeltaTypeFossil {
-						pubToUse = &protocol.Publication{
-							Offset: fullPub.Offset,
-							Data:   json.Escape(convert.BytesToString(fullPub.Data)),
-							Info:   fullPub.Info,
-							Tags:   fullPub.Tags,
-						}
-					}
-					push := &protocol.Push{Channel: channel, Pub: pubToUse}
-					var err error
-					fullData, err = protocol.DefaultJsonReplyEncoder.Encode(&protocol.Reply{Push: push})
-					if err != nil {
-						jsonEncodeErr = &encodeError{client: sub.client.ID(), user: sub.client.UserID(), error: err}
-					}
+		} else if key.ProtocolType == protocol.TypeProtobuf {
+			if sub.client.transport.Unidirectional() {
+				push := &protocol.Push{Channel: channel, Pub: compressedPub}
+				var err error
+				fullData, err = protocol.DefaultProtobufPushEncoder.Encode(push)
+				if err != nil {
+					return err
 				}
-			} else if key.ProtocolType == protocol.TypeProtobuf {
-				if sub.client.transport.Unidirectional() {
-					push := &protocol.Push{Channel: channel, Pub: fullPub}
-					var err error
-					fullData, err = protocol.DefaultProtobufPushEncoder.Encode(push)
-					if err != nil {
-						return err
-					}
-				} else {
-					push := &protocol.Push{Channel: channel, Pub: fullPub}
-					var err error
-					fullData, err = protocol.DefaultProtobufReplyEncoder.Encode(&protocol.Reply{Push: push})
-					if err != nil {
-						return err
-					}
+			} else {
+				push := &protocol.Push{Channel: channel, Pub: compressedPub}
+				var err error
+				fullData, err = protocol.DefaultProtobufReplyEncoder.Encode(&protocol.Reply{Push: push})
+				if err != nil {
+					return err
 				}
 			}
-
-			prepValue = preparedData{
-				fullData:        fullData,
-				brokerDeltaData: brokerDeltaData,
-				localDeltaData:  localDeltaData,
-				deltaSub:        key.DeltaType != deltaTypeNone,
-			}
-			preparedDataByKey[key] = prepValue
-		}
-		if sub.client.transport.Protocol() == ProtocolTypeJSON && jsonEncodeErr != nil {
-			go func(c *Client) { c.Disconnect(DisconnectInappropriateProtocol) }(sub.client)
-			continue
 		}
 
-		_ = sub.client.writePublication(channel, fullPub, prepValue, sp, maxLagExceeded)
+		prepValue = preparedData{
+			fullData:        fullData,
+			brokerDeltaData: brokerDeltaData,
+			localDeltaData:  localDeltaData,
+			deltaSub:        key.DeltaType != deltaTypeNone,
+			priority:        queue.Priority(delivery.priority),
+			freshnessTTL:    delivery.freshnessTTL,
+			queueCounters:   sub.client.node.clientQueueCounters(channel),
+		}
+		preparedDataByKey[key] = prepValue
 	}
-	if jsonEncodeErr != nil && h.logger.enabled(LogLevelWarn) {
-		// Log that we had clients with inappropriate protocol, and point to the first such client.
-		h.logger.log(NewLogEntry(LogLevelWarn, "inappropriate protocol publication", map[string]any{
-			"channel": channel,
-			"user":    jsonEncodeErr.user,
-			"client":  jsonEncodeErr.client,
-			"error":   jsonEncodeErr.error,
-		}))
+	if sub.client.transport.Protocol() == ProtocolTypeJSON && *jsonEncodeErr != nil {
+		go func(c *Client) { c.Disconnect(DisconnectInappropriateProtocol) }(sub.client)
+		return nil
 	}
 
-	h.metrics.observeBroadcastDuration(now)
+	_ = sub.client.writePublication(channel, fullPub, prepValue, sp, maxLagExceeded)
 	return nil
 }
 
+// broadcastPublicationParallel splits channel subscribers into chunks and delivers
+// publication to them over broadcast worker pool. It waits for all chunks to be
+// processed to keep the order of publications for each subscriber. Must be called
+// without shard lock held.
+func (h *subShard) broadcastPublicationParallel(
+	subs []subInfo, channel string, sp StreamPosition, fullPub *protocol.Publication,
+	prevPub, localPrevPub *Publication, maxLagExceeded bool, delivery deliveryOptions,
+) (*encodeError, error) {
+	chunkSize := h.broadcastPool.chunkSize
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		encErr   *encodeError
+	)
+	for i := 0; i < len(subs); i += chunkSize {
+		chunk := subs[i:min(i+chunkSize, len(subs))]
+		wg.Add(1)
+		h.broadcastPool.submit(func() {
+			defer wg.Done()
+			preparedDataByKey := make(map[preparedKey]preparedData)
+			var jsonEncodeErr *encodeError
+			var err error
+			for _, sub := range chunk {
+				err = h.deliverPublication(sub, channel, sp, fullPub, prevPub, localPrevPub, maxLagExceeded, delivery, preparedDataByKey, &jsonEncodeErr)
+				if err != nil {
+					break
+				}
+			}
+			mu.Lock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if jsonEncodeErr != nil && encErr == nil {
+				encErr = jsonEncodeErr
+			}
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return encErr, firstErr
+}
+
 // broadcastJoin sends message to all clients subscribed on channel.
 func (h *subShard) broadcastJoin(channel string, join *protocol.Join) error {
 	h.mu.RLock()
@@ -996,6 +1293,36 @@ func (h *subShard) Channels() []string {
 	return channels
 }
 
+func (h *subShard) channelStatsSnapshot(pattern string, result map[string]channelStatsItem) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch, clients := range h.subs {
+		if pattern != "" {
+			matched, err := path.Match(pattern, ch)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				continue
+			}
+		}
+		item := channelStatsItem{NumSubscribers: len(clients)}
+		if stats, ok := h.stats[ch]; ok {
+			item.PublicationRate = stats.publicationRate
+		}
+		result[ch] = item
+	}
+	return nil
+}
+
+func (h *subShard) updateChannelStats(elapsed time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, stats := range h.stats {
+		stats.publicationRate = float64(stats.numPublications.Swap(0)) / elapsed.Seconds()
+	}
+}
+
 // NumSubscribers returns number of current subscribers for a given channel.
 func (h *subShard) NumSubscribers(ch string) int {
 	h.mu.RLock()
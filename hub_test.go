@@ -1060,6 +1060,29 @@ func BenchmarkHub_Contention(b *testing.B) {
 	})
 }
 
+func Test_acquireBroadcastPub(t *testing.T) {
+	pub := acquireBroadcastPub(&Publication{
+		Offset: 42,
+		Data:   []byte("data"),
+		Info:   &ClientInfo{ClientID: "client_id", UserID: "user_id", ConnInfo: []byte("{}")},
+		Tags:   map[string]string{"k": "v"},
+	})
+	require.Equal(t, pubToProto(&Publication{
+		Offset: 42,
+		Data:   []byte("data"),
+		Info:   &ClientInfo{ClientID: "client_id", UserID: "user_id", ConnInfo: []byte("{}")},
+		Tags:   map[string]string{"k": "v"},
+	}).String(), pub.String())
+	releaseBroadcastPub(pub)
+
+	// Reused publication must not keep fields of previous one.
+	pub = acquireBroadcastPub(&Publication{Data: []byte("data")})
+	defer releaseBroadcastPub(pub)
+	require.Zero(t, pub.Offset)
+	require.Nil(t, pub.Info)
+	require.Nil(t, pub.Tags)
+}
+
 var broadcastBenches = []struct {
 	NumSubscribers int
 }{
@@ -1073,7 +1096,7 @@ var broadcastBenches = []struct {
 // BenchmarkHub_MassiveBroadcast allows estimating time to broadcast
 // a single message to many subscribers inside one channel.
 func BenchmarkHub_MassiveBroadcast(b *testing.B) {
-	pub := &Publication{Data: []byte(`{"input": "test"}`)}
+	pub := &Publication{Data: []byte(`{"input": "test"}`), Info: &ClientInfo{ClientID: "1", UserID: "12"}}
 	streamPosition := StreamPosition{}
 
 	for _, tt := range broadcastBenches {
@@ -1323,3 +1346,101 @@ func BenchmarkDeltaFossil(b *testing.B) {
 		}
 	}
 }
+
+func TestHubBroadcastPublication_WorkerPool(t *testing.T) {
+	n := defaultTestNode()
+	n.broadcastPool = newBroadcastWorkerPool(2, 0, 2, BroadcastOverflowBlock)
+	n.hub.setBroadcastWorkerPool(n.broadcastPool)
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	var transports []*testTransport
+	for i := 0; i < 5; i++ {
+		ctx, cancelFn := context.WithCancel(context.Background())
+		transport := newTestTransport(cancelFn)
+		transport.sink = make(chan []byte, 100)
+		transport.setProtocolType(ProtocolTypeJSON)
+		transport.setProtocolVersion(ProtocolVersion2)
+		newTestSubscribedClientWithTransport(t, ctx, n, transport, strconv.Itoa(i), "test_channel")
+		transports = append(transports, transport)
+	}
+
+	for i := 0; i < 3; i++ {
+		err := n.hub.BroadcastPublication(
+			"test_channel",
+			&Publication{Data: []byte(`{"data": "` + strconv.Itoa(i) + `"}`)},
+			StreamPosition{},
+		)
+		require.NoError(t, err)
+	}
+
+	for _, transport := range transports {
+		for i := 0; i < 3; {
+			select {
+			case data := <-transport.sink:
+				if !strings.Contains(string(data), `"pub"`) {
+					// Skip connect and subscribe replies.
+					continue
+				}
+				require.Contains(t, string(data), `{"data": "`+strconv.Itoa(i)+`"}`)
+				i++
+			case <-time.After(2 * time.Second):
+				require.Fail(t, "timeout receiving publication")
+			}
+		}
+	}
+}
+
+func TestHubBroadcastPublication_WorkerPoolNoShardLock(t *testing.T) {
+	n := defaultTestNode()
+	// No workers – broadcast waits for chunks till worker started below.
+	n.broadcastPool = newBroadcastWorkerPool(0, 0, 2, BroadcastOverflowBlock)
+	n.hub.setBroadcastWorkerPool(n.broadcastPool)
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	for i := 0; i < 4; i++ {
+		newTestSubscribedClientWithTransport(t, context.Background(), n, newTestTransport(func() {}), strconv.Itoa(i), "test_channel")
+	}
+
+	broadcastDone := make(chan error, 1)
+	go func() {
+		broadcastDone <- n.hub.BroadcastPublication("test_channel", &Publication{Data: []byte(`{}`)}, StreamPosition{})
+	}()
+	require.Eventually(t, func() bool { return len(n.broadcastPool.tasks) == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	// Subscribing to the same channel (so the same hub shard) must not wait for broadcast.
+	subscribed := make(chan struct{})
+	go func() {
+		newTestSubscribedClientWithTransport(t, context.Background(), n, newTestTransport(func() {}), "5", "test_channel")
+		close(subscribed)
+	}()
+	select {
+	case <-subscribed:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "subscribe blocked by broadcast")
+	}
+
+	go n.broadcastPool.runWorker()
+	select {
+	case err := <-broadcastDone:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "timeout waiting for broadcast")
+	}
+}
+
+func TestBroadcastWorkerPool(t *testing.T) {
+	p := newBroadcastWorkerPool(0, 1, 0, BroadcastOverflowInline)
+	require.Equal(t, defaultBroadcastChunkSize, p.chunkSize)
+
+	var numCalls int
+	// No workers: first task waits in queue, second executed inline.
+	p.submit(func() { numCalls++ })
+	p.submit(func() { numCalls++ })
+	require.Equal(t, 1, numCalls)
+
+	p.close()
+	// Closed pool executes tasks inline.
+	p.submit(func() { numCalls++ })
+	require.Equal(t, 2, numCalls)
+	p.close()
+}
@@ -0,0 +1,94 @@
+package hll
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+const (
+	precision    = 12
+	numRegisters = 1 << precision
+)
+
+// Sketch is a HyperLogLog sketch to estimate a number of distinct strings. With
+// 4096 registers standard error of estimation is about 1.6%, small cardinalities
+// are estimated with linear counting and are almost exact.
+type Sketch struct {
+	registers []byte
+}
+
+// New creates empty Sketch.
+func New() *Sketch {
+	return &Sketch{registers: make([]byte, numRegisters)}
+}
+
+// FromBytes creates Sketch from data returned by Sketch.Bytes.
+func FromBytes(data []byte) (*Sketch, error) {
+	if len(data) != numRegisters {
+		return nil, errors.New("hll: wrong sketch size")
+	}
+	s := New()
+	copy(s.registers, data)
+	return s, nil
+}
+
+// Bytes returns sketch registers. Sketches created with the same version of package
+// on different nodes can be merged.
+func (s *Sketch) Bytes() []byte {
+	return s.registers
+}
+
+// Add value to sketch.
+func (s *Sketch) Add(value string) {
+	h := hash(value)
+	idx := h >> (64 - precision)
+	// Set bit after the remaining bits so rank never exceeds 64 - precision + 1.
+	rank := byte(bits.LeadingZeros64(h<<precision|1<<(precision-1)) + 1)
+	if rank > s.registers[idx] {
+		s.registers[idx] = rank
+	}
+}
+
+// Merge other sketch into this one.
+func (s *Sketch) Merge(other *Sketch) {
+	for i, rank := range other.registers {
+		if rank > s.registers[i] {
+			s.registers[i] = rank
+		}
+	}
+}
+
+// Count returns estimated number of distinct values added to sketch.
+func (s *Sketch) Count() int {
+	var sum float64
+	var zeros int
+	for _, rank := range s.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+	m := float64(numRegisters)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(estimate))
+}
+
+// hash is FNV-1a with murmur3 finalizer to spread bits – hash must be the same on
+// all nodes, so seeded hashes like maphash can't be used.
+func hash(value string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(value); i++ {
+		h ^= uint64(value[i])
+		h *= 1099511628211
+	}
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,41 @@
+package hll
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketch_Count(t *testing.T) {
+	s := New()
+	require.Zero(t, s.Count())
+	s.Add("1")
+	s.Add("1")
+	s.Add("2")
+	require.Equal(t, 2, s.Count())
+
+	s = New()
+	for i := 0; i < 100000; i++ {
+		s.Add(strconv.Itoa(i))
+	}
+	require.InDelta(t, 100000, s.Count(), 5000)
+}
+
+func TestSketch_Merge(t *testing.T) {
+	s1 := New()
+	s2 := New()
+	for i := 0; i < 1000; i++ {
+		s1.Add(strconv.Itoa(i))
+		s2.Add(strconv.Itoa(i + 500))
+	}
+	merged, err := FromBytes(s1.Bytes())
+	require.NoError(t, err)
+	merged.Merge(s2)
+	require.InDelta(t, 1500, merged.Count(), 50)
+	// Source sketch not modified.
+	require.InDelta(t, 1000, s1.Count(), 30)
+
+	_, err = FromBytes([]byte("1"))
+	require.Error(t, err)
+}
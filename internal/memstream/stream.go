@@ -91,9 +91,13 @@ func (s *Stream) Clear() {
 
 // Get items since provided position.
 // If seq is zero then elements since current first element in stream will be returned.
+// In reverse mode offset greater than stream top means getting items since stream top.
 func (s *Stream) Get(offset uint64, useOffset bool, limit int, reverse bool) ([]Item, uint64, error) {
 	if useOffset && offset >= s.top+1 {
-		return nil, s.top, nil
+		if !reverse {
+			return nil, s.top, nil
+		}
+		useOffset = false
 	}
 
 	var el *list.Element
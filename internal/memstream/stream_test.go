@@ -46,6 +46,11 @@ func TestStream(t *testing.T) {
 	require.Equal(t, streamTop, uint64(5))
 	require.Nil(t, items)
 
+	items, streamTop, err = s.Get(7, true, 2, true)
+	require.NoError(t, err)
+	require.Equal(t, streamTop, uint64(5))
+	require.Equal(t, []Item{{5, []byte("5")}, {4, []byte("4")}}, items)
+
 	items, streamTop, err = s.Get(0, false, 2, true)
 	// Returns all items.
 	require.NoError(t, err)
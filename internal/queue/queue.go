@@ -6,10 +6,42 @@ import (
 	"github.com/centrifugal/protocol"
 )
 
+// Priority of Item in Queue.
+type Priority int8
+
+const (
+	// PriorityLow items are dropped first when queue is overflowed – see DropLow.
+	PriorityLow Priority = -1
+	// PriorityNormal is a default priority.
+	PriorityNormal Priority = 0
+	// PriorityHigh publication items are removed from queue before publications of
+	// other channels added earlier – see Queue.Add.
+	PriorityHigh Priority = 1
+)
+
 type Item struct {
 	Data      []byte
 	Channel   string
 	FrameType protocol.FrameType
+	Priority  Priority
+	// ExpireAt is an optional Unix time in nanoseconds after which Item is stale
+	// and should not be sent. Zero value means Item never expires.
+	ExpireAt int64
+
+	// seq is a sequence number of non-PriorityHigh Item in Queue.
+	seq uint64
+}
+
+// highItem is a PriorityHigh Item waiting in Queue.
+type highItem struct {
+	Item
+	// after is a seq of the last queued Item which highItem must not overtake.
+	after uint64
+}
+
+// Expired checks whether Item is stale at provided Unix time in nanoseconds.
+func (i Item) Expired(now int64) bool {
+	return i.ExpireAt > 0 && i.ExpireAt < now
 }
 
 // Queue is an unbounded queue of Item.
@@ -19,6 +51,8 @@ type Queue struct {
 	mu      sync.RWMutex
 	cond    *sync.Cond
 	nodes   []Item
+	high    []highItem // FIFO of PriorityHigh items, usually short.
+	seq     uint64
 	head    int
 	tail    int
 	cnt     int
@@ -55,17 +89,31 @@ func (q *Queue) resize(n int) {
 // Add an Item to the back of the queue
 // will return false if the queue is closed.
 // In that case the Item is dropped.
+//
+// PriorityHigh only reorders publications between channels: PriorityHigh item
+// overtakes queued publications (protocol.FrameTypePushPublication items with
+// Channel set) of other channels but never replies, other pushes or items of the
+// same channel. PriorityHigh items keep FIFO order between themselves.
 func (q *Queue) Add(i Item) bool {
 	q.mu.Lock()
 	if q.closed {
 		q.mu.Unlock()
 		return false
 	}
+	if i.Priority == PriorityHigh {
+		q.high = append(q.high, highItem{Item: i, after: q.barrier(i)})
+		q.size += len(i.Data)
+		q.cond.Signal()
+		q.mu.Unlock()
+		return true
+	}
 	if q.cnt == len(q.nodes) {
 		// Also tested a growth rate of 1.5, see: http://stackoverflow.com/questions/2269063/buffer-growth-strategy
 		// In Go this resulted in a higher memory usage.
 		q.resize(q.cnt * 2)
 	}
+	q.seq++
+	i.seq = q.seq
 	q.nodes[q.tail] = i
 	q.tail = (q.tail + 1) % len(q.nodes)
 	q.size += len(i.Data)
@@ -75,6 +123,25 @@ func (q *Queue) Add(i Item) bool {
 	return true
 }
 
+// barrier returns seq of the last queued Item which PriorityHigh Item must not
+// overtake, zero if it can overtake all queued items. Mutex must be held when
+// calling. Scans queue from the back, PriorityHigh items are expected to be rare.
+func (q *Queue) barrier(i Item) uint64 {
+	for j := q.cnt - 1; j >= 0; j-- {
+		queued := q.nodes[(q.head+j)%len(q.nodes)]
+		if queued.FrameType != protocol.FrameTypePushPublication || queued.Channel == "" || queued.Channel == i.Channel {
+			return queued.seq
+		}
+	}
+	return 0
+}
+
+// highReady reports whether the first PriorityHigh item should be removed before
+// the head of queue. Mutex must be held when calling.
+func (q *Queue) highReady() bool {
+	return len(q.high) > 0 && (q.cnt == 0 || q.nodes[q.head].seq > q.high[0].after)
+}
+
 // Close the queue and discard all entries in the queue
 // all goroutines in wait() will return
 func (q *Queue) Close() {
@@ -83,6 +150,7 @@ func (q *Queue) Close() {
 	q.closed = true
 	q.cnt = 0
 	q.nodes = nil
+	q.high = nil
 	q.size = 0
 	q.cond.Broadcast()
 }
@@ -95,8 +163,13 @@ func (q *Queue) CloseRemaining() []Item {
 	if q.closed {
 		return []Item{}
 	}
-	rem := make([]Item, 0, q.cnt)
-	for q.cnt > 0 {
+	rem := make([]Item, 0, q.cnt+len(q.high))
+	for q.cnt > 0 || len(q.high) > 0 {
+		if q.highReady() {
+			rem = append(rem, q.high[0].Item)
+			q.high = q.high[1:]
+			continue
+		}
 		i := q.nodes[q.head]
 		q.head = (q.head + 1) % len(q.nodes)
 		q.cnt--
@@ -105,6 +178,7 @@ func (q *Queue) CloseRemaining() []Item {
 	q.closed = true
 	q.cnt = 0
 	q.nodes = nil
+	q.high = nil
 	q.size = 0
 	q.cond.Broadcast()
 	return rem
@@ -130,7 +204,7 @@ func (q *Queue) Wait() bool {
 		q.mu.Unlock()
 		return false
 	}
-	if q.cnt != 0 {
+	if q.cnt != 0 || len(q.high) != 0 {
 		q.mu.Unlock()
 		return true
 	}
@@ -144,6 +218,17 @@ func (q *Queue) Wait() bool {
 // or 2) the queue is closed.
 func (q *Queue) Remove() (Item, bool) {
 	q.mu.Lock()
+	if q.highReady() {
+		i := q.high[0].Item
+		q.high[0] = highItem{}
+		q.high = q.high[1:]
+		if len(q.high) == 0 {
+			q.high = nil
+		}
+		q.size -= len(i.Data)
+		q.mu.Unlock()
+		return i, true
+	}
 	if q.cnt == 0 {
 		q.mu.Unlock()
 		return Item{}, false
@@ -161,6 +246,47 @@ func (q *Queue) Remove() (Item, bool) {
 	return i, true
 }
 
+// Peek returns an Item from the front of the queue without removing it.
+// If false is returned, there were no items on the queue.
+func (q *Queue) Peek() (Item, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.highReady() {
+		return q.high[0].Item, true
+	}
+	if q.cnt == 0 {
+		return Item{}, false
+	}
+	return q.nodes[q.head], true
+}
+
+// DropLow removes all PriorityLow items from the queue keeping the order of
+// remaining items. Returns number of removed items.
+func (q *Queue) DropLow() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.cnt == 0 {
+		return 0
+	}
+	kept := make([]Item, len(q.nodes))
+	n := 0
+	for j := 0; j < q.cnt; j++ {
+		i := q.nodes[(q.head+j)%len(q.nodes)]
+		if i.Priority == PriorityLow {
+			q.size -= len(i.Data)
+			continue
+		}
+		kept[n] = i
+		n++
+	}
+	dropped := q.cnt - n
+	q.nodes = kept
+	q.head = 0
+	q.cnt = n
+	q.tail = n % len(kept)
+	return dropped
+}
+
 // Cap returns the capacity (without allocations)
 func (q *Queue) Cap() int {
 	q.mu.RLock()
@@ -172,7 +298,7 @@ func (q *Queue) Cap() int {
 // Len returns the current length of the queue.
 func (q *Queue) Len() int {
 	q.mu.RLock()
-	l := q.cnt
+	l := q.cnt + len(q.high)
 	q.mu.RUnlock()
 	return l
 }
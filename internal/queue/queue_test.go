@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/centrifugal/protocol"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,6 +44,18 @@ func TestByteQueueSize(t *testing.T) {
 	require.Equal(t, 1, q.Size())
 }
 
+func TestByteQueuePeek(t *testing.T) {
+	q := New(initialCapacity)
+	_, ok := q.Peek()
+	require.False(t, ok)
+	q.Add(testItem([]byte("1")))
+	q.Add(testItem([]byte("2")))
+	i, ok := q.Peek()
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), i.Data)
+	require.Equal(t, 2, q.Len())
+}
+
 func TestByteQueueWait(t *testing.T) {
 	q := New(initialCapacity)
 	q.Add(testItem([]byte("1")))
@@ -150,3 +163,108 @@ func BenchmarkQueueAddConsume(b *testing.B) {
 	b.StopTimer()
 	q.Close()
 }
+
+func pubItem(data string, ch string, priority Priority) Item {
+	return Item{Data: []byte(data), Channel: ch, FrameType: protocol.FrameTypePushPublication, Priority: priority}
+}
+
+func removeAll(q *Queue) []string {
+	var order []string
+	for {
+		i, ok := q.Remove()
+		if !ok {
+			break
+		}
+		order = append(order, string(i.Data))
+	}
+	return order
+}
+
+func TestByteQueuePriority(t *testing.T) {
+	q := New(initialCapacity)
+	q.Add(pubItem("1", "a", PriorityNormal))
+	q.Add(pubItem("2", "a", PriorityLow))
+	q.Add(pubItem("high1", "b", PriorityHigh))
+	q.Add(pubItem("3", "a", PriorityNormal))
+	q.Add(pubItem("high2", "c", PriorityHigh))
+	require.Equal(t, 5, q.Len())
+	require.Equal(t, 13, q.Size())
+
+	i, ok := q.Peek()
+	require.True(t, ok)
+	require.Equal(t, "high1", string(i.Data))
+
+	require.Equal(t, []string{"high1", "high2", "1", "2", "3"}, removeAll(q))
+	require.Equal(t, 0, q.Size())
+}
+
+func TestByteQueuePrioritySameChannel(t *testing.T) {
+	q := New(initialCapacity)
+	q.Add(pubItem("a1", "a", PriorityNormal))
+	q.Add(pubItem("b1", "b", PriorityNormal))
+	q.Add(pubItem("a2", "a", PriorityNormal))
+	q.Add(pubItem("b2", "b", PriorityHigh))
+	q.Add(pubItem("a3", "a", PriorityNormal))
+
+	i, ok := q.Peek()
+	require.True(t, ok)
+	require.Equal(t, "a1", string(i.Data))
+	// High priority publication overtakes publications of other channels only.
+	require.Equal(t, []string{"a1", "b1", "b2", "a2", "a3"}, removeAll(q))
+}
+
+func TestByteQueuePriorityReplies(t *testing.T) {
+	q := New(initialCapacity)
+	q.Add(pubItem("a1", "a", PriorityNormal))
+	q.Add(Item{Data: []byte("subscribe"), Channel: "b", FrameType: protocol.FrameTypeSubscribe})
+	q.Add(pubItem("a2", "a", PriorityNormal))
+	q.Add(pubItem("b1", "b", PriorityHigh))
+	q.Add(pubItem("c1", "c", PriorityHigh))
+	// Reply without channel.
+	q.Add(Item{Data: []byte("rpc"), FrameType: protocol.FrameTypeRPC})
+	q.Add(pubItem("a3", "a", PriorityNormal))
+
+	// Publication never overtakes subscribe reply, high priority items keep FIFO order.
+	require.Equal(t, []string{"a1", "subscribe", "b1", "c1", "a2", "rpc", "a3"}, removeAll(q))
+
+	q.Add(pubItem("a1", "a", PriorityNormal))
+	q.Add(Item{Data: []byte("subscribe"), Channel: "b", FrameType: protocol.FrameTypeSubscribe})
+	q.Add(pubItem("b1", "b", PriorityHigh))
+	q.Add(pubItem("a2", "a", PriorityNormal))
+	var order []string
+	for _, i := range q.CloseRemaining() {
+		order = append(order, string(i.Data))
+	}
+	require.Equal(t, []string{"a1", "subscribe", "b1", "a2"}, order)
+}
+
+func TestByteQueueDropLow(t *testing.T) {
+	q := New(initialCapacity)
+	require.Equal(t, 0, q.DropLow())
+	// Remove first items to make ring wrap around.
+	q.Add(Item{Data: []byte("0")})
+	q.Add(Item{Data: []byte("0")})
+	q.Remove()
+	q.Remove()
+	q.Add(pubItem("1", "a", PriorityNormal))
+	q.Add(pubItem("22", "a", PriorityLow))
+	q.Add(pubItem("3", "a", PriorityNormal))
+	q.Add(pubItem("44", "a", PriorityLow))
+	q.Add(pubItem("high", "b", PriorityHigh))
+	require.Equal(t, 2, q.DropLow())
+	require.Equal(t, 3, q.Len())
+	require.Equal(t, 6, q.Size())
+
+	q.Add(pubItem("5", "a", PriorityNormal))
+	var order []string
+	for _, i := range q.CloseRemaining() {
+		order = append(order, string(i.Data))
+	}
+	require.Equal(t, []string{"high", "1", "3", "5"}, order)
+}
+
+func TestItemExpired(t *testing.T) {
+	require.False(t, Item{}.Expired(100))
+	require.False(t, Item{ExpireAt: 100}.Expired(100))
+	require.True(t, Item{ExpireAt: 99}.Expired(100))
+}
@@ -46,3 +46,23 @@ func MergePublications(recoveredPubs []*protocol.Publication, bufferedPubs []*pr
 	}
 	return recoveredPubs, true
 }
+
+func copyPublication(pub *protocol.Publication) *protocol.Publication {
+	var info *protocol.ClientInfo
+	if pub.Info != nil {
+		info = &protocol.ClientInfo{
+			User:     pub.Info.User,
+			Client:   pub.Info.Client,
+			ConnInfo: pub.Info.ConnInfo,
+			ChanInfo: pub.Info.ChanInfo,
+		}
+	}
+	return &protocol.Publication{
+		Offset: pub.Offset,
+		Data:   pub.Data,
+		Info:   info,
+		Tags:   pub.Tags,
+		Delta:  pub.Delta,
+		Time:   pub.Time,
+	}
+}
@@ -30,6 +30,8 @@ type subscribeState struct {
 }
 
 // SyncPublication ...
+// Publication may be reused by caller after SyncPublication returns, so buffer
+// keeps a copy of it.
 func (c *PubSubSync) SyncPublication(channel string, pub *protocol.Publication, syncedFn func()) {
 	c.subSyncMu.Lock()
 	s, ok := c.subSync[channel]
@@ -47,7 +49,7 @@ func (c *PubSubSync) SyncPublication(channel string, pub *protocol.Publication,
 		s.pubBufferMu.Lock()
 		if atomic.LoadUint32(&s.inSubscribe) == 1 {
 			// Sync point not reached yet - put Publication to tmp slice.
-			s.pubBuffer = append(s.pubBuffer, pub)
+			s.pubBuffer = append(s.pubBuffer, copyPublication(pub))
 			s.pubBufferMu.Unlock()
 			return
 		}
@@ -94,6 +94,23 @@ func TestPubSubSyncNonSynchronized(t *testing.T) {
 	require.Empty(t, psSync.subSync)
 }
 
+func TestPubSubSyncBufferCopy(t *testing.T) {
+	psSync := NewPubSubSync()
+	psSync.StartBuffering("ch")
+	pub := &protocol.Publication{Offset: 1, Data: []byte("1"), Info: &protocol.ClientInfo{Client: "1"}}
+	psSync.SyncPublication("ch", pub, func() {
+		require.Fail(t, "must be buffered")
+	})
+	// Publication may be reused by caller.
+	pub.Offset = 2
+	pub.Info.Client = "2"
+	pubs := psSync.LockBufferAndReadBuffered("ch")
+	psSync.StopBuffering("ch")
+	require.Len(t, pubs, 1)
+	require.Equal(t, uint64(1), pubs[0].Offset)
+	require.Equal(t, "1", pubs[0].Info.Client)
+}
+
 func BenchmarkPubSubSync(b *testing.B) {
 	psSync := NewPubSubSync()
 	var channels []string
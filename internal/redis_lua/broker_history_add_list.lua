@@ -10,6 +10,8 @@ local new_epoch_if_empty = ARGV[6]
 local publish_command = ARGV[7]
 local result_key_expire = ARGV[8]
 local use_delta = ARGV[9]
+-- Delivery options of publication are only sent over PUB/SUB, not saved to history.
+local delivery_fields = ARGV[10]
 
 if result_key_expire ~= '' then
     local cached_result = redis.call("hmget", result_key, "e", "s")
@@ -42,8 +44,11 @@ redis.call("ltrim", list_key, 0, ltrim_right_bound)
 redis.call("expire", list_key, list_ttl)
 
 if channel ~= '' then
+  local pubsub_message_payload = message_payload .. delivery_fields
   if use_delta == "1" then
-    payload = "__" .. "d1:" .. top_offset .. ":" .. current_epoch .. ":" .. #prev_message_payload .. ":" .. prev_message_payload .. ":" .. #message_payload .. ":" .. message_payload
+    payload = "__" .. "d1:" .. top_offset .. ":" .. current_epoch .. ":" .. #prev_message_payload .. ":" .. prev_message_payload .. ":" .. #pubsub_message_payload .. ":" .. pubsub_message_payload
+  else
+    payload = "__" .. "p1:" .. top_offset .. ":" .. current_epoch .. "__" .. pubsub_message_payload
   end
   redis.call(publish_command, channel, payload)
 end
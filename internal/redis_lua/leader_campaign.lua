@@ -0,0 +1,15 @@
+-- Acquire or renew leadership.
+-- KEYS[1] - leader key
+-- ARGV[1] - candidate ID
+-- ARGV[2] - leadership TTL in milliseconds
+-- Returns 1 if candidate is a leader, 0 otherwise.
+local current = redis.call("get", KEYS[1])
+if current == ARGV[1] then
+  redis.call("pexpire", KEYS[1], ARGV[2])
+  return 1
+end
+if not current then
+  redis.call("set", KEYS[1], ARGV[1], "px", ARGV[2])
+  return 1
+end
+return 0
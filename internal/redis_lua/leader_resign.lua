@@ -0,0 +1,7 @@
+-- Give up leadership if candidate is a leader.
+-- KEYS[1] - leader key
+-- ARGV[1] - candidate ID
+if redis.call("get", KEYS[1]) == ARGV[1] then
+  redis.call("del", KEYS[1])
+end
+return 0
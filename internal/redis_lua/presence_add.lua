@@ -9,18 +9,25 @@
 -- ARGV[4] - info payload
 -- ARGV[5] - user ID
 -- ARGV[6] - enable user mapping "0" or "1"
+-- ARGV[7] - stats only "0" or "1", when "1" client info is not stored in hash
 
 -- Check if client ID is new.
 local isNewClient = false
 if ARGV[6] ~= '0' then
-  isNewClient = redis.call("hexists", KEYS[2], ARGV[3]) == 0
+  if ARGV[7] ~= '0' then
+    isNewClient = redis.call("zscore", KEYS[1], ARGV[3]) == false
+  else
+    isNewClient = redis.call("hexists", KEYS[2], ARGV[3]) == 0
+  end
 end
 
 -- Add per-client presence.
 redis.call("zadd", KEYS[1], ARGV[2], ARGV[3])
-redis.call("hset", KEYS[2], ARGV[3], ARGV[4])
 redis.call("expire", KEYS[1], ARGV[1])
-redis.call("expire", KEYS[2], ARGV[1])
+if ARGV[7] == '0' then
+  redis.call("hset", KEYS[2], ARGV[3], ARGV[4])
+  redis.call("expire", KEYS[2], ARGV[1])
+end
 
 -- Add per-user information.
 if ARGV[6] ~= '0' then
@@ -6,11 +6,17 @@
 -- ARGV[1] - client ID
 -- ARGV[2] - user ID
 -- ARGV[3] - enable user mapping "0" or "1"
+-- ARGV[4] - stats only "0" or "1", when "1" client info is not stored in hash
 
 local clientExists = false
 if ARGV[3] ~= '0' then
-    -- Check if client ID exists in hash.
-    clientExists = redis.call("hexists", KEYS[2], ARGV[1]) == 1
+    if ARGV[4] ~= '0' then
+        -- Check if client ID exists in set.
+        clientExists = redis.call("zscore", KEYS[1], ARGV[1]) ~= false
+    else
+        -- Check if client ID exists in hash.
+        clientExists = redis.call("hexists", KEYS[2], ARGV[1]) == 1
+    end
 end
 
 redis.call("hdel", KEYS[2], ARGV[1])
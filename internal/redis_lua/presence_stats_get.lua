@@ -4,6 +4,7 @@
 -- KEYS[3] - per-user zset key
 -- KEYS[4] - per-user hash key
 -- ARGV[1] - current timestamp in seconds
+-- ARGV[2] - stats only "0" or "1", when "1" clients are counted using set
 local expired = redis.call("zrangebyscore", KEYS[1], "0", ARGV[1])
 if #expired > 0 then
   for num = 1, #expired do
@@ -20,7 +21,12 @@ if #userExpired > 0 then
   redis.call("zremrangebyscore", KEYS[3], "0", ARGV[1])
 end
 
-local clientCount = redis.call("hlen", KEYS[2])
+local clientCount
+if ARGV[2] ~= '0' then
+  clientCount = redis.call("zcard", KEYS[1])
+else
+  clientCount = redis.call("hlen", KEYS[2])
+end
 local userCount = redis.call("hlen", KEYS[4])
 
 return {clientCount, userCount}
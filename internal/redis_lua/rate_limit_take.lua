@@ -0,0 +1,28 @@
+-- Take one token from a token bucket.
+-- KEYS[1] - bucket hash key
+-- ARGV[1] - rate (tokens per second)
+-- ARGV[2] - burst (bucket capacity)
+-- ARGV[3] - bucket key expiration in milliseconds
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+-- Use Redis time so that clock skew between nodes does not affect shared bucket.
+local time = redis.call("time")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local tokens = burst
+local state = redis.call("hmget", KEYS[1], "tokens", "ts")
+if state[1] then
+    local elapsed = math.max(0, now - tonumber(state[2]))
+    tokens = math.min(burst, tonumber(state[1]) + elapsed * rate / 1000)
+end
+
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+
+redis.call("hset", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("pexpire", KEYS[1], ARGV[3])
+return allowed
@@ -0,0 +1,183 @@
+package centrifuge
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IPConnectLimiterConfig is a config for IPConnectLimiter.
+type IPConnectLimiterConfig struct {
+	// Rate is a number of connection attempts per second allowed from one IP address.
+	// Zero value disables rate limiting, only ban list is checked then.
+	Rate float64
+	// Burst is a number of connection attempts allowed from one IP address at once.
+	// Zero value means max(1, Rate).
+	Burst int
+	// BanTTL if set bans IP address for BanTTL when it exceeds connection rate.
+	BanTTL time.Duration
+	// ClientIP extracts client IP address from request. By default, host part of
+	// http.Request.RemoteAddr is used. Set this to extract IP from headers like
+	// X-Forwarded-For when running behind trusted proxy.
+	ClientIP func(r *http.Request) string
+}
+
+// IPConnectLimiter limits connection attempts per IP address and keeps a dynamic list
+// of banned IP addresses. It protects Node from connection floods before
+// authentication runs. IPConnectLimiter is local to the process, it's safe to share
+// one IPConnectLimiter between several handlers – see WebsocketConfig.ConnectLimiter
+// and IPConnectLimiter.Middleware.
+type IPConnectLimiter struct {
+	config IPConnectLimiterConfig
+
+	mu        sync.Mutex
+	buckets   map[string]*ipBucket
+	bans      map[string]time.Time // IP -> ban expiration, zero time for permanent ban.
+	lastSweep time.Time
+}
+
+type ipBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// ipLimiterSweepInterval is a minimal interval between removals of outdated buckets and bans.
+const ipLimiterSweepInterval = time.Minute
+
+// NewIPConnectLimiter creates new IPConnectLimiter.
+func NewIPConnectLimiter(config IPConnectLimiterConfig) *IPConnectLimiter {
+	if config.Burst <= 0 {
+		config.Burst = int(config.Rate)
+		if config.Burst < 1 {
+			config.Burst = 1
+		}
+	}
+	if config.ClientIP == nil {
+		config.ClientIP = remoteAddrIP
+	}
+	return &IPConnectLimiter{
+		config:    config,
+		buckets:   map[string]*ipBucket{},
+		bans:      map[string]time.Time{},
+		lastSweep: time.Now(),
+	}
+}
+
+func remoteAddrIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Ban bans IP address for ttl. Zero ttl bans IP address till Unban called.
+func (l *IPConnectLimiter) Ban(ip string, ttl time.Duration) {
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bans[ip] = expireAt
+}
+
+// Unban removes IP address from ban list.
+func (l *IPConnectLimiter) Unban(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.bans, ip)
+}
+
+// Banned returns true if IP address currently banned.
+func (l *IPConnectLimiter) Banned(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bannedLocked(ip, time.Now())
+}
+
+func (l *IPConnectLimiter) bannedLocked(ip string, now time.Time) bool {
+	expireAt, ok := l.bans[ip]
+	if !ok {
+		return false
+	}
+	if !expireAt.IsZero() && !now.Before(expireAt) {
+		delete(l.bans, ip)
+		return false
+	}
+	return true
+}
+
+// Allow checks whether connection attempt from IP address is allowed. It returns
+// false if IP address is banned or exceeded connection rate.
+func (l *IPConnectLimiter) Allow(ip string) bool {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= ipLimiterSweepInterval {
+		l.sweep(now)
+	}
+	if l.bannedLocked(ip, now) {
+		return false
+	}
+	if l.config.Rate <= 0 {
+		return true
+	}
+	burst := float64(l.config.Burst)
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &ipBucket{tokens: burst, updated: now}
+		l.buckets[ip] = b
+	} else {
+		b.tokens += now.Sub(b.updated).Seconds() * l.config.Rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.updated = now
+	}
+	if b.tokens < 1 {
+		if l.config.BanTTL > 0 {
+			l.bans[ip] = now.Add(l.config.BanTTL)
+		}
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep removes expired bans and buckets which are full already.
+func (l *IPConnectLimiter) sweep(now time.Time) {
+	l.lastSweep = now
+	for ip, expireAt := range l.bans {
+		if !expireAt.IsZero() && !now.Before(expireAt) {
+			delete(l.bans, ip)
+		}
+	}
+	for ip, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.config.Rate >= float64(l.config.Burst) {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// allowRequest checks request and writes error response if it's not allowed.
+func (l *IPConnectLimiter) allowRequest(rw http.ResponseWriter, r *http.Request) bool {
+	if l.Allow(l.config.ClientIP(r)) {
+		return true
+	}
+	http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	return false
+}
+
+// Middleware wraps handler to reject requests from banned IP addresses or exceeding
+// connection rate with 429 status code. Useful to protect handlers which do not
+// support IPConnectLimiter natively (for example, SSEHandler and HTTPStreamHandler).
+func (l *IPConnectLimiter) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !l.allowRequest(rw, r) {
+			return
+		}
+		h.ServeHTTP(rw, r)
+	})
+}
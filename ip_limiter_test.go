@@ -0,0 +1,78 @@
+package centrifuge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPConnectLimiter_Rate(t *testing.T) {
+	l := NewIPConnectLimiter(IPConnectLimiterConfig{Rate: 100, Burst: 2})
+	require.True(t, l.Allow("1.1.1.1"))
+	require.True(t, l.Allow("1.1.1.1"))
+	require.False(t, l.Allow("1.1.1.1"))
+	require.True(t, l.Allow("2.2.2.2"))
+	time.Sleep(20 * time.Millisecond)
+	require.True(t, l.Allow("1.1.1.1"))
+	require.False(t, l.Banned("1.1.1.1"))
+}
+
+func TestIPConnectLimiter_Ban(t *testing.T) {
+	l := NewIPConnectLimiter(IPConnectLimiterConfig{})
+	require.True(t, l.Allow("1.1.1.1"))
+	l.Ban("1.1.1.1", 0)
+	require.False(t, l.Allow("1.1.1.1"))
+	require.True(t, l.Banned("1.1.1.1"))
+	l.Unban("1.1.1.1")
+	require.True(t, l.Allow("1.1.1.1"))
+
+	l.Ban("1.1.1.1", 10*time.Millisecond)
+	require.False(t, l.Allow("1.1.1.1"))
+	time.Sleep(20 * time.Millisecond)
+	require.True(t, l.Allow("1.1.1.1"))
+}
+
+func TestIPConnectLimiter_BanOnExceed(t *testing.T) {
+	l := NewIPConnectLimiter(IPConnectLimiterConfig{Rate: 1000, Burst: 1, BanTTL: time.Minute})
+	require.True(t, l.Allow("1.1.1.1"))
+	require.False(t, l.Allow("1.1.1.1"))
+	time.Sleep(10 * time.Millisecond)
+	// Rate allows new attempt already, but IP is banned.
+	require.False(t, l.Allow("1.1.1.1"))
+	require.True(t, l.Banned("1.1.1.1"))
+}
+
+func TestIPConnectLimiter_Sweep(t *testing.T) {
+	l := NewIPConnectLimiter(IPConnectLimiterConfig{Rate: 1000, Burst: 1})
+	require.True(t, l.Allow("1.1.1.1"))
+	l.Ban("2.2.2.2", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	l.sweep(time.Now())
+	require.Empty(t, l.buckets)
+	require.Empty(t, l.bans)
+}
+
+func TestIPConnectLimiter_Middleware(t *testing.T) {
+	l := NewIPConnectLimiter(IPConnectLimiterConfig{
+		ClientIP: func(r *http.Request) string {
+			return r.Header.Get("X-Real-IP")
+		},
+	})
+	l.Ban("1.1.1.1", 0)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Real-IP", "1.1.1.1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Real-IP", "2.2.2.2")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,76 @@
+package centrifuge
+
+import (
+	"sync"
+)
+
+type joinLeaveEvent struct {
+	info  *ClientInfo
+	leave bool
+}
+
+type joinLeaveChannel struct {
+	events []*joinLeaveEvent
+	// index of pending event by client ID.
+	index map[string]int
+}
+
+// joinLeaveAggregator collects join and leave messages for channels over an
+// interval and emits them to local subscribers in batches. Join and leave of
+// the same client within one interval cancel each other out, repeated events
+// of the same client collapse into the latest one. This way subscribers of
+// large channels with high churn receive only net presence changes.
+type joinLeaveAggregator struct {
+	mu       sync.Mutex
+	channels map[string]*joinLeaveChannel
+}
+
+func newJoinLeaveAggregator() *joinLeaveAggregator {
+	return &joinLeaveAggregator{
+		channels: map[string]*joinLeaveChannel{},
+	}
+}
+
+func (a *joinLeaveAggregator) add(ch string, info *ClientInfo, leave bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.channels[ch]
+	if !ok {
+		c = &joinLeaveChannel{index: map[string]int{}}
+		a.channels[ch] = c
+	}
+	if i, ok := c.index[info.ClientID]; ok {
+		prev := c.events[i]
+		if !prev.leave && leave {
+			// Client joined and left within interval – nothing to report.
+			c.events[i] = nil
+			delete(c.index, info.ClientID)
+			return
+		}
+		c.events[i] = nil
+	}
+	c.index[info.ClientID] = len(c.events)
+	c.events = append(c.events, &joinLeaveEvent{info: info, leave: leave})
+}
+
+// flush returns aggregated events for all channels and resets aggregator state.
+func (a *joinLeaveAggregator) flush() map[string][]*joinLeaveEvent {
+	a.mu.Lock()
+	channels := a.channels
+	a.channels = make(map[string]*joinLeaveChannel, len(channels))
+	a.mu.Unlock()
+
+	result := make(map[string][]*joinLeaveEvent, len(channels))
+	for ch, c := range channels {
+		events := make([]*joinLeaveEvent, 0, len(c.index))
+		for _, e := range c.events {
+			if e != nil {
+				events = append(events, e)
+			}
+		}
+		if len(events) > 0 {
+			result[ch] = events
+		}
+	}
+	return result
+}
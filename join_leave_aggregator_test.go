@@ -0,0 +1,78 @@
+package centrifuge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinLeaveAggregator(t *testing.T) {
+	a := newJoinLeaveAggregator()
+	require.Empty(t, a.flush())
+
+	a.add("test", &ClientInfo{ClientID: "1"}, false)
+	a.add("test", &ClientInfo{ClientID: "2"}, false)
+	a.add("test", &ClientInfo{ClientID: "1"}, true)
+	a.add("test", &ClientInfo{ClientID: "3"}, true)
+	a.add("test", &ClientInfo{ClientID: "2"}, false)
+	a.add("other", &ClientInfo{ClientID: "4"}, false)
+	a.add("empty", &ClientInfo{ClientID: "5"}, false)
+	a.add("empty", &ClientInfo{ClientID: "5"}, true)
+
+	result := a.flush()
+	require.Len(t, result, 2)
+	require.Len(t, result["test"], 2)
+	require.Equal(t, "3", result["test"][0].info.ClientID)
+	require.True(t, result["test"][0].leave)
+	require.Equal(t, "2", result["test"][1].info.ClientID)
+	require.False(t, result["test"][1].leave)
+	require.Len(t, result["other"], 1)
+
+	require.Empty(t, a.flush())
+}
+
+func TestNode_JoinLeaveAggregation(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:                     LogLevelTrace,
+		LogHandler:                   func(entry LogEntry) {},
+		JoinLeaveAggregationInterval: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	n.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(_ SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{Options: SubscribeOptions{PushJoinLeave: true}}, nil)
+		})
+	})
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	transport := newTestTransport(cancelFn)
+	transport.sink = make(chan []byte, 100)
+	newTestSubscribedClientWithTransport(t, ctx, n, transport, "42", "test")
+
+	require.NoError(t, n.handleJoin("test", &ClientInfo{ClientID: "short_lived"}))
+	require.NoError(t, n.handleJoin("test", &ClientInfo{ClientID: "joined"}))
+	require.NoError(t, n.handleLeave("test", &ClientInfo{ClientID: "short_lived"}))
+
+	var received []string
+	timeout := time.After(time.Second)
+LOOP:
+	for {
+		select {
+		case data := <-transport.sink:
+			if strings.Contains(string(data), "short_lived") || strings.Contains(string(data), `"joined"`) {
+				received = append(received, string(data))
+			}
+		case <-timeout:
+			break LOOP
+		}
+	}
+	require.Len(t, received, 1)
+	require.Contains(t, received[0], `"join"`)
+	require.Contains(t, received[0], `"joined"`)
+}
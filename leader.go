@@ -0,0 +1,166 @@
+package centrifuge
+
+import (
+	"context"
+	"time"
+)
+
+// LeaderElector decides which node is a leader for a named task. Leader election
+// allows running exactly-one-instance tasks (for example, scheduled broadcasts) in
+// application co-located with Centrifuge nodes – see Node.RunAsLeader.
+type LeaderElector interface {
+	// Campaign tries to acquire leadership for name or renew it if this node is
+	// already a leader. Returns true if node is a leader.
+	Campaign(ctx context.Context, name string) (bool, error)
+	// Resign gives up leadership for name if node is a leader.
+	Resign(ctx context.Context, name string) error
+}
+
+// NodeLeaderElector elects leader using information about running nodes which
+// nodes exchange over control protocol: node with the smallest ID is a leader for
+// all names. It does not require any storage but provides weaker guarantees than
+// RedisLeaderElector – leadership may overlap for several seconds when nodes join
+// or leave.
+type NodeLeaderElector struct {
+	node *Node
+}
+
+var _ LeaderElector = (*NodeLeaderElector)(nil)
+
+// NewNodeLeaderElector creates NodeLeaderElector.
+func NewNodeLeaderElector(n *Node) *NodeLeaderElector {
+	return &NodeLeaderElector{node: n}
+}
+
+// Campaign – see LeaderElector.
+func (e *NodeLeaderElector) Campaign(_ context.Context, _ string) (bool, error) {
+	// Newly started node does not know about other nodes yet, so it waits till
+	// other nodes published their info at least once.
+	if time.Since(time.Unix(e.node.startedAt, 0)) < e.node.config.NodeHeartbeatExpiration {
+		return false, nil
+	}
+	nodeID := e.node.ID()
+	for _, info := range e.node.nodes.list() {
+		if info.Uid < nodeID {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Resign – see LeaderElector. Noop here as leadership is defined by node IDs.
+func (e *NodeLeaderElector) Resign(_ context.Context, _ string) error {
+	return nil
+}
+
+// LeaderLeaseElector is a LeaderElector with leadership which expires after LeaseTTL
+// unless renewed with Campaign. Node.RunAsLeader stops task when leadership was not
+// renewed during LeaseTTL – for example, when node lost connection to storage and
+// other node may have become a leader already.
+type LeaderLeaseElector interface {
+	LeaderElector
+	// LeaseTTL returns time leadership is held after successful Campaign.
+	LeaseTTL() time.Duration
+}
+
+// RunAsLeader runs task while node is a leader for name according to elector. Leadership
+// is checked every interval (zero value means 1 second), task context is canceled as soon
+// as node loses leadership – task is run again when node becomes leader next time. Task
+// must return when its context canceled, if task returns on its own it's run again upon
+// next leadership check. RunAsLeader blocks until ctx canceled or node shut down,
+// leadership is resigned then.
+//
+// On election errors task keeps running only if elector implements LeaderLeaseElector
+// and until lease obtained with the last successful Campaign expires. For other electors
+// task is stopped on election error since leadership can not be confirmed.
+func (n *Node) RunAsLeader(ctx context.Context, elector LeaderElector, name string, interval time.Duration, task func(ctx context.Context)) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var leaseTTL time.Duration
+	if e, ok := elector.(LeaderLeaseElector); ok {
+		leaseTTL = e.LeaseTTL()
+	}
+	var (
+		taskCancel context.CancelFunc
+		taskDone   chan struct{}
+	)
+	leaseTimer := time.NewTimer(time.Hour)
+	stopLeaseTimer(leaseTimer)
+	defer leaseTimer.Stop()
+	stopTask := func() {
+		stopLeaseTimer(leaseTimer)
+		if taskCancel != nil {
+			taskCancel()
+			<-taskDone
+			taskCancel = nil
+		}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if taskCancel != nil {
+			select {
+			case <-taskDone:
+				// Task returned on its own, run it again below if node is still a leader.
+				taskCancel()
+				taskCancel = nil
+			default:
+			}
+		}
+		started := time.Now()
+		leader, err := elector.Campaign(ctx, name)
+		if err != nil {
+			n.logger.log(newLogEntry(LogLevelError, "leader election error", map[string]any{"name": name, "error": err.Error()}))
+			if leaseTTL == 0 {
+				stopTask()
+			}
+		} else if leader {
+			if leaseTTL > 0 {
+				// Lease in storage was renewed not earlier than campaign started.
+				stopLeaseTimer(leaseTimer)
+				leaseTimer.Reset(leaseTTL - time.Since(started))
+			}
+			if taskCancel == nil {
+				taskCancel, taskDone = runLeaderTask(ctx, task)
+			}
+		} else {
+			stopTask()
+		}
+		select {
+		case <-ctx.Done():
+		case <-n.shutdownCh:
+		case <-leaseTimer.C:
+			n.logger.log(newLogEntry(LogLevelError, "leadership lease expired without renewal, stopping task", map[string]any{"name": name}))
+			stopTask()
+			continue
+		case <-ticker.C:
+			continue
+		}
+		stopTask()
+		resignCtx, cancel := context.WithTimeout(context.Background(), interval)
+		_ = elector.Resign(resignCtx, name)
+		cancel()
+		return
+	}
+}
+
+// stopLeaseTimer stops timer and drains its channel so timer can be safely reset.
+func stopLeaseTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
+
+func runLeaderTask(ctx context.Context, task func(ctx context.Context)) (context.CancelFunc, chan struct{}) {
+	taskCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		task(taskCtx)
+	}()
+	return cancel, done
+}
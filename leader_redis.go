@@ -0,0 +1,104 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	_ "embed"
+
+	"github.com/redis/rueidis"
+)
+
+// RedisLeaderElectorConfig is a config for RedisLeaderElector.
+type RedisLeaderElectorConfig struct {
+	// Prefix to use before every key in Redis. By default, "centrifuge" prefix will be used.
+	Prefix string
+	// Shards is a slice of RedisShard to use. At least one shard must be provided.
+	// Leadership keys will be consistently sharded by name over provided Redis shards.
+	Shards []*RedisShard
+	// TTL of leadership. Leader must renew leadership (see LeaderElector.Campaign)
+	// before TTL passes, otherwise other node may become a leader. Zero value means
+	// 10 seconds.
+	TTL time.Duration
+}
+
+// RedisLeaderElector is a LeaderElector which keeps leadership in Redis key with
+// expiration. Only one node can be a leader for name at a time, as long as leader
+// renews leadership more often than RedisLeaderElectorConfig.TTL.
+type RedisLeaderElector struct {
+	node           *Node
+	config         RedisLeaderElectorConfig
+	campaignScript *rueidis.Lua
+	resignScript   *rueidis.Lua
+}
+
+var _ LeaderLeaseElector = (*RedisLeaderElector)(nil)
+
+var (
+	//go:embed internal/redis_lua/leader_campaign.lua
+	leaderCampaignScriptSource string
+	//go:embed internal/redis_lua/leader_resign.lua
+	leaderResignScriptSource string
+)
+
+// NewRedisLeaderElector creates new RedisLeaderElector.
+func NewRedisLeaderElector(n *Node, config RedisLeaderElectorConfig) (*RedisLeaderElector, error) {
+	if len(config.Shards) == 0 {
+		return nil, errors.New("leader elector: no Redis shards provided in configuration")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "centrifuge"
+	}
+	if config.TTL == 0 {
+		config.TTL = 10 * time.Second
+	}
+	return &RedisLeaderElector{
+		node:           n,
+		config:         config,
+		campaignScript: rueidis.NewLuaScript(leaderCampaignScriptSource),
+		resignScript:   rueidis.NewLuaScript(leaderResignScriptSource),
+	}, nil
+}
+
+// LeaseTTL – see LeaderLeaseElector.
+func (e *RedisLeaderElector) LeaseTTL() time.Duration {
+	return e.config.TTL
+}
+
+func (e *RedisLeaderElector) getShard(name string) *RedisShard {
+	if len(e.config.Shards) == 1 {
+		return e.config.Shards[0]
+	}
+	return e.config.Shards[consistentIndex(name, len(e.config.Shards))]
+}
+
+func (e *RedisLeaderElector) leaderKey(s *RedisShard, name string) string {
+	if s.useCluster {
+		name = "{" + name + "}"
+	}
+	return e.config.Prefix + ".leader." + name
+}
+
+// Campaign – see LeaderElector.
+func (e *RedisLeaderElector) Campaign(ctx context.Context, name string) (bool, error) {
+	s := e.getShard(name)
+	args := []string{e.node.ID(), strconv.FormatInt(e.config.TTL.Milliseconds(), 10)}
+	leader, err := e.campaignScript.Exec(ctx, s.client, []string{e.leaderKey(s, name)}, args).AsInt64()
+	if err != nil {
+		return false, fmt.Errorf("error campaigning for leadership: %w", err)
+	}
+	return leader == 1, nil
+}
+
+// Resign – see LeaderElector.
+func (e *RedisLeaderElector) Resign(ctx context.Context, name string) error {
+	s := e.getShard(name)
+	err := e.resignScript.Exec(ctx, s.client, []string{e.leaderKey(s, name)}, []string{e.node.ID()}).Error()
+	if err != nil {
+		return fmt.Errorf("error resigning leadership: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build integration
+
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisLeaderElector(tb testing.TB, n *Node, prefix string) *RedisLeaderElector {
+	s, err := NewRedisShard(n, testSingleRedisConf(0))
+	require.NoError(tb, err)
+	tb.Cleanup(s.Close)
+	e, err := NewRedisLeaderElector(n, RedisLeaderElectorConfig{
+		Prefix: prefix,
+		Shards: []*RedisShard{s},
+		TTL:    500 * time.Millisecond,
+	})
+	require.NoError(tb, err)
+	return e
+}
+
+func TestRedisLeaderElector(t *testing.T) {
+	prefix := getUniquePrefix()
+	e1 := newTestRedisLeaderElector(t, testNode(t), prefix)
+	e2 := newTestRedisLeaderElector(t, testNode(t), prefix)
+	ctx := context.Background()
+
+	leader, err := e1.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+	leader, err = e2.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.False(t, leader)
+	// Renew.
+	leader, err = e1.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+	// Other names are independent.
+	leader, err = e2.Campaign(ctx, "other")
+	require.NoError(t, err)
+	require.True(t, leader)
+
+	// Resign of non-leader does nothing.
+	require.NoError(t, e2.Resign(ctx, "task"))
+	leader, err = e2.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.False(t, leader)
+
+	require.NoError(t, e1.Resign(ctx, "task"))
+	leader, err = e2.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+
+	// Leadership expires if not renewed.
+	time.Sleep(time.Second)
+	leader, err = e1.Campaign(ctx, "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+}
@@ -0,0 +1,184 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeLeaderElector(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	e := NewNodeLeaderElector(node)
+
+	// Just started node is not a leader.
+	leader, err := e.Campaign(context.Background(), "task")
+	require.NoError(t, err)
+	require.False(t, leader)
+
+	node.startedAt = time.Now().Add(-time.Minute).Unix()
+	leader, err = e.Campaign(context.Background(), "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+
+	node.nodes.add(&controlpb.Node{Uid: node.ID() + "-greater"})
+	leader, err = e.Campaign(context.Background(), "task")
+	require.NoError(t, err)
+	require.True(t, leader)
+
+	node.nodes.add(&controlpb.Node{Uid: ""})
+	leader, err = e.Campaign(context.Background(), "task")
+	require.NoError(t, err)
+	require.False(t, leader)
+	require.NoError(t, e.Resign(context.Background(), "task"))
+}
+
+type testLeaderElector struct {
+	mu       sync.Mutex
+	leader   bool
+	err      error
+	resigned bool
+}
+
+func (e *testLeaderElector) set(leader bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = leader
+	e.err = err
+}
+
+func (e *testLeaderElector) Campaign(_ context.Context, _ string) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader, e.err
+}
+
+func (e *testLeaderElector) Resign(_ context.Context, _ string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resigned = true
+	return nil
+}
+
+func TestNode_RunAsLeader(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	e := &testLeaderElector{leader: true}
+	started := make(chan struct{}, 10)
+	stopped := make(chan struct{}, 10)
+	task := func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		node.RunAsLeader(ctx, e, "task", 10*time.Millisecond, task)
+	}()
+
+	waitLeaderTaskEvent(t, started, "task not started")
+	e.set(false, nil)
+	waitLeaderTaskEvent(t, stopped, "task not stopped on leadership loss")
+	e.set(true, nil)
+	waitLeaderTaskEvent(t, started, "task not restarted")
+	// Leadership can't be confirmed without lease.
+	e.set(false, errors.New("boom"))
+	waitLeaderTaskEvent(t, stopped, "task not stopped on election error")
+	e.set(true, nil)
+	waitLeaderTaskEvent(t, started, "task not restarted")
+
+	cancel()
+	waitLeaderTaskEvent(t, stopped, "task not stopped on cancel")
+	<-done
+	e.mu.Lock()
+	require.True(t, e.resigned)
+	e.mu.Unlock()
+}
+
+type testLeaderLeaseElector struct {
+	testLeaderElector
+	ttl time.Duration
+}
+
+func (e *testLeaderLeaseElector) LeaseTTL() time.Duration {
+	return e.ttl
+}
+
+func TestNode_RunAsLeader_LeaseExpiration(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	e := &testLeaderLeaseElector{testLeaderElector: testLeaderElector{leader: true}, ttl: 200 * time.Millisecond}
+	started := make(chan struct{}, 10)
+	stopped := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go node.RunAsLeader(ctx, e, "task", 10*time.Millisecond, func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+	})
+
+	waitLeaderTaskEvent(t, started, "task not started")
+	// Task keeps running on election errors while lease is not expired.
+	e.set(false, errors.New("boom"))
+	select {
+	case <-stopped:
+		require.Fail(t, "task stopped before lease expiration")
+	case <-time.After(100 * time.Millisecond):
+	}
+	waitLeaderTaskEvent(t, stopped, "task not stopped on lease expiration")
+	e.set(true, nil)
+	waitLeaderTaskEvent(t, started, "task not restarted")
+}
+
+func TestNode_RunAsLeader_TaskReturned(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	e := &testLeaderElector{leader: true}
+	started := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go node.RunAsLeader(ctx, e, "task", 10*time.Millisecond, func(ctx context.Context) {
+		started <- struct{}{}
+	})
+	waitLeaderTaskEvent(t, started, "task not started")
+	waitLeaderTaskEvent(t, started, "task not restarted after return")
+}
+
+func TestNode_RunAsLeader_Shutdown(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	e := &testLeaderElector{leader: true}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		node.RunAsLeader(context.Background(), e, "task", 10*time.Millisecond, func(ctx context.Context) {
+			<-ctx.Done()
+		})
+	}()
+	_ = node.Shutdown(context.Background())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "RunAsLeader not finished on shutdown")
+	}
+}
+
+func waitLeaderTaskEvent(t *testing.T, ch chan struct{}, msg string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		require.Fail(t, msg)
+	}
+}
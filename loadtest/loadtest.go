@@ -0,0 +1,552 @@
+// Package loadtest contains a load testing client for Centrifuge nodes. It opens
+// many real WebSocket connections, subscribes them to channels, publishes
+// timestamped messages at a configured rate and reports delivery latency
+// percentiles. It is suitable both for capacity planning against a running
+// node and for detecting performance regressions in CI.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/websocket"
+
+	"github.com/centrifugal/protocol"
+)
+
+// Protocol is a client protocol format used by load test connections.
+type Protocol string
+
+const (
+	// ProtocolJSON uses JSON protocol over text WebSocket frames.
+	ProtocolJSON Protocol = "json"
+	// ProtocolProtobuf uses Protobuf protocol over binary WebSocket frames.
+	ProtocolProtobuf Protocol = "protobuf"
+)
+
+// PublishFunc publishes data into channel. It may be used to publish over
+// server API instead of client protocol.
+type PublishFunc func(ctx context.Context, channel string, data []byte) error
+
+// Config of load test.
+type Config struct {
+	// URL of WebSocket endpoint, ex. ws://localhost:8000/connection/websocket.
+	URL string
+	// Header is sent with every WebSocket handshake request.
+	Header http.Header
+	// Protocol used by connections. By default, ProtocolProtobuf is used.
+	Protocol Protocol
+	// NumConnections is a number of subscriber connections to open.
+	NumConnections int
+	// ConnectConcurrency limits the number of connections established
+	// concurrently. By default, 64.
+	ConnectConcurrency int
+	// Token returns connection token for connection with index i. Publisher
+	// connection uses index -1. If not set connections are established
+	// without token.
+	Token func(i int) string
+	// Channels returns channels connection with index i should subscribe to.
+	// See SpreadChannels for a helper to distribute connections over a fixed
+	// set of channels.
+	Channels func(i int) []string
+	// PublishChannels is a list of channels to publish into in a round-robin
+	// manner. If not set all channels returned by Channels are used.
+	PublishChannels []string
+	// PublishRate is a number of publications per second. Zero means no
+	// publications – only connection and subscription phase is measured.
+	PublishRate int
+	// PayloadSize pads each publication to approximately this number of bytes.
+	PayloadSize int
+	// Duration of publishing phase.
+	Duration time.Duration
+	// DrainTimeout is how long to wait for in-flight publications after
+	// publishing phase finished. By default, 1 second.
+	DrainTimeout time.Duration
+	// Publish allows publishing over server API. If not set a separate client
+	// connection is used to publish over client protocol, so the node must
+	// allow client-side publications into PublishChannels.
+	Publish PublishFunc
+}
+
+// SpreadChannels returns a function for Config.Channels which subscribes
+// connection i to channel prefix + strconv.Itoa(i % numChannels).
+func SpreadChannels(prefix string, numChannels int) func(i int) []string {
+	return func(i int) []string {
+		return []string{prefix + strconv.Itoa(i%numChannels)}
+	}
+}
+
+// Latency contains delivery latency statistics.
+type Latency struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// Result of load test.
+type Result struct {
+	// Connected is a number of successfully connected and subscribed connections.
+	Connected int
+	// ConnectErrors is a number of connections failed to connect or subscribe.
+	ConnectErrors int
+	// ConnectLatency contains connect and subscribe phase latency statistics.
+	ConnectLatency Latency
+	// Published is a number of successfully published messages.
+	Published int64
+	// PublishErrors is a number of failed publish attempts.
+	PublishErrors int64
+	// Expected is a number of publications subscribers should have received.
+	// When publishing over client protocol publications rejected by server
+	// are still accounted here.
+	Expected int64
+	// Received is a number of publications received by subscribers.
+	Received int64
+	// Latency contains publication delivery latency statistics.
+	Latency Latency
+}
+
+// String returns a human-readable summary of Result.
+func (r Result) String() string {
+	return fmt.Sprintf(
+		"connected: %d, connect errors: %d, published: %d, publish errors: %d, received: %d/%d, latency p50: %s, p90: %s, p99: %s, max: %s",
+		r.Connected, r.ConnectErrors, r.Published, r.PublishErrors, r.Received, r.Expected,
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max,
+	)
+}
+
+func (c *Config) setDefaults() error {
+	if c.URL == "" {
+		return errors.New("loadtest: URL required")
+	}
+	if c.Protocol == "" {
+		c.Protocol = ProtocolProtobuf
+	}
+	if c.Protocol != ProtocolJSON && c.Protocol != ProtocolProtobuf {
+		return fmt.Errorf("loadtest: unknown protocol %q", c.Protocol)
+	}
+	if c.ConnectConcurrency <= 0 {
+		c.ConnectConcurrency = 64
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = time.Second
+	}
+	if c.Channels == nil {
+		c.Channels = func(int) []string { return nil }
+	}
+	if c.PublishRate > 0 && c.Duration <= 0 {
+		return errors.New("loadtest: Duration required when PublishRate set")
+	}
+	return nil
+}
+
+// Run load test with Config. It returns when publishing phase is finished and
+// all connections are closed, or when ctx is canceled.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if err := cfg.setDefaults(); err != nil {
+		return Result{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var result Result
+
+	subscribers := map[string]int64{}
+	conns := make([]*conn, cfg.NumConnections)
+	connectLatencies := make([]time.Duration, cfg.NumConnections)
+	sem := make(chan struct{}, cfg.ConnectConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.NumConnections; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			closeConns(conns)
+			return result, ctx.Err()
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			started := time.Now()
+			c, err := dial(ctx, cfg, i)
+			if err != nil {
+				return
+			}
+			conns[i] = c
+			connectLatencies[i] = time.Since(started)
+		}(i)
+	}
+	wg.Wait()
+
+	okLatencies := connectLatencies[:0]
+	for i, c := range conns {
+		if c == nil {
+			result.ConnectErrors++
+			continue
+		}
+		result.Connected++
+		okLatencies = append(okLatencies, connectLatencies[i])
+		for _, ch := range c.channels {
+			subscribers[ch]++
+		}
+		go c.readLoop()
+	}
+	result.ConnectLatency = computeLatency(okLatencies)
+
+	publishChannels := cfg.PublishChannels
+	if len(publishChannels) == 0 {
+		for ch := range subscribers {
+			publishChannels = append(publishChannels, ch)
+		}
+		sort.Strings(publishChannels)
+	}
+
+	if cfg.PublishRate > 0 && len(publishChannels) > 0 {
+		publish := cfg.Publish
+		var publisher *conn
+		if publish == nil {
+			var err error
+			publisher, err = dial(ctx, cfg, -1)
+			if err != nil {
+				closeConns(conns)
+				return result, fmt.Errorf("loadtest: error connecting publisher: %w", err)
+			}
+			defer func() { _ = publisher.close() }()
+			go publisher.readLoop()
+			publish = publisher.publish
+		}
+		result.Published, result.PublishErrors = runPublisher(ctx, cfg, publish, publishChannels, subscribers, &result.Expected)
+
+		drainTimer := time.NewTimer(cfg.DrainTimeout)
+	drain:
+		for {
+			var received int64
+			for _, c := range conns {
+				if c != nil {
+					received += c.received.Load()
+				}
+			}
+			if received >= result.Expected && (publisher == nil || publisher.replies.Load() >= result.Published) {
+				break
+			}
+			select {
+			case <-drainTimer.C:
+				break drain
+			case <-ctx.Done():
+				break drain
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		drainTimer.Stop()
+
+		if publisher != nil {
+			// Client protocol publish errors arrive asynchronously in replies.
+			numErrors := publisher.publishErrors.Load()
+			result.PublishErrors += numErrors
+			result.Published -= numErrors
+		}
+	}
+
+	closeConns(conns)
+
+	var latencies []time.Duration
+	for _, c := range conns {
+		if c == nil {
+			continue
+		}
+		<-c.closed
+		result.Received += c.received.Load()
+		latencies = append(latencies, c.latencies...)
+	}
+	result.Latency = computeLatency(latencies)
+	return result, ctx.Err()
+}
+
+func runPublisher(ctx context.Context, cfg Config, publish PublishFunc, channels []string, subscribers map[string]int64, expected *int64) (int64, int64) {
+	var published, publishErrors int64
+	interval := time.Second / time.Duration(cfg.PublishRate)
+	if interval <= 0 {
+		interval = time.Nanosecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(cfg.Duration)
+	defer deadline.Stop()
+
+	padding := ""
+	if cfg.PayloadSize > 0 {
+		padding = strings.Repeat("x", cfg.PayloadSize)
+	}
+
+	var i int
+	for {
+		select {
+		case <-ctx.Done():
+			return published, publishErrors
+		case <-deadline.C:
+			return published, publishErrors
+		case <-ticker.C:
+			ch := channels[i%len(channels)]
+			i++
+			if err := publish(ctx, ch, encodePayload(time.Now(), padding)); err != nil {
+				publishErrors++
+				continue
+			}
+			published++
+			*expected += subscribers[ch]
+		}
+	}
+}
+
+const payloadPrefix = `{"t":`
+
+func encodePayload(t time.Time, padding string) []byte {
+	b := make([]byte, 0, len(payloadPrefix)+32+len(padding))
+	b = append(b, payloadPrefix...)
+	b = strconv.AppendInt(b, t.UnixNano(), 10)
+	if padding != "" {
+		b = append(b, `,"p":"`...)
+		b = append(b, padding...)
+		b = append(b, '"')
+	}
+	b = append(b, '}')
+	return b
+}
+
+func decodePayload(data []byte) (time.Time, bool) {
+	s := string(data)
+	if !strings.HasPrefix(s, payloadPrefix) {
+		return time.Time{}, false
+	}
+	s = s[len(payloadPrefix):]
+	end := strings.IndexAny(s, ",}")
+	if end < 0 {
+		return time.Time{}, false
+	}
+	ts, err := strconv.ParseInt(s[:end], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ts), true
+}
+
+func computeLatency(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	var sum time.Duration
+	for _, s := range samples {
+		sum += s
+	}
+	return Latency{
+		Min:  samples[0],
+		Mean: sum / time.Duration(len(samples)),
+		P50:  percentile(samples, 0.5),
+		P90:  percentile(samples, 0.9),
+		P99:  percentile(samples, 0.99),
+		Max:  samples[len(samples)-1],
+	}
+}
+
+// percentile expects sorted samples.
+func percentile(samples []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(samples)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return samples[idx]
+}
+
+func closeConns(conns []*conn) {
+	for _, c := range conns {
+		if c != nil {
+			_ = c.close()
+		}
+	}
+}
+
+type conn struct {
+	ws       *websocket.Conn
+	protocol Protocol
+	encoder  protocol.CommandEncoder
+	channels []string
+
+	writeMu sync.Mutex
+	nextID  atomic.Uint32
+
+	received      atomic.Int64
+	replies       atomic.Int64
+	publishErrors atomic.Int64
+	latencies     []time.Duration
+	closed        chan struct{}
+}
+
+func dial(ctx context.Context, cfg Config, i int) (*conn, error) {
+	url := cfg.URL
+	if cfg.Protocol == ProtocolProtobuf {
+		if strings.Contains(url, "?") {
+			url += "&format=protobuf"
+		} else {
+			url += "?format=protobuf"
+		}
+	}
+	dialer := &websocket.Dialer{}
+	ws, resp, _, err := dialer.DialContext(ctx, url, cfg.Header)
+	if err != nil {
+		return nil, err
+	}
+	if resp != nil && resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+	c := &conn{
+		ws:       ws,
+		protocol: cfg.Protocol,
+		closed:   make(chan struct{}),
+	}
+	if cfg.Protocol == ProtocolProtobuf {
+		c.encoder = protocol.NewProtobufCommandEncoder()
+	} else {
+		c.encoder = protocol.NewJSONCommandEncoder()
+	}
+	stop := context.AfterFunc(ctx, func() { _ = ws.Close() })
+	defer stop()
+
+	var token string
+	if cfg.Token != nil {
+		token = cfg.Token(i)
+	}
+	if _, err := c.call(&protocol.Command{Connect: &protocol.ConnectRequest{Token: token}}); err != nil {
+		_ = ws.Close()
+		return nil, err
+	}
+	if i >= 0 {
+		for _, ch := range cfg.Channels(i) {
+			if _, err := c.call(&protocol.Command{Subscribe: &protocol.SubscribeRequest{Channel: ch}}); err != nil {
+				_ = ws.Close()
+				return nil, err
+			}
+			c.channels = append(c.channels, ch)
+		}
+	}
+	return c, nil
+}
+
+func (c *conn) close() error {
+	return c.ws.Close()
+}
+
+func (c *conn) write(cmd *protocol.Command) error {
+	data, err := c.encoder.Encode(cmd)
+	if err != nil {
+		return err
+	}
+	messageType := websocket.TextMessage
+	if c.protocol == ProtocolProtobuf {
+		messageType = websocket.BinaryMessage
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(messageType, data)
+}
+
+func (c *conn) decoder(data []byte) protocol.ReplyDecoder {
+	if c.protocol == ProtocolProtobuf {
+		return protocol.NewProtobufReplyDecoder(data)
+	}
+	return protocol.NewJSONReplyDecoder(data)
+}
+
+// call sends command and synchronously waits for its reply. Must only be
+// used before readLoop started.
+func (c *conn) call(cmd *protocol.Command) (*protocol.Reply, error) {
+	cmd.Id = c.nextID.Add(1)
+	if err := c.write(cmd); err != nil {
+		return nil, err
+	}
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		decoder := c.decoder(data)
+		for {
+			reply, err := decoder.Decode()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			if reply.Id != cmd.Id {
+				c.handleReply(reply)
+				continue
+			}
+			if reply.Error != nil {
+				return nil, fmt.Errorf("loadtest: %d: %s", reply.Error.Code, reply.Error.Message)
+			}
+			return reply, nil
+		}
+	}
+}
+
+func (c *conn) publish(_ context.Context, channel string, data []byte) error {
+	return c.write(&protocol.Command{
+		Id:      c.nextID.Add(1),
+		Publish: &protocol.PublishRequest{Channel: channel, Data: data},
+	})
+}
+
+func (c *conn) readLoop() {
+	defer close(c.closed)
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		decoder := c.decoder(data)
+		for {
+			reply, err := decoder.Decode()
+			if err != nil {
+				break
+			}
+			c.handleReply(reply)
+		}
+	}
+}
+
+func (c *conn) handleReply(reply *protocol.Reply) {
+	if reply.Id > 0 {
+		c.replies.Add(1)
+		if reply.Error != nil {
+			c.publishErrors.Add(1)
+		}
+		return
+	}
+	if reply.Push == nil {
+		// Server ping, respond with pong.
+		_ = c.write(&protocol.Command{})
+		return
+	}
+	if reply.Push.Pub == nil {
+		return
+	}
+	if t, ok := decodePayload(reply.Push.Pub.Data); ok {
+		c.latencies = append(c.latencies, time.Since(t))
+		c.received.Add(1)
+	}
+}
@@ -0,0 +1,173 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t testing.TB) (*centrifuge.Node, string) {
+	node, err := centrifuge.New(centrifuge.Config{})
+	require.NoError(t, err)
+	node.OnConnecting(func(ctx context.Context, e centrifuge.ConnectEvent) (centrifuge.ConnectReply, error) {
+		return centrifuge.ConnectReply{
+			Credentials: &centrifuge.Credentials{UserID: "loadtest"},
+		}, nil
+	})
+	node.OnConnect(func(client *centrifuge.Client) {
+		client.OnSubscribe(func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
+			cb(centrifuge.SubscribeReply{}, nil)
+		})
+		client.OnPublish(func(e centrifuge.PublishEvent, cb centrifuge.PublishCallback) {
+			if strings.HasPrefix(e.Channel, "forbidden") {
+				cb(centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied)
+				return
+			}
+			cb(centrifuge.PublishReply{}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+
+	mux := http.NewServeMux()
+	mux.Handle("/connection/websocket", centrifuge.NewWebsocketHandler(node, centrifuge.WebsocketConfig{}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return node, "ws" + server.URL[4:] + "/connection/websocket"
+}
+
+func TestRun(t *testing.T) {
+	for _, p := range []Protocol{ProtocolJSON, ProtocolProtobuf} {
+		t.Run(string(p), func(t *testing.T) {
+			_, url := newTestServer(t)
+			result, err := Run(context.Background(), Config{
+				URL:            url,
+				Protocol:       p,
+				NumConnections: 20,
+				Channels:       SpreadChannels("test", 4),
+				PublishRate:    200,
+				PayloadSize:    64,
+				Duration:       200 * time.Millisecond,
+				DrainTimeout:   5 * time.Second,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 20, result.Connected)
+			require.Zero(t, result.ConnectErrors)
+			require.Positive(t, result.Published)
+			require.Zero(t, result.PublishErrors)
+			require.Equal(t, result.Published*5, result.Expected)
+			require.Equal(t, result.Expected, result.Received)
+			require.Positive(t, result.Latency.Max)
+			require.LessOrEqual(t, result.Latency.P50, result.Latency.P99)
+			require.LessOrEqual(t, result.Latency.P99, result.Latency.Max)
+		})
+	}
+}
+
+func TestRun_ServerPublish(t *testing.T) {
+	node, url := newTestServer(t)
+	result, err := Run(context.Background(), Config{
+		URL:             url,
+		NumConnections:  10,
+		Channels:        SpreadChannels("test", 2),
+		PublishChannels: []string{"test0"},
+		PublishRate:     100,
+		Duration:        100 * time.Millisecond,
+		DrainTimeout:    5 * time.Second,
+		Publish: func(ctx context.Context, channel string, data []byte) error {
+			_, err := node.Publish(channel, data)
+			return err
+		},
+	})
+	require.NoError(t, err)
+	require.Positive(t, result.Published)
+	require.Equal(t, result.Published*5, result.Expected)
+	require.Equal(t, result.Expected, result.Received)
+}
+
+func TestRun_PublishErrors(t *testing.T) {
+	_, url := newTestServer(t)
+	result, err := Run(context.Background(), Config{
+		URL:             url,
+		NumConnections:  1,
+		PublishChannels: []string{"forbidden"},
+		PublishRate:     100,
+		Duration:        100 * time.Millisecond,
+		DrainTimeout:    100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Published)
+	require.Positive(t, result.PublishErrors)
+	require.Zero(t, result.Received)
+}
+
+func TestRun_ConnectErrors(t *testing.T) {
+	result, err := Run(context.Background(), Config{
+		URL:            "ws://127.0.0.1:1/connection/websocket",
+		NumConnections: 3,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Connected)
+	require.Equal(t, 3, result.ConnectErrors)
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := Run(context.Background(), Config{})
+	require.Error(t, err)
+	_, err = Run(context.Background(), Config{URL: "ws://localhost", Protocol: "xml"})
+	require.Error(t, err)
+	_, err = Run(context.Background(), Config{URL: "ws://localhost", PublishRate: 1})
+	require.Error(t, err)
+}
+
+func TestPayload(t *testing.T) {
+	now := time.Now()
+	for _, padding := range []string{"", "xxx"} {
+		tm, ok := decodePayload(encodePayload(now, padding))
+		require.True(t, ok)
+		require.Equal(t, now.UnixNano(), tm.UnixNano())
+	}
+	_, ok := decodePayload([]byte(`{"x":1}`))
+	require.False(t, ok)
+	_, ok = decodePayload([]byte(`{"t":abc}`))
+	require.False(t, ok)
+}
+
+func TestComputeLatency(t *testing.T) {
+	require.Equal(t, Latency{}, computeLatency(nil))
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	l := computeLatency(samples)
+	require.Equal(t, time.Millisecond, l.Min)
+	require.Equal(t, 50*time.Millisecond, l.P50)
+	require.Equal(t, 90*time.Millisecond, l.P90)
+	require.Equal(t, 99*time.Millisecond, l.P99)
+	require.Equal(t, 100*time.Millisecond, l.Max)
+	require.Equal(t, 50500*time.Microsecond, l.Mean)
+}
+
+func BenchmarkDeliveryLatency(b *testing.B) {
+	_, url := newTestServer(b)
+	for i := 0; i < b.N; i++ {
+		result, err := Run(context.Background(), Config{
+			URL:            url,
+			NumConnections: 100,
+			Channels:       SpreadChannels("bench", 10),
+			PublishRate:    1000,
+			Duration:       time.Second,
+		})
+		require.NoError(b, err)
+		b.ReportMetric(float64(result.Latency.P50.Microseconds()), "p50_us")
+		b.ReportMetric(float64(result.Latency.P99.Microseconds()), "p99_us")
+		b.ReportMetric(float64(result.Received), "received")
+	}
+}
@@ -1,5 +1,11 @@
 package centrifuge
 
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // LogLevel describes the chosen log level.
 type LogLevel int
 
@@ -69,18 +75,26 @@ func NewLogEntry(level LogLevel, message string, fields ...map[string]any) LogEn
 type LogHandler func(LogEntry)
 
 func newLogger(level LogLevel, handler LogHandler) *logger {
-	return &logger{
-		level:   level,
+	l := &logger{
 		handler: handler,
 	}
+	l.level.Store(int64(level))
+	return l
 }
 
 // logger can log entries.
 type logger struct {
-	level   LogLevel
+	level   atomic.Int64
 	handler LogHandler
 }
 
+func (l *logger) setLevel(level LogLevel) {
+	if l == nil {
+		return
+	}
+	l.level.Store(int64(level))
+}
+
 // log calls log handler with provided LogEntry.
 func (l *logger) log(entry LogEntry) {
 	if l == nil {
@@ -96,5 +110,36 @@ func (l *logger) enabled(level LogLevel) bool {
 	if l == nil {
 		return false
 	}
-	return level >= l.level && l.level != LogLevelNone
+	current := LogLevel(l.level.Load())
+	return level >= current && current != LogLevelNone
+}
+
+// logSampler limits frequency of repeated log entries – for example, about messages
+// dropped in hot paths.
+type logSampler struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+func newLogSampler(interval time.Duration) *logSampler {
+	return &logSampler{interval: interval}
+}
+
+// allow returns true if entry should be logged now. In this case it also returns
+// the number of entries suppressed since the previous logged one.
+func (s *logSampler) allow() (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if !s.last.IsZero() && now.Sub(s.last) < s.interval {
+		s.suppressed++
+		return false, 0
+	}
+	suppressed := s.suppressed
+	s.last = now
+	s.suppressed = 0
+	return true, suppressed
 }
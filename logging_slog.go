@@ -0,0 +1,46 @@
+package centrifuge
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevelTraceSlog is a slog.Level used for LogLevelTrace entries since slog
+// has no trace level out of the box.
+const LogLevelTraceSlog = slog.LevelDebug - 4
+
+// NewSlogLogHandler returns LogHandler which writes log entries into provided
+// slog.Logger. LogEntry fields are passed as slog attributes. Use it together
+// with Config.LogLevel to integrate Centrifuge logs into application logging
+// pipeline. Other structured loggers (zap, zerolog, etc.) may be connected in
+// a similar way – by implementing LogHandler function which maps LogEntry to
+// logger call.
+func NewSlogLogHandler(logger *slog.Logger) LogHandler {
+	return func(entry LogEntry) {
+		level := slogLevel(entry.Level)
+		ctx := context.Background()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		attrs := make([]slog.Attr, 0, len(entry.Fields))
+		for k, v := range entry.Fields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+		logger.LogAttrs(ctx, level, entry.Message, attrs...)
+	}
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelTrace:
+		return LogLevelTraceSlog
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
@@ -1,7 +1,11 @@
 package centrifuge
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,3 +47,32 @@ func TestNewLogEntry(t *testing.T) {
 	require.NotNil(t, entry.Fields)
 	require.Equal(t, true, entry.Fields["one"].(bool))
 }
+
+func TestNewSlogLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	h := NewSlogLogHandler(logger)
+
+	h(newLogEntry(LogLevelTrace, "trace message"))
+	require.Zero(t, buf.Len())
+
+	h(newLogEntry(LogLevelWarn, "warn message", map[string]any{"client": "42"}))
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "WARN", record["level"])
+	require.Equal(t, "warn message", record["msg"])
+	require.Equal(t, "42", record["client"])
+}
+
+func TestLogSampler(t *testing.T) {
+	s := newLogSampler(time.Hour)
+	ok, suppressed := s.allow()
+	require.True(t, ok)
+	require.Equal(t, 0, suppressed)
+	ok, _ = s.allow()
+	require.False(t, ok)
+	s.last = time.Now().Add(-2 * time.Hour)
+	ok, suppressed = s.allow()
+	require.True(t, ok)
+	require.Equal(t, 1, suppressed)
+}
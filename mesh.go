@@ -0,0 +1,378 @@
+package centrifuge
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Mesh is a set of direct TCP connections between nodes used by GossipBroker and
+// DirectControl. Every mesh frame is:
+// length (4 bytes, big endian, includes type) | type (1 byte) | payload.
+// Connections are one-directional: dialing node only writes frames after initial
+// hello exchange, so every pair of nodes uses two connections.
+//
+// Nodes must authenticate each other – with mutual TLS or with a shared secret.
+// With shared secret accepting node starts with challenge frame containing random
+// nonce, dialing node answers with hello containing HMAC-SHA256 of nonce and its
+// node ID, so hello can not be replayed by someone who captured it.
+const (
+	meshFrameChallenge byte = 'n'
+	meshFrameHello     byte = 'h'
+	meshFrameGossip    byte = 'g'
+	meshFramePush      byte = 'p'
+	meshFrameControl   byte = 'c'
+)
+
+const (
+	meshMaxFrameSize  = 64 << 20
+	meshPeerQueueSize = 4096
+	meshDialTimeout   = 5 * time.Second
+	meshHelloTimeout  = 5 * time.Second
+	meshWriteTimeout  = 10 * time.Second
+	meshNonceSize     = 32
+)
+
+var errMeshAuthRequired = errors.New("mutual TLS or shared secret required")
+
+// meshAuth describes how nodes of mesh authenticate each other.
+type meshAuth struct {
+	tls    *MutualTLS
+	secret []byte
+}
+
+func newMeshAuth(mtls *MutualTLS, secret string) (meshAuth, error) {
+	if mtls == nil && secret == "" {
+		return meshAuth{}, errMeshAuthRequired
+	}
+	return meshAuth{tls: mtls, secret: []byte(secret)}, nil
+}
+
+func (a meshAuth) mac(nonce []byte, nodeID string) []byte {
+	h := hmac.New(sha256.New, a.secret)
+	h.Write(nonce)
+	h.Write([]byte(nodeID))
+	return h.Sum(nil)
+}
+
+func listenMesh(addr string, auth meshAuth) (net.Listener, error) {
+	if auth.tls != nil {
+		return tls.Listen("tcp", addr, auth.tls.ServerConfig())
+	}
+	return net.Listen("tcp", addr)
+}
+
+// meshServer accepts connections from other nodes and passes received frames to
+// handler.
+type meshServer struct {
+	node     *Node
+	listener net.Listener
+	auth     meshAuth
+	handler  func(frameType byte, payload []byte) error
+
+	mu      sync.Mutex
+	conns   map[net.Conn]struct{}
+	closed  bool
+	closeCh chan struct{}
+}
+
+func newMeshServer(n *Node, listener net.Listener, auth meshAuth, handler func(frameType byte, payload []byte) error) *meshServer {
+	return &meshServer{
+		node:     n,
+		listener: listener,
+		auth:     auth,
+		handler:  handler,
+		conns:    map[net.Conn]struct{}{},
+		closeCh:  make(chan struct{}),
+	}
+}
+
+func (s *meshServer) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.closeCh)
+	_ = s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *meshServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.closeCh:
+				return
+			default:
+			}
+			s.node.logger.log(newLogEntry(LogLevelError, "mesh accept error", map[string]any{"error": err.Error()}))
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn reads frames from incoming connection. First frame must be hello
+// with ID of remote node, it's answered with hello containing ID of this node.
+func (s *meshServer) handleConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(meshHelloTimeout))
+	nodeID, err := s.acceptHello(conn, r)
+	if err != nil {
+		s.node.logger.log(newLogEntry(LogLevelWarn, "mesh handshake failed", map[string]any{"remote": conn.RemoteAddr().String(), "error": err.Error()}))
+		return
+	}
+	if err := writeMeshFrame(conn, meshFrameHello, []byte(s.node.ID())); err != nil {
+		return
+	}
+	if nodeID == s.node.ID() {
+		// Connection to itself.
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	for {
+		frameType, payload, err := readMeshFrame(r)
+		if err != nil {
+			return
+		}
+		if err := s.handler(frameType, payload); err != nil {
+			s.node.logger.log(newLogEntry(LogLevelError, "error handling mesh frame", map[string]any{"error": err.Error()}))
+		}
+	}
+}
+
+// acceptHello reads hello frame of dialing node and returns its ID. With shared
+// secret hello must contain valid HMAC of challenge sent first.
+func (s *meshServer) acceptHello(conn net.Conn, r *bufio.Reader) (string, error) {
+	var nonce []byte
+	if len(s.auth.secret) > 0 {
+		nonce = make([]byte, meshNonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		if err := writeMeshFrame(conn, meshFrameChallenge, nonce); err != nil {
+			return "", err
+		}
+	}
+	frameType, payload, err := readMeshFrame(r)
+	if err != nil {
+		return "", err
+	}
+	if frameType != meshFrameHello {
+		return "", errors.New("unexpected mesh frame")
+	}
+	if len(s.auth.secret) == 0 {
+		return string(payload), nil
+	}
+	if len(payload) < sha256.Size {
+		return "", errors.New("malformed mesh hello")
+	}
+	mac, nodeID := payload[:sha256.Size], string(payload[sha256.Size:])
+	if !hmac.Equal(mac, s.auth.mac(nonce, nodeID)) {
+		return "", errors.New("invalid mesh hello signature")
+	}
+	return nodeID, nil
+}
+
+const (
+	meshDropReasonQueueFull   = "queue_full"
+	meshDropReasonUnknownNode = "unknown_node"
+)
+
+// meshDropLogInterval is a minimal interval between logs about dropped frames.
+const meshDropLogInterval = 10 * time.Second
+
+// meshDrops counts and logs frames which were not sent to other nodes.
+type meshDrops struct {
+	node      *Node
+	component string
+	sampler   *logSampler
+}
+
+func newMeshDrops(n *Node, component string) *meshDrops {
+	return &meshDrops{node: n, component: component, sampler: newLogSampler(meshDropLogInterval)}
+}
+
+func (d *meshDrops) dropped(reason string, target string) {
+	d.node.metrics.incMeshDropped(d.component, reason)
+	if ok, suppressed := d.sampler.allow(); ok {
+		d.node.logger.log(newLogEntry(LogLevelWarn, "mesh frame dropped", map[string]any{"component": d.component, "reason": reason, "target": target, "suppressed": suppressed}))
+	}
+}
+
+// meshPeer maintains outgoing connection to another node.
+type meshPeer struct {
+	node          *Node
+	addr          string
+	auth          meshAuth
+	retryInterval time.Duration
+	// onConnect is called with ID of remote node when connection established.
+	onConnect func(nodeID string)
+
+	queue   chan []byte
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+func newMeshPeer(n *Node, addr string, auth meshAuth, retryInterval time.Duration, onConnect func(nodeID string)) *meshPeer {
+	return &meshPeer{
+		node:          n,
+		addr:          addr,
+		auth:          auth,
+		retryInterval: retryInterval,
+		onConnect:     onConnect,
+		queue:         make(chan []byte, meshPeerQueueSize),
+		closeCh:       make(chan struct{}),
+	}
+}
+
+func (p *meshPeer) close() {
+	p.once.Do(func() { close(p.closeCh) })
+}
+
+// send enqueues frame, frame is dropped if queue is full – false returned in
+// this case.
+func (p *meshPeer) send(frameType byte, data []byte) bool {
+	select {
+	case p.queue <- encodeMeshFrame(frameType, data):
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *meshPeer) run() {
+	for {
+		self, err := p.connectAndWrite()
+		if self {
+			// Peer address points to this node, keep peer to not redial.
+			return
+		}
+		if err != nil && p.node.LogEnabled(LogLevelDebug) {
+			p.node.logger.log(newLogEntry(LogLevelDebug, "mesh peer connection error", map[string]any{"addr": p.addr, "error": err.Error()}))
+		}
+		// Add jitter to not reconnect to all peers at once.
+		delay := p.retryInterval + time.Duration(mathrand.Int63n(int64(p.retryInterval)))
+		select {
+		case <-p.closeCh:
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (p *meshPeer) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: meshDialTimeout}
+	if p.auth.tls != nil {
+		return tls.DialWithDialer(dialer, "tcp", p.addr, p.auth.tls.ClientConfig())
+	}
+	return dialer.Dial("tcp", p.addr)
+}
+
+func (p *meshPeer) connectAndWrite() (bool, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(meshHelloTimeout))
+	r := bufio.NewReader(conn)
+	hello := []byte(p.node.ID())
+	if len(p.auth.secret) > 0 {
+		frameType, nonce, err := readMeshFrame(r)
+		if err != nil {
+			return false, err
+		}
+		if frameType != meshFrameChallenge {
+			return false, errors.New("unexpected mesh frame")
+		}
+		hello = append(p.auth.mac(nonce, p.node.ID()), hello...)
+	}
+	if err := writeMeshFrame(conn, meshFrameHello, hello); err != nil {
+		return false, err
+	}
+	frameType, payload, err := readMeshFrame(r)
+	if err != nil {
+		return false, err
+	}
+	if frameType != meshFrameHello {
+		return false, errors.New("unexpected mesh frame")
+	}
+	if string(payload) == p.node.ID() {
+		return true, nil
+	}
+	if p.onConnect != nil {
+		p.onConnect(string(payload))
+	}
+	_ = conn.SetDeadline(time.Time{})
+	for {
+		select {
+		case <-p.closeCh:
+			return false, nil
+		case frame := <-p.queue:
+			_ = conn.SetWriteDeadline(time.Now().Add(meshWriteTimeout))
+			if _, err := conn.Write(frame); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+func encodeMeshFrame(frameType byte, data []byte) []byte {
+	frame := make([]byte, 5+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)+1))
+	frame[4] = frameType
+	copy(frame[5:], data)
+	return frame
+}
+
+func writeMeshFrame(w io.Writer, frameType byte, data []byte) error {
+	_, err := w.Write(encodeMeshFrame(frameType, data))
+	return err
+}
+
+func readMeshFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size == 0 || size > meshMaxFrameSize {
+		return 0, nil, fmt.Errorf("invalid mesh frame size: %d", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return 0, nil, err
+	}
+	return frame[0], frame[1:], nil
+}
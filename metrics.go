@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/centrifugal/protocol"
@@ -29,6 +30,7 @@ type metrics struct {
 	replyErrorCount               *prometheus.CounterVec
 	serverUnsubscribeCount        *prometheus.CounterVec
 	serverDisconnectCount         *prometheus.CounterVec
+	connectionDurationHistogram   prometheus.Histogram
 	commandDurationSummary        *prometheus.SummaryVec
 	surveyDurationSummary         *prometheus.SummaryVec
 	recoverCount                  *prometheus.CounterVec
@@ -37,6 +39,17 @@ type metrics struct {
 	transportMessagesSentSize     *prometheus.CounterVec
 	transportMessagesReceived     *prometheus.CounterVec
 	transportMessagesReceivedSize *prometheus.CounterVec
+	publishCount                  *prometheus.CounterVec
+	subscribeCount                *prometheus.CounterVec
+	clientQueueEnqueuedCount      *prometheus.CounterVec
+	clientQueueOverflowCount      *prometheus.CounterVec
+
+	transportMessagesSentCache     sync.Map
+	transportMessagesReceivedCache sync.Map
+
+	// numPublications and numMessagesSent are used to calculate NodeRates.
+	numPublications atomic.Uint64
+	numMessagesSent atomic.Uint64
 
 	messagesReceivedCountPublication prometheus.Counter
 	messagesReceivedCountJoin        prometheus.Counter
@@ -63,6 +76,10 @@ type metrics struct {
 	actionCountHistoryRemove             prometheus.Counter
 	actionCountSurvey                    prometheus.Counter
 	actionCountNotify                    prometheus.Counter
+	actionCountSend                      prometheus.Counter
+	actionCountRequestShutdown           prometheus.Counter
+	actionCountRevoke                    prometheus.Counter
+	actionCountHistoryCacheHit           prometheus.Counter
 
 	recoverCountYes prometheus.Counter
 	recoverCountNo  prometheus.Counter
@@ -86,6 +103,14 @@ type metrics struct {
 
 	pubSubLagHistogram         prometheus.Histogram
 	broadcastDurationHistogram prometheus.Histogram
+
+	webhookDurationHistogram *prometheus.HistogramVec
+	webhookErrorCount        *prometheus.CounterVec
+	webhookInflightGauge     *prometheus.GaugeVec
+
+	consumerSkippedCount *prometheus.CounterVec
+
+	meshDroppedCount *prometheus.CounterVec
 }
 
 func (m *metrics) observeCommandDuration(frameType protocol.FrameType, d time.Duration) {
@@ -131,6 +156,29 @@ func (m *metrics) observeBroadcastDuration(started time.Time) {
 	m.broadcastDurationHistogram.Observe(time.Since(started).Seconds())
 }
 
+// observeWebhookRequest tracks webhook request in flight, returned function must be
+// called when request finished.
+func (m *metrics) observeWebhookRequest(endpoint string, eventType string) func(err error) {
+	inflight := m.webhookInflightGauge.WithLabelValues(endpoint, eventType)
+	inflight.Inc()
+	started := time.Now()
+	return func(err error) {
+		inflight.Dec()
+		m.webhookDurationHistogram.WithLabelValues(endpoint, eventType).Observe(time.Since(started).Seconds())
+		if err != nil {
+			m.webhookErrorCount.WithLabelValues(endpoint, eventType).Inc()
+		}
+	}
+}
+
+func (m *metrics) incConsumerSkipped(consumer string, reason string) {
+	m.consumerSkippedCount.WithLabelValues(consumer, reason).Inc()
+}
+
+func (m *metrics) incMeshDropped(component string, reason string) {
+	m.meshDroppedCount.WithLabelValues(component, reason).Inc()
+}
+
 func (m *metrics) setBuildInfo(version string) {
 	m.buildInfoGauge.WithLabelValues(version).Set(1)
 }
@@ -196,18 +244,14 @@ type transportMessagesReceived struct {
 	counterReceivedSize prometheus.Counter
 }
 
-var (
-	transportMessagesSentCache     sync.Map
-	transportMessagesReceivedCache sync.Map
-)
-
 func (m *metrics) incTransportMessagesSent(transport string, frameType protocol.FrameType, channelGroup string, size int) {
+	m.numMessagesSent.Add(1)
 	labels := transportMessageLabels{
 		Transport:    transport,
 		ChannelGroup: channelGroup,
 		FrameType:    frameType.String(),
 	}
-	counters, ok := transportMessagesSentCache.Load(labels)
+	counters, ok := m.transportMessagesSentCache.Load(labels)
 	if !ok {
 		counterSent := m.transportMessagesSent.WithLabelValues(transport, labels.FrameType, channelGroup)
 		counterSentSize := m.transportMessagesSentSize.WithLabelValues(transport, labels.FrameType, channelGroup)
@@ -215,7 +259,7 @@ func (m *metrics) incTransportMessagesSent(transport string, frameType protocol.
 			counterSent:     counterSent,
 			counterSentSize: counterSentSize,
 		}
-		transportMessagesSentCache.Store(labels, counters)
+		m.transportMessagesSentCache.Store(labels, counters)
 	}
 	counters.(transportMessagesSent).counterSent.Inc()
 	counters.(transportMessagesSent).counterSentSize.Add(float64(size))
@@ -227,7 +271,7 @@ func (m *metrics) incTransportMessagesReceived(transport string, frameType proto
 		ChannelGroup: channelGroup,
 		FrameType:    frameType.String(),
 	}
-	counters, ok := transportMessagesReceivedCache.Load(labels)
+	counters, ok := m.transportMessagesReceivedCache.Load(labels)
 	if !ok {
 		counterReceived := m.transportMessagesReceived.WithLabelValues(transport, labels.FrameType, channelGroup)
 		counterReceivedSize := m.transportMessagesReceivedSize.WithLabelValues(transport, labels.FrameType, channelGroup)
@@ -235,14 +279,108 @@ func (m *metrics) incTransportMessagesReceived(transport string, frameType proto
 			counterReceived:     counterReceived,
 			counterReceivedSize: counterReceivedSize,
 		}
-		transportMessagesReceivedCache.Store(labels, counters)
+		m.transportMessagesReceivedCache.Store(labels, counters)
 	}
 	counters.(transportMessagesReceived).counterReceived.Inc()
 	counters.(transportMessagesReceived).counterReceivedSize.Add(float64(size))
 }
 
+func (m *metrics) incPublish(channelNamespace string) {
+	m.publishCount.WithLabelValues(channelNamespace).Inc()
+}
+
+func (m *metrics) incSubscribe(channelNamespace string) {
+	m.subscribeCount.WithLabelValues(channelNamespace).Inc()
+}
+
+// clientQueueCounters are client queue counters of channel namespace. Resolved once
+// per broadcast to not look up channel namespace label for every subscriber.
+type clientQueueCounters struct {
+	enqueued prometheus.Counter
+	overflow prometheus.Counter
+}
+
+func (m *metrics) getClientQueueCounters(channelNamespace string) clientQueueCounters {
+	return clientQueueCounters{
+		enqueued: m.clientQueueEnqueuedCount.WithLabelValues(channelNamespace),
+		overflow: m.clientQueueOverflowCount.WithLabelValues(channelNamespace),
+	}
+}
+
+// channelNamespaceOtherLabel used for channel namespaces exceeding cardinality limit.
+const channelNamespaceOtherLabel = "_other"
+
+// channelNamespaceLabeler resolves channel_namespace label values keeping
+// the number of unique values under a limit.
+type channelNamespaceLabeler struct {
+	resolve        func(channel string) string
+	maxCardinality int
+	mu             sync.RWMutex
+	seen           map[string]struct{}
+}
+
+func newChannelNamespaceLabeler(resolve func(channel string) string, maxCardinality int) *channelNamespaceLabeler {
+	return &channelNamespaceLabeler{
+		resolve:        resolve,
+		maxCardinality: maxCardinality,
+		seen:           map[string]struct{}{},
+	}
+}
+
+func (l *channelNamespaceLabeler) label(channel string) string {
+	value := l.resolve(channel)
+	if l.maxCardinality <= 0 {
+		return value
+	}
+	l.mu.RLock()
+	_, ok := l.seen[value]
+	l.mu.RUnlock()
+	if ok {
+		return value
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[value]; ok {
+		return value
+	}
+	if len(l.seen) >= l.maxCardinality {
+		return channelNamespaceOtherLabel
+	}
+	l.seen[value] = struct{}{}
+	return value
+}
+
+// disconnectReasons used to build reason label of server disconnect metrics. Custom
+// disconnects have arbitrary reasons, so they are labeled with "other" reason
+// to keep metric cardinality under control.
+var disconnectReasons = map[uint32]string{}
+
+func init() {
+	for _, d := range []Disconnect{
+		DisconnectShutdown, DisconnectServerError, DisconnectExpired, DisconnectSubExpired,
+		DisconnectSlow, DisconnectWriteError, DisconnectInsufficientState, DisconnectForceReconnect,
+		DisconnectNoPong, DisconnectTooManyRequests, DisconnectInvalidToken, DisconnectBadRequest,
+		DisconnectStale, DisconnectForceNoReconnect, DisconnectConnectionLimit, DisconnectChannelLimit,
+		DisconnectInappropriateProtocol, DisconnectPermissionDenied, DisconnectNotAvailable,
+		DisconnectTooManyErrors,
+	} {
+		disconnectReasons[d.Code] = d.Reason
+	}
+}
+
+func disconnectReasonLabel(code uint32) string {
+	if reason, ok := disconnectReasons[code]; ok {
+		return reason
+	}
+	return "other"
+}
+
 func (m *metrics) incServerDisconnect(code uint32) {
-	m.serverDisconnectCount.WithLabelValues(strconv.FormatUint(uint64(code), 10)).Inc()
+	m.serverDisconnectCount.WithLabelValues(strconv.FormatUint(uint64(code), 10), disconnectReasonLabel(code)).Inc()
+}
+
+func (m *metrics) observeConnectionDuration(duration time.Duration) {
+	m.connectionDurationHistogram.Observe(duration.Seconds())
 }
 
 func (m *metrics) incServerUnsubscribe(code uint32) {
@@ -253,6 +391,7 @@ func (m *metrics) incMessagesSent(msgType string) {
 	switch msgType {
 	case "publication":
 		m.messagesSentCountPublication.Inc()
+		m.numPublications.Add(1)
 	case "join":
 		m.messagesSentCountJoin.Inc()
 	case "leave":
@@ -311,6 +450,14 @@ func (m *metrics) incActionCount(action string) {
 		m.actionCountSurvey.Inc()
 	case "notify":
 		m.actionCountNotify.Inc()
+	case "send":
+		m.actionCountSend.Inc()
+	case "request_shutdown":
+		m.actionCountRequestShutdown.Inc()
+	case "revoke":
+		m.actionCountRevoke.Inc()
+	case "history_cache_hit":
+		m.actionCountHistoryCacheHit.Inc()
 	}
 }
 
@@ -420,8 +567,16 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 		Namespace: metricsNamespace,
 		Subsystem: "client",
 		Name:      "num_server_disconnects",
-		Help:      "Number of server initiated disconnects.",
-	}, []string{"code"})
+		Help:      "Number of server initiated disconnects by code and reason.",
+	}, []string{"code", "reason"})
+
+	m.connectionDurationHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "client",
+		Name:      "connection_duration_seconds",
+		Help:      "Duration of authenticated client connections in seconds. Its count minus server disconnects gives number of connections closed by clients.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 300, 600, 1800, 3600, 3 * 3600, 6 * 3600, 12 * 3600, 24 * 3600},
+	})
 
 	m.commandDurationSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
 		Namespace:  metricsNamespace,
@@ -473,6 +628,34 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 		Help:      "Size in bytes of messages received from client connections over specific transport.",
 	}, []string{"transport", "frame_type", "channel_namespace"})
 
+	m.publishCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "node",
+		Name:      "publish_count",
+		Help:      "Number of publications made by channel namespace.",
+	}, []string{"channel_namespace"})
+
+	m.subscribeCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "node",
+		Name:      "subscribe_count",
+		Help:      "Number of channel subscriptions made by channel namespace.",
+	}, []string{"channel_namespace"})
+
+	m.clientQueueEnqueuedCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "client",
+		Name:      "queue_enqueued_count",
+		Help:      "Number of messages added to client connection queues by channel namespace.",
+	}, []string{"channel_namespace"})
+
+	m.clientQueueOverflowCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "client",
+		Name:      "queue_overflow_count",
+		Help:      "Number of client connection queue overflows by channel namespace of message caused overflow.",
+	}, []string{"channel_namespace"})
+
 	m.pubSubLagHistogram = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Namespace: metricsNamespace,
 		Subsystem: "node",
@@ -491,6 +674,44 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 			1.0, 2.5, 5.0, 10.0, // Second resolution.
 		}})
 
+	m.webhookDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "webhook",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of webhook requests in seconds, every attempt is observed.",
+		Buckets: []float64{
+			0.001, 0.005, 0.010, 0.025, 0.050, 0.100, 0.250, 0.500, // Millisecond resolution.
+			1.0, 2.5, 5.0, 10.0, // Second resolution.
+		}}, []string{"endpoint", "event"})
+
+	m.webhookErrorCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "webhook",
+		Name:      "errors_count",
+		Help:      "Number of failed webhook requests, every attempt is counted.",
+	}, []string{"endpoint", "event"})
+
+	m.webhookInflightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "webhook",
+		Name:      "inflight_requests",
+		Help:      "Number of webhook requests in flight.",
+	}, []string{"endpoint", "event"})
+
+	m.consumerSkippedCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "consumer",
+		Name:      "skipped_messages_count",
+		Help:      "Number of consumed messages skipped due to permanent errors.",
+	}, []string{"consumer", "reason"})
+
+	m.meshDroppedCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "mesh",
+		Name:      "dropped_frames_count",
+		Help:      "Number of frames not sent to other nodes over direct node connections.",
+	}, []string{"component", "reason"})
+
 	m.messagesReceivedCountPublication = m.messagesReceivedCount.WithLabelValues("publication")
 	m.messagesReceivedCountJoin = m.messagesReceivedCount.WithLabelValues("join")
 	m.messagesReceivedCountLeave = m.messagesReceivedCount.WithLabelValues("leave")
@@ -516,6 +737,10 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 	m.actionCountHistoryRemove = m.actionCount.WithLabelValues("history_remove")
 	m.actionCountSurvey = m.actionCount.WithLabelValues("survey")
 	m.actionCountNotify = m.actionCount.WithLabelValues("notify")
+	m.actionCountSend = m.actionCount.WithLabelValues("send")
+	m.actionCountRequestShutdown = m.actionCount.WithLabelValues("request_shutdown")
+	m.actionCountRevoke = m.actionCount.WithLabelValues("revoke")
+	m.actionCountHistoryCacheHit = m.actionCount.WithLabelValues("history_cache_hit")
 
 	m.recoverCountYes = m.recoverCount.WithLabelValues("yes")
 	m.recoverCountNo = m.recoverCount.WithLabelValues("no")
@@ -597,6 +822,18 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 	if err := registry.Register(m.transportMessagesReceivedSize); err != nil && !errors.As(err, &alreadyRegistered) {
 		return nil, err
 	}
+	if err := registry.Register(m.publishCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.subscribeCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.clientQueueEnqueuedCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.clientQueueOverflowCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
 	if err := registry.Register(m.buildInfoGauge); err != nil && !errors.As(err, &alreadyRegistered) {
 		return nil, err
 	}
@@ -609,5 +846,20 @@ func initMetricsRegistry(registry prometheus.Registerer, metricsNamespace string
 	if err := registry.Register(m.broadcastDurationHistogram); err != nil && !errors.As(err, &alreadyRegistered) {
 		return nil, err
 	}
+	if err := registry.Register(m.webhookDurationHistogram); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.webhookErrorCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.webhookInflightGauge); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.consumerSkippedCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
+	if err := registry.Register(m.meshDroppedCount); err != nil && !errors.As(err, &alreadyRegistered) {
+		return nil, err
+	}
 	return m, nil
 }
@@ -1,11 +1,14 @@
 package centrifuge
 
 import (
+	"context"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/centrifugal/protocol"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -34,3 +37,118 @@ func BenchmarkTransportMessagesReceived(b *testing.B) {
 		}
 	})
 }
+
+func TestNode_MetricsRegisterer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	node, err := New(Config{
+		MetricsRegisterer: registry,
+		MetricsNamespace:  "custom",
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err = node.Publish("test", []byte(`{}`))
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var found bool
+	for _, family := range families {
+		if family.GetName() == "custom_node_messages_sent_count" {
+			found = true
+		}
+	}
+	require.True(t, found)
+	require.Equal(t, prometheus.Gatherer(registry), node.metricsGatherer())
+}
+
+func TestChannelNamespaceLabeler(t *testing.T) {
+	l := newChannelNamespaceLabeler(func(channel string) string {
+		return strings.Split(channel, ":")[0]
+	}, 2)
+	require.Equal(t, "a", l.label("a:1"))
+	require.Equal(t, "b", l.label("b:1"))
+	require.Equal(t, channelNamespaceOtherLabel, l.label("c:1"))
+	require.Equal(t, "a", l.label("a:2"))
+
+	l = newChannelNamespaceLabeler(func(channel string) string {
+		return channel
+	}, 0)
+	for i := 0; i < 10; i++ {
+		require.Equal(t, strconv.Itoa(i), l.label(strconv.Itoa(i)))
+	}
+}
+
+func TestNode_ChannelNamespaceMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	node, err := New(Config{
+		MetricsRegisterer: registry,
+		GetChannelNamespaceLabel: func(channel string) string {
+			return strings.Split(channel, ":")[0]
+		},
+		ChannelNamespaceLabelForPublish:     true,
+		ChannelNamespaceLabelMaxCardinality: 1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	for _, ch := range []string{"chat:1", "chat:2", "news:1"} {
+		_, err = node.Publish(ch, []byte(`{}`))
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, float64(2), testutil.ToFloat64(node.metrics.publishCount.WithLabelValues("chat")))
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.publishCount.WithLabelValues(channelNamespaceOtherLabel)))
+}
+
+func TestDisconnectReasonLabel(t *testing.T) {
+	require.Equal(t, "slow", disconnectReasonLabel(DisconnectSlow.Code))
+	require.Equal(t, "shutdown", disconnectReasonLabel(DisconnectShutdown.Code))
+	require.Equal(t, "other", disconnectReasonLabel(4000))
+}
+
+func TestClient_DisconnectMetrics(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	node.OnConnect(func(client *Client) {})
+
+	client := newTestConnectedClientV2(t, node, "42")
+	code := strconv.FormatUint(uint64(DisconnectForceNoReconnect.Code), 10)
+	before := testutil.ToFloat64(node.metrics.serverDisconnectCount.WithLabelValues(code, DisconnectForceNoReconnect.Reason))
+	require.NoError(t, client.close(DisconnectForceNoReconnect))
+	require.Equal(t, before+1, testutil.ToFloat64(node.metrics.serverDisconnectCount.WithLabelValues(code, DisconnectForceNoReconnect.Reason)))
+}
+
+func TestClient_ChannelNamespaceQueueMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	node, err := New(Config{
+		LogLevel:          LogLevelTrace,
+		LogHandler:        func(entry LogEntry) {},
+		MetricsRegisterer: registry,
+		GetChannelNamespaceLabel: func(channel string) string {
+			return strings.Split(channel, ":")[0]
+		},
+		ChannelNamespaceLabelForClientQueue: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	node.OnConnect(func(client *Client) {})
+
+	client := newTestConnectedClientV2(t, node, "42")
+	require.NoError(t, client.transportEnqueue([]byte(`{}`), "chat:1", protocol.FrameTypePushPublication))
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.clientQueueEnqueuedCount.WithLabelValues("chat")))
+	require.Equal(t, float64(0), testutil.ToFloat64(node.metrics.clientQueueOverflowCount.WithLabelValues("chat")))
+
+	// Counters resolved upon broadcast.
+	_, _ = node.hub.addSub("chat:3", subInfo{client: client})
+	require.NoError(t, node.hub.broadcastPublication("chat:3", StreamPosition{}, &Publication{Data: []byte(`{}`)}, nil, nil))
+	require.Equal(t, float64(2), testutil.ToFloat64(node.metrics.clientQueueEnqueuedCount.WithLabelValues("chat")))
+
+	client.messageWriter.setMaxQueueSize(1)
+	require.Error(t, client.transportEnqueue([]byte(`{"data":"overflow"}`), "chat:2", protocol.FrameTypePushPublication))
+	require.Equal(t, float64(3), testutil.ToFloat64(node.metrics.clientQueueEnqueuedCount.WithLabelValues("chat")))
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.clientQueueOverflowCount.WithLabelValues("chat")))
+}
@@ -0,0 +1,122 @@
+package centrifuge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChannelNamespace describes options of channels with a common prefix. Namespace
+// of channel is a part of channel name before Config.ChannelNamespaceBoundary,
+// for example channel "chat:index" belongs to namespace "chat". Channels without
+// boundary belong to namespace with empty Name.
+type ChannelNamespace struct {
+	// Name of namespace.
+	Name string
+	// ChannelOptions of namespace channels.
+	ChannelOptions
+}
+
+// ChannelOptions are options applied by Node to channels of namespace. Options
+// are applied on top of the options set in event handlers, so handlers may only
+// enable additional features for a channel.
+type ChannelOptions struct {
+	// Presence enables presence for client subscriptions – i.e. sets
+	// SubscribeOptions.EmitPresence.
+	Presence bool
+	// JoinLeave enables sending join/leave messages for client subscriptions
+	// – i.e. sets SubscribeOptions.EmitJoinLeave and SubscribeOptions.PushJoinLeave.
+	JoinLeave bool
+	// HistorySize and HistoryTTL are used for publications made with Node.Publish
+	// (including publications from clients) without WithHistory or WithoutHistory option.
+	HistorySize int
+	HistoryTTL  time.Duration
+	// MaxSubscribers limits the number of channel subscribers on this Node. Client
+	// subscription over limit is rejected with ErrorLimitExceeded. Zero value means
+	// no limit. The limit is per node, not cluster-wide: with N nodes a channel may
+	// have up to N*MaxSubscribers subscribers in total.
+	MaxSubscribers int
+	// PublicationMaxSize limits size of publication data in bytes for publications
+	// made with Node.Publish (including publications from clients). Publications
+	// over limit are rejected with ErrorPublicationTooLarge. Zero value means
+	// Config.PublicationMaxSize is used.
+	PublicationMaxSize int
+	// ValidatePayload if set is called for every publication made with Node.Publish
+	// (including publications from clients) to validate publication data – for example,
+	// against protobuf or JSON schema. If *Error is returned then it's passed to the
+	// caller as is, so application may use custom error codes to describe validation
+	// problem. Other errors result into ErrorInvalidPayload. Must be safe for concurrent
+	// use.
+	ValidatePayload func(channel string, data []byte) error
+	// AllowPublishForClient allows clients to publish into channels when no
+	// Client.OnPublish handler set. By default, client publications require
+	// OnPublish handler.
+	AllowPublishForClient bool
+	// Ephemeral enables automatic removal of channel history and presence when the
+	// last channel subscriber on all nodes leaves. Cleanup happens after
+	// EphemeralGracePeriod, so channel state survives short reconnects. Useful for
+	// short-lived channels (rooms) to avoid unbounded growth of keys in Redis.
+	Ephemeral bool
+	// EphemeralGracePeriod is a time to wait after the last subscriber left ephemeral
+	// channel before removing channel state. Zero value means 30 seconds.
+	EphemeralGracePeriod time.Duration
+}
+
+type channelNamespaces struct {
+	boundary   string
+	namespaces map[string]ChannelOptions
+}
+
+func newChannelNamespaces(c Config) (*channelNamespaces, error) {
+	if len(c.Namespaces) == 0 {
+		return nil, nil
+	}
+	boundary := c.ChannelNamespaceBoundary
+	if boundary == "" {
+		boundary = ":"
+	}
+	namespaces := make(map[string]ChannelOptions, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		if strings.Contains(ns.Name, boundary) {
+			return nil, fmt.Errorf("namespace name %q contains namespace boundary", ns.Name)
+		}
+		if _, ok := namespaces[ns.Name]; ok {
+			return nil, fmt.Errorf("duplicate namespace name %q", ns.Name)
+		}
+		if ns.HistorySize < 0 || ns.HistoryTTL < 0 || ns.MaxSubscribers < 0 || ns.PublicationMaxSize < 0 || ns.EphemeralGracePeriod < 0 {
+			return nil, fmt.Errorf("negative option value in namespace %q", ns.Name)
+		}
+		if (ns.HistorySize > 0) != (ns.HistoryTTL > 0) {
+			return nil, fmt.Errorf("both history size and history TTL must be set in namespace %q", ns.Name)
+		}
+		namespaces[ns.Name] = ns.ChannelOptions
+	}
+	return &channelNamespaces{boundary: boundary, namespaces: namespaces}, nil
+}
+
+// name returns namespace name of channel.
+func (c *channelNamespaces) name(channel string) string {
+	name, _, found := strings.Cut(channel, c.boundary)
+	if !found {
+		return ""
+	}
+	return name
+}
+
+func (c *channelNamespaces) resolve(channel string) (ChannelOptions, bool) {
+	opts, ok := c.namespaces[c.name(channel)]
+	return opts, ok
+}
+
+// ChannelOptions returns options of channel namespace configured over Config.Namespaces.
+// The second return value is false if channel does not belong to any configured
+// namespace – such channels are rejected for client subscribe and publish requests
+// with ErrorUnknownChannel. If Config.Namespaces is empty ChannelOptions always
+// returns zero ChannelOptions and true.
+func (n *Node) ChannelOptions(channel string) (ChannelOptions, bool) {
+	namespaces := n.reloadableConfig().namespaces
+	if namespaces == nil {
+		return ChannelOptions{}, true
+	}
+	return namespaces.resolve(channel)
+}
@@ -0,0 +1,192 @@
+package centrifuge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNamespaceNode(t *testing.T, namespaces []ChannelNamespace) *Node {
+	node, err := New(Config{
+		LogLevel:   LogLevelTrace,
+		LogHandler: func(entry LogEntry) {},
+		Namespaces: namespaces,
+	})
+	require.NoError(t, err)
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(event SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+	return node
+}
+
+func TestNew_InvalidNamespaces(t *testing.T) {
+	for _, namespaces := range [][]ChannelNamespace{
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "a:b"}},
+		{{Name: "a", ChannelOptions: ChannelOptions{HistorySize: 10}}},
+		{{Name: "a", ChannelOptions: ChannelOptions{MaxSubscribers: -1}}},
+	} {
+		_, err := New(Config{Namespaces: namespaces})
+		require.Error(t, err)
+	}
+}
+
+func TestNode_ChannelOptions(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	opts, ok := node.ChannelOptions("any")
+	require.True(t, ok)
+	require.Equal(t, ChannelOptions{}, opts)
+
+	node = newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "", ChannelOptions: ChannelOptions{Presence: true}},
+		{Name: "chat", ChannelOptions: ChannelOptions{JoinLeave: true}},
+	})
+	opts, ok = node.ChannelOptions("index")
+	require.True(t, ok)
+	require.True(t, opts.Presence)
+	opts, ok = node.ChannelOptions("chat:index")
+	require.True(t, ok)
+	require.True(t, opts.JoinLeave)
+	_, ok = node.ChannelOptions("unknown:index")
+	require.False(t, ok)
+}
+
+func TestNamespace_Subscribe(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "chat", ChannelOptions: ChannelOptions{Presence: true, JoinLeave: true, MaxSubscribers: 1}},
+	})
+
+	client := newTestConnectedClientV2(t, node, "42")
+	rwWrapper := testReplyWriterWrapper()
+	err := client.handleSubscribe(&protocol.SubscribeRequest{Channel: "unknown:index"}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorUnknownChannel, err)
+
+	subscribeClientV2(t, client, "chat:index")
+	chCtx := client.channels["chat:index"]
+	require.True(t, channelHasFlag(chCtx.flags, flagEmitPresence))
+	require.True(t, channelHasFlag(chCtx.flags, flagEmitJoinLeave))
+	require.True(t, channelHasFlag(chCtx.flags, flagPushJoinLeave))
+
+	// Already subscribed client gets ErrorAlreadySubscribed even when limit reached.
+	err = client.handleSubscribe(&protocol.SubscribeRequest{Channel: "chat:index"}, &protocol.Command{Id: 2}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorAlreadySubscribed, err)
+
+	client2 := newTestConnectedClientV2(t, node, "43")
+	err = client2.handleSubscribe(&protocol.SubscribeRequest{Channel: "chat:index"}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorLimitExceeded, err)
+	// Rejected channel is not kept as subscribing.
+	require.NotContains(t, client2.channels, "chat:index")
+	require.NotContains(t, client.channels, "unknown:index")
+}
+
+func TestNamespace_Publish(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "chat", ChannelOptions: ChannelOptions{HistorySize: 10, HistoryTTL: time.Minute, AllowPublishForClient: true}},
+		{Name: "news"},
+	})
+
+	client := newTestConnectedClientV2(t, node, "42")
+	rwWrapper := testReplyWriterWrapper()
+	err := client.handlePublish(&protocol.PublishRequest{Channel: "news:index", Data: []byte(`{}`)}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorNotAvailable, err)
+
+	err = client.handlePublish(&protocol.PublishRequest{Channel: "chat:index", Data: []byte(`{}`)}, &protocol.Command{Id: 2}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	require.Len(t, rwWrapper.replies, 1)
+	require.Nil(t, rwWrapper.replies[0].Error)
+
+	// History options of namespace applied.
+	_, err = node.Publish("chat:index", []byte(`{}`))
+	require.NoError(t, err)
+	result, err := node.History("chat:index", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 2)
+
+	// WithoutHistory skips history of namespace.
+	_, err = node.Publish("chat:index", []byte(`{}`), WithoutHistory())
+	require.NoError(t, err)
+	result, err = node.History("chat:index", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 2)
+
+	client.OnPublish(func(e PublishEvent, cb PublishCallback) {
+		cb(PublishReply{}, nil)
+	})
+	err = client.handlePublish(&protocol.PublishRequest{Channel: "unknown:index", Data: []byte(`{}`)}, &protocol.Command{Id: 3}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorUnknownChannel, err)
+}
+
+func TestNamespace_PublicationMaxSize(t *testing.T) {
+	node, err := New(Config{
+		LogLevel:           LogLevelTrace,
+		LogHandler:         func(entry LogEntry) {},
+		PublicationMaxSize: 4,
+		Namespaces: []ChannelNamespace{
+			{Name: ""},
+			{Name: "big", ChannelOptions: ChannelOptions{PublicationMaxSize: 8, AllowPublishForClient: true}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err = node.Publish("test", []byte(`"1234"`))
+	require.ErrorIs(t, err, ErrorPublicationTooLarge)
+	_, err = node.Publish("test", []byte(`"12"`))
+	require.NoError(t, err)
+	_, err = node.Publish("big:test", []byte(`"123456"`))
+	require.NoError(t, err)
+	_, err = node.Publish("big:test", []byte(`"1234567"`))
+	require.ErrorIs(t, err, ErrorPublicationTooLarge)
+
+	client := newTestConnectedClientV2(t, node, "42")
+	rwWrapper := testReplyWriterWrapper()
+	err = client.handlePublish(&protocol.PublishRequest{Channel: "big:test", Data: []byte(`"1234567"`)}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.Equal(t, ErrorPublicationTooLarge, err)
+}
+
+func TestNamespace_ValidatePayload(t *testing.T) {
+	errCustom := &Error{Code: 4000, Message: "schema mismatch"}
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: ""},
+		{Name: "json", ChannelOptions: ChannelOptions{
+			AllowPublishForClient: true,
+			ValidatePayload: func(channel string, data []byte) error {
+				if !json.Valid(data) {
+					return errors.New("malformed JSON")
+				}
+				if string(data) == `"custom"` {
+					return errCustom
+				}
+				return nil
+			},
+		}},
+	})
+
+	_, err := node.Publish("json:test", []byte(`{"a": 1}`))
+	require.NoError(t, err)
+	_, err = node.Publish("json:test", []byte(`{"a": 1`))
+	require.ErrorIs(t, err, ErrorInvalidPayload)
+	_, err = node.Publish("json:test", []byte(`"custom"`))
+	require.ErrorIs(t, err, errCustom)
+	// Other namespaces are not validated.
+	_, err = node.Publish("test", []byte(`{"a": 1`))
+	require.NoError(t, err)
+
+	client := newTestConnectedClientV2(t, node, "42")
+	rwWrapper := testReplyWriterWrapper()
+	err = client.handlePublish(&protocol.PublishRequest{Channel: "json:test", Data: []byte(`{"a": 1`)}, &protocol.Command{Id: 1}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	require.Len(t, rwWrapper.replies, 1)
+	require.Equal(t, ErrorInvalidPayload.toProto(), rwWrapper.replies[0].Error)
+}
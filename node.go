@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/centrifugal/centrifuge/internal/controlpb"
@@ -44,10 +45,18 @@ type Node struct {
 	broker Broker
 	// presenceManager is responsible for presence information management.
 	presenceManager PresenceManager
+	// controlTransport if set is used to send control messages instead of broker.
+	controlTransport ControlTransport
+	// webhookEmitter if set receives events of Node lifecycle.
+	webhookEmitter *WebhookEmitter
 	// nodes contains registry of known nodes.
 	nodes *nodeRegistry
+	// infoData is an application data attached to node control frames.
+	infoData []byte
 	// metrics registry.
 	metrics *metrics
+	// channelNamespaceLabeler is set when Config.GetChannelNamespaceLabel provided.
+	channelNamespaceLabeler *channelNamespaceLabeler
 	// shutdown is a flag which is only true when node is going to shut down.
 	shutdown bool
 	// shutdownCh is a channel which is closed when node shutdown initiated.
@@ -79,12 +88,38 @@ type Node struct {
 	surveyMu       sync.RWMutex
 	surveyID       uint64
 
-	notificationHandler NotificationHandler
-	nodeInfoSendHandler NodeInfoSendHandler
+	notificationHandler    NotificationHandler
+	nodeInfoSendHandler    NodeInfoSendHandler
+	nodeJoinHandler        NodeJoinHandler
+	nodeLeaveHandler       NodeLeaveHandler
+	startHandlers          []StartHandler
+	shutdownRequestHandler ShutdownRequestHandler
+	shutdownHandlers       []ShutdownHandler
 
-	emulationSurveyHandler *emulationSurveyHandler
+	publishMiddlewares []PublishMiddleware
+	publishFunc        PublishFunc
+
+	commandMiddlewares []CommandMiddleware
+	commandFunc        CommandFunc
+
+	// internalSurveyHandlers contain handlers for survey ops reserved by Centrifuge library.
+	internalSurveyHandlers map[string]SurveyHandler
 
 	mediums map[string]*channelMedium
+
+	broadcastPool *broadcastWorkerPool
+	presenceCache *presenceCache
+	historyCache  *historyCache
+
+	joinLeaveAggregator *joinLeaveAggregator
+	firehose            *firehose
+	reloadable          atomic.Pointer[reloadableConfig]
+	debugEvents         debugEvents
+	ratesTracker        *nodeRatesTracker
+	revocations         *revocationRegistry
+
+	ephemeralMu     sync.Mutex
+	ephemeralTimers map[string]*time.Timer
 }
 
 const (
@@ -94,9 +129,18 @@ const (
 
 // New creates Node with provided Config.
 func New(c Config) (*Node, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid node config: %w", err)
+	}
 	if c.NodeInfoMetricsAggregateInterval == 0 {
 		c.NodeInfoMetricsAggregateInterval = 60 * time.Second
 	}
+	if c.NodeHeartbeatInterval == 0 {
+		c.NodeHeartbeatInterval = nodeInfoPublishInterval
+	}
+	if c.NodeHeartbeatExpiration == 0 {
+		c.NodeHeartbeatExpiration = c.NodeHeartbeatInterval*2 + time.Second
+	}
 	if c.ClientPresenceUpdateInterval == 0 {
 		c.ClientPresenceUpdateInterval = 25 * time.Second
 	}
@@ -124,6 +168,9 @@ func New(c Config) (*Node, error) {
 	if c.HistoryMetaTTL == 0 {
 		c.HistoryMetaTTL = 30 * 24 * time.Hour // 30 days by default.
 	}
+	if c.HistoryCacheTTL == 0 {
+		c.HistoryCacheTTL = time.Minute
+	}
 
 	uidObj, err := uuid.NewRandom()
 	if err != nil {
@@ -150,30 +197,65 @@ func New(c Config) (*Node, error) {
 	}
 
 	n := &Node{
-		uid:            uid,
-		nodes:          newNodeRegistry(uid),
-		config:         c,
-		startedAt:      time.Now().Unix(),
-		shutdownCh:     make(chan struct{}),
-		logger:         lg,
-		controlEncoder: controlproto.NewProtobufEncoder(),
-		controlDecoder: controlproto.NewProtobufDecoder(),
-		clientEvents:   &eventHub{},
-		subLocks:       subLocks,
-		subDissolver:   dissolve.New(numSubDissolverWorkers),
-		nowTimeGetter:  nowtime.Get,
-		surveyRegistry: make(map[uint64]chan survey),
-		mediums:        map[string]*channelMedium{},
-	}
-	n.emulationSurveyHandler = newEmulationSurveyHandler(n)
-
-	if m, err := initMetricsRegistry(prometheus.DefaultRegisterer, c.MetricsNamespace); err != nil {
+		uid:             uid,
+		nodes:           newNodeRegistry(uid),
+		config:          c,
+		startedAt:       time.Now().Unix(),
+		shutdownCh:      make(chan struct{}),
+		logger:          lg,
+		controlEncoder:  controlproto.NewProtobufEncoder(),
+		controlDecoder:  controlproto.NewProtobufDecoder(),
+		clientEvents:    &eventHub{},
+		subLocks:        subLocks,
+		subDissolver:    dissolve.New(numSubDissolverWorkers),
+		nowTimeGetter:   nowtime.Get,
+		surveyRegistry:  make(map[uint64]chan survey),
+		mediums:         map[string]*channelMedium{},
+		ephemeralTimers: map[string]*time.Timer{},
+		ratesTracker:    newNodeRatesTracker(),
+		revocations:     newRevocationRegistry(),
+	}
+	n.internalSurveyHandlers = map[string]SurveyHandler{
+		emulationOp:          newEmulationSurveyHandler(n).HandleEmulation,
+		channelsOp:           n.handleChannelsSurvey,
+		connectionsOp:        n.handleConnectionsSurvey,
+		runtimeStatsOp:       n.handleRuntimeStatsSurvey,
+		updatePresenceOp:     n.handleUpdatePresenceSurvey,
+		onlineUsersOp:        n.handleOnlineUsersSurvey,
+		channelSubscribersOp: n.handleChannelSubscribersSurvey,
+		clientStateOp:        n.handleClientStateSurvey,
+	}
+
+	rc, err := newReloadableConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	n.reloadable.Store(rc)
+
+	if c.GetChannelNamespaceLabel != nil {
+		n.channelNamespaceLabeler = newChannelNamespaceLabeler(c.GetChannelNamespaceLabel, c.ChannelNamespaceLabelMaxCardinality)
+	}
+
+	if m, err := initMetricsRegistry(c.MetricsRegisterer, c.MetricsNamespace); err != nil {
 		return nil, err
 	} else {
 		n.metrics = m
 	}
 
 	n.hub = newHub(lg, n.metrics, c.ClientChannelPositionMaxTimeLag.Milliseconds())
+	if c.HistoryCacheSize > 0 {
+		n.historyCache = newHistoryCache(c.HistoryCacheSize)
+	}
+	if c.PresenceCacheTTL > 0 {
+		n.presenceCache = newPresenceCache(c.PresenceCacheTTL)
+	}
+	if c.JoinLeaveAggregationInterval > 0 {
+		n.joinLeaveAggregator = newJoinLeaveAggregator()
+	}
+	if c.BroadcastWorkerPoolSize > 0 {
+		n.broadcastPool = newBroadcastWorkerPool(c.BroadcastWorkerPoolSize, c.BroadcastWorkerQueueSize, c.BroadcastChunkSize, c.BroadcastOverflowPolicy)
+		n.hub.setBroadcastWorkerPool(n.broadcastPool)
+	}
 
 	b, err := NewMemoryBroker(n, MemoryBrokerConfig{})
 	if err != nil {
@@ -214,11 +296,85 @@ func (n *Node) subLock(ch string) *sync.Mutex {
 	return n.subLocks[index(ch, numSubLocks)]
 }
 
+// NewNode creates Node configured with options. It's an alternative to New which
+// allows setting Broker, PresenceManager, event handlers and middlewares in one call:
+//
+//	node, err := centrifuge.NewNode(
+//		centrifuge.WithConfig(centrifuge.Config{}),
+//		centrifuge.WithBroker(func(n *centrifuge.Node) (centrifuge.Broker, error) {
+//			return centrifuge.NewRedisBroker(n, centrifuge.RedisBrokerConfig{Shards: shards})
+//		}),
+//		centrifuge.WithOnConnect(func(client *centrifuge.Client) {}),
+//	)
+//
+// Node.Run must still be called to start Node.
+func NewNode(opts ...NodeOption) (*Node, error) {
+	options := &nodeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	n, err := New(options.config)
+	if err != nil {
+		return nil, err
+	}
+	if options.brokerFunc != nil {
+		b, err := options.brokerFunc(n)
+		if err != nil {
+			return nil, err
+		}
+		n.SetBroker(b)
+	}
+	if options.presenceManagerFunc != nil {
+		m, err := options.presenceManagerFunc(n)
+		if err != nil {
+			return nil, err
+		}
+		n.SetPresenceManager(m)
+	}
+	if options.connectingHandler != nil {
+		n.OnConnecting(options.connectingHandler)
+	}
+	if options.connectHandler != nil {
+		n.OnConnect(options.connectHandler)
+	}
+	if len(options.publishMiddlewares) > 0 {
+		n.UsePublishMiddleware(options.publishMiddlewares...)
+	}
+	if len(options.commandMiddlewares) > 0 {
+		n.UseCommandMiddleware(options.commandMiddlewares...)
+	}
+	for _, h := range options.startHandlers {
+		n.OnStart(h)
+	}
+	for _, h := range options.shutdownHandlers {
+		n.OnShutdown(h)
+	}
+	return n, nil
+}
+
 // SetBroker allows setting Broker implementation to use.
 func (n *Node) SetBroker(b Broker) {
 	n.broker = b
 }
 
+// SetControlTransport allows sending control messages between nodes over
+// ControlTransport instead of Broker. Must be called before Node.Run.
+func (n *Node) SetControlTransport(t ControlTransport) {
+	n.controlTransport = t
+}
+
+// SetWebhookEmitter allows sending events of Node lifecycle to WebhookEmitter.
+// Must be called before Node.Run.
+func (n *Node) SetWebhookEmitter(e *WebhookEmitter) {
+	n.webhookEmitter = e
+}
+
+func (n *Node) emitWebhookEvent(event WebhookEvent) {
+	if n.webhookEmitter != nil {
+		n.webhookEmitter.emitNodeEvent(event)
+	}
+}
+
 // SetPresenceManager allows setting PresenceManager to use.
 func (n *Node) SetPresenceManager(m PresenceManager) {
 	n.presenceManager = m
@@ -235,6 +391,11 @@ func (n *Node) Run() error {
 	if err := n.broker.Run(&brokerEventHandler{n}); err != nil {
 		return err
 	}
+	if n.controlTransport != nil {
+		if err := n.controlTransport.Run(n.handleControl); err != nil {
+			return err
+		}
+	}
 	err := n.initMetrics()
 	if err != nil {
 		n.logger.log(newLogEntry(LogLevelError, "error on init metrics", map[string]any{"error": err.Error()}))
@@ -248,7 +409,27 @@ func (n *Node) Run() error {
 	go n.sendNodePing()
 	go n.cleanNodeInfo()
 	go n.updateMetrics()
-	return n.subDissolver.Run()
+	go n.updateChannelStats()
+	if n.presenceCache != nil {
+		go n.cleanPresenceCache()
+	}
+	if n.joinLeaveAggregator != nil {
+		go n.flushJoinLeave()
+	}
+	if n.firehose != nil {
+		n.firehose.running.Store(true)
+		go n.runFirehose()
+	}
+	if err := n.subDissolver.Run(); err != nil {
+		return err
+	}
+	for _, h := range n.startHandlers {
+		if err := h(context.Background()); err != nil {
+			n.logger.log(newLogEntry(LogLevelError, "error from start handler", map[string]any{"error": err.Error()}))
+			return err
+		}
+	}
+	return nil
 }
 
 // Log allows logging a LogEntry.
@@ -262,7 +443,9 @@ func (n *Node) LogEnabled(level LogLevel) bool {
 }
 
 // Shutdown sets shutdown flag to Node so handlers could stop accepting
-// new requests and disconnects clients with shutdown reason.
+// new requests and disconnects clients with shutdown reason. ShutdownHandler
+// added with OnShutdown are called before disconnecting clients, their errors
+// are returned joined with context error.
 func (n *Node) Shutdown(ctx context.Context) error {
 	n.mu.Lock()
 	if n.shutdown {
@@ -272,14 +455,27 @@ func (n *Node) Shutdown(ctx context.Context) error {
 	n.shutdown = true
 	close(n.shutdownCh)
 	n.mu.Unlock()
+	// Let other nodes remove this node from registry right away, without waiting
+	// for heartbeat expiration. Shutdown handlers may take a while to complete.
 	cmd := &controlpb.Command{
 		Uid:      n.uid,
 		Shutdown: &controlpb.Shutdown{},
 	}
 	_ = n.publishControl(cmd, "")
+	var handlerErrs []error
+	for i := len(n.shutdownHandlers) - 1; i >= 0; i-- {
+		if err := n.shutdownHandlers[i](ctx); err != nil {
+			n.logger.log(newLogEntry(LogLevelError, "error from shutdown handler", map[string]any{"error": err.Error()}))
+			handlerErrs = append(handlerErrs, err)
+		}
+	}
+	n.stopEphemeralCleanup()
 	if closer, ok := n.broker.(Closer); ok {
 		defer func() { _ = closer.Close(ctx) }()
 	}
+	if closer, ok := n.controlTransport.(Closer); ok {
+		defer func() { _ = closer.Close(ctx) }()
+	}
 	if n.presenceManager != nil {
 		if closer, ok := n.presenceManager.(Closer); ok {
 			defer func() { _ = closer.Close(ctx) }()
@@ -296,6 +492,18 @@ func (n *Node) Shutdown(ctx context.Context) error {
 		_ = n.hub.shutdown(ctx)
 	}()
 	wg.Wait()
+	if n.broadcastPool != nil {
+		n.broadcastPool.close()
+	}
+	if n.firehose != nil && n.firehose.running.Load() {
+		select {
+		case <-n.firehose.done:
+		case <-ctx.Done():
+		}
+	}
+	if len(handlerErrs) > 0 {
+		return errors.Join(append(handlerErrs, ctx.Err())...)
+	}
 	return ctx.Err()
 }
 
@@ -329,6 +537,88 @@ func (n *Node) updateMetrics() {
 	}
 }
 
+// channelStatsInterval is an interval to calculate channel publication rates.
+const channelStatsInterval = 10 * time.Second
+
+func (n *Node) updateChannelStats() {
+	for {
+		select {
+		case <-n.shutdownCh:
+			return
+		case <-time.After(channelStatsInterval):
+			n.hub.updateChannelStats(channelStatsInterval)
+		}
+	}
+}
+
+func (n *Node) cleanPresenceCache() {
+	for {
+		select {
+		case <-n.shutdownCh:
+			return
+		case <-time.After(n.config.PresenceCacheTTL * 10):
+			n.presenceCache.removeExpired()
+		}
+	}
+}
+
+func (n *Node) flushJoinLeave() {
+	for {
+		select {
+		case <-n.shutdownCh:
+			return
+		case <-time.After(n.config.JoinLeaveAggregationInterval):
+			n.broadcastJoinLeave(n.joinLeaveAggregator.flush())
+		}
+	}
+}
+
+func (n *Node) broadcastJoinLeave(channels map[string][]*joinLeaveEvent) {
+	for ch, events := range channels {
+		if n.hub.NumSubscribers(ch) == 0 {
+			continue
+		}
+		for _, e := range events {
+			var err error
+			if e.leave {
+				err = n.hub.broadcastLeave(ch, e.info)
+			} else {
+				err = n.hub.broadcastJoin(ch, e.info)
+			}
+			if err != nil {
+				n.logger.log(newLogEntry(LogLevelError, "error broadcasting aggregated join/leave", map[string]any{"channel": ch, "error": err.Error()}))
+			}
+		}
+	}
+}
+
+// channelNamespaceLabel returns channel_namespace label value for a channel.
+func (n *Node) channelNamespaceLabel(ch string) string {
+	if ch == "" || n.channelNamespaceLabeler == nil {
+		return "_"
+	}
+	return n.channelNamespaceLabeler.label(ch)
+}
+
+// clientQueueCounters returns client queue counters of channel namespace. Zero value
+// returned if Config.ChannelNamespaceLabelForClientQueue is not enabled.
+func (n *Node) clientQueueCounters(ch string) clientQueueCounters {
+	if !n.config.ChannelNamespaceLabelForClientQueue {
+		return clientQueueCounters{}
+	}
+	return n.metrics.getClientQueueCounters(n.channelNamespaceLabel(ch))
+}
+
+func (n *Node) metricsGatherer() prometheus.Gatherer {
+	if n.config.MetricsGatherer != nil {
+		return n.config.MetricsGatherer
+	}
+	if gatherer, ok := n.config.MetricsRegisterer.(prometheus.Gatherer); ok {
+		return gatherer
+	}
+	return prometheus.DefaultGatherer
+}
+
 // Centrifuge library uses Prometheus metrics for instrumentation. But we also try to
 // aggregate Prometheus metrics periodically and share this information between Nodes.
 func (n *Node) initMetrics() error {
@@ -337,7 +627,7 @@ func (n *Node) initMetrics() error {
 	}
 	metricsSink := make(chan eagle.Metrics)
 	n.metricsExporter = eagle.New(eagle.Config{
-		Gatherer: prometheus.DefaultGatherer,
+		Gatherer: n.metricsGatherer(),
 		Interval: n.config.NodeInfoMetricsAggregateInterval,
 		Sink:     metricsSink,
 	})
@@ -368,7 +658,7 @@ func (n *Node) sendNodePing() {
 		select {
 		case <-n.shutdownCh:
 			return
-		case <-time.After(nodeInfoPublishInterval):
+		case <-time.After(n.config.NodeHeartbeatInterval):
 			err := n.pubNode("")
 			if err != nil {
 				n.logger.log(newLogEntry(LogLevelError, "error publishing node control command", map[string]any{"error": err.Error()}))
@@ -382,8 +672,10 @@ func (n *Node) cleanNodeInfo() {
 		select {
 		case <-n.shutdownCh:
 			return
-		case <-time.After(nodeInfoCleanInterval):
-			n.nodes.clean(nodeInfoMaxDelay)
+		case <-time.After(n.config.NodeHeartbeatInterval):
+			for _, node := range n.nodes.clean(n.config.NodeHeartbeatExpiration) {
+				n.handleNodeLeave(node, true)
+			}
 		}
 	}
 }
@@ -401,9 +693,6 @@ func (n *Node) handleNotification(fromNodeID string, req *controlpb.Notification
 }
 
 func (n *Node) handleSurveyRequest(fromNodeID string, req *controlpb.SurveyRequest) error {
-	if n.surveyHandler == nil && n.emulationSurveyHandler == nil {
-		return nil
-	}
 	cb := func(reply SurveyReply) {
 		surveyResponse := &controlpb.SurveyResponse{
 			Id:   req.Id,
@@ -416,14 +705,11 @@ func (n *Node) handleSurveyRequest(fromNodeID string, req *controlpb.SurveyReque
 		}
 		_ = n.publishControl(cmd, fromNodeID)
 	}
-	if req.Op == emulationOp && n.emulationSurveyHandler != nil {
-		n.emulationSurveyHandler.HandleEmulation(SurveyEvent{Op: req.Op, Data: req.Data}, cb)
+	handler, ok := n.getSurveyHandler(req.Op)
+	if !ok {
 		return nil
 	}
-	if n.surveyHandler == nil {
-		return nil
-	}
-	n.surveyHandler(SurveyEvent{Op: req.Op, Data: req.Data}, cb)
+	handler(SurveyEvent{Op: req.Op, Data: req.Data}, cb)
 	return nil
 }
 
@@ -462,6 +748,19 @@ type survey struct {
 
 var errSurveyHandlerNotRegistered = errors.New("no survey handler registered")
 
+// getSurveyHandler returns handler for a survey op. Ops reserved by Centrifuge
+// library are processed by internal handlers, all other ops are passed to a
+// handler set over Node.OnSurvey.
+func (n *Node) getSurveyHandler(op string) (SurveyHandler, bool) {
+	if handler, ok := n.internalSurveyHandlers[op]; ok {
+		return handler, true
+	}
+	if n.surveyHandler == nil {
+		return nil, false
+	}
+	return n.surveyHandler, true
+}
+
 const defaultSurveyTimeout = 10 * time.Second
 
 // Survey allows collecting data from all running Centrifuge nodes. This method publishes
@@ -475,7 +774,8 @@ const defaultSurveyTimeout = 10 * time.Second
 // method to handle received surveys.
 // Survey ops starting with `centrifuge_` are reserved by Centrifuge library.
 func (n *Node) Survey(ctx context.Context, op string, data []byte, toNodeID string) (map[string]SurveyResult, error) {
-	if n.surveyHandler == nil && op != emulationOp {
+	handler, ok := n.getSurveyHandler(op)
+	if !ok {
 		return nil, errSurveyHandlerNotRegistered
 	}
 
@@ -526,21 +826,12 @@ func (n *Node) Survey(ctx context.Context, op string, data []byte, toNodeID stri
 		if toNodeID == n.ID() || (toNodeID == "" && numNodes == 1) {
 			needDistributedPublish = false
 		}
-		if op == emulationOp {
-			n.emulationSurveyHandler.HandleEmulation(SurveyEvent{Op: op, Data: data}, func(reply SurveyReply) {
-				surveyChan <- survey{
-					UID:    n.uid,
-					Result: SurveyResult(reply),
-				}
-			})
-		} else {
-			n.surveyHandler(SurveyEvent{Op: op, Data: data}, func(reply SurveyReply) {
-				surveyChan <- survey{
-					UID:    n.uid,
-					Result: SurveyResult(reply),
-				}
-			})
-		}
+		handler(SurveyEvent{Op: op, Data: data}, func(reply SurveyReply) {
+			surveyChan <- survey{
+				UID:    n.uid,
+				Result: SurveyResult(reply),
+			}
+		})
 	}
 
 	var wg sync.WaitGroup
@@ -599,6 +890,9 @@ type NodeInfo struct {
 	Uptime      uint32
 	Metrics     *Metrics
 	Data        []byte
+	// Rates contains live node rates, nil if node did not send them (for example,
+	// node runs older Centrifuge version).
+	Rates *NodeRates
 }
 
 // Info returns aggregated stats from all nodes.
@@ -606,24 +900,7 @@ func (n *Node) Info() (Info, error) {
 	nodes := n.nodes.list()
 	nodeResults := make([]NodeInfo, len(nodes))
 	for i, nd := range nodes {
-		info := NodeInfo{
-			UID:         nd.Uid,
-			Name:        nd.Name,
-			Version:     nd.Version,
-			NumClients:  nd.NumClients,
-			NumUsers:    nd.NumUsers,
-			NumSubs:     nd.NumSubs,
-			NumChannels: nd.NumChannels,
-			Uptime:      nd.Uptime,
-			Data:        nd.Data,
-		}
-		if nd.Metrics != nil {
-			info.Metrics = &Metrics{
-				Interval: nd.Metrics.Interval,
-				Items:    nd.Metrics.Items,
-			}
-		}
-		nodeResults[i] = info
+		nodeResults[i] = nodeInfoFromProto(nd)
 	}
 
 	return Info{
@@ -631,6 +908,39 @@ func (n *Node) Info() (Info, error) {
 	}, nil
 }
 
+// SetInfoData sets an arbitrary data which is attached to the control node frame
+// periodically published by the current Node, so it will be available in NodeInfo.Data
+// of Node.Info result on all nodes after the next node frame publication. Data returned
+// from NodeInfoSendHandler (if set and non-nil) takes precedence. Keep this data
+// reasonably small.
+func (n *Node) SetInfoData(data []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.infoData = data
+}
+
+func nodeInfoFromProto(nd *controlpb.Node) NodeInfo {
+	info := NodeInfo{
+		UID:         nd.Uid,
+		Name:        nd.Name,
+		Version:     nd.Version,
+		NumClients:  nd.NumClients,
+		NumUsers:    nd.NumUsers,
+		NumSubs:     nd.NumSubs,
+		NumChannels: nd.NumChannels,
+		Uptime:      nd.Uptime,
+		Data:        nd.Data,
+		Rates:       nodeRatesFromProto(nd.Rates),
+	}
+	if nd.Metrics != nil {
+		info.Metrics = &Metrics{
+			Interval: nd.Metrics.Interval,
+			Items:    nd.Metrics.Items,
+		}
+	}
+	return info
+}
+
 // handleControl handles messages from control channel - control messages used for internal
 // communication between nodes to share state or proto.
 func (n *Node) handleControl(data []byte) error {
@@ -649,6 +959,10 @@ func (n *Node) handleControl(data []byte) error {
 
 	uid := cmd.Uid
 
+	if n.debugEventsEnabled() {
+		n.emitDebugEvent(DebugEvent{Type: DebugEventControl, Command: controlCommandName(cmd), Node: uid})
+	}
+
 	// control proto v2.
 	if cmd.Node != nil {
 		return n.nodeCmd(cmd.Node)
@@ -679,6 +993,17 @@ func (n *Node) handleControl(data []byte) error {
 	} else if cmd.Refresh != nil {
 		cmd := cmd.Refresh
 		return n.hub.refresh(cmd.User, cmd.Client, cmd.Session, WithRefreshExpired(cmd.Expired), WithRefreshExpireAt(cmd.ExpireAt), WithRefreshInfo(cmd.Info))
+	} else if cmd.Send != nil {
+		cmd := cmd.Send
+		return n.hub.send(cmd.User, cmd.Data, cmd.Client, cmd.Session)
+	} else if cmd.ShutdownRequest != nil {
+		return n.handleShutdownRequest(uid, cmd.ShutdownRequest)
+	} else if cmd.Revoke != nil {
+		n.handleRevoke(cmd.Revoke)
+		return nil
+	} else if cmd.HistoryRemove != nil {
+		n.handleHistoryRemove(cmd.HistoryRemove.Channel)
+		return nil
 	}
 	n.logger.log(newLogEntry(LogLevelError, "unknown control command", map[string]any{"command": fmt.Sprintf("%#v", cmd)}))
 	return nil
@@ -690,10 +1015,20 @@ func (n *Node) handleControl(data []byte) error {
 func (n *Node) handlePublication(ch string, sp StreamPosition, pub, prevPub, localPrevPub *Publication) error {
 	n.metrics.incMessagesReceived("publication")
 	numSubscribers := n.hub.NumSubscribers(ch)
+	if n.debugEventsEnabled() {
+		n.emitDebugEvent(DebugEvent{Type: DebugEventPublicationReceived, Channel: ch, Offset: sp.Offset, Epoch: sp.Epoch, NumSubscribers: numSubscribers})
+	}
 	hasCurrentSubscribers := numSubscribers > 0
 	if !hasCurrentSubscribers {
 		return nil
 	}
+	if n.isWildcardChannel(ch) {
+		var ok bool
+		pub, ok = n.wildcardDelivery(ch, pub)
+		if !ok {
+			return nil
+		}
+	}
 	return n.hub.broadcastPublication(ch, sp, pub, prevPub, localPrevPub)
 }
 
@@ -706,6 +1041,10 @@ func (n *Node) handleJoin(ch string, info *ClientInfo) error {
 	if !hasCurrentSubscribers {
 		return nil
 	}
+	if n.joinLeaveAggregator != nil {
+		n.joinLeaveAggregator.add(ch, info, false)
+		return nil
+	}
 	return n.hub.broadcastJoin(ch, info)
 }
 
@@ -718,22 +1057,164 @@ func (n *Node) handleLeave(ch string, info *ClientInfo) error {
 	if !hasCurrentSubscribers {
 		return nil
 	}
+	if n.joinLeaveAggregator != nil {
+		n.joinLeaveAggregator.add(ch, info, true)
+		return nil
+	}
 	return n.hub.broadcastLeave(ch, info)
 }
 
-func (n *Node) publish(ch string, data []byte, opts ...PublishOption) (PublishResult, error) {
+func (n *Node) publish(ctx context.Context, ch string, data []byte, opts ...PublishOption) (PublishResult, error) {
 	pubOpts := &PublishOptions{}
 	for _, opt := range opts {
 		opt(pubOpts)
 	}
+	pubOpts.ctx = ctx
+	if n.isWildcardChannel(ch) {
+		return PublishResult{}, ErrorBadRequest
+	}
+	if namespaces := n.reloadableConfig().namespaces; namespaces != nil && !pubOpts.withoutHistory && pubOpts.HistorySize == 0 && pubOpts.HistoryTTL == 0 {
+		if chOpts, ok := namespaces.resolve(ch); ok {
+			pubOpts.HistorySize = chOpts.HistorySize
+			pubOpts.HistoryTTL = chOpts.HistoryTTL
+		}
+	}
+	if (pubOpts.Priority != PublicationPriorityNormal || pubOpts.FreshnessTTL > 0) && (pubOpts.HistorySize > 0 || pubOpts.HistoryTTL > 0) {
+		// Priority and freshness reorder or drop publications in connection queue, this
+		// creates gaps in a stream which clients can't notice.
+		n.logger.log(newLogEntry(LogLevelInfo, "publication delivery options not allowed in channel with history", map[string]any{"channel": ch}))
+		return PublishResult{}, ErrorBadRequest
+	}
+	if maxSize := n.publicationMaxSize(ch); maxSize > 0 && len(data) > maxSize {
+		n.logger.log(newLogEntry(LogLevelInfo, "publication too large", map[string]any{"channel": ch, "size": len(data), "max": maxSize}))
+		return PublishResult{}, ErrorPublicationTooLarge
+	}
+	if err := n.validatePayload(ch, data); err != nil {
+		return PublishResult{}, err
+	}
+	if n.publishFunc != nil {
+		return n.publishFunc(ch, data, *pubOpts)
+	}
+	return n.brokerPublish(ch, data, *pubOpts)
+}
+
+// publicationMaxSize returns max size of publication data in channel, zero means
+// no limit.
+func (n *Node) publicationMaxSize(ch string) int {
+	rc := n.reloadableConfig()
+	if rc.namespaces != nil {
+		if chOpts, ok := rc.namespaces.resolve(ch); ok && chOpts.PublicationMaxSize > 0 {
+			return chOpts.PublicationMaxSize
+		}
+	}
+	return rc.publicationMaxSize
+}
+
+// validatePayload validates publication data with ChannelOptions.ValidatePayload
+// of channel namespace.
+func (n *Node) validatePayload(ch string, data []byte) error {
+	namespaces := n.reloadableConfig().namespaces
+	if namespaces == nil {
+		return nil
+	}
+	chOpts, ok := namespaces.resolve(ch)
+	if !ok || chOpts.ValidatePayload == nil {
+		return nil
+	}
+	err := chOpts.ValidatePayload(ch, data)
+	if err == nil {
+		return nil
+	}
+	n.logger.log(newLogEntry(LogLevelInfo, "invalid publication payload", map[string]any{"channel": ch, "error": err.Error()}))
+	var clientErr *Error
+	if errors.As(err, &clientErr) {
+		return clientErr
+	}
+	return ErrorInvalidPayload
+}
+
+func (n *Node) brokerPublish(ch string, data []byte, opts PublishOptions) (PublishResult, error) {
 	n.metrics.incMessagesSent("publication")
-	streamPos, fromCache, err := n.broker.Publish(ch, data, *pubOpts)
+	if n.config.ChannelNamespaceLabelForPublish {
+		n.metrics.incPublish(n.channelNamespaceLabel(ch))
+	}
+	started := time.Now()
+	streamPos, fromCache, err := n.brokerPublishContext(ch, data, opts)
+	n.logSlowOperation("publish", ch, started)
 	if err != nil {
+		n.emitWebhookEvent(WebhookEvent{Type: WebhookEventPublishFailure, Channel: ch, Reason: err.Error()})
 		return PublishResult{}, err
 	}
+	if n.firehose != nil && !fromCache {
+		n.sendToFirehose(ch, data, opts, streamPos)
+	}
+	if n.debugEventsEnabled() {
+		n.emitDebugEvent(DebugEvent{Type: DebugEventPublish, Channel: ch, Offset: streamPos.Offset, Epoch: streamPos.Epoch})
+	}
 	return PublishResult{StreamPosition: streamPos, FromCache: fromCache}, nil
 }
 
+func (n *Node) brokerPublishContext(ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
+	ctx := opts.ctx
+	if ctx == nil {
+		return n.broker.Publish(ch, data, opts)
+	}
+	if err := ctx.Err(); err != nil {
+		return StreamPosition{}, false, err
+	}
+	if b, ok := n.broker.(ContextBroker); ok {
+		return b.PublishContext(ctx, ch, data, opts)
+	}
+	return n.broker.Publish(ch, data, opts)
+}
+
+// PublishFunc publishes data into a channel with provided PublishOptions.
+type PublishFunc func(channel string, data []byte, opts PublishOptions) (PublishResult, error)
+
+// PublishMiddleware wraps PublishFunc. Middleware may modify channel data or
+// PublishOptions (for example, add tags) before calling next, or return an error
+// without calling next to reject publication. When publication comes from a client
+// and middleware returns *Error or Disconnect then it is passed to the client.
+type PublishMiddleware func(next PublishFunc) PublishFunc
+
+// UsePublishMiddleware appends middlewares to a chain called for every publication
+// made over Node.Publish – including publications coming from clients. Middlewares
+// are called in order they were added, the first one is the outermost. This should
+// be done before Node.Run called.
+func (n *Node) UsePublishMiddleware(middlewares ...PublishMiddleware) {
+	n.publishMiddlewares = append(n.publishMiddlewares, middlewares...)
+	publishFunc := n.brokerPublish
+	for i := len(n.publishMiddlewares) - 1; i >= 0; i-- {
+		publishFunc = n.publishMiddlewares[i](publishFunc)
+	}
+	n.publishFunc = publishFunc
+}
+
+// CommandFunc handles client command.
+type CommandFunc func(client *Client, e CommandEvent) error
+
+// CommandMiddleware wraps CommandFunc. Middleware may inspect command and client
+// before calling next, or return an error without calling next to prevent command
+// execution. If middleware returns *Error then client receives error reply to the
+// command, if Disconnect returned then client is disconnected. Other errors result
+// into ErrorInternal reply.
+type CommandMiddleware func(next CommandFunc) CommandFunc
+
+// UseCommandMiddleware appends middlewares to a chain called for every command
+// received from bidirectional client connections (after CommandReadHandler). Middlewares
+// are called in order they were added, the first one is the outermost. This should
+// be done before Node.Run called.
+func (n *Node) UseCommandMiddleware(middlewares ...CommandMiddleware) {
+	n.commandMiddlewares = append(n.commandMiddlewares, middlewares...)
+	var commandFunc CommandFunc = func(c *Client, e CommandEvent) error {
+		return c.handleCommand(e.Command, e.started)
+	}
+	for i := len(n.commandMiddlewares) - 1; i >= 0; i-- {
+		commandFunc = n.commandMiddlewares[i](commandFunc)
+	}
+	n.commandFunc = commandFunc
+}
+
 // PublishResult returned from Publish operation.
 type PublishResult struct {
 	StreamPosition
@@ -760,7 +1241,14 @@ type PublishResult struct {
 // enabled (i.e. when Publications only sent to PUB/SUB system) StreamPosition will
 // be an empty struct (i.e. PublishResult.Offset will be zero).
 func (n *Node) Publish(channel string, data []byte, opts ...PublishOption) (PublishResult, error) {
-	return n.publish(channel, data, opts...)
+	return n.publish(context.Background(), channel, data, opts...)
+}
+
+// PublishContext is the same as Publish but respects ctx cancellation and deadline.
+// Context is passed to Broker if it implements ContextBroker, otherwise it's only
+// checked before calling Broker.
+func (n *Node) PublishContext(ctx context.Context, channel string, data []byte, opts ...PublishOption) (PublishResult, error) {
+	return n.publish(ctx, channel, data, opts...)
 }
 
 // publishJoin allows publishing join message into channel when someone subscribes on it
@@ -825,6 +1313,9 @@ func (n *Node) publishControl(cmd *controlpb.Command, nodeID string) error {
 	if err != nil {
 		return err
 	}
+	if n.controlTransport != nil {
+		return n.controlTransport.PublishControl(data, nodeID)
+	}
 	return n.broker.PublishControl(data, nodeID, "")
 }
 
@@ -844,6 +1335,9 @@ func (n *Node) pubNode(nodeID string) error {
 		data = reply.Data
 	}
 	n.mu.RLock()
+	if data == nil {
+		data = n.infoData
+	}
 	node := &controlpb.Node{
 		Uid:         n.uid,
 		Name:        n.config.Name,
@@ -854,6 +1348,7 @@ func (n *Node) pubNode(nodeID string) error {
 		NumSubs:     uint32(n.hub.NumSubscriptions()),
 		Uptime:      uint32(time.Now().Unix() - n.startedAt),
 		Data:        data,
+		Rates:       n.ratesTracker.rates(n.metrics.numPublications.Load(), n.metrics.numMessagesSent.Load()),
 	}
 
 	n.metricsMu.Lock()
@@ -924,6 +1419,22 @@ func (n *Node) pubRefresh(user string, opts RefreshOptions) error {
 	return n.publishControl(cmd, "")
 }
 
+// pubSend publishes send control message to all nodes – so all nodes could
+// send message to user connections.
+func (n *Node) pubSend(user string, data []byte, clientID, sessionID string) error {
+	send := &controlpb.Send{
+		User:    user,
+		Data:    data,
+		Client:  clientID,
+		Session: sessionID,
+	}
+	cmd := &controlpb.Command{
+		Uid:  n.uid,
+		Send: send,
+	}
+	return n.publishControl(cmd, "")
+}
+
 // pubUnsubscribe publishes unsubscribe control message to all nodes – so all
 // nodes could unsubscribe user from channel.
 func (n *Node) pubUnsubscribe(user string, ch string, unsubscribe Unsubscribe, clientID, sessionID string) error {
@@ -964,7 +1475,11 @@ func (n *Node) pubDisconnect(user string, disconnect Disconnect, clientID string
 // this allows to make operations with user connection on demand.
 func (n *Node) addClient(c *Client) error {
 	n.metrics.incActionCount("add_client")
-	return n.hub.add(c)
+	if err := n.hub.add(c); err != nil {
+		return err
+	}
+	n.emitWebhookEvent(WebhookEvent{Type: WebhookEventConnect, User: c.user, Client: c.uid})
+	return nil
 }
 
 // removeClient removes client connection from connection registry.
@@ -977,6 +1492,9 @@ func (n *Node) removeClient(c *Client) error {
 // Hub and Broker.
 func (n *Node) addSubscription(ch string, sub subInfo) error {
 	n.metrics.incActionCount("add_subscription")
+	if n.config.ChannelNamespaceLabelForSubscribe {
+		n.metrics.incSubscribe(n.channelNamespaceLabel(ch))
+	}
 	mu := n.subLock(ch)
 	mu.Lock()
 	defer mu.Unlock()
@@ -985,6 +1503,7 @@ func (n *Node) addSubscription(ch string, sub subInfo) error {
 		return err
 	}
 	if first {
+		n.cancelEphemeralCleanup(ch)
 		if n.config.GetChannelMediumOptions != nil {
 			mediumOptions := n.config.GetChannelMediumOptions(ch)
 			if mediumOptions.isMediumEnabled() {
@@ -996,7 +1515,9 @@ func (n *Node) addSubscription(ch string, sub subInfo) error {
 			}
 		}
 
-		err := n.broker.Subscribe(ch)
+		started := time.Now()
+		err := n.brokerSubscribe(ch)
+		n.logSlowOperation("subscribe", ch, started)
 		if err != nil {
 			_, _ = n.hub.removeSub(ch, sub.client)
 			if n.config.GetChannelMediumOptions != nil {
@@ -1009,6 +1530,10 @@ func (n *Node) addSubscription(ch string, sub subInfo) error {
 			return err
 		}
 	}
+	if n.debugEventsEnabled() {
+		n.emitDebugEvent(DebugEvent{Type: DebugEventSubscribe, Channel: ch, Client: sub.client.uid, User: sub.client.user})
+	}
+	n.emitWebhookEvent(WebhookEvent{Type: WebhookEventSubscribe, User: sub.client.user, Client: sub.client.uid, Channel: ch})
 	return nil
 }
 
@@ -1023,6 +1548,9 @@ func (n *Node) removeSubscription(ch string, c *Client) error {
 	if err != nil {
 		return err
 	}
+	if n.debugEventsEnabled() {
+		n.emitDebugEvent(DebugEvent{Type: DebugEventUnsubscribe, Channel: ch, Client: c.uid, User: c.user})
+	}
 	if empty {
 		submittedAt := time.Now()
 		_ = n.subDissolver.Submit(func() error {
@@ -1035,7 +1563,12 @@ func (n *Node) removeSubscription(ch string, c *Client) error {
 			defer mu.Unlock()
 			empty := n.hub.NumSubscribers(ch) == 0
 			if empty {
-				err := n.broker.Unsubscribe(ch)
+				started := time.Now()
+				err := n.brokerUnsubscribe(ch)
+				n.logSlowOperation("unsubscribe", ch, started)
+				if n.historyCache != nil {
+					n.historyCache.remove(ch)
+				}
 				if err != nil {
 					// Cool down a bit since broker is not ready to process unsubscription.
 					time.Sleep(500 * time.Millisecond)
@@ -1045,6 +1578,7 @@ func (n *Node) removeSubscription(ch string, c *Client) error {
 						medium.close()
 						delete(n.mediums, ch)
 					}
+					n.scheduleEphemeralCleanup(ch)
 				}
 				return err
 			}
@@ -1060,16 +1594,62 @@ func (n *Node) nodeCmd(node *controlpb.Node) error {
 	if isNewNode && node.Uid != n.uid {
 		// New Node in cluster
 		_ = n.pubNode(node.Uid)
+		if n.nodeJoinHandler != nil {
+			n.nodeJoinHandler(NodeJoinEvent{Node: nodeInfoFromProto(node)})
+		}
 	}
 	return nil
 }
 
 // shutdownCmd handles shutdown control command sent when node leaves cluster.
 func (n *Node) shutdownCmd(nodeID string) error {
-	n.nodes.remove(nodeID)
+	if node, ok := n.nodes.remove(nodeID); ok {
+		n.handleNodeLeave(node, false)
+	}
 	return nil
 }
 
+var errNodeIDRequired = errors.New("node ID required")
+
+// RequestNodeShutdown asks node with provided ID to shut down gracefully. This allows
+// triggering operational actions from any node of a cluster. Node handles request
+// with ShutdownRequestHandler set over OnShutdownRequest, request is ignored if handler
+// not set. Request is sent asynchronously, so successful call does not mean node
+// started shutting down.
+func (n *Node) RequestNodeShutdown(nodeID string, reason string) error {
+	if nodeID == "" {
+		return errNodeIDRequired
+	}
+	n.metrics.incActionCount("request_shutdown")
+	req := &controlpb.ShutdownRequest{Reason: reason}
+	if nodeID == n.uid {
+		// Control message handler ignores commands sent from the current Node.
+		return n.handleShutdownRequest(n.uid, req)
+	}
+	cmd := &controlpb.Command{
+		Uid:             n.uid,
+		ShutdownRequest: req,
+	}
+	return n.publishControl(cmd, nodeID)
+}
+
+func (n *Node) handleShutdownRequest(fromNodeID string, req *controlpb.ShutdownRequest) error {
+	if n.shutdownRequestHandler == nil {
+		n.logger.log(newLogEntry(LogLevelWarn, "shutdown request ignored since handler not set", map[string]any{"from": fromNodeID, "reason": req.Reason}))
+		return nil
+	}
+	n.logger.log(newLogEntry(LogLevelInfo, "shutdown requested", map[string]any{"from": fromNodeID, "reason": req.Reason}))
+	go n.shutdownRequestHandler(ShutdownRequestEvent{FromNodeID: fromNodeID, Reason: req.Reason})
+	return nil
+}
+
+func (n *Node) handleNodeLeave(node *controlpb.Node, expired bool) {
+	if n.nodeLeaveHandler == nil {
+		return
+	}
+	n.nodeLeaveHandler(NodeLeaveEvent{Node: nodeInfoFromProto(node), Expired: expired})
+}
+
 // Subscribe subscribes user to a channel.
 // Note, that OnSubscribe event won't be called in this case
 // since this is a server-side subscription. If user have been already
@@ -1147,12 +1727,59 @@ func (n *Node) Refresh(userID string, opts ...RefreshOption) error {
 	return n.pubRefresh(userID, *refreshOpts)
 }
 
+// Send sends an asynchronous message with data to all user connections on all nodes.
+// Message is not related to any channel, it is delivered as a message push and
+// triggers a message event on a client side. Use WithSendClient or WithSendSession
+// to send data to a concrete connection only.
+func (n *Node) Send(userID string, data []byte, opts ...SendOption) error {
+	sendOpts := &SendOptions{}
+	for _, opt := range opts {
+		opt(sendOpts)
+	}
+	n.metrics.incActionCount("send")
+	// Send to user connections on this node.
+	err := n.hub.send(userID, data, sendOpts.clientID, sendOpts.sessionID)
+	if err != nil {
+		return err
+	}
+	// Send control message to other nodes.
+	return n.pubSend(userID, data, sendOpts.clientID, sendOpts.sessionID)
+}
+
+// logSlowOperation logs operation with channel if it took longer than
+// Config.SlowOperationThreshold.
+func (n *Node) logSlowOperation(op string, ch string, started time.Time) {
+	threshold := n.reloadableConfig().slowOperationThreshold
+	if threshold <= 0 {
+		return
+	}
+	if duration := time.Since(started); duration >= threshold {
+		n.logger.log(newLogEntry(LogLevelWarn, "slow operation", map[string]any{"operation": op, "channel": ch, "duration": duration.String()}))
+	}
+}
+
 // addPresence proxies presence adding to PresenceManager.
 func (n *Node) addPresence(ch string, uid string, info *ClientInfo) error {
 	if n.presenceManager == nil {
 		return nil
 	}
 	n.metrics.incActionCount("add_presence")
+	defer n.logSlowOperation("add_presence", ch, time.Now())
+	if n.presenceCache != nil {
+		defer n.presenceCache.invalidate(ch)
+	}
+	return n.presenceManager.AddPresence(ch, uid, info)
+}
+
+// refreshPresence prolongs presence of client which was already added with addPresence
+// with the same info. Unlike addPresence it does not invalidate presence cache since
+// presence does not change – periodic refreshes of all clients would make cache useless.
+func (n *Node) refreshPresence(ch string, uid string, info *ClientInfo) error {
+	if n.presenceManager == nil {
+		return nil
+	}
+	n.metrics.incActionCount("add_presence")
+	defer n.logSlowOperation("add_presence", ch, time.Now())
 	return n.presenceManager.AddPresence(ch, uid, info)
 }
 
@@ -1162,6 +1789,10 @@ func (n *Node) removePresence(ch string, clientID string, userID string) error {
 		return nil
 	}
 	n.metrics.incActionCount("remove_presence")
+	defer n.logSlowOperation("remove_presence", ch, time.Now())
+	if n.presenceCache != nil {
+		defer n.presenceCache.invalidate(ch)
+	}
 	return n.presenceManager.RemovePresence(ch, clientID, userID)
 }
 
@@ -1176,27 +1807,75 @@ type PresenceResult struct {
 	Presence map[string]*ClientInfo
 }
 
-func (n *Node) presence(ch string) (PresenceResult, error) {
-	presence, err := n.presenceManager.Presence(ch)
+func (n *Node) presence(ctx context.Context, ch string) (PresenceResult, error) {
+	if n.presenceCache != nil {
+		if presence, ok := n.presenceCache.getPresence(ch); ok {
+			return PresenceResult{Presence: presence}, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return PresenceResult{}, err
+	}
+	defer n.logSlowOperation("presence", ch, time.Now())
+	var presence map[string]*ClientInfo
+	var err error
+	if m, ok := n.presenceManager.(ContextPresenceManager); ok {
+		presence, err = m.PresenceContext(ctx, ch)
+	} else {
+		presence, err = n.presenceManager.Presence(ch)
+	}
 	if err != nil {
 		return PresenceResult{}, err
 	}
+	if n.presenceCache != nil {
+		n.presenceCache.setPresence(ch, presence)
+	}
 	return PresenceResult{Presence: presence}, nil
 }
 
 // Presence returns a map with information about active clients in channel.
 func (n *Node) Presence(ch string) (PresenceResult, error) {
+	return n.PresenceContext(context.Background(), ch)
+}
+
+// PresenceContext is the same as Presence but respects ctx cancellation and deadline.
+// Config.UseSingleFlight is only applied for contexts which are never canceled.
+func (n *Node) PresenceContext(ctx context.Context, ch string) (PresenceResult, error) {
 	if n.presenceManager == nil {
 		return PresenceResult{}, ErrorNotAvailable
 	}
 	n.metrics.incActionCount("presence")
-	if n.config.UseSingleFlight {
+	if n.config.UseSingleFlight && ctx.Done() == nil {
 		result, err, _ := presenceGroup.Do(ch, func() (any, error) {
-			return n.presence(ch)
+			return n.presence(ctx, ch)
 		})
 		return result.(PresenceResult), err
 	}
-	return n.presence(ch)
+	return n.presence(ctx, ch)
+}
+
+// PresencePage returns a page of information about active clients in channel. Use it
+// instead of Presence for channels with many members to avoid loading whole presence
+// at once. Iterate until returned PresencePage.Cursor is empty passing it with
+// WithPresenceCursor option to get next page.
+func (n *Node) PresencePage(ch string, opts ...PresencePageOption) (PresencePage, error) {
+	if n.presenceManager == nil {
+		return PresencePage{}, ErrorNotAvailable
+	}
+	pageOpts := PresencePageOptions{}
+	for _, opt := range opts {
+		opt(&pageOpts)
+	}
+	n.metrics.incActionCount("presence")
+	defer n.logSlowOperation("presence_page", ch, time.Now())
+	if pager, ok := n.presenceManager.(PresencePager); ok {
+		return pager.PresencePage(ch, pageOpts)
+	}
+	presence, err := n.presenceManager.Presence(ch)
+	if err != nil {
+		return PresencePage{}, err
+	}
+	return paginatePresence(presence, pageOpts), nil
 }
 
 func infoFromProto(v *protocol.ClientInfo) *ClientInfo {
@@ -1263,27 +1942,52 @@ type PresenceStatsResult struct {
 	PresenceStats
 }
 
-func (n *Node) presenceStats(ch string) (PresenceStatsResult, error) {
-	presenceStats, err := n.presenceManager.PresenceStats(ch)
+func (n *Node) presenceStats(ctx context.Context, ch string) (PresenceStatsResult, error) {
+	if n.presenceCache != nil {
+		if presenceStats, ok := n.presenceCache.getPresenceStats(ch); ok {
+			return PresenceStatsResult{PresenceStats: presenceStats}, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return PresenceStatsResult{}, err
+	}
+	defer n.logSlowOperation("presence_stats", ch, time.Now())
+	var presenceStats PresenceStats
+	var err error
+	if m, ok := n.presenceManager.(ContextPresenceManager); ok {
+		presenceStats, err = m.PresenceStatsContext(ctx, ch)
+	} else {
+		presenceStats, err = n.presenceManager.PresenceStats(ch)
+	}
 	if err != nil {
 		return PresenceStatsResult{}, err
 	}
+	if n.presenceCache != nil {
+		n.presenceCache.setPresenceStats(ch, presenceStats)
+	}
 	return PresenceStatsResult{PresenceStats: presenceStats}, nil
 }
 
 // PresenceStats returns presence stats from PresenceManager.
 func (n *Node) PresenceStats(ch string) (PresenceStatsResult, error) {
+	return n.PresenceStatsContext(context.Background(), ch)
+}
+
+// PresenceStatsContext is the same as PresenceStats but respects ctx cancellation
+// and deadline. Config.UseSingleFlight is only applied for contexts which are never
+// canceled.
+func (n *Node) PresenceStatsContext(ctx context.Context, ch string) (PresenceStatsResult, error) {
 	if n.presenceManager == nil {
 		return PresenceStatsResult{}, ErrorNotAvailable
 	}
 	n.metrics.incActionCount("presence_stats")
-	if n.config.UseSingleFlight {
+	if n.config.UseSingleFlight && ctx.Done() == nil {
 		result, err, _ := presenceStatsGroup.Do(ch, func() (any, error) {
-			return n.presenceStats(ch)
+			return n.presenceStats(ctx, ch)
 		})
 		return result.(PresenceStatsResult), err
 	}
-	return n.presenceStats(ch)
+	return n.presenceStats(ctx, ch)
 }
 
 // HistoryResult contains Publications and current stream top StreamPosition.
@@ -1294,11 +1998,29 @@ type HistoryResult struct {
 	Publications []*Publication
 }
 
-func (n *Node) history(ch string, opts *HistoryOptions) (HistoryResult, error) {
+func (n *Node) history(ctx context.Context, ch string, opts *HistoryOptions) (HistoryResult, error) {
 	if opts.Filter.Reverse && opts.Filter.Since != nil && opts.Filter.Since.Offset == 0 {
 		return HistoryResult{}, ErrorBadRequest
 	}
-	pubs, streamTop, err := n.broker.History(ch, *opts)
+	if n.historyCache != nil && opts.Filter.Since != nil && !opts.Filter.Reverse {
+		if result, ok := n.historyCache.history(ch, *opts.Filter.Since, opts.Filter.Limit); ok {
+			n.metrics.incActionCount("history_cache_hit")
+			return result, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return HistoryResult{}, err
+	}
+	started := time.Now()
+	var pubs []*Publication
+	var streamTop StreamPosition
+	var err error
+	if b, ok := n.broker.(ContextBroker); ok {
+		pubs, streamTop, err = b.HistoryContext(ctx, ch, *opts)
+	} else {
+		pubs, streamTop, err = n.broker.History(ch, *opts)
+	}
+	n.logSlowOperation("history", ch, started)
 	if err != nil {
 		return HistoryResult{}, err
 	}
@@ -1321,12 +2043,18 @@ func (n *Node) history(ch string, opts *HistoryOptions) (HistoryResult, error) {
 // History allows extracting Publications in channel.
 // The channel must belong to namespace where history is on.
 func (n *Node) History(ch string, opts ...HistoryOption) (HistoryResult, error) {
+	return n.HistoryContext(context.Background(), ch, opts...)
+}
+
+// HistoryContext is the same as History but respects ctx cancellation and deadline.
+// Config.UseSingleFlight is only applied for contexts which are never canceled.
+func (n *Node) HistoryContext(ctx context.Context, ch string, opts ...HistoryOption) (HistoryResult, error) {
 	n.metrics.incActionCount("history")
 	historyOpts := &HistoryOptions{}
 	for _, opt := range opts {
 		opt(historyOpts)
 	}
-	if n.config.UseSingleFlight {
+	if n.config.UseSingleFlight && ctx.Done() == nil {
 		var builder strings.Builder
 		builder.WriteString("channel:")
 		builder.WriteString(ch)
@@ -1345,11 +2073,11 @@ func (n *Node) History(ch string, opts ...HistoryOption) (HistoryResult, error)
 		key := builder.String()
 
 		result, err, _ := historyGroup.Do(key, func() (any, error) {
-			return n.history(ch, historyOpts)
+			return n.history(ctx, ch, historyOpts)
 		})
 		return result.(HistoryResult), err
 	}
-	return n.history(ch, historyOpts)
+	return n.history(ctx, ch, historyOpts)
 }
 
 // recoverHistory recovers publications since StreamPosition last seen by client.
@@ -1417,10 +2145,54 @@ func (n *Node) checkPosition(ch string, clientPosition StreamPosition, historyMe
 	return validPosition, nil
 }
 
-// RemoveHistory removes channel history.
+// RemoveHistory removes channel history. History cache of channel (see
+// Config.HistoryCacheSize) is invalidated on all nodes.
 func (n *Node) RemoveHistory(ch string) error {
+	return n.RemoveHistoryContext(context.Background(), ch)
+}
+
+// RemoveHistoryContext is the same as RemoveHistory but respects ctx cancellation
+// and deadline.
+func (n *Node) RemoveHistoryContext(ctx context.Context, ch string) error {
 	n.metrics.incActionCount("history_remove")
-	return n.broker.RemoveHistory(ch)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var err error
+	if b, ok := n.broker.(ContextBroker); ok {
+		err = b.RemoveHistoryContext(ctx, ch)
+	} else {
+		err = n.broker.RemoveHistory(ch)
+	}
+	if err != nil {
+		return err
+	}
+	// Cached publications of removed stream must not be used for recovery, on
+	// this Node and on other nodes.
+	n.handleHistoryRemove(ch)
+	cmd := &controlpb.Command{
+		Uid:           n.uid,
+		HistoryRemove: &controlpb.HistoryRemove{Channel: ch},
+	}
+	return n.publishControl(cmd, "")
+}
+
+// handleHistoryRemove drops history cache of channel which history was removed.
+func (n *Node) handleHistoryRemove(ch string) {
+	if n.historyCache != nil {
+		n.historyCache.remove(ch)
+	}
+}
+
+// historyCacheTTL returns TTL of channel history cache.
+func (n *Node) historyCacheTTL(ch string) time.Duration {
+	ttl := n.config.HistoryCacheTTL
+	if namespaces := n.reloadableConfig().namespaces; namespaces != nil {
+		if chOpts, ok := namespaces.resolve(ch); ok && chOpts.HistoryTTL > 0 && chOpts.HistoryTTL < ttl {
+			ttl = chOpts.HistoryTTL
+		}
+	}
+	return ttl
 }
 
 type nodeRegistry struct {
@@ -1430,8 +2202,12 @@ type nodeRegistry struct {
 	currentUID string
 	// nodes is a map with information about known nodes.
 	nodes map[string]*controlpb.Node
-	// updates track time we last received ping from node. Used to clean up nodes map.
+	// updates track time (Unix nanoseconds) we last received ping from node. Used to
+	// clean up nodes map.
 	updates map[string]int64
+	// left tracks time (Unix nanoseconds) nodes left cluster at. Used to ignore
+	// heartbeats from left nodes which were in flight when node left.
+	left map[string]int64
 }
 
 func newNodeRegistry(currentUID string) *nodeRegistry {
@@ -1439,6 +2215,7 @@ func newNodeRegistry(currentUID string) *nodeRegistry {
 		currentUID: currentUID,
 		nodes:      make(map[string]*controlpb.Node),
 		updates:    make(map[string]int64),
+		left:       make(map[string]int64),
 	}
 }
 
@@ -1471,6 +2248,10 @@ func (r *nodeRegistry) get(uid string) (*controlpb.Node, bool) {
 func (r *nodeRegistry) add(info *controlpb.Node) bool {
 	var isNewNode bool
 	r.mu.Lock()
+	if _, ok := r.left[info.Uid]; ok {
+		r.mu.Unlock()
+		return false
+	}
 	if node, ok := r.nodes[info.Uid]; ok {
 		if info.Metrics != nil {
 			r.nodes[info.Uid] = info
@@ -1485,6 +2266,7 @@ func (r *nodeRegistry) add(info *controlpb.Node) bool {
 				Uptime:      info.Uptime,
 				Data:        info.Data,
 				NumSubs:     info.NumSubs,
+				Rates:       info.Rates,
 				Metrics:     node.Metrics,
 			}
 		}
@@ -1492,20 +2274,30 @@ func (r *nodeRegistry) add(info *controlpb.Node) bool {
 		r.nodes[info.Uid] = info
 		isNewNode = true
 	}
-	r.updates[info.Uid] = time.Now().Unix()
+	r.updates[info.Uid] = time.Now().UnixNano()
 	r.mu.Unlock()
 	return isNewNode
 }
 
-func (r *nodeRegistry) remove(uid string) {
+func (r *nodeRegistry) remove(uid string) (*controlpb.Node, bool) {
 	r.mu.Lock()
+	node, ok := r.nodes[uid]
 	delete(r.nodes, uid)
 	delete(r.updates, uid)
+	r.left[uid] = time.Now().UnixNano()
 	r.mu.Unlock()
+	return node, ok
 }
 
-func (r *nodeRegistry) clean(delay time.Duration) {
+// clean removes nodes not seen for a delay and returns removed nodes.
+func (r *nodeRegistry) clean(delay time.Duration) []*controlpb.Node {
+	var removed []*controlpb.Node
 	r.mu.Lock()
+	for uid, leftAt := range r.left {
+		if time.Now().UnixNano()-leftAt > int64(delay) {
+			delete(r.left, uid)
+		}
+	}
 	for uid := range r.nodes {
 		if uid == r.currentUID {
 			// No need to clean info for current node.
@@ -1514,16 +2306,19 @@ func (r *nodeRegistry) clean(delay time.Duration) {
 		updated, ok := r.updates[uid]
 		if !ok {
 			// As we do all operations with nodes under lock this should never happen.
+			removed = append(removed, r.nodes[uid])
 			delete(r.nodes, uid)
 			continue
 		}
-		if time.Now().Unix()-updated > int64(delay.Seconds()) {
+		if time.Now().UnixNano()-updated > int64(delay) {
 			// Too many seconds since this node have been last seen - remove it from map.
+			removed = append(removed, r.nodes[uid])
 			delete(r.nodes, uid)
 			delete(r.updates, uid)
 		}
 	}
 	r.mu.Unlock()
+	return removed
 }
 
 // OnSurvey allows setting SurveyHandler. This should be done before Node.Run called.
@@ -1541,6 +2336,38 @@ func (n *Node) OnNodeInfoSend(handler NodeInfoSendHandler) {
 	n.nodeInfoSendHandler = handler
 }
 
+// OnNodeJoin allows setting NodeJoinHandler. This should be done before Node.Run called.
+func (n *Node) OnNodeJoin(handler NodeJoinHandler) {
+	n.nodeJoinHandler = handler
+}
+
+// OnNodeLeave allows setting NodeLeaveHandler. This should be done before Node.Run called.
+func (n *Node) OnNodeLeave(handler NodeLeaveHandler) {
+	n.nodeLeaveHandler = handler
+}
+
+// OnShutdownRequest allows setting ShutdownRequestHandler. Without handler set
+// shutdown requests are ignored. This should be done before Node.Run called.
+func (n *Node) OnShutdownRequest(handler ShutdownRequestHandler) {
+	n.shutdownRequestHandler = handler
+}
+
+// OnStart adds StartHandler. Unlike other Node event handlers, several StartHandler
+// may be added, they are called in order of adding. This should be done before
+// Node.Run called.
+func (n *Node) OnStart(handler StartHandler) {
+	n.startHandlers = append(n.startHandlers, handler)
+}
+
+// OnShutdown adds ShutdownHandler – for example, to flush application state or to
+// deregister from service discovery before clients are disconnected. Several
+// ShutdownHandler may be added, they are called in reverse order of adding – similar
+// to deferred calls. All handlers are called even if some of them return error.
+// This should be done before Node.Run called.
+func (n *Node) OnShutdown(handler ShutdownHandler) {
+	n.shutdownHandlers = append(n.shutdownHandlers, handler)
+}
+
 // eventHub allows binding client event handlers.
 // All eventHub methods are not goroutine-safe and supposed
 // to be called once before Node Run called.
@@ -1600,6 +2427,9 @@ func (h *brokerEventHandler) HandlePublication(ch string, pub *Publication, sp S
 	if pub == nil {
 		panic("nil Publication received, this must never happen")
 	}
+	if h.node.historyCache != nil {
+		h.node.historyCache.add(ch, pub, sp, h.node.historyCacheTTL(ch))
+	}
 	if h.node.config.GetChannelMediumOptions != nil {
 		mu := h.node.subLock(ch)
 		mu.Lock()
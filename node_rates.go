@@ -0,0 +1,108 @@
+package centrifuge
+
+import (
+	runtimemetrics "runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+)
+
+// NodeRates contains live rates of node calculated over the interval between two
+// node control frames (see Config.NodeHeartbeatInterval).
+type NodeRates struct {
+	// Publications is a number of publications made over the node per second.
+	Publications float64
+	// MessagesSent is a number of messages sent to client connections per second.
+	MessagesSent float64
+	// CPUUsage is an estimated share of available CPU time (according to GOMAXPROCS)
+	// used by node process, in range [0, 1].
+	CPUUsage float64
+	// Memory is a total number of bytes of memory mapped by the Go runtime.
+	Memory uint64
+}
+
+var runtimeRateSamples = []string{
+	"/cpu/classes/total:cpu-seconds",
+	"/cpu/classes/idle:cpu-seconds",
+	"/memory/classes/total:bytes",
+}
+
+// nodeRatesTracker calculates NodeRates using deltas of counters between calls.
+type nodeRatesTracker struct {
+	mu               sync.Mutex
+	samples          []runtimemetrics.Sample
+	lastTime         time.Time
+	lastPublications uint64
+	lastMessagesSent uint64
+	lastCPUTotal     float64
+	lastCPUIdle      float64
+}
+
+func newNodeRatesTracker() *nodeRatesTracker {
+	samples := make([]runtimemetrics.Sample, len(runtimeRateSamples))
+	for i, name := range runtimeRateSamples {
+		samples[i].Name = name
+	}
+	return &nodeRatesTracker{samples: samples}
+}
+
+func sampleFloat64(s runtimemetrics.Sample) float64 {
+	if s.Value.Kind() == runtimemetrics.KindFloat64 {
+		return s.Value.Float64()
+	}
+	return 0
+}
+
+func sampleUint64(s runtimemetrics.Sample) uint64 {
+	if s.Value.Kind() == runtimemetrics.KindUint64 {
+		return s.Value.Uint64()
+	}
+	return 0
+}
+
+// rates returns NodeRates since the previous call. On first call only memory is set.
+func (t *nodeRatesTracker) rates(publications, messagesSent uint64) *controlpb.NodeRates {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	runtimemetrics.Read(t.samples)
+	cpuTotal := sampleFloat64(t.samples[0])
+	cpuIdle := sampleFloat64(t.samples[1])
+	rates := &controlpb.NodeRates{
+		Memory: sampleUint64(t.samples[2]),
+	}
+	if !t.lastTime.IsZero() {
+		if elapsed := now.Sub(t.lastTime).Seconds(); elapsed > 0 {
+			rates.Publications = float64(publications-t.lastPublications) / elapsed
+			rates.MessagesSent = float64(messagesSent-t.lastMessagesSent) / elapsed
+		}
+		if totalDelta := cpuTotal - t.lastCPUTotal; totalDelta > 0 {
+			usage := 1 - (cpuIdle-t.lastCPUIdle)/totalDelta
+			if usage < 0 {
+				usage = 0
+			} else if usage > 1 {
+				usage = 1
+			}
+			rates.CpuUsage = usage
+		}
+	}
+	t.lastTime = now
+	t.lastPublications = publications
+	t.lastMessagesSent = messagesSent
+	t.lastCPUTotal = cpuTotal
+	t.lastCPUIdle = cpuIdle
+	return rates
+}
+
+func nodeRatesFromProto(r *controlpb.NodeRates) *NodeRates {
+	if r == nil {
+		return nil
+	}
+	return &NodeRates{
+		Publications: r.Publications,
+		MessagesSent: r.MessagesSent,
+		CPUUsage:     r.CpuUsage,
+		Memory:       r.Memory,
+	}
+}
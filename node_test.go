@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -242,6 +243,17 @@ func TestErrorMessage(t *testing.T) {
 	require.Equal(t, "111: too many requests", errMessage)
 }
 
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ErrorLimitExceeded)
+	require.ErrorIs(t, err, ErrorLimitExceeded)
+	require.NotErrorIs(t, err, ErrorInternal)
+	// Errors re-created from client protocol replies match by code.
+	require.ErrorIs(t, &Error{Code: ErrorUnknownChannel.Code, Message: "unknown channel"}, ErrorUnknownChannel)
+	require.Equal(t, ErrorLimitExceeded, toClientErr(err))
+	require.Equal(t, ErrorInternal, toClientErr(errors.New("boom")))
+	require.Equal(t, DisconnectPermissionDenied, extractUnidirectionalDisconnect(fmt.Errorf("wrapped: %w", ErrorPermissionDenied)))
+}
+
 func TestNode_Shutdown(t *testing.T) {
 	n := defaultNodeNoHandlers()
 	require.NoError(t, n.Shutdown(context.Background()))
@@ -250,6 +262,130 @@ func TestNode_Shutdown(t *testing.T) {
 	require.NoError(t, n.Shutdown(context.Background()))
 }
 
+func TestNode_LifecycleHandlers(t *testing.T) {
+	var calls []string
+	n, err := NewNode(
+		WithOnStart(func(ctx context.Context) error {
+			calls = append(calls, "start")
+			return nil
+		}),
+		WithOnShutdown(func(ctx context.Context) error {
+			calls = append(calls, "shutdown1")
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	n.OnShutdown(func(ctx context.Context) error {
+		require.True(t, n.shutdown)
+		calls = append(calls, "shutdown2")
+		return errors.New("boom")
+	})
+	require.NoError(t, n.Run())
+	require.Equal(t, []string{"start"}, calls)
+	err = n.Shutdown(context.Background())
+	require.ErrorContains(t, err, "boom")
+	require.Equal(t, []string{"start", "shutdown2", "shutdown1"}, calls)
+}
+
+func TestNode_StartHandlerError(t *testing.T) {
+	n, err := New(Config{})
+	require.NoError(t, err)
+	n.OnStart(func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	require.ErrorContains(t, n.Run(), "boom")
+	_ = n.Shutdown(context.Background())
+}
+
+func TestNewNode(t *testing.T) {
+	var logged bool
+	broker := NewTestBroker()
+	presenceManager := NewTestPresenceManager()
+	n, err := NewNode(
+		WithConfig(Config{Name: "test"}),
+		WithLogger(LogLevelInfo, func(entry LogEntry) { logged = true }),
+		WithBroker(func(_ *Node) (Broker, error) { return broker, nil }),
+		WithPresenceManager(func(_ *Node) (PresenceManager, error) { return presenceManager, nil }),
+		WithOnConnect(func(_ *Client) {}),
+		WithPublishMiddleware(func(next PublishFunc) PublishFunc { return next }),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "test", n.config.Name)
+	require.Equal(t, LogLevelInfo, n.config.LogLevel)
+	require.Equal(t, broker, n.broker)
+	require.Equal(t, presenceManager, n.presenceManager)
+	require.NotNil(t, n.clientEvents.connectHandler)
+	require.NotNil(t, n.publishFunc)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	n.logger.log(newLogEntry(LogLevelInfo, "test", nil))
+	require.True(t, logged)
+
+	_, err = NewNode(WithBroker(func(_ *Node) (Broker, error) { return nil, errors.New("boom") }))
+	require.Error(t, err)
+}
+
+type testContextBroker struct {
+	*TestBroker
+	publishCtx context.Context
+	historyCtx context.Context
+	removeCtx  context.Context
+}
+
+func (b *testContextBroker) PublishContext(ctx context.Context, ch string, data []byte, opts PublishOptions) (StreamPosition, bool, error) {
+	b.publishCtx = ctx
+	return b.Publish(ch, data, opts)
+}
+
+func (b *testContextBroker) HistoryContext(ctx context.Context, ch string, opts HistoryOptions) ([]*Publication, StreamPosition, error) {
+	b.historyCtx = ctx
+	return b.History(ch, opts)
+}
+
+func (b *testContextBroker) RemoveHistoryContext(ctx context.Context, ch string) error {
+	b.removeCtx = ctx
+	return b.RemoveHistory(ch)
+}
+
+func TestNode_ContextMethods(t *testing.T) {
+	broker := &testContextBroker{TestBroker: NewTestBroker()}
+	n := nodeWithBroker(broker)
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "1")
+	_, err := n.PublishContext(ctx, "test", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, "1", broker.publishCtx.Value(ctxKey{}))
+	_, err = n.HistoryContext(ctx, "test")
+	require.NoError(t, err)
+	require.Equal(t, "1", broker.historyCtx.Value(ctxKey{}))
+	require.NoError(t, n.RemoveHistoryContext(ctx, "test"))
+	require.Equal(t, "1", broker.removeCtx.Value(ctxKey{}))
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = n.PublishContext(canceledCtx, "test", []byte(`{}`))
+	require.ErrorIs(t, err, context.Canceled)
+	require.EqualValues(t, 1, atomic.LoadInt32(&broker.publishCount))
+	_, err = n.HistoryContext(canceledCtx, "test")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, n.RemoveHistoryContext(canceledCtx, "test"), context.Canceled)
+	_, err = n.PresenceContext(canceledCtx, "test")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = n.PresenceStatsContext(canceledCtx, "test")
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Context is also passed through publish middleware chain.
+	n.UsePublishMiddleware(func(next PublishFunc) PublishFunc {
+		return next
+	})
+	broker.publishCtx = nil
+	_, err = n.PublishContext(ctx, "test", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, "1", broker.publishCtx.Value(ctxKey{}))
+}
+
 func TestNode_shutdownCmd(t *testing.T) {
 	// Testing that shutdownCmd removes node from nodes registry.
 	n := defaultNodeNoHandlers()
@@ -278,14 +414,83 @@ func TestNodeRegistry(t *testing.T) {
 	info, ok := registry.get("node1")
 	require.True(t, ok)
 	require.Equal(t, "node1", info.Uid)
-	registry.clean(10 * time.Second)
+	require.Empty(t, registry.clean(10*time.Second))
 	time.Sleep(2 * time.Second)
-	registry.clean(time.Second)
+	removed := registry.clean(time.Second)
+	require.Len(t, removed, 1)
+	require.Equal(t, "node2", removed[0].Uid)
 	// Current node info should still be in node registry - we never delete it.
 	require.Equal(t, 1, len(registry.list()))
 	require.Equal(t, 1, registry.size())
 }
 
+func TestNodeRegistry_Left(t *testing.T) {
+	registry := newNodeRegistry("node1")
+	nodeInfo2 := controlpb.Node{Uid: "node2"}
+	require.True(t, registry.add(&nodeInfo2))
+	_, ok := registry.remove("node2")
+	require.True(t, ok)
+	// Heartbeat from left node which was in flight must be ignored.
+	require.False(t, registry.add(&nodeInfo2))
+	require.Equal(t, 0, registry.size())
+	time.Sleep(20 * time.Millisecond)
+	require.Empty(t, registry.clean(10*time.Millisecond))
+	require.True(t, registry.add(&nodeInfo2))
+}
+
+func TestNode_HeartbeatExpiration(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:                LogLevelTrace,
+		LogHandler:              func(entry LogEntry) {},
+		NodeHeartbeatInterval:   50 * time.Millisecond,
+		NodeHeartbeatExpiration: 150 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	left := make(chan NodeLeaveEvent, 1)
+	n.OnNodeLeave(func(e NodeLeaveEvent) {
+		left <- e
+	})
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	require.NoError(t, n.nodeCmd(&controlpb.Node{Uid: "other"}))
+	require.Equal(t, 2, n.nodes.size())
+	select {
+	case e := <-left:
+		require.Equal(t, "other", e.Node.UID)
+		require.True(t, e.Expired)
+	case <-time.After(time.Second):
+		require.Fail(t, "node not expired")
+	}
+	require.Equal(t, 1, n.nodes.size())
+}
+
+func TestNode_OnNodeJoinLeave(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	var joined []NodeJoinEvent
+	var left []NodeLeaveEvent
+	n.OnNodeJoin(func(e NodeJoinEvent) {
+		joined = append(joined, e)
+	})
+	n.OnNodeLeave(func(e NodeLeaveEvent) {
+		left = append(left, e)
+	})
+
+	require.NoError(t, n.nodeCmd(&controlpb.Node{Uid: "node2", Name: "second"}))
+	require.NoError(t, n.nodeCmd(&controlpb.Node{Uid: "node2", Name: "second"}))
+	require.Len(t, joined, 1)
+	require.Equal(t, "node2", joined[0].Node.UID)
+	require.Equal(t, "second", joined[0].Node.Name)
+
+	require.NoError(t, n.shutdownCmd("node2"))
+	require.NoError(t, n.shutdownCmd("node2"))
+	require.Len(t, left, 1)
+	require.Equal(t, "node2", left[0].Node.UID)
+	require.False(t, left[0].Expired)
+}
+
 func TestNodeLogHandler(t *testing.T) {
 	doneCh := make(chan struct{})
 	n, _ := New(Config{
@@ -368,6 +573,46 @@ func TestNode_Info(t *testing.T) {
 	require.Len(t, info.Nodes, 1)
 }
 
+func TestNode_SetInfoData(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	n.SetInfoData([]byte(`{"region":"eu"}`))
+	require.NoError(t, n.pubNode(""))
+	info, err := n.Info()
+	require.NoError(t, err)
+	require.Len(t, info.Nodes, 1)
+	require.Equal(t, []byte(`{"region":"eu"}`), info.Nodes[0].Data)
+
+	n.OnNodeInfoSend(func() NodeInfoSendReply {
+		return NodeInfoSendReply{Data: []byte(`{}`)}
+	})
+	require.NoError(t, n.pubNode(""))
+	info, err = n.Info()
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), info.Nodes[0].Data)
+}
+
+func TestNode_InfoRates(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	require.NoError(t, n.pubNode(""))
+	for i := 0; i < 10; i++ {
+		_, err := n.Publish("test", []byte(`{}`))
+		require.NoError(t, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, n.pubNode(""))
+	info, err := n.Info()
+	require.NoError(t, err)
+	require.Len(t, info.Nodes, 1)
+	rates := info.Nodes[0].Rates
+	require.NotNil(t, rates)
+	require.Greater(t, rates.Publications, float64(0))
+	require.GreaterOrEqual(t, rates.CPUUsage, float64(0))
+	require.LessOrEqual(t, rates.CPUUsage, float64(1))
+	require.Greater(t, rates.Memory, uint64(0))
+}
+
 func TestNode_handleJoin(t *testing.T) {
 	n := defaultNodeNoHandlers()
 	defer func() { _ = n.Shutdown(context.Background()) }()
@@ -472,6 +717,90 @@ func TestNode_Disconnect(t *testing.T) {
 	require.True(t, len(n.hub.UserConnections("42")) == 0)
 }
 
+func TestNode_DisconnectClient(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	client1 := newTestConnectedClientV2(t, n, "42")
+	client2 := newTestConnectedClientV2(t, n, "42")
+	require.Len(t, n.hub.UserConnections("42"), 2)
+
+	err := n.Disconnect("42", WithDisconnectClient(client1.ID()))
+	require.NoError(t, err)
+	require.Len(t, n.hub.UserConnections("42"), 1)
+	require.Contains(t, n.hub.UserConnections("42"), client2.ID())
+}
+
+func TestNode_DisconnectClientWhitelist(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	client1 := newTestConnectedClientV2(t, n, "42")
+	_ = newTestConnectedClientV2(t, n, "42")
+	_ = newTestConnectedClientV2(t, n, "42")
+	require.Len(t, n.hub.UserConnections("42"), 3)
+
+	err := n.Disconnect("42", WithDisconnectClientWhitelist([]string{client1.ID()}))
+	require.NoError(t, err)
+	require.Len(t, n.hub.UserConnections("42"), 1)
+	require.Contains(t, n.hub.UserConnections("42"), client1.ID())
+}
+
+func TestNode_Send(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	ctx1, cancelFn1 := context.WithCancel(context.Background())
+	transport1 := newTestTransport(cancelFn1)
+	transport1.sink = make(chan []byte, 100)
+	client1 := newTestConnectedClientWithTransport(t, ctx1, n, transport1, "42")
+
+	ctx2, cancelFn2 := context.WithCancel(context.Background())
+	transport2 := newTestTransport(cancelFn2)
+	transport2.sink = make(chan []byte, 100)
+	newTestConnectedClientWithTransport(t, ctx2, n, transport2, "42")
+
+	waitMessage := func(sink chan []byte, expected string) {
+		for {
+			select {
+			case data := <-sink:
+				if string(data) == expected {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				require.Fail(t, "timeout waiting message")
+				return
+			}
+		}
+	}
+
+	err := n.Send("42", []byte(`{"all":true}`))
+	require.NoError(t, err)
+	waitMessage(transport1.sink, `{"push":{"message":{"data":{"all":true}}}}`)
+	waitMessage(transport2.sink, `{"push":{"message":{"data":{"all":true}}}}`)
+
+	err = n.Send("42", []byte(`{"one":true}`), WithSendClient(client1.ID()))
+	require.NoError(t, err)
+	waitMessage(transport1.sink, `{"push":{"message":{"data":{"one":true}}}}`)
+	select {
+	case data := <-transport2.sink:
+		require.Fail(t, "unexpected message", string(data))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNode_pubSend(t *testing.T) {
+	node := nodeWithTestBroker()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	testBroker, _ := node.broker.(*TestBroker)
+	require.EqualValues(t, 1, testBroker.publishControlCount)
+
+	err := node.pubSend("42", []byte(`{}`), "", "")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, testBroker.publishControlCount)
+}
+
 func TestNode_pubUnsubscribe(t *testing.T) {
 	node := nodeWithTestBroker()
 	defer func() { _ = node.Shutdown(context.Background()) }()
@@ -496,6 +825,102 @@ func TestNode_pubDisconnect(t *testing.T) {
 	require.EqualValues(t, 2, testBroker.publishControlCount)
 }
 
+func TestNode_UsePublishMiddleware(t *testing.T) {
+	n := defaultNodeNoHandlers()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	var calls []string
+	n.UsePublishMiddleware(func(next PublishFunc) PublishFunc {
+		return func(channel string, data []byte, opts PublishOptions) (PublishResult, error) {
+			calls = append(calls, "first")
+			if channel == "forbidden" {
+				return PublishResult{}, ErrorPermissionDenied
+			}
+			opts.Tags = map[string]string{"checked": "1"}
+			return next(channel, data, opts)
+		}
+	}, func(next PublishFunc) PublishFunc {
+		return func(channel string, data []byte, opts PublishOptions) (PublishResult, error) {
+			calls = append(calls, "second")
+			require.Equal(t, "1", opts.Tags["checked"])
+			return next(channel, []byte(`{"enriched":true}`), opts)
+		}
+	})
+
+	_, err := n.Publish("test", []byte(`{}`), WithHistory(10, time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second"}, calls)
+
+	pubs, err := n.History("test", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, pubs.Publications, 1)
+	require.Equal(t, []byte(`{"enriched":true}`), pubs.Publications[0].Data)
+	require.Equal(t, "1", pubs.Publications[0].Tags["checked"])
+
+	calls = nil
+	_, err = n.Publish("forbidden", []byte(`{}`))
+	require.ErrorIs(t, err, ErrorPermissionDenied)
+	require.Equal(t, []string{"first"}, calls)
+}
+
+func TestNode_UseCommandMiddleware(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	var calls []string
+	node.UseCommandMiddleware(func(next CommandFunc) CommandFunc {
+		return func(client *Client, e CommandEvent) error {
+			calls = append(calls, "first:"+e.FrameType.String())
+			if e.Command.Rpc != nil && e.Command.Rpc.Method == "forbidden" {
+				return ErrorPermissionDenied
+			}
+			if e.Command.Rpc != nil && e.Command.Rpc.Method == "bye" {
+				return DisconnectForceNoReconnect
+			}
+			return next(client, e)
+		}
+	}, func(next CommandFunc) CommandFunc {
+		return func(client *Client, e CommandEvent) error {
+			calls = append(calls, "second")
+			return next(client, e)
+		}
+	})
+
+	var rpcCalls int
+	node.OnConnect(func(client *Client) {
+		client.OnRPC(func(e RPCEvent, cb RPCCallback) {
+			rpcCalls++
+			cb(RPCReply{}, nil)
+		})
+	})
+
+	client := newTestClientV2(t, node, "42")
+	disconnect, proceed := client.dispatchCommand(&protocol.Command{Id: 1, Connect: &protocol.ConnectRequest{}}, 0)
+	require.Nil(t, disconnect)
+	require.True(t, proceed)
+	require.Equal(t, []string{"first:connect", "second"}, calls)
+
+	calls = nil
+	disconnect, proceed = client.dispatchCommand(&protocol.Command{Id: 2, Rpc: &protocol.RPCRequest{Method: "test"}}, 0)
+	require.Nil(t, disconnect)
+	require.True(t, proceed)
+	require.Equal(t, []string{"first:rpc", "second"}, calls)
+	require.Equal(t, 1, rpcCalls)
+
+	calls = nil
+	disconnect, proceed = client.dispatchCommand(&protocol.Command{Id: 3, Rpc: &protocol.RPCRequest{Method: "forbidden"}}, 0)
+	require.Nil(t, disconnect)
+	require.True(t, proceed)
+	require.Equal(t, []string{"first:rpc"}, calls)
+	require.Equal(t, 1, rpcCalls)
+
+	disconnect, proceed = client.dispatchCommand(&protocol.Command{Id: 4, Rpc: &protocol.RPCRequest{Method: "bye"}}, 0)
+	require.NotNil(t, disconnect)
+	require.Equal(t, DisconnectForceNoReconnect.Code, disconnect.Code)
+	require.False(t, proceed)
+	require.Equal(t, 1, rpcCalls)
+}
+
 func TestNode_publishJoin(t *testing.T) {
 	n := nodeWithTestBroker()
 	defer func() { _ = n.Shutdown(context.Background()) }()
@@ -697,6 +1122,27 @@ func BenchmarkHistory(b *testing.B) {
 	b.ReportAllocs()
 }
 
+func TestNode_RequestNodeShutdown(t *testing.T) {
+	n := defaultTestNode()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+	require.Error(t, n.RequestNodeShutdown("", "test"))
+
+	events := make(chan ShutdownRequestEvent, 1)
+	n.OnShutdownRequest(func(e ShutdownRequestEvent) {
+		events <- e
+	})
+	require.NoError(t, n.RequestNodeShutdown(n.ID(), "test"))
+	select {
+	case e := <-events:
+		require.Equal(t, n.ID(), e.FromNodeID)
+		require.Equal(t, "test", e.Reason)
+	case <-time.After(time.Second):
+		require.Fail(t, "timeout")
+	}
+	// Request to another node is sent over Broker.
+	require.NoError(t, n.RequestNodeShutdown("other", "test"))
+}
+
 func TestNode_handleControl(t *testing.T) {
 	t.Run("BrokenData", func(t *testing.T) {
 		t.Parallel()
@@ -847,6 +1293,35 @@ func TestNode_handleControl(t *testing.T) {
 		require.Zero(t, len(n.hub.UserConnections("42")))
 	})
 
+	t.Run("ShutdownRequest", func(t *testing.T) {
+		t.Parallel()
+
+		n := defaultTestNode()
+		defer func() { _ = n.Shutdown(context.Background()) }()
+
+		enc := controlproto.NewProtobufEncoder()
+		cmdBytes, err := enc.EncodeCommand(&controlpb.Command{
+			Uid:             "other",
+			ShutdownRequest: &controlpb.ShutdownRequest{Reason: "maintenance"},
+		})
+		require.NoError(t, err)
+		// Ignored without handler.
+		require.NoError(t, n.handleControl(cmdBytes))
+
+		events := make(chan ShutdownRequestEvent, 1)
+		n.OnShutdownRequest(func(e ShutdownRequestEvent) {
+			events <- e
+		})
+		require.NoError(t, n.handleControl(cmdBytes))
+		select {
+		case e := <-events:
+			require.Equal(t, "other", e.FromNodeID)
+			require.Equal(t, "maintenance", e.Reason)
+		case <-time.After(time.Second):
+			require.Fail(t, "timeout")
+		}
+	})
+
 	t.Run("Refresh", func(t *testing.T) {
 		t.Parallel()
 
@@ -865,6 +1340,43 @@ func TestNode_handleControl(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Send", func(t *testing.T) {
+		t.Parallel()
+
+		n := defaultTestNode()
+		defer func() { _ = n.Shutdown(context.Background()) }()
+
+		ctx, cancelFn := context.WithCancel(context.Background())
+		transport := newTestTransport(cancelFn)
+		transport.sink = make(chan []byte, 100)
+		newTestConnectedClientWithTransport(t, ctx, n, transport, "42")
+
+		enc := controlproto.NewProtobufEncoder()
+		cmdBytes, err := enc.EncodeCommand(&controlpb.Command{
+			Uid: "",
+			Send: &controlpb.Send{
+				User: "42",
+				Data: []byte(`{}`),
+			},
+		})
+		require.NoError(t, err)
+		err = n.handleControl(cmdBytes)
+		require.NoError(t, err)
+
+	LOOP:
+		for {
+			select {
+			case data := <-transport.sink:
+				if string(data) == `{"push":{"message":{"data":{}}}}` {
+					break LOOP
+				}
+			case <-time.After(2 * time.Second):
+				require.Fail(t, "no data in sink")
+				return
+			}
+		}
+	})
+
 	t.Run("Unknown", func(t *testing.T) {
 		t.Parallel()
 
@@ -1385,3 +1897,58 @@ func TestNodeCheckPosition(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, isValid)
 }
+
+func TestNode_SlowOperationLog(t *testing.T) {
+	slowOps := make(chan LogEntry, 16)
+	n, err := New(Config{
+		LogLevel: LogLevelWarn,
+		LogHandler: func(entry LogEntry) {
+			if entry.Message == "slow operation" {
+				slowOps <- entry
+			}
+		},
+		SlowOperationThreshold: time.Nanosecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	_, err = n.History("test")
+	require.NoError(t, err)
+	select {
+	case entry := <-slowOps:
+		require.Equal(t, LogLevelWarn, entry.Level)
+		require.Equal(t, "history", entry.Fields["operation"])
+		require.Equal(t, "test", entry.Fields["channel"])
+	case <-time.After(time.Second):
+		require.Fail(t, "timeout waiting slow operation log")
+	}
+}
+
+func TestNode_PresencePage(t *testing.T) {
+	n := defaultTestNode()
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	for i := 0; i < 5; i++ {
+		clientID := strconv.Itoa(i)
+		require.NoError(t, n.addPresence("test", clientID, &ClientInfo{ClientID: clientID, UserID: "42"}))
+	}
+	page, err := n.PresencePage("test", WithPresenceLimit(3))
+	require.NoError(t, err)
+	require.Len(t, page.Presence, 3)
+	require.NotEmpty(t, page.Cursor)
+	page, err = n.PresencePage("test", WithPresenceLimit(3), WithPresenceCursor(page.Cursor))
+	require.NoError(t, err)
+	require.Len(t, page.Presence, 2)
+	require.Empty(t, page.Cursor)
+	page, err = n.PresencePage("test", WithPresenceUser("13"))
+	require.NoError(t, err)
+	require.Empty(t, page.Presence)
+
+	// PresenceManager without PresencePager support – paginated in memory.
+	n.SetPresenceManager(struct{ PresenceManager }{n.presenceManager})
+	page, err = n.PresencePage("test", WithPresenceLimit(4), WithPresenceUser("42"))
+	require.NoError(t, err)
+	require.Len(t, page.Presence, 4)
+	require.Equal(t, "3", page.Cursor)
+}
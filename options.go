@@ -10,12 +10,27 @@ func WithHistory(size int, ttl time.Duration, metaTTL ...time.Duration) PublishO
 	return func(opts *PublishOptions) {
 		opts.HistorySize = size
 		opts.HistoryTTL = ttl
+		opts.withoutHistory = false
 		if len(metaTTL) > 0 {
 			opts.HistoryMetaTTL = metaTTL[0]
 		}
 	}
 }
 
+// WithoutHistory tells Broker to not save message to history stream. Publications are
+// not saved to history by default, this option is useful to override WithHistory set
+// earlier in a list of options – for example, when applying default options for a channel
+// namespace and skipping history for a concrete publication. It also disables history
+// set by ChannelOptions of channel namespace.
+func WithoutHistory() PublishOption {
+	return func(opts *PublishOptions) {
+		opts.HistorySize = 0
+		opts.HistoryTTL = 0
+		opts.HistoryMetaTTL = 0
+		opts.withoutHistory = true
+	}
+}
+
 // WithIdempotencyKey tells Broker the idempotency key for the publication.
 // See PublishOptions.IdempotencyKey.
 func WithIdempotencyKey(key string) PublishOption {
@@ -53,6 +68,30 @@ func WithTags(meta map[string]string) PublishOption {
 	}
 }
 
+// WithPriority allows setting delivery priority hint of Publication.
+// See PublishOptions.Priority.
+func WithPriority(priority PublicationPriority) PublishOption {
+	return func(opts *PublishOptions) {
+		opts.Priority = priority
+	}
+}
+
+// WithFreshnessTTL allows setting FreshnessTTL of Publication.
+// See PublishOptions.FreshnessTTL.
+func WithFreshnessTTL(ttl time.Duration) PublishOption {
+	return func(opts *PublishOptions) {
+		opts.FreshnessTTL = ttl
+	}
+}
+
+// WithCompressionThreshold allows setting CompressionThreshold of Publication.
+// See PublishOptions.CompressionThreshold.
+func WithCompressionThreshold(threshold int) PublishOption {
+	return func(opts *PublishOptions) {
+		opts.CompressionThreshold = threshold
+	}
+}
+
 // SubscribeOptions define per-subscription options.
 type SubscribeOptions struct {
 	// ExpireAt defines time in future when subscription should expire,
@@ -102,6 +141,15 @@ type SubscribeOptions struct {
 	// Delta encoding is an EXPERIMENTAL feature and may be changed.
 	AllowedDeltaTypes []DeltaType
 
+	// Compression is a compression of publication data supported by subscriber. Client
+	// protocol has no way to negotiate compression, so application must find out whether
+	// client supports compression – for example, from subscribe request data or connect
+	// headers. Publications are compressed only when published with
+	// PublishOptions.CompressionThreshold, compressed publications have tag
+	// PublicationCompressionTag set. Compression is not applied when subscriber
+	// negotiated delta encoding.
+	Compression PublicationCompression
+
 	// clientID to subscribe.
 	clientID string
 	// sessionID to subscribe.
@@ -275,6 +323,73 @@ func WithRefreshInfo(info []byte) RefreshOption {
 	}
 }
 
+// PresencePageOption is a type to represent various PresencePage options.
+type PresencePageOption func(options *PresencePageOptions)
+
+// WithPresenceCursor sets cursor returned with previous presence page.
+func WithPresenceCursor(cursor string) PresencePageOption {
+	return func(opts *PresencePageOptions) {
+		opts.Cursor = cursor
+	}
+}
+
+// WithPresenceLimit sets desired max number of entries in presence page.
+func WithPresenceLimit(limit int) PresencePageOption {
+	return func(opts *PresencePageOptions) {
+		opts.Limit = limit
+	}
+}
+
+// WithPresenceUser allows returning presence of specific user only.
+func WithPresenceUser(userID string) PresencePageOption {
+	return func(opts *PresencePageOptions) {
+		opts.UserID = userID
+	}
+}
+
+// UpdatePresenceOptions define ClientInfo changes for UpdatePresence.
+type UpdatePresenceOptions struct {
+	// ConnInfo if set replaces connection info.
+	ConnInfo []byte
+	// ChanInfo if set replaces channel info.
+	ChanInfo []byte
+	// clientID to update.
+	clientID string
+	// sessionID to update.
+	sessionID string
+}
+
+// UpdatePresenceOption is a type to represent various UpdatePresence options.
+type UpdatePresenceOption func(options *UpdatePresenceOptions)
+
+// WithPresenceConnInfo replaces connection info attached to presence.
+func WithPresenceConnInfo(info []byte) UpdatePresenceOption {
+	return func(opts *UpdatePresenceOptions) {
+		opts.ConnInfo = info
+	}
+}
+
+// WithPresenceChanInfo replaces channel info attached to presence.
+func WithPresenceChanInfo(info []byte) UpdatePresenceOption {
+	return func(opts *UpdatePresenceOptions) {
+		opts.ChanInfo = info
+	}
+}
+
+// WithUpdatePresenceClient to limit presence update only for specified client ID.
+func WithUpdatePresenceClient(clientID string) UpdatePresenceOption {
+	return func(opts *UpdatePresenceOptions) {
+		opts.clientID = clientID
+	}
+}
+
+// WithUpdatePresenceSession to limit presence update only for specified session ID.
+func WithUpdatePresenceSession(sessionID string) UpdatePresenceOption {
+	return func(opts *UpdatePresenceOptions) {
+		opts.sessionID = sessionID
+	}
+}
+
 // UnsubscribeOptions ...
 type UnsubscribeOptions struct {
 	// clientID to unsubscribe.
@@ -334,7 +449,8 @@ func WithCustomDisconnect(disconnect Disconnect) DisconnectOption {
 	}
 }
 
-// WithDisconnectClient allows setting Client.
+// WithDisconnectClient allows setting Client. If set then only connection with
+// the given client ID will be disconnected, other connections of the user are kept.
 func WithDisconnectClient(clientID string) DisconnectOption {
 	return func(opts *DisconnectOptions) {
 		opts.clientID = clientID
@@ -348,13 +464,39 @@ func WithDisconnectSession(sessionID string) DisconnectOption {
 	}
 }
 
-// WithDisconnectClientWhitelist allows setting ClientWhitelist.
+// WithDisconnectClientWhitelist allows setting ClientWhitelist. Connections with
+// client IDs from the whitelist won't be disconnected.
 func WithDisconnectClientWhitelist(whitelist []string) DisconnectOption {
 	return func(opts *DisconnectOptions) {
 		opts.ClientWhitelist = whitelist
 	}
 }
 
+// SendOptions define some fields to alter behaviour of Send operation.
+type SendOptions struct {
+	// clientID to send message to.
+	clientID string
+	// sessionID to send message to.
+	sessionID string
+}
+
+// SendOption is a type to represent various Send options.
+type SendOption func(options *SendOptions)
+
+// WithSendClient allows sending message only to connection with the given client ID.
+func WithSendClient(clientID string) SendOption {
+	return func(opts *SendOptions) {
+		opts.clientID = clientID
+	}
+}
+
+// WithSendSession allows sending message only to connection with the given session ID.
+func WithSendSession(sessionID string) SendOption {
+	return func(opts *SendOptions) {
+		opts.sessionID = sessionID
+	}
+}
+
 // HistoryOption is a type to represent various History options.
 type HistoryOption func(options *HistoryOptions)
 
@@ -393,3 +535,177 @@ func WithHistoryMetaTTL(metaTTL time.Duration) HistoryOption {
 		opts.MetaTTL = metaTTL
 	}
 }
+
+// ChannelsOptions define some fields to alter behaviour of Channels operation.
+type ChannelsOptions struct {
+	// Pattern to filter channels, see WithChannelsPattern.
+	Pattern string
+	// Limit is a maximum number of channels to return. Zero value means no limit.
+	Limit int
+}
+
+// ChannelsOption is a type to represent various Channels options.
+type ChannelsOption func(options *ChannelsOptions)
+
+// WithChannelsPattern allows returning only channels matching a glob pattern.
+// Pattern syntax is the same as in path.Match from Go standard library.
+func WithChannelsPattern(pattern string) ChannelsOption {
+	return func(opts *ChannelsOptions) {
+		opts.Pattern = pattern
+	}
+}
+
+// WithChannelsLimit allows setting ChannelsOptions.Limit.
+func WithChannelsLimit(limit int) ChannelsOption {
+	return func(opts *ChannelsOptions) {
+		opts.Limit = limit
+	}
+}
+
+// OnlineUsersOptions define some fields to alter behaviour of OnlineUsers operation.
+type OnlineUsersOptions struct {
+	// SampleSize is a maximum number of user IDs to return, up to 1000. Zero value
+	// means that only number of users is returned.
+	SampleSize int
+}
+
+// OnlineUsersOption is a type to represent various OnlineUsers options.
+type OnlineUsersOption func(options *OnlineUsersOptions)
+
+// WithOnlineUsersSample allows setting OnlineUsersOptions.SampleSize.
+func WithOnlineUsersSample(size int) OnlineUsersOption {
+	return func(opts *OnlineUsersOptions) {
+		opts.SampleSize = size
+	}
+}
+
+// ConnectionsOptions define some fields to alter behaviour of FindConnections operation.
+type ConnectionsOptions struct {
+	// User to return connections for, see WithConnectionsUser.
+	User string
+	// Channel to return connections subscribed to.
+	Channel string
+	// Limit is a maximum number of connections to return. Zero value means no limit.
+	// Limit must be set if connections not filtered by user or channel.
+	Limit int
+
+	// userSet is true when User filter set, empty User filters anonymous connections.
+	userSet bool
+}
+
+// ConnectionsOption is a type to represent various FindConnections options.
+type ConnectionsOption func(options *ConnectionsOptions)
+
+// WithConnectionsUser allows returning only connections of a user. Empty user ID
+// matches anonymous connections.
+func WithConnectionsUser(userID string) ConnectionsOption {
+	return func(opts *ConnectionsOptions) {
+		opts.User = userID
+		opts.userSet = true
+	}
+}
+
+// WithConnectionsChannel allows returning only connections subscribed to a channel.
+func WithConnectionsChannel(channel string) ConnectionsOption {
+	return func(opts *ConnectionsOptions) {
+		opts.Channel = channel
+	}
+}
+
+// NodeOption is a type to represent various Node options for NewNode.
+type NodeOption func(*nodeOptions)
+
+type nodeOptions struct {
+	config              Config
+	brokerFunc          func(*Node) (Broker, error)
+	presenceManagerFunc func(*Node) (PresenceManager, error)
+	connectingHandler   ConnectingHandler
+	connectHandler      ConnectHandler
+	publishMiddlewares  []PublishMiddleware
+	commandMiddlewares  []CommandMiddleware
+	startHandlers       []StartHandler
+	shutdownHandlers    []ShutdownHandler
+}
+
+// WithConfig sets Node Config. Config is replaced as a whole, so WithConfig
+// should go before other options modifying Config fields.
+func WithConfig(c Config) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.config = c
+	}
+}
+
+// WithLogger sets Config.LogLevel and Config.LogHandler.
+func WithLogger(level LogLevel, handler LogHandler) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.config.LogLevel = level
+		opts.config.LogHandler = handler
+	}
+}
+
+// WithBroker sets function to create Broker for Node – Broker constructors
+// require Node instance. By default, MemoryBroker is used.
+func WithBroker(fn func(*Node) (Broker, error)) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.brokerFunc = fn
+	}
+}
+
+// WithPresenceManager sets function to create PresenceManager for Node. By
+// default, MemoryPresenceManager is used.
+func WithPresenceManager(fn func(*Node) (PresenceManager, error)) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.presenceManagerFunc = fn
+	}
+}
+
+// WithOnConnecting sets Node.OnConnecting handler.
+func WithOnConnecting(handler ConnectingHandler) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.connectingHandler = handler
+	}
+}
+
+// WithOnConnect sets Node.OnConnect handler.
+func WithOnConnect(handler ConnectHandler) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.connectHandler = handler
+	}
+}
+
+// WithPublishMiddleware appends middlewares to Node publish chain, see
+// Node.UsePublishMiddleware.
+func WithPublishMiddleware(middlewares ...PublishMiddleware) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.publishMiddlewares = append(opts.publishMiddlewares, middlewares...)
+	}
+}
+
+// WithCommandMiddleware appends middlewares to Node client command chain, see
+// Node.UseCommandMiddleware.
+func WithCommandMiddleware(middlewares ...CommandMiddleware) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.commandMiddlewares = append(opts.commandMiddlewares, middlewares...)
+	}
+}
+
+// WithOnStart adds StartHandler, see Node.OnStart.
+func WithOnStart(handler StartHandler) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.startHandlers = append(opts.startHandlers, handler)
+	}
+}
+
+// WithOnShutdown adds ShutdownHandler, see Node.OnShutdown.
+func WithOnShutdown(handler ShutdownHandler) NodeOption {
+	return func(opts *nodeOptions) {
+		opts.shutdownHandlers = append(opts.shutdownHandlers, handler)
+	}
+}
+
+// WithConnectionsLimit allows setting ConnectionsOptions.Limit.
+func WithConnectionsLimit(limit int) ConnectionsOption {
+	return func(opts *ConnectionsOptions) {
+		opts.Limit = limit
+	}
+}
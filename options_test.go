@@ -15,6 +15,16 @@ func TestWithHistory(t *testing.T) {
 	require.Equal(t, time.Second, opts.HistoryTTL)
 }
 
+func TestWithoutHistory(t *testing.T) {
+	opts := &PublishOptions{}
+	for _, opt := range []PublishOption{WithHistory(10, time.Second, time.Hour), WithoutHistory()} {
+		opt(opts)
+	}
+	require.Zero(t, opts.HistorySize)
+	require.Zero(t, opts.HistoryTTL)
+	require.Zero(t, opts.HistoryMetaTTL)
+}
+
 func TestWithIdempotencyKey(t *testing.T) {
 	opt := WithIdempotencyKey("ik")
 	opts := &PublishOptions{}
@@ -168,3 +178,17 @@ func TestRefreshOptions(t *testing.T) {
 	require.Equal(t, []byte(`test`), opts.Info)
 	require.Equal(t, "session", opts.sessionID)
 }
+
+func TestWithSendClient(t *testing.T) {
+	opt := WithSendClient("client")
+	opts := &SendOptions{}
+	opt(opts)
+	require.Equal(t, "client", opts.clientID)
+}
+
+func TestWithSendSession(t *testing.T) {
+	opt := WithSendSession("session")
+	opts := &SendOptions{}
+	opt(opts)
+	require.Equal(t, "session", opts.sessionID)
+}
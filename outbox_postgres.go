@@ -0,0 +1,213 @@
+package centrifuge
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostgresOutboxConfig is a config for PostgreSQL transactional outbox consumer.
+// Outbox table must have the following columns:
+//
+//	CREATE TABLE centrifuge_outbox (
+//		id BIGSERIAL PRIMARY KEY,
+//		channel TEXT NOT NULL,
+//		payload BYTEA NOT NULL,
+//		partition INTEGER NOT NULL DEFAULT 0,
+//		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+//	);
+//
+// Application inserts rows into outbox table in the same transaction with its
+// business data, so realtime events are published if and only if transaction
+// committed.
+type PostgresOutboxConfig struct {
+	// DB is a database handle. Centrifuge does not depend on PostgreSQL driver,
+	// open DB with driver of your choice (ex. github.com/jackc/pgx/v5/stdlib).
+	DB *sql.DB
+	// Table is an outbox table name. Zero value means "centrifuge_outbox".
+	Table string
+	// Partitions if set limits consumer to rows with partition column value from
+	// the list. Rows of the same partition are published in id order as long as
+	// each partition is consumed by one consumer. By default, all rows consumed.
+	Partitions []int
+	// BatchSize is a max number of rows fetched at once. Zero value means 100.
+	BatchSize int
+	// PollInterval is a delay before polling again when outbox is empty. Zero
+	// value means 100ms.
+	PollInterval time.Duration
+	// PublishOptions are applied to every publication, ex. WithHistory.
+	PublishOptions []PublishOption
+	// MaxBatchRetries limits the number of publish and commit retries while rows of
+	// a batch are locked by open transaction. After that transaction is rolled back
+	// and rows fetched again, so transaction is not kept open while Broker is
+	// unavailable. Zero value means 5.
+	MaxBatchRetries int
+}
+
+var outboxTableRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// NewPostgresOutboxConsumer creates Consumer publishing rows of PostgreSQL outbox table
+// into channels. Rows are selected with FOR UPDATE SKIP LOCKED inside transaction
+// and deleted in the same transaction after successful publish – so several nodes
+// may consume the same table concurrently and rows of failed nodes are republished.
+// Each publication has idempotency key "outbox:<id>", so with Broker supporting
+// idempotent publish (see WithIdempotencyKey) republished rows are not delivered
+// twice. Call Consumer.Run to start consuming.
+func NewPostgresOutboxConsumer(n *Node, config PostgresOutboxConfig) (*Consumer, error) {
+	source, err := newPostgresOutboxSource(config)
+	if err != nil {
+		return nil, err
+	}
+	publishOptions := config.PublishOptions
+	if config.MaxBatchRetries == 0 {
+		config.MaxBatchRetries = 5
+	}
+	return NewConsumer(n, ConsumerConfig{
+		Name:            "postgres_outbox",
+		Source:          source,
+		MaxBatchRetries: config.MaxBatchRetries,
+		Route: func(msg ConsumerMessage) ([]ConsumerPublication, error) {
+			opts := make([]PublishOption, 0, len(publishOptions)+1)
+			opts = append(opts, publishOptions...)
+			opts = append(opts, WithIdempotencyKey("outbox:"+msg.Headers["id"]))
+			return []ConsumerPublication{{
+				Channel: msg.Topic,
+				Data:    msg.Data,
+				Options: opts,
+			}}, nil
+		},
+	})
+}
+
+type postgresOutboxSource struct {
+	config      PostgresOutboxConfig
+	selectQuery string
+}
+
+var _ ConsumerReleaser = (*postgresOutboxSource)(nil)
+
+func newPostgresOutboxSource(config PostgresOutboxConfig) (*postgresOutboxSource, error) {
+	if config.DB == nil {
+		return nil, errors.New("outbox: DB required")
+	}
+	if config.Table == "" {
+		config.Table = "centrifuge_outbox"
+	}
+	if !outboxTableRegexp.MatchString(config.Table) {
+		return nil, fmt.Errorf("outbox: invalid table name %q", config.Table)
+	}
+	if config.BatchSize == 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = 100 * time.Millisecond
+	}
+	query := "SELECT id, channel, payload FROM " + config.Table
+	if len(config.Partitions) > 0 {
+		query += " WHERE partition IN (" + joinInts(config.Partitions) + ")"
+	}
+	query += " ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED"
+	return &postgresOutboxSource{config: config, selectQuery: query}, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *postgresOutboxSource) Fetch(ctx context.Context) ([]ConsumerMessage, error) {
+	tx, err := s.config.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.selectRows(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if len(messages) == 0 {
+		_ = tx.Rollback()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.config.PollInterval):
+		}
+		return nil, nil
+	}
+	return messages, nil
+}
+
+func (s *postgresOutboxSource) selectRows(ctx context.Context, tx *sql.Tx) ([]ConsumerMessage, error) {
+	rows, err := tx.QueryContext(ctx, s.selectQuery, s.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	var messages []ConsumerMessage
+	for rows.Next() {
+		var (
+			id      int64
+			channel string
+			payload []byte
+		)
+		if err := rows.Scan(&id, &channel, &payload); err != nil {
+			return nil, err
+		}
+		messages = append(messages, ConsumerMessage{
+			Topic:   channel,
+			Data:    payload,
+			Headers: map[string]string{"id": strconv.FormatInt(id, 10)},
+			Ack:     tx,
+		})
+	}
+	return messages, rows.Err()
+}
+
+// Release rolls back transaction of batch so rows are unlocked and fetched again.
+func (s *postgresOutboxSource) Release(_ context.Context, messages []ConsumerMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	tx, ok := messages[0].Ack.(*sql.Tx)
+	if !ok {
+		return errors.New("outbox: unexpected message ack")
+	}
+	err := tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+func (s *postgresOutboxSource) Commit(ctx context.Context, messages []ConsumerMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	tx, ok := messages[0].Ack.(*sql.Tx)
+	if !ok {
+		return errors.New("outbox: unexpected message ack")
+	}
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.Headers["id"]
+	}
+	_, err := tx.ExecContext(ctx, "DELETE FROM "+s.config.Table+" WHERE id IN ("+strings.Join(ids, ",")+")")
+	if errors.Is(err, sql.ErrTxDone) {
+		// Transaction rolled back upon previous attempt, rows are unlocked and
+		// will be fetched and published again.
+		return nil
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,238 @@
+package centrifuge
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testOutboxDriver is a minimal database/sql driver emulating outbox table.
+type testOutboxDriver struct {
+	mu        sync.Mutex
+	rows      map[int64]testOutboxRow
+	queries   []string
+	rollbacks int
+}
+
+type testOutboxRow struct {
+	channel string
+	payload []byte
+}
+
+func (d *testOutboxDriver) Open(_ string) (driver.Conn, error) {
+	return &testOutboxConn{driver: d}, nil
+}
+
+func (d *testOutboxDriver) Connect(_ context.Context) (driver.Conn, error) {
+	return d.Open("")
+}
+
+func (d *testOutboxDriver) Driver() driver.Driver {
+	return d
+}
+
+type testOutboxConn struct {
+	driver  *testOutboxDriver
+	deleted []int64
+}
+
+func (c *testOutboxConn) Prepare(query string) (driver.Stmt, error) {
+	return &testOutboxStmt{conn: c, query: query}, nil
+}
+
+func (c *testOutboxConn) Close() error { return nil }
+
+func (c *testOutboxConn) Begin() (driver.Tx, error) {
+	c.deleted = nil
+	return c, nil
+}
+
+func (c *testOutboxConn) Commit() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	for _, id := range c.deleted {
+		delete(c.driver.rows, id)
+	}
+	return nil
+}
+
+func (c *testOutboxConn) Rollback() error {
+	c.driver.mu.Lock()
+	c.driver.rollbacks++
+	c.driver.mu.Unlock()
+	c.deleted = nil
+	return nil
+}
+
+type testOutboxStmt struct {
+	conn  *testOutboxConn
+	query string
+}
+
+func (s *testOutboxStmt) Close() error  { return nil }
+func (s *testOutboxStmt) NumInput() int { return -1 }
+
+func (s *testOutboxStmt) Exec(_ []driver.Value) (driver.Result, error) {
+	s.conn.driver.mu.Lock()
+	s.conn.driver.queries = append(s.conn.driver.queries, s.query)
+	s.conn.driver.mu.Unlock()
+	idList := s.query[strings.Index(s.query, "(")+1 : strings.Index(s.query, ")")]
+	for _, part := range strings.Split(idList, ",") {
+		id, _ := strconv.ParseInt(part, 10, 64)
+		s.conn.deleted = append(s.conn.deleted, id)
+	}
+	return driver.RowsAffected(len(s.conn.deleted)), nil
+}
+
+func (s *testOutboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+	ids := make([]int64, 0, len(d.rows))
+	for id := range d.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	limit := int(args[0].(int64))
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	rows := &testOutboxRows{}
+	for _, id := range ids {
+		rows.values = append(rows.values, []driver.Value{id, d.rows[id].channel, d.rows[id].payload})
+	}
+	return rows, nil
+}
+
+type testOutboxRows struct {
+	values [][]driver.Value
+}
+
+func (r *testOutboxRows) Columns() []string { return []string{"id", "channel", "payload"} }
+func (r *testOutboxRows) Close() error      { return nil }
+
+func (r *testOutboxRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestNewPostgresOutboxConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err := NewPostgresOutboxConsumer(node, PostgresOutboxConfig{})
+	require.Error(t, err)
+	db := sql.OpenDB(&testOutboxDriver{})
+	defer func() { _ = db.Close() }()
+	_, err = NewPostgresOutboxConsumer(node, PostgresOutboxConfig{DB: db, Table: "outbox; DROP TABLE users"})
+	require.Error(t, err)
+
+	source, err := newPostgresOutboxSource(PostgresOutboxConfig{DB: db, Table: "app.outbox", Partitions: []int{1, 2}})
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id, channel, payload FROM app.outbox WHERE partition IN (1,2) ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED", source.selectQuery)
+}
+
+func TestPostgresOutboxConsumer(t *testing.T) {
+	node := defaultTestNode()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	d := &testOutboxDriver{rows: map[int64]testOutboxRow{
+		1: {channel: "a", payload: []byte(`{"n":1}`)},
+		2: {channel: "b", payload: []byte(`{"n":2}`)},
+		3: {channel: "a", payload: []byte(`{"n":3}`)},
+	}}
+	db := sql.OpenDB(d)
+	defer func() { _ = db.Close() }()
+
+	consumer, err := NewPostgresOutboxConsumer(node, PostgresOutboxConfig{
+		DB:             db,
+		BatchSize:      2,
+		PollInterval:   time.Millisecond,
+		PublishOptions: []PublishOption{WithHistory(10, time.Minute)},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.rows) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	result, err := node.History("a", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 2)
+	require.Equal(t, []byte(`{"n":1}`), result.Publications[0].Data)
+	require.Equal(t, []byte(`{"n":3}`), result.Publications[1].Data)
+	result, err = node.History("b", WithLimit(NoLimit))
+	require.NoError(t, err)
+	require.Len(t, result.Publications, 1)
+
+	d.mu.Lock()
+	require.Contains(t, d.queries, "DELETE FROM centrifuge_outbox WHERE id IN (1,2)")
+	require.Contains(t, d.queries, "DELETE FROM centrifuge_outbox WHERE id IN (3)")
+	d.mu.Unlock()
+}
+
+func TestPostgresOutboxConsumer_MaxBatchRetries(t *testing.T) {
+	broker := NewTestBroker()
+	broker.errorOnPublish = true
+	node := nodeWithBroker(broker)
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	d := &testOutboxDriver{rows: map[int64]testOutboxRow{
+		1: {channel: "a", payload: []byte(`{"n":1}`)},
+	}}
+	db := sql.OpenDB(d)
+	defer func() { _ = db.Close() }()
+
+	consumer, err := NewPostgresOutboxConsumer(node, PostgresOutboxConfig{
+		DB:              db,
+		PollInterval:    time.Millisecond,
+		MaxBatchRetries: 1,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	// Transaction rolled back after retries and rows fetched again.
+	require.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return d.rollbacks >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	require.Len(t, d.rows, 1)
+	var numSelects int
+	for _, q := range d.queries {
+		if strings.HasPrefix(q, "SELECT") {
+			numSelects++
+		}
+	}
+	require.GreaterOrEqual(t, numSelects, 2)
+}
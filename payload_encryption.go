@@ -0,0 +1,105 @@
+package centrifuge
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// PayloadEncryptionKey is an AEAD key used to encrypt publication payloads at rest.
+type PayloadEncryptionKey struct {
+	// ID of key. ID is stored together with encrypted payload, so payloads encrypted
+	// with older key can still be decrypted during key rotation. ID must not be longer
+	// than 255 bytes.
+	ID string
+	// AEAD cipher, for example AES-GCM – see NewAESGCMPayloadEncryptionKey.
+	AEAD cipher.AEAD
+}
+
+// NewAESGCMPayloadEncryptionKey creates PayloadEncryptionKey using AES-GCM. Key must
+// be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func NewAESGCMPayloadEncryptionKey(id string, key []byte) (PayloadEncryptionKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return PayloadEncryptionKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return PayloadEncryptionKey{}, err
+	}
+	return PayloadEncryptionKey{ID: id, AEAD: aead}, nil
+}
+
+// encryptedPayloadPrefix marks encrypted payloads. Payloads without prefix are
+// considered written before encryption was enabled and returned as is.
+var encryptedPayloadPrefix = []byte("\x00cfenc1")
+
+var errUnknownPayloadKey = errors.New("unknown payload encryption key")
+
+// payloadCipher encrypts payloads with the first key and decrypts payloads with
+// any known key. Encrypted payload format is:
+// prefix | key ID length (1 byte) | key ID | nonce | sealed data.
+// Channel is used as additional authenticated data, so encrypted payload can't be
+// moved to another channel.
+type payloadCipher struct {
+	keys []PayloadEncryptionKey
+	byID map[string]cipher.AEAD
+}
+
+func newPayloadCipher(keys []PayloadEncryptionKey) (*payloadCipher, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	c := &payloadCipher{keys: keys, byID: make(map[string]cipher.AEAD, len(keys))}
+	for i, key := range keys {
+		if key.AEAD == nil {
+			return nil, fmt.Errorf("payload encryption key %d: AEAD required", i)
+		}
+		if len(key.ID) > 255 {
+			return nil, fmt.Errorf("payload encryption key %d: ID too long", i)
+		}
+		if _, ok := c.byID[key.ID]; ok {
+			return nil, fmt.Errorf("payload encryption key %d: duplicate ID %q", i, key.ID)
+		}
+		c.byID[key.ID] = key.AEAD
+	}
+	return c, nil
+}
+
+func (c *payloadCipher) encrypt(ch string, data []byte) ([]byte, error) {
+	key := c.keys[0]
+	nonceSize := key.AEAD.NonceSize()
+	headerSize := len(encryptedPayloadPrefix) + 1 + len(key.ID)
+	out := make([]byte, headerSize+nonceSize, headerSize+nonceSize+len(data)+key.AEAD.Overhead())
+	copy(out, encryptedPayloadPrefix)
+	out[len(encryptedPayloadPrefix)] = byte(len(key.ID))
+	copy(out[len(encryptedPayloadPrefix)+1:], key.ID)
+	nonce := out[headerSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return key.AEAD.Seal(out, nonce, data, []byte(ch)), nil
+}
+
+func (c *payloadCipher) decrypt(ch string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedPayloadPrefix) {
+		return data, nil
+	}
+	data = data[len(encryptedPayloadPrefix):]
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, errors.New("malformed encrypted payload")
+	}
+	keyID := string(data[1 : 1+int(data[0])])
+	data = data[1+int(data[0]):]
+	aead, ok := c.byID[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownPayloadKey, keyID)
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted payload")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(ch))
+}
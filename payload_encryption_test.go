@@ -0,0 +1,73 @@
+package centrifuge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPayloadEncryptionKey(t *testing.T, id string, b byte) PayloadEncryptionKey {
+	key, err := NewAESGCMPayloadEncryptionKey(id, bytes.Repeat([]byte{b}, 32))
+	require.NoError(t, err)
+	return key
+}
+
+func TestPayloadCipher(t *testing.T) {
+	c, err := newPayloadCipher([]PayloadEncryptionKey{testPayloadEncryptionKey(t, "k1", 1)})
+	require.NoError(t, err)
+
+	encrypted, err := c.encrypt("ch", []byte(`{"secret":true}`))
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), "secret")
+
+	decrypted, err := c.decrypt("ch", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"secret":true}`), decrypted)
+
+	// Channel is authenticated.
+	_, err = c.decrypt("other", encrypted)
+	require.Error(t, err)
+
+	// Plain payloads written before encryption enabled returned as is.
+	decrypted, err = c.decrypt("ch", []byte(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{}`), decrypted)
+
+	_, err = c.decrypt("ch", encryptedPayloadPrefix)
+	require.Error(t, err)
+}
+
+func TestPayloadCipher_Rotation(t *testing.T) {
+	oldCipher, err := newPayloadCipher([]PayloadEncryptionKey{testPayloadEncryptionKey(t, "old", 1)})
+	require.NoError(t, err)
+	encrypted, err := oldCipher.encrypt("ch", []byte("data"))
+	require.NoError(t, err)
+
+	c, err := newPayloadCipher([]PayloadEncryptionKey{testPayloadEncryptionKey(t, "new", 2), testPayloadEncryptionKey(t, "old", 1)})
+	require.NoError(t, err)
+	decrypted, err := c.decrypt("ch", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), decrypted)
+
+	newEncrypted, err := c.encrypt("ch", []byte("data"))
+	require.NoError(t, err)
+	_, err = oldCipher.decrypt("ch", newEncrypted)
+	require.ErrorIs(t, err, errUnknownPayloadKey)
+}
+
+func TestNewPayloadCipher_Errors(t *testing.T) {
+	c, err := newPayloadCipher(nil)
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	_, err = newPayloadCipher([]PayloadEncryptionKey{{ID: "k1"}})
+	require.Error(t, err)
+
+	key := testPayloadEncryptionKey(t, "k1", 1)
+	_, err = newPayloadCipher([]PayloadEncryptionKey{key, key})
+	require.Error(t, err)
+
+	_, err = NewAESGCMPayloadEncryptionKey("k1", []byte("short"))
+	require.Error(t, err)
+}
@@ -1,5 +1,10 @@
 package centrifuge
 
+import (
+	"context"
+	"sort"
+)
+
 // PresenceStats represents a short presence information for channel.
 type PresenceStats struct {
 	// NumClients is a number of client connections in channel.
@@ -8,9 +13,24 @@ type PresenceStats struct {
 	NumUsers int
 }
 
-// PresenceManager is responsible for channel presence management.
+// PresenceManager is responsible for channel presence management. Node calls
+// AddPresence when client subscribes to channel with presence enabled and then
+// periodically (see Config.ClientPresenceUpdateInterval) while client stays
+// subscribed, RemovePresence is called when client unsubscribes. Since node may
+// die without calling RemovePresence implementations must expire entries not
+// updated for some time – this time must be larger than presence update interval.
+//
+// Methods are called concurrently from many goroutines so implementations must be
+// safe for concurrent use. ClientInfo passed to AddPresence and returned from
+// Presence must be treated as immutable. Channel without presence is not an error –
+// empty result must be returned.
+//
+// Centrifuge provides MemoryPresenceManager (used by default, single node only) and
+// RedisPresenceManager. See _examples for more implementations. PresenceManager may
+// additionally implement Closer, HealthChecker and PresencePager interfaces.
 type PresenceManager interface {
-	// Presence returns actual presence information for channel.
+	// Presence returns actual presence information for channel – a map of
+	// ClientInfo keyed by client ID.
 	Presence(ch string) (map[string]*ClientInfo, error)
 	// PresenceStats returns short stats of current presence data
 	// suitable for scenarios when caller does not need full client
@@ -22,6 +42,72 @@ type PresenceManager interface {
 	// (touched) after some configured time interval.
 	AddPresence(ch string, clientID string, info *ClientInfo) error
 	// RemovePresence removes presence information for connection
-	// with specified client and user identifiers.
+	// with specified client and user identifiers. Removing entry which
+	// does not exist is not an error.
 	RemovePresence(ch string, clientID string, userID string) error
 }
+
+// PresencePageOptions define which part of channel presence to return.
+type PresencePageOptions struct {
+	// Cursor returned with previous page. Empty cursor means first page.
+	Cursor string
+	// Limit is a desired max number of entries in page. Zero value means no limit.
+	// Implementations may return pages with fewer entries than limit (even empty
+	// page with non-empty cursor), iteration is complete only when returned
+	// cursor is empty.
+	Limit int
+	// UserID if set tells to return only connections of this user.
+	UserID string
+}
+
+// PresencePage is a part of channel presence information.
+type PresencePage struct {
+	// Presence contains client info keyed by client ID.
+	Presence map[string]*ClientInfo
+	// Cursor to get next page. Empty when all entries returned.
+	Cursor string
+}
+
+// PresencePager may be implemented by PresenceManager to iterate over channel
+// presence without loading it at once. This matters for channels with huge number
+// of members. If PresenceManager does not implement PresencePager then Node loads
+// full presence and paginates it in memory.
+type PresencePager interface {
+	// PresencePage returns page of channel presence.
+	PresencePage(ch string, opts PresencePageOptions) (PresencePage, error)
+}
+
+// ContextPresenceManager is an optional PresenceManager interface with context-aware
+// methods. Node uses it in Node.PresenceContext and Node.PresenceStatsContext.
+type ContextPresenceManager interface {
+	// PresenceContext is the same as PresenceManager.Presence but respects ctx.
+	PresenceContext(ctx context.Context, ch string) (map[string]*ClientInfo, error)
+	// PresenceStatsContext is the same as PresenceManager.PresenceStats but respects ctx.
+	PresenceStatsContext(ctx context.Context, ch string) (PresenceStats, error)
+}
+
+// paginatePresence returns page of presence map iterating over entries in client ID
+// order. Cursor is the last client ID of previous page.
+func paginatePresence(presence map[string]*ClientInfo, opts PresencePageOptions) PresencePage {
+	clientIDs := make([]string, 0, len(presence))
+	for clientID, info := range presence {
+		if clientID <= opts.Cursor && opts.Cursor != "" {
+			continue
+		}
+		if opts.UserID != "" && info.UserID != opts.UserID {
+			continue
+		}
+		clientIDs = append(clientIDs, clientID)
+	}
+	sort.Strings(clientIDs)
+	var cursor string
+	if opts.Limit > 0 && len(clientIDs) > opts.Limit {
+		clientIDs = clientIDs[:opts.Limit]
+		cursor = clientIDs[len(clientIDs)-1]
+	}
+	page := make(map[string]*ClientInfo, len(clientIDs))
+	for _, clientID := range clientIDs {
+		page[clientID] = presence[clientID]
+	}
+	return PresencePage{Presence: page, Cursor: cursor}
+}
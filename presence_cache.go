@@ -0,0 +1,101 @@
+package centrifuge
+
+import (
+	"sync"
+	"time"
+)
+
+type presenceCacheItem struct {
+	presence  map[string]*ClientInfo
+	expiresAt time.Time
+}
+
+type presenceStatsCacheItem struct {
+	stats     PresenceStats
+	expiresAt time.Time
+}
+
+// presenceCache keeps Presence and PresenceStats results for a short time
+// to reduce load on PresenceManager for channels where presence is requested
+// often. Entries are invalidated on local presence changes.
+type presenceCache struct {
+	ttl   time.Duration
+	mu    sync.RWMutex
+	items map[string]presenceCacheItem
+	stats map[string]presenceStatsCacheItem
+}
+
+func newPresenceCache(ttl time.Duration) *presenceCache {
+	return &presenceCache{
+		ttl:   ttl,
+		items: map[string]presenceCacheItem{},
+		stats: map[string]presenceStatsCacheItem{},
+	}
+}
+
+func (c *presenceCache) getPresence(ch string) (map[string]*ClientInfo, bool) {
+	c.mu.RLock()
+	item, ok := c.items[ch]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(item.expiresAt) {
+		return nil, false
+	}
+	// Copy map so callers can't modify cached value.
+	presence := make(map[string]*ClientInfo, len(item.presence))
+	for k, v := range item.presence {
+		presence[k] = v
+	}
+	return presence, true
+}
+
+func (c *presenceCache) setPresence(ch string, presence map[string]*ClientInfo) {
+	cached := make(map[string]*ClientInfo, len(presence))
+	for k, v := range presence {
+		cached[k] = v
+	}
+	c.mu.Lock()
+	c.items[ch] = presenceCacheItem{presence: cached, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+func (c *presenceCache) getPresenceStats(ch string) (PresenceStats, bool) {
+	c.mu.RLock()
+	item, ok := c.stats[ch]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(item.expiresAt) {
+		return PresenceStats{}, false
+	}
+	return item.stats, true
+}
+
+func (c *presenceCache) setPresenceStats(ch string, stats PresenceStats) {
+	c.mu.Lock()
+	c.stats[ch] = presenceStatsCacheItem{stats: stats, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+}
+
+// invalidate removes cached values for channel.
+func (c *presenceCache) invalidate(ch string) {
+	c.mu.Lock()
+	delete(c.items, ch)
+	delete(c.stats, ch)
+	c.mu.Unlock()
+}
+
+// removeExpired removes expired entries to not keep values for channels where
+// presence is not requested anymore.
+func (c *presenceCache) removeExpired() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ch, item := range c.items {
+		if now.After(item.expiresAt) {
+			delete(c.items, ch)
+		}
+	}
+	for ch, item := range c.stats {
+		if now.After(item.expiresAt) {
+			delete(c.stats, ch)
+		}
+	}
+}
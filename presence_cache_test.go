@@ -0,0 +1,76 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_PresenceCache(t *testing.T) {
+	n, err := New(Config{
+		LogLevel:         LogLevelTrace,
+		LogHandler:       func(entry LogEntry) {},
+		PresenceCacheTTL: time.Minute,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Run())
+	defer func() { _ = n.Shutdown(context.Background()) }()
+
+	require.NoError(t, n.addPresence("test", "1", &ClientInfo{ClientID: "1", UserID: "1"}))
+
+	result, err := n.Presence("test")
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 1)
+	stats, err := n.PresenceStats("test")
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.NumClients)
+
+	// Change presence bypassing Node as another node would do – cached result returned.
+	require.NoError(t, n.presenceManager.AddPresence("test", "2", &ClientInfo{ClientID: "2", UserID: "2"}))
+	result, err = n.Presence("test")
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 1)
+	stats, err = n.PresenceStats("test")
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.NumClients)
+
+	// Periodic presence refresh keeps cache.
+	require.NoError(t, n.refreshPresence("test", "1", &ClientInfo{ClientID: "1", UserID: "1"}))
+	result, err = n.Presence("test")
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 1)
+
+	// Modifying result must not affect cache.
+	delete(result.Presence, "1")
+	result, err = n.Presence("test")
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 1)
+
+	// Local change invalidates cache.
+	require.NoError(t, n.removePresence("test", "3", "3"))
+	result, err = n.Presence("test")
+	require.NoError(t, err)
+	require.Len(t, result.Presence, 2)
+	stats, err = n.PresenceStats("test")
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.NumClients)
+}
+
+func TestPresenceCache_Expiration(t *testing.T) {
+	c := newPresenceCache(time.Millisecond)
+	c.setPresence("test", map[string]*ClientInfo{"1": {}})
+	c.setPresenceStats("test", PresenceStats{NumClients: 1})
+	_, ok := c.getPresence("test")
+	require.True(t, ok)
+
+	time.Sleep(5 * time.Millisecond)
+	_, ok = c.getPresence("test")
+	require.False(t, ok)
+	_, ok = c.getPresenceStats("test")
+	require.False(t, ok)
+	c.removeExpired()
+	require.Empty(t, c.items)
+	require.Empty(t, c.stats)
+}
@@ -23,6 +23,7 @@ type MemoryPresenceManager struct {
 }
 
 var _ PresenceManager = (*MemoryPresenceManager)(nil)
+var _ PresencePager = (*MemoryPresenceManager)(nil)
 
 // MemoryPresenceManagerConfig is a MemoryPresenceManager config.
 type MemoryPresenceManagerConfig struct{}
@@ -51,6 +52,11 @@ func (m *MemoryPresenceManager) Presence(ch string) (map[string]*ClientInfo, err
 	return m.presenceHub.get(ch)
 }
 
+// PresencePage - see PresencePager interface description.
+func (m *MemoryPresenceManager) PresencePage(ch string, opts PresencePageOptions) (PresencePage, error) {
+	return m.presenceHub.getPage(ch, opts)
+}
+
 // PresenceStats - see PresenceManager interface description.
 func (m *MemoryPresenceManager) PresenceStats(ch string) (PresenceStats, error) {
 	return m.presenceHub.getStats(ch)
@@ -122,6 +128,12 @@ func (h *presenceHub) get(ch string) (map[string]*ClientInfo, error) {
 	return data, nil
 }
 
+func (h *presenceHub) getPage(ch string, opts PresencePageOptions) (PresencePage, error) {
+	h.RLock()
+	defer h.RUnlock()
+	return paginatePresence(h.presence[ch], opts), nil
+}
+
 func (h *presenceHub) getStats(ch string) (PresenceStats, error) {
 	h.RLock()
 	defer h.RUnlock()
@@ -2,6 +2,7 @@ package centrifuge
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -143,3 +144,45 @@ func BenchmarkMemoryPresence_OneChannel_Parallel(b *testing.B) {
 		}
 	})
 }
+
+func TestMemoryPresenceManager_PresencePage(t *testing.T) {
+	m := testMemoryPresenceManager(t)
+	defer func() { _ = m.node.Shutdown(context.Background()) }()
+
+	for i := 0; i < 25; i++ {
+		userID := "1"
+		if i%5 == 0 {
+			userID = "2"
+		}
+		require.NoError(t, m.AddPresence("channel", fmt.Sprintf("client%02d", i), &ClientInfo{ClientID: fmt.Sprintf("client%02d", i), UserID: userID}))
+	}
+
+	collected := map[string]*ClientInfo{}
+	var cursor string
+	var numPages int
+	for {
+		page, err := m.PresencePage("channel", PresencePageOptions{Cursor: cursor, Limit: 10})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Presence), 10)
+		for k, v := range page.Presence {
+			collected[k] = v
+		}
+		numPages++
+		cursor = page.Cursor
+		if cursor == "" {
+			break
+		}
+	}
+	require.Equal(t, 3, numPages)
+	require.Len(t, collected, 25)
+
+	page, err := m.PresencePage("channel", PresencePageOptions{UserID: "2"})
+	require.NoError(t, err)
+	require.Len(t, page.Presence, 5)
+	require.Empty(t, page.Cursor)
+
+	page, err = m.PresencePage("not_existing", PresencePageOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, page.Presence)
+	require.Empty(t, page.Cursor)
+}
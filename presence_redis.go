@@ -16,6 +16,7 @@ import (
 )
 
 var _ PresenceManager = (*RedisPresenceManager)(nil)
+var _ PresencePager = (*RedisPresenceManager)(nil)
 
 // RedisPresenceManager keeps presence in Redis thus allows scaling nodes.
 type RedisPresenceManager struct {
@@ -27,6 +28,7 @@ type RedisPresenceManager struct {
 	remPresenceScript   *rueidis.Lua
 	presenceScript      *rueidis.Lua
 	presenceStatsScript *rueidis.Lua
+	presencePageScript  *rueidis.Lua
 }
 
 // RedisPresenceManagerConfig is a config for RedisPresenceManager.
@@ -52,6 +54,14 @@ type RedisPresenceManagerConfig struct {
 	// Redis side instead of loading the entire presence information. By default, user mapping
 	// is not maintained.
 	EnableUserMapping func(channel string) bool
+
+	// EnableStatsOnly when returns true tells RedisPresenceManager to keep only data required
+	// for presence stats in channel – i.e. client and user counters without ClientInfo of
+	// each connection. This significantly reduces Redis memory usage for channels where only
+	// online count matters. Presence and PresencePage return empty results for such channels.
+	// User mapping is always maintained for stats only channels. By default, full presence
+	// information is stored.
+	EnableStatsOnly func(channel string) bool
 }
 
 var (
@@ -66,6 +76,9 @@ var (
 
 	//go:embed internal/redis_lua/presence_stats_get.lua
 	presenceStatsScriptSource string
+
+	//go:embed internal/redis_lua/presence_page.lua
+	presencePageScriptSource string
 )
 
 // NewRedisPresenceManager creates new RedisPresenceManager.
@@ -96,6 +109,7 @@ func NewRedisPresenceManager(n *Node, config RedisPresenceManagerConfig) (*Redis
 		remPresenceScript:   rueidis.NewLuaScript(remPresenceScriptSource),
 		presenceScript:      rueidis.NewLuaScript(presenceScriptSource),
 		presenceStatsScript: rueidis.NewLuaScript(presenceStatsScriptSource),
+		presencePageScript:  rueidis.NewLuaScript(presencePageScriptSource),
 	}
 	return m, nil
 }
@@ -104,6 +118,16 @@ func (m *RedisPresenceManager) Close(_ context.Context) error {
 	return nil
 }
 
+// CheckHealth pings all Redis shards. See HealthChecker.
+func (m *RedisPresenceManager) CheckHealth(ctx context.Context) error {
+	for _, s := range m.shards {
+		if err := s.ping(ctx); err != nil {
+			return fmt.Errorf("error ping Redis shard %s: %w", s.string(), err)
+		}
+	}
+	return nil
+}
+
 func (m *RedisPresenceManager) getShard(channel string) *RedisShard {
 	if !m.sharding {
 		return m.shards[0]
@@ -113,7 +137,10 @@ func (m *RedisPresenceManager) getShard(channel string) *RedisShard {
 
 // AddPresence - see PresenceManager interface description.
 func (m *RedisPresenceManager) AddPresence(ch string, uid string, info *ClientInfo) error {
-	return m.addPresence(m.getShard(ch), ch, uid, info)
+	s := m.getShard(ch)
+	return s.retry(context.Background(), func() error {
+		return m.addPresence(s, ch, uid, info)
+	})
 }
 
 func (m *RedisPresenceManager) addPresenceScriptKeysArgs(s *RedisShard, ch string, uid string, info *ClientInfo) ([]string, []string, error) {
@@ -131,19 +158,34 @@ func (m *RedisPresenceManager) addPresenceScriptKeysArgs(s *RedisShard, ch strin
 
 	expireAt := time.Now().Unix() + int64(expire)
 	useUserMapping := m.useUserMappingArg(ch)
-	args := []string{strconv.Itoa(expire), strconv.FormatInt(expireAt, 10), uid, convert.BytesToString(infoBytes), info.UserID, useUserMapping}
+	args := []string{strconv.Itoa(expire), strconv.FormatInt(expireAt, 10), uid, convert.BytesToString(infoBytes), info.UserID, useUserMapping, m.statsOnlyArg(ch)}
 
 	return keys, args, nil
 }
 
 func (m *RedisPresenceManager) useUserMappingArg(ch string) string {
 	useUserMapping := "0"
-	if m.config.EnableUserMapping != nil && m.config.EnableUserMapping(ch) {
+	if m.useUserMapping(ch) {
 		useUserMapping = "1"
 	}
 	return useUserMapping
 }
 
+func (m *RedisPresenceManager) useUserMapping(ch string) bool {
+	return (m.config.EnableUserMapping != nil && m.config.EnableUserMapping(ch)) || m.statsOnly(ch)
+}
+
+func (m *RedisPresenceManager) statsOnly(ch string) bool {
+	return m.config.EnableStatsOnly != nil && m.config.EnableStatsOnly(ch)
+}
+
+func (m *RedisPresenceManager) statsOnlyArg(ch string) string {
+	if m.statsOnly(ch) {
+		return "1"
+	}
+	return "0"
+}
+
 func (m *RedisPresenceManager) addPresence(s *RedisShard, ch string, uid string, info *ClientInfo) error {
 	keys, args, err := m.addPresenceScriptKeysArgs(s, ch, uid, info)
 	if err != nil {
@@ -158,7 +200,10 @@ func (m *RedisPresenceManager) addPresence(s *RedisShard, ch string, uid string,
 
 // RemovePresence - see PresenceManager interface description.
 func (m *RedisPresenceManager) RemovePresence(ch string, clientID string, userID string) error {
-	return m.removePresence(m.getShard(ch), ch, clientID, userID)
+	s := m.getShard(ch)
+	return s.retry(context.Background(), func() error {
+		return m.removePresence(s, ch, clientID, userID)
+	})
 }
 
 func (m *RedisPresenceManager) removePresenceScriptKeysArgs(s *RedisShard, ch string, uid string, userID string) ([]string, []string, error) {
@@ -170,7 +215,7 @@ func (m *RedisPresenceManager) removePresenceScriptKeysArgs(s *RedisShard, ch st
 	keys := []string{string(setKey), string(hashKey), string(userSetKey), string(userHashKey)}
 
 	useUserMapping := m.useUserMappingArg(ch)
-	args := []string{uid, userID, useUserMapping}
+	args := []string{uid, userID, useUserMapping, m.statsOnlyArg(ch)}
 	return keys, args, nil
 }
 
@@ -188,7 +233,19 @@ func (m *RedisPresenceManager) removePresence(s *RedisShard, ch string, clientID
 
 // Presence - see PresenceManager interface description.
 func (m *RedisPresenceManager) Presence(ch string) (map[string]*ClientInfo, error) {
-	return m.presence(m.getShard(ch), ch)
+	return m.PresenceContext(context.Background(), ch)
+}
+
+// PresenceContext - see ContextPresenceManager.PresenceContext.
+func (m *RedisPresenceManager) PresenceContext(ctx context.Context, ch string) (map[string]*ClientInfo, error) {
+	s := m.getShard(ch)
+	var presence map[string]*ClientInfo
+	err := s.retry(ctx, func() error {
+		var err error
+		presence, err = m.presence(ctx, s, ch)
+		return err
+	})
+	return presence, err
 }
 
 func (m *RedisPresenceManager) presenceScriptKeysArgs(s *RedisShard, ch string) ([]string, []string, error) {
@@ -210,23 +267,76 @@ func (m *RedisPresenceManager) presenceStatsScriptKeysArgs(s *RedisShard, ch str
 	keys := []string{string(setKey), string(hashKey), string(userSetKey), string(userHashKey)}
 
 	now := int(time.Now().Unix())
-	args := []string{strconv.Itoa(now)}
+	args := []string{strconv.Itoa(now), m.statsOnlyArg(ch)}
 
 	return keys, args, nil
 }
 
-func (m *RedisPresenceManager) presence(s *RedisShard, ch string) (map[string]*ClientInfo, error) {
+func (m *RedisPresenceManager) presence(ctx context.Context, s *RedisShard, ch string) (map[string]*ClientInfo, error) {
 	keys, args, err := m.presenceScriptKeysArgs(s, ch)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := m.presenceScript.Exec(context.Background(), s.client, keys, args).ToArray()
+	resp, err := m.presenceScript.Exec(ctx, s.client, keys, args).ToArray()
 	if err != nil {
 		return nil, err
 	}
 	return mapStringClientInfo(resp)
 }
 
+// PresencePage - see PresencePager interface description. Pages are iterated
+// using Redis HSCAN so page may contain slightly more or fewer entries than
+// requested limit.
+func (m *RedisPresenceManager) PresencePage(ch string, opts PresencePageOptions) (PresencePage, error) {
+	s := m.getShard(ch)
+	if opts.Limit <= 0 {
+		presence, err := m.presence(context.Background(), s, ch)
+		if err != nil {
+			return PresencePage{}, err
+		}
+		return paginatePresence(presence, PresencePageOptions{UserID: opts.UserID}), nil
+	}
+	cursor := opts.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	keys, args, err := m.presenceScriptKeysArgs(s, ch)
+	if err != nil {
+		return PresencePage{}, err
+	}
+	args = append(args, cursor, strconv.Itoa(opts.Limit))
+	resp, err := m.presencePageScript.Exec(context.Background(), s.client, keys, args).ToArray()
+	if err != nil {
+		return PresencePage{}, err
+	}
+	if len(resp) != 2 {
+		return PresencePage{}, errors.New("wrong Redis reply: must have two values")
+	}
+	nextCursor, err := resp[0].ToString()
+	if err != nil {
+		return PresencePage{}, errors.New("wrong Redis reply cursor")
+	}
+	if nextCursor == "0" {
+		nextCursor = ""
+	}
+	values, err := resp[1].ToArray()
+	if err != nil {
+		return PresencePage{}, err
+	}
+	presence, err := mapStringClientInfo(values)
+	if err != nil {
+		return PresencePage{}, err
+	}
+	if opts.UserID != "" {
+		for clientID, info := range presence {
+			if info.UserID != opts.UserID {
+				delete(presence, clientID)
+			}
+		}
+	}
+	return PresencePage{Presence: presence, Cursor: nextCursor}, nil
+}
+
 func mapStringClientInfo(result []rueidis.RedisMessage) (map[string]*ClientInfo, error) {
 	if len(result)%2 != 0 {
 		return nil, errors.New("mapStringClientInfo expects even number of values result")
@@ -251,12 +361,12 @@ func mapStringClientInfo(result []rueidis.RedisMessage) (map[string]*ClientInfo,
 	return m, nil
 }
 
-func (m *RedisPresenceManager) presenceStats(s *RedisShard, ch string) (PresenceStats, error) {
+func (m *RedisPresenceManager) presenceStats(ctx context.Context, s *RedisShard, ch string) (PresenceStats, error) {
 	keys, args, err := m.presenceStatsScriptKeysArgs(s, ch)
 	if err != nil {
 		return PresenceStats{}, err
 	}
-	replies, err := m.presenceStatsScript.Exec(context.Background(), s.client, keys, args).ToArray()
+	replies, err := m.presenceStatsScript.Exec(ctx, s.client, keys, args).ToArray()
 	if err != nil {
 		return PresenceStats{}, err
 	}
@@ -280,11 +390,23 @@ func (m *RedisPresenceManager) presenceStats(s *RedisShard, ch string) (Presence
 
 // PresenceStats - see PresenceManager interface description.
 func (m *RedisPresenceManager) PresenceStats(ch string) (PresenceStats, error) {
-	if m.config.EnableUserMapping != nil && m.config.EnableUserMapping(ch) {
-		return m.presenceStats(m.getShard(ch), ch)
+	return m.PresenceStatsContext(context.Background(), ch)
+}
+
+// PresenceStatsContext - see ContextPresenceManager.PresenceStatsContext.
+func (m *RedisPresenceManager) PresenceStatsContext(ctx context.Context, ch string) (PresenceStats, error) {
+	if m.useUserMapping(ch) {
+		s := m.getShard(ch)
+		var stats PresenceStats
+		err := s.retry(ctx, func() error {
+			var err error
+			stats, err = m.presenceStats(ctx, s, ch)
+			return err
+		})
+		return stats, err
 	}
 
-	presence, err := m.Presence(ch)
+	presence, err := m.PresenceContext(ctx, ch)
 	if err != nil {
 		return PresenceStats{}, err
 	}
@@ -410,3 +410,81 @@ func BenchmarkRedisPresenceStatsWithMapping(b *testing.B) {
 		})
 	}
 }
+
+func TestRedisPresenceManager_PresencePage(t *testing.T) {
+	for _, tt := range redisPresenceTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			node := testNode(t)
+			pm := newTestRedisPresenceManager(t, node, tt.UseCluster, false, tt.Port)
+			defer func() { _ = node.Shutdown(context.Background()) }()
+			defer stopRedisPresenceManager(pm)
+
+			for i := 0; i < 1000; i++ {
+				clientID := strconv.Itoa(i)
+				require.NoError(t, pm.AddPresence("channel", clientID, &ClientInfo{ClientID: clientID, UserID: strconv.Itoa(i % 10)}))
+			}
+
+			collected := map[string]*ClientInfo{}
+			var cursor string
+			for {
+				page, err := pm.PresencePage("channel", PresencePageOptions{Cursor: cursor, Limit: 100})
+				require.NoError(t, err)
+				for k, v := range page.Presence {
+					collected[k] = v
+				}
+				cursor = page.Cursor
+				if cursor == "" {
+					break
+				}
+			}
+			require.Len(t, collected, 1000)
+
+			page, err := pm.PresencePage("channel", PresencePageOptions{UserID: "1"})
+			require.NoError(t, err)
+			require.Len(t, page.Presence, 100)
+			require.Empty(t, page.Cursor)
+		})
+	}
+}
+
+func TestRedisPresenceManagerStatsOnly(t *testing.T) {
+	for _, tt := range redisPresenceTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			node := testNode(t)
+			pm := newTestRedisPresenceManager(t, node, tt.UseCluster, false, tt.Port)
+			defer func() { _ = node.Shutdown(context.Background()) }()
+			defer stopRedisPresenceManager(pm)
+			pm.config.EnableStatsOnly = func(ch string) bool {
+				return ch == "stats_only"
+			}
+
+			require.NoError(t, pm.AddPresence("stats_only", "uid", &ClientInfo{ClientID: "uid", UserID: "1", ConnInfo: []byte(`{}`)}))
+			require.NoError(t, pm.AddPresence("stats_only", "uid", &ClientInfo{ClientID: "uid", UserID: "1", ConnInfo: []byte(`{}`)}))
+			require.NoError(t, pm.AddPresence("stats_only", "uid-2", &ClientInfo{ClientID: "uid-2", UserID: "1"}))
+			require.NoError(t, pm.AddPresence("stats_only", "uid-3", &ClientInfo{ClientID: "uid-3", UserID: "2"}))
+
+			stats, err := pm.PresenceStats("stats_only")
+			require.NoError(t, err)
+			require.Equal(t, 3, stats.NumClients)
+			require.Equal(t, 2, stats.NumUsers)
+
+			// No ClientInfo stored.
+			p, err := pm.Presence("stats_only")
+			require.NoError(t, err)
+			require.Empty(t, p)
+
+			require.NoError(t, pm.RemovePresence("stats_only", "uid", "1"))
+			require.NoError(t, pm.RemovePresence("stats_only", "uid-3", "2"))
+			stats, err = pm.PresenceStats("stats_only")
+			require.NoError(t, err)
+			require.Equal(t, 1, stats.NumClients)
+			require.Equal(t, 1, stats.NumUsers)
+
+			// Other channels keep full presence.
+			require.NoError(t, pm.AddPresence("channel", "uid", &ClientInfo{ClientID: "uid", UserID: "1"}))
+			p, err = pm.Presence("channel")
+			require.NoError(t, err)
+			require.Len(t, p, 1)
+		})
+	}
+}
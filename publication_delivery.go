@@ -0,0 +1,121 @@
+package centrifuge
+
+import (
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// deliveryOptions of Publication, affect how Publication is sent to connections.
+type deliveryOptions struct {
+	priority             PublicationPriority
+	freshnessTTL         time.Duration
+	compressionThreshold int
+	// userAllowed if set limits delivery to connections of users for which it
+	// returns true. Used for publications of user-limited channels delivered over
+	// wildcard subscriptions.
+	userAllowed func(user string) bool
+}
+
+// deliveryFromPublishOptions returns deliveryOptions set in PublishOptions.
+func deliveryFromPublishOptions(opts PublishOptions) deliveryOptions {
+	return deliveryOptions{
+		priority:             opts.Priority,
+		freshnessTTL:         opts.FreshnessTTL,
+		compressionThreshold: opts.CompressionThreshold,
+	}
+}
+
+// pubWithDelivery returns Publication with delivery options set. Publication is
+// copied if options are not default since original may be saved to history.
+func pubWithDelivery(pub *Publication, d deliveryOptions) *Publication {
+	if d.priority == PublicationPriorityNormal && d.freshnessTTL <= 0 && d.compressionThreshold <= 0 {
+		return pub
+	}
+	pubCopy := *pub
+	pubCopy.delivery = d
+	return &pubCopy
+}
+
+// Delivery options are passed between nodes as fields of protocol Publication which
+// are not defined in client protocol schema. Field numbers are far from ones used by
+// client protocol, nodes which know nothing about delivery options skip such fields
+// as unknown. Publications with these fields are never saved to history and never
+// sent to clients – Publication is converted with pubFromProto before delivery.
+const (
+	deliveryPriorityField             protowire.Number = 1001
+	deliveryFreshnessTTLField         protowire.Number = 1002
+	deliveryCompressionThresholdField protowire.Number = 1003
+)
+
+// appendDeliveryFields appends delivery options to protobuf encoded Publication.
+func appendDeliveryFields(b []byte, d deliveryOptions) []byte {
+	if d.priority != PublicationPriorityNormal {
+		b = protowire.AppendTag(b, deliveryPriorityField, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(d.priority)))
+	}
+	if ttl := d.freshnessTTL.Milliseconds(); ttl > 0 {
+		b = protowire.AppendTag(b, deliveryFreshnessTTLField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(ttl))
+	}
+	if d.compressionThreshold > 0 {
+		b = protowire.AppendTag(b, deliveryCompressionThresholdField, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(d.compressionThreshold))
+	}
+	return b
+}
+
+// setProtoDelivery sets delivery options to protocol Publication, so they are
+// encoded together with Publication.
+func setProtoDelivery(pub *protocol.Publication, d deliveryOptions) {
+	if fields := appendDeliveryFields(nil, d); len(fields) > 0 {
+		pub.ProtoReflect().SetUnknown(fields)
+	}
+}
+
+// protoDelivery extracts delivery options from protocol Publication decoded from
+// data encoded with appendDeliveryFields.
+func protoDelivery(pub *protocol.Publication) deliveryOptions {
+	var d deliveryOptions
+	b := pub.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return d
+		}
+		b = b[n:]
+		if typ != protowire.VarintType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return d
+			}
+			b = b[n:]
+			continue
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return d
+		}
+		b = b[n:]
+		switch num {
+		case deliveryPriorityField:
+			d.priority = PublicationPriority(protowire.DecodeZigZag(v))
+		case deliveryFreshnessTTLField:
+			d.freshnessTTL = time.Duration(v) * time.Millisecond
+		case deliveryCompressionThresholdField:
+			d.compressionThreshold = int(v)
+		}
+	}
+	return d
+}
+
+// pubFromProtoWithDelivery converts protocol Publication received from other node
+// to Publication keeping delivery options.
+func pubFromProtoWithDelivery(pub *protocol.Publication) *Publication {
+	p := pubFromProto(pub)
+	if p != nil {
+		p.delivery = protoDelivery(pub)
+	}
+	return p
+}
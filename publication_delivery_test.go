@@ -0,0 +1,123 @@
+package centrifuge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryFields(t *testing.T) {
+	require.Empty(t, appendDeliveryFields(nil, deliveryOptions{}))
+	// Sub-millisecond TTL is ignored.
+	require.Empty(t, appendDeliveryFields(nil, deliveryOptions{freshnessTTL: time.Microsecond}))
+
+	for _, d := range []deliveryOptions{
+		{priority: PublicationPriorityHigh, freshnessTTL: time.Second},
+		{priority: PublicationPriorityLow, compressionThreshold: 100},
+		{freshnessTTL: 1500 * time.Millisecond},
+	} {
+		pub := &protocol.Publication{Data: []byte("{}"), Tags: map[string]string{"k": "v"}, Offset: 1}
+		data, err := pub.MarshalVT()
+		require.NoError(t, err)
+		data = appendDeliveryFields(data, d)
+
+		var decoded protocol.Publication
+		require.NoError(t, decoded.UnmarshalVT(data))
+		require.Equal(t, d, protoDelivery(&decoded))
+		p := pubFromProtoWithDelivery(&decoded)
+		require.Equal(t, d, p.delivery)
+		require.Equal(t, map[string]string{"k": "v"}, p.Tags)
+		require.Equal(t, uint64(1), p.Offset)
+
+		setProtoDelivery(pub, d)
+		var decodedSet protocol.Publication
+		data, err = pub.MarshalVT()
+		require.NoError(t, err)
+		require.NoError(t, decodedSet.UnmarshalVT(data))
+		require.Equal(t, d, protoDelivery(&decodedSet))
+	}
+	require.Nil(t, pubFromProtoWithDelivery(nil))
+}
+
+func TestPubWithDelivery(t *testing.T) {
+	pub := &Publication{Data: []byte("{}"), Tags: map[string]string{"k": "v"}}
+	require.Same(t, pub, pubWithDelivery(pub, deliveryOptions{}))
+
+	withDelivery := pubWithDelivery(pub, deliveryOptions{priority: PublicationPriorityHigh})
+	require.NotSame(t, pub, withDelivery)
+	require.Equal(t, PublicationPriorityHigh, withDelivery.delivery.priority)
+	require.Equal(t, PublicationPriorityNormal, pub.delivery.priority, "original publication must not be modified")
+	require.Equal(t, pub.Tags, withDelivery.Tags)
+}
+
+func TestNode_PublishPriority(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	transport := newTestTransport(func() {})
+	transport.sink = make(chan []byte, 100)
+	client, err := newClient(SetCredentials(context.Background(), &Credentials{UserID: "42"}), node, transport)
+	require.NoError(t, err)
+	connectClientV2(t, client)
+
+	rwWrapper := testReplyWriterWrapper()
+	subCtx := client.subscribeCmd(&protocol.SubscribeRequest{
+		Channel: "test",
+	}, SubscribeReply{}, &protocol.Command{}, false, time.Now(), rwWrapper.rw)
+	require.Nil(t, subCtx.disconnect)
+
+	_, err = node.Publish("test", []byte(`{"text": "test message"}`),
+		WithTags(map[string]string{"k": "v"}), WithPriority(PublicationPriorityHigh), WithFreshnessTTL(time.Minute))
+	require.NoError(t, err)
+
+	timeout := time.After(5 * time.Second)
+	for {
+		var data []byte
+		select {
+		case data = <-transport.sink:
+		case <-timeout:
+			require.Fail(t, "timeout receiving publication")
+		}
+		if !strings.Contains(string(data), "test message") {
+			continue
+		}
+		require.Contains(t, string(data), `"k":"v"`)
+		break
+	}
+}
+
+func TestNode_PublishDeliveryOptionsWithHistory(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{{Name: "history", ChannelOptions: ChannelOptions{HistorySize: 10, HistoryTTL: time.Minute}}})
+
+	_, err := node.Publish("test", []byte(`{}`), WithPriority(PublicationPriorityHigh), WithHistory(10, time.Minute))
+	require.ErrorIs(t, err, ErrorBadRequest)
+	_, err = node.Publish("test", []byte(`{}`), WithFreshnessTTL(time.Second), WithHistory(10, time.Minute))
+	require.ErrorIs(t, err, ErrorBadRequest)
+	_, err = node.Publish("history:test", []byte(`{}`), WithPriority(PublicationPriorityLow))
+	require.ErrorIs(t, err, ErrorBadRequest)
+
+	_, err = node.Publish("test", []byte(`{}`), WithPriority(PublicationPriorityLow), WithFreshnessTTL(time.Second))
+	require.NoError(t, err)
+}
+
+func TestMemoryBroker_DeliveryOptionsNotInHistory(t *testing.T) {
+	e := testMemoryBroker()
+	defer func() { _ = e.node.Shutdown(context.Background()) }()
+
+	_, _, err := e.Publish("channel", []byte("{}"), PublishOptions{
+		HistorySize:          10,
+		HistoryTTL:           time.Minute,
+		Tags:                 map[string]string{"k": "v"},
+		CompressionThreshold: 1,
+	})
+	require.NoError(t, err)
+	pubs, _, err := e.History("channel", HistoryOptions{Filter: HistoryFilter{Limit: -1}})
+	require.NoError(t, err)
+	require.Len(t, pubs, 1)
+	require.Equal(t, map[string]string{"k": "v"}, pubs[0].Tags)
+	require.Equal(t, deliveryOptions{}, pubs[0].delivery)
+}
@@ -0,0 +1,137 @@
+package centrifuge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	_ "embed"
+
+	"github.com/redis/rueidis"
+)
+
+// PublishRateLimit defines a token bucket limit for publications.
+type PublishRateLimit struct {
+	// Key of a bucket. Publications in channels with the same key share the
+	// same bucket – so it's possible to limit the entire channel namespace.
+	// If empty then channel name is used as a key.
+	Key string
+	// Rate is a number of publications per second. Zero value means no limit.
+	Rate float64
+	// Burst is a maximum number of publications allowed at once. If zero
+	// then Burst equals to Rate rounded up.
+	Burst int
+}
+
+// RedisPublishRateLimiterConfig is a config for RedisPublishRateLimiter.
+type RedisPublishRateLimiterConfig struct {
+	// Prefix to use before every key in Redis. By default, "centrifuge" prefix will be used.
+	Prefix string
+	// Shards is a slice of RedisShard to use. At least one shard must be provided.
+	// Buckets will be consistently sharded by key over provided Redis shards.
+	Shards []*RedisShard
+	// GetLimit returns PublishRateLimit for a channel. Must be set.
+	GetLimit func(channel string) PublishRateLimit
+}
+
+// RedisPublishRateLimiter is a cluster-wide rate limiter for channel publications.
+// It keeps token bucket state in Redis so the limit is shared by all nodes. Use
+// RedisPublishRateLimiter.Middleware with Node.UsePublishMiddleware to apply it.
+type RedisPublishRateLimiter struct {
+	config     RedisPublishRateLimiterConfig
+	shards     []*RedisShard
+	sharding   bool
+	takeScript *rueidis.Lua
+}
+
+//go:embed internal/redis_lua/rate_limit_take.lua
+var rateLimitTakeScriptSource string
+
+// NewRedisPublishRateLimiter creates new RedisPublishRateLimiter.
+func NewRedisPublishRateLimiter(_ *Node, config RedisPublishRateLimiterConfig) (*RedisPublishRateLimiter, error) {
+	if len(config.Shards) == 0 {
+		return nil, errors.New("rate limiter: no Redis shards provided in configuration")
+	}
+	if config.GetLimit == nil {
+		return nil, errors.New("rate limiter: GetLimit must be set")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "centrifuge"
+	}
+	return &RedisPublishRateLimiter{
+		config:     config,
+		shards:     config.Shards,
+		sharding:   len(config.Shards) > 1,
+		takeScript: rueidis.NewLuaScript(rateLimitTakeScriptSource),
+	}, nil
+}
+
+func (l *RedisPublishRateLimiter) getShard(key string) *RedisShard {
+	if !l.sharding {
+		return l.shards[0]
+	}
+	return l.shards[consistentIndex(key, len(l.shards))]
+}
+
+func (l *RedisPublishRateLimiter) bucketKey(s *RedisShard, key string) string {
+	if s.useCluster {
+		key = "{" + key + "}"
+	}
+	return l.config.Prefix + ".rate_limit.publish." + key
+}
+
+// Allow takes a token from a bucket for a channel and reports whether
+// publication is allowed.
+func (l *RedisPublishRateLimiter) Allow(ctx context.Context, channel string) (bool, error) {
+	limit := l.config.GetLimit(channel)
+	if limit.Rate <= 0 {
+		return true, nil
+	}
+	key := limit.Key
+	if key == "" {
+		key = channel
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(limit.Rate))
+	}
+	// Keep bucket state while it is not full.
+	ttl := time.Duration(float64(burst)/limit.Rate*float64(time.Second)) + time.Second
+
+	s := l.getShard(key)
+	args := []string{
+		strconv.FormatFloat(limit.Rate, 'f', -1, 64),
+		strconv.Itoa(burst),
+		strconv.FormatInt(ttl.Milliseconds(), 10),
+	}
+	allowed, err := l.takeScript.Exec(ctx, s.client, []string{l.bucketKey(s, key)}, args).AsInt64()
+	if err != nil {
+		return false, fmt.Errorf("error taking rate limit token: %w", err)
+	}
+	return allowed == 1, nil
+}
+
+// Middleware returns PublishMiddleware which rejects publications with
+// ErrorLimitExceeded when rate limit for a channel exceeded. Context passed to
+// Node.PublishContext is used for Redis calls.
+func (l *RedisPublishRateLimiter) Middleware() PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		return func(channel string, data []byte, opts PublishOptions) (PublishResult, error) {
+			ctx := opts.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			allowed, err := l.Allow(ctx, channel)
+			if err != nil {
+				return PublishResult{}, err
+			}
+			if !allowed {
+				return PublishResult{}, ErrorLimitExceeded
+			}
+			return next(channel, data, opts)
+		}
+	}
+}
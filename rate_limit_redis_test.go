@@ -0,0 +1,80 @@
+//go:build integration
+
+package centrifuge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisPublishRateLimiter(tb testing.TB, n *Node, getLimit func(string) PublishRateLimit) *RedisPublishRateLimiter {
+	s, err := NewRedisShard(n, testSingleRedisConf(0))
+	require.NoError(tb, err)
+	tb.Cleanup(s.Close)
+	l, err := NewRedisPublishRateLimiter(n, RedisPublishRateLimiterConfig{
+		Prefix:   getUniquePrefix(),
+		Shards:   []*RedisShard{s},
+		GetLimit: getLimit,
+	})
+	require.NoError(tb, err)
+	return l
+}
+
+func TestRedisPublishRateLimiter_Allow(t *testing.T) {
+	node := testNode(t)
+	l := newTestRedisPublishRateLimiter(t, node, func(channel string) PublishRateLimit {
+		if channel == "unlimited" {
+			return PublishRateLimit{}
+		}
+		return PublishRateLimit{Key: "chat", Rate: 0.1, Burst: 2}
+	})
+
+	for i := 0; i < 5; i++ {
+		allowed, err := l.Allow(context.Background(), "unlimited")
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, err := l.Allow(context.Background(), "chat:1")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	// Channels share the same bucket key.
+	allowed, err = l.Allow(context.Background(), "chat:2")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	allowed, err = l.Allow(context.Background(), "chat:1")
+	require.NoError(t, err)
+	require.False(t, allowed)
+}
+
+func TestRedisPublishRateLimiter_Middleware(t *testing.T) {
+	node := testNode(t)
+	l := newTestRedisPublishRateLimiter(t, node, func(channel string) PublishRateLimit {
+		return PublishRateLimit{Rate: 0.1, Burst: 1}
+	})
+	node.UsePublishMiddleware(l.Middleware())
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	_, err := node.Publish("test", []byte(`{}`))
+	require.NoError(t, err)
+	_, err = node.Publish("test", []byte(`{}`))
+	require.ErrorIs(t, err, ErrorLimitExceeded)
+}
+
+func TestRedisPublishRateLimiter_MiddlewareContext(t *testing.T) {
+	node := testNode(t)
+	l := newTestRedisPublishRateLimiter(t, node, func(channel string) PublishRateLimit {
+		return PublishRateLimit{Rate: 10}
+	})
+	node.UsePublishMiddleware(l.Middleware())
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := node.PublishContext(ctx, "test", []byte(`{}`))
+	require.ErrorIs(t, err, context.Canceled)
+}
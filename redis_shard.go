@@ -1,10 +1,12 @@
 package centrifuge
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"io"
 	"net"
 	"net/url"
 	"strconv"
@@ -76,6 +78,9 @@ func confFromAddress(address string, conf RedisShardConfig) (RedisShardConfig, e
 
 // NewRedisShard initializes new Redis shard.
 func NewRedisShard(_ *Node, conf RedisShardConfig) (*RedisShard, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Redis shard config: %w", err)
+	}
 	var err error
 	if conf.Address != "" {
 		conf, err = confFromAddress(conf.Address, conf)
@@ -144,6 +149,54 @@ func NewRedisShard(_ *Node, conf RedisShardConfig) (*RedisShard, error) {
 	return shard, nil
 }
 
+// Validate checks RedisShardConfig for invalid and conflicting values. It returns
+// an error listing all problems found, or nil if config is valid. Validate is called
+// by NewRedisShard.
+func (c RedisShardConfig) Validate() error {
+	var errs []error
+	modes := 0
+	for _, set := range []bool{c.Address != "", len(c.ClusterAddresses) > 0, len(c.SentinelAddresses) > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes == 0 {
+		errs = append(errs, errors.New("one of Address, ClusterAddresses or SentinelAddresses must be set"))
+	} else if modes > 1 {
+		errs = append(errs, errors.New("only one of Address, ClusterAddresses or SentinelAddresses must be set"))
+	}
+	if c.Address != "" {
+		if _, err := confFromAddress(c.Address, c); err != nil {
+			errs = append(errs, fmt.Errorf("invalid Address %q: %w", c.Address, err))
+		}
+	}
+	if len(c.SentinelAddresses) > 0 && c.SentinelMasterName == "" {
+		errs = append(errs, errors.New("SentinelMasterName must be set when using SentinelAddresses"))
+	}
+	if len(c.ClusterAddresses) > 0 && c.DB != 0 {
+		errs = append(errs, fmt.Errorf("DB must be 0 in Redis Cluster mode, got %d", c.DB))
+	}
+	if c.DB < 0 {
+		errs = append(errs, fmt.Errorf("DB must not be negative, got %d", c.DB))
+	}
+	if c.ConnectTimeout < 0 {
+		errs = append(errs, fmt.Errorf("ConnectTimeout must not be negative, got %s", c.ConnectTimeout))
+	}
+	if c.IOTimeout < 0 {
+		errs = append(errs, fmt.Errorf("IOTimeout must not be negative, got %s", c.IOTimeout))
+	}
+	if c.RetryPolicy.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("RetryPolicy.MaxAttempts must not be negative, got %d", c.RetryPolicy.MaxAttempts))
+	}
+	if c.RetryPolicy.MinBackoff < 0 || c.RetryPolicy.MaxBackoff < 0 {
+		errs = append(errs, errors.New("RetryPolicy backoff values must not be negative"))
+	}
+	if c.RetryPolicy.MinBackoff > 0 && c.RetryPolicy.MaxBackoff > 0 && c.RetryPolicy.MinBackoff > c.RetryPolicy.MaxBackoff {
+		errs = append(errs, fmt.Errorf("RetryPolicy.MinBackoff (%s) must not exceed RetryPolicy.MaxBackoff (%s)", c.RetryPolicy.MinBackoff, c.RetryPolicy.MaxBackoff))
+	}
+	return errors.Join(errs...)
+}
+
 // RedisShardConfig contains Redis connection options.
 type RedisShardConfig struct {
 	// Address is a Redis server connection address.
@@ -200,10 +253,97 @@ type RedisShardConfig struct {
 	// trying RESP3 first.
 	ForceRESP2 bool
 
+	// RetryPolicy of publish, history and presence operations made over this shard.
+	// By default, operations are not retried and Redis errors returned immediately.
+	RetryPolicy RedisRetryPolicy
+
 	network string
 	address string
 }
 
+// RedisRetryPolicy configures retries of Redis operations failed with transient
+// errors – for example, during Redis failover. Note that publication may be published
+// twice if Redis processed command but connection was broken before reply was received,
+// use PublishOptions.IdempotencyKey to avoid this.
+type RedisRetryPolicy struct {
+	// MaxAttempts is a maximum number of operation attempts including the first one.
+	// Zero or one means no retries.
+	MaxAttempts int
+	// MinBackoff is a delay before the first retry, doubled on every next retry up
+	// to MaxBackoff. Zero value means 50ms.
+	MinBackoff time.Duration
+	// MaxBackoff is a maximum delay between retries. Zero value means 1s.
+	MaxBackoff time.Duration
+	// IsRetryable reports whether operation failed with error should be retried.
+	// Zero value means IsRetryableRedisError.
+	IsRetryable func(error) bool
+}
+
+// IsRetryableRedisError reports whether error is a transient Redis error: network
+// error, client unable to connect, or Redis replied with LOADING, TRYAGAIN,
+// CLUSTERDOWN, MASTERDOWN or READONLY error.
+func IsRetryableRedisError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if redisErr, ok := rueidis.IsRedisErr(err); ok {
+		if redisErr.IsTryAgain() || redisErr.IsClusterDown() {
+			return true
+		}
+		msg := redisErr.Error()
+		for _, prefix := range []string{"LOADING", "MASTERDOWN", "READONLY"} {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	if errors.Is(err, rueidis.ErrClosing) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retry calls fn according to shard RetryPolicy. Retries stop when ctx is done.
+func (s *RedisShard) retry(ctx context.Context, fn func() error) error {
+	policy := s.config.RetryPolicy
+	err := fn()
+	if err == nil || policy.MaxAttempts <= 1 {
+		return err
+	}
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableRedisError
+	}
+	backoff := policy.MinBackoff
+	if backoff == 0 {
+		backoff = 50 * time.Millisecond
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = time.Second
+	}
+	for attempt := 1; attempt < policy.MaxAttempts && isRetryable(err); attempt++ {
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+		select {
+		case <-s.closeCh:
+			return err
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = fn()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 func (s *RedisShard) Close() {
 	s.closeOnce.Do(func() {
 		close(s.closeCh)
@@ -211,6 +351,10 @@ func (s *RedisShard) Close() {
 	})
 }
 
+func (s *RedisShard) ping(ctx context.Context) error {
+	return s.client.Do(ctx, s.client.B().Ping().Build()).Error()
+}
+
 func (s *RedisShard) string() string {
 	return s.config.address
 }
@@ -1,8 +1,14 @@
 package centrifuge
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/redis/rueidis"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,3 +55,87 @@ func TestRedisShard_ConfFromAddress(t *testing.T) {
 	require.Equal(t, 0, conf.DB)
 	require.Equal(t, "pass", conf.Password)
 }
+
+func TestIsRetryableRedisError(t *testing.T) {
+	require.False(t, IsRetryableRedisError(nil))
+	require.False(t, IsRetryableRedisError(context.Canceled))
+	require.False(t, IsRetryableRedisError(errors.New("boom")))
+	require.True(t, IsRetryableRedisError(io.EOF))
+	require.True(t, IsRetryableRedisError(rueidis.ErrClosing))
+	require.True(t, IsRetryableRedisError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+}
+
+func TestRedisShard_Retry(t *testing.T) {
+	shard := &RedisShard{
+		config: RedisShardConfig{RetryPolicy: RedisRetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+		}},
+		closeCh: make(chan struct{}),
+	}
+
+	var calls int
+	err := shard.retry(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return io.EOF
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = shard.retry(context.Background(), func() error {
+		calls++
+		return io.EOF
+	})
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 3, calls)
+
+	// Non-retryable error returned immediately.
+	calls = 0
+	boom := errors.New("boom")
+	err = shard.retry(context.Background(), func() error {
+		calls++
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+	require.Equal(t, 1, calls)
+
+	// Custom classifier.
+	shard.config.RetryPolicy.IsRetryable = func(err error) bool { return err == boom }
+	calls = 0
+	_ = shard.retry(context.Background(), func() error {
+		calls++
+		return boom
+	})
+	require.Equal(t, 3, calls)
+
+	// No retries without policy.
+	shard.config.RetryPolicy = RedisRetryPolicy{}
+	calls = 0
+	_ = shard.retry(context.Background(), func() error {
+		calls++
+		return io.EOF
+	})
+	require.Equal(t, 1, calls)
+}
+
+func TestRedisShardConfig_Validate(t *testing.T) {
+	require.NoError(t, RedisShardConfig{Address: "127.0.0.1:6379"}.Validate())
+	require.ErrorContains(t, RedisShardConfig{}.Validate(), "one of Address, ClusterAddresses or SentinelAddresses must be set")
+	err := RedisShardConfig{
+		Address:           "localhost:",
+		SentinelAddresses: []string{"127.0.0.1:26379"},
+		RetryPolicy:       RedisRetryPolicy{MinBackoff: time.Second, MaxBackoff: time.Millisecond},
+	}.Validate()
+	require.Error(t, err)
+	msg := err.Error()
+	require.Contains(t, msg, "only one of Address")
+	require.Contains(t, msg, "invalid Address")
+	require.Contains(t, msg, "SentinelMasterName must be set")
+	require.Contains(t, msg, "RetryPolicy.MinBackoff (1s) must not exceed")
+	_, err = NewRedisShard(nil, RedisShardConfig{})
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package centrifuge
+
+import (
+	"fmt"
+	"time"
+)
+
+// reloadableConfig contains Config options which can be changed at runtime
+// using Node.ReloadConfig.
+type reloadableConfig struct {
+	namespaces             *channelNamespaces
+	publicationMaxSize     int
+	slowOperationThreshold time.Duration
+	slowCommandThreshold   time.Duration
+	clientQueueMaxSize     int
+	reconnectAdvice        map[uint32]ReconnectAdvice
+}
+
+func newReloadableConfig(c Config) (*reloadableConfig, error) {
+	namespaces, err := newChannelNamespaces(c)
+	if err != nil {
+		return nil, err
+	}
+	clientQueueMaxSize := c.ClientQueueMaxSize
+	if clientQueueMaxSize == 0 {
+		clientQueueMaxSize = 1048576 // 1MB by default.
+	}
+	return &reloadableConfig{
+		namespaces:             namespaces,
+		publicationMaxSize:     c.PublicationMaxSize,
+		slowOperationThreshold: c.SlowOperationThreshold,
+		slowCommandThreshold:   c.SlowCommandThreshold,
+		clientQueueMaxSize:     clientQueueMaxSize,
+		reconnectAdvice:        c.ClientReconnectAdvice,
+	}, nil
+}
+
+// ReloadConfig applies options of Config which can be safely changed at runtime.
+// These are:
+//   - LogLevel (only if Node was created with LogHandler set)
+//   - SlowOperationThreshold and SlowCommandThreshold
+//   - ClientQueueMaxSize – applied to queues of already connected clients too
+//   - PublicationMaxSize
+//   - ClientReconnectAdvice
+//   - Namespaces and ChannelNamespaceBoundary
+//
+// All other Config fields are ignored. Options configured on transport level
+// (for example ping/pong intervals of PingPongConfig) are not affected. Options
+// are validated before being applied, in case of error Node keeps using previous
+// configuration. Namespace options apply to subsequent operations only – already
+// established subscriptions are not re-checked.
+func (n *Node) ReloadConfig(c Config) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("invalid node config: %w", err)
+	}
+	rc, err := newReloadableConfig(c)
+	if err != nil {
+		return err
+	}
+	n.reloadable.Store(rc)
+	n.logger.setLevel(c.LogLevel)
+	for _, client := range n.hub.Connections() {
+		if client.messageWriter != nil {
+			client.messageWriter.setMaxQueueSize(rc.clientQueueMaxSize)
+		}
+	}
+	return nil
+}
+
+func (n *Node) reloadableConfig() *reloadableConfig {
+	return n.reloadable.Load()
+}
@@ -0,0 +1,59 @@
+package centrifuge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNode_ReloadConfig(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "chat", ChannelOptions: ChannelOptions{PublicationMaxSize: 10}},
+	})
+	client := newTestSubscribedClientV2(t, node, "42", "chat:1")
+
+	_, ok := node.ChannelOptions("news:1")
+	require.False(t, ok)
+	_, err := node.Publish("chat:1", []byte(`{"data":"0123456789"}`))
+	require.ErrorIs(t, err, ErrorPublicationTooLarge)
+	require.True(t, node.LogEnabled(LogLevelDebug))
+
+	err = node.ReloadConfig(Config{
+		LogLevel:               LogLevelError,
+		ClientQueueMaxSize:     100,
+		SlowOperationThreshold: time.Second,
+		Namespaces: []ChannelNamespace{
+			{Name: "chat", ChannelOptions: ChannelOptions{}},
+			{Name: "news", ChannelOptions: ChannelOptions{HistorySize: 10, HistoryTTL: time.Minute}},
+		},
+	})
+	require.NoError(t, err)
+
+	opts, ok := node.ChannelOptions("news:1")
+	require.True(t, ok)
+	require.Equal(t, 10, opts.HistorySize)
+	_, err = node.Publish("chat:1", []byte(`{"data":"0123456789"}`))
+	require.NoError(t, err)
+	require.False(t, node.LogEnabled(LogLevelDebug))
+	require.True(t, node.LogEnabled(LogLevelError))
+	require.Equal(t, time.Second, node.reloadableConfig().slowOperationThreshold)
+	require.Equal(t, int64(100), client.messageWriter.maxQueueSize.Load())
+}
+
+func TestNode_ReloadConfigInvalid(t *testing.T) {
+	node := newTestNamespaceNode(t, []ChannelNamespace{
+		{Name: "chat", ChannelOptions: ChannelOptions{}},
+	})
+	err := node.ReloadConfig(Config{
+		LogLevel: LogLevelError,
+		Namespaces: []ChannelNamespace{
+			{Name: "chat", ChannelOptions: ChannelOptions{HistorySize: -1}},
+		},
+	})
+	require.Error(t, err)
+	// Previous configuration is kept.
+	_, ok := node.ChannelOptions("chat:1")
+	require.True(t, ok)
+	require.True(t, node.LogEnabled(LogLevelDebug))
+}
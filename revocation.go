@@ -0,0 +1,198 @@
+package centrifuge
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+)
+
+// revocationRegistry keeps revoked token IDs and issue-time cutoffs. Entries are kept
+// in memory of every Node until their expiration time.
+type revocationRegistry struct {
+	mu     sync.RWMutex
+	tokens map[string]int64 // token ID -> expire at.
+	users  map[string]issueCutoff
+	global issueCutoff
+}
+
+// issueCutoff revokes tokens issued before issuedBefore.
+type issueCutoff struct {
+	issuedBefore int64
+	expireAt     int64
+}
+
+func (c issueCutoff) active(now int64) bool {
+	return c.issuedBefore > 0 && (c.expireAt == 0 || c.expireAt > now)
+}
+
+func (c issueCutoff) merge(other issueCutoff) issueCutoff {
+	if other.issuedBefore > c.issuedBefore {
+		c.issuedBefore = other.issuedBefore
+	}
+	if c.expireAt != 0 && (other.expireAt == 0 || other.expireAt > c.expireAt) {
+		c.expireAt = other.expireAt
+	}
+	return c
+}
+
+func newRevocationRegistry() *revocationRegistry {
+	return &revocationRegistry{
+		tokens: map[string]int64{},
+		users:  map[string]issueCutoff{},
+	}
+}
+
+func (r *revocationRegistry) add(rev *controlpb.Revoke) {
+	now := time.Now().Unix()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeExpired(now)
+	if rev.TokenId != "" {
+		if expireAt, ok := r.tokens[rev.TokenId]; !ok || (expireAt != 0 && (rev.ExpireAt == 0 || rev.ExpireAt > expireAt)) {
+			r.tokens[rev.TokenId] = rev.ExpireAt
+		}
+		return
+	}
+	cutoff := issueCutoff{issuedBefore: rev.IssuedBefore, expireAt: rev.ExpireAt}
+	if rev.User == "" {
+		if r.global.active(now) {
+			cutoff = r.global.merge(cutoff)
+		}
+		r.global = cutoff
+		return
+	}
+	if existing, ok := r.users[rev.User]; ok {
+		cutoff = existing.merge(cutoff)
+	}
+	r.users[rev.User] = cutoff
+}
+
+func (r *revocationRegistry) removeExpired(now int64) {
+	for tokenID, expireAt := range r.tokens {
+		if expireAt != 0 && expireAt <= now {
+			delete(r.tokens, tokenID)
+		}
+	}
+	for user, cutoff := range r.users {
+		if !cutoff.active(now) {
+			delete(r.users, user)
+		}
+	}
+}
+
+// revoked checks whether token with provided credentials is revoked.
+func (r *revocationRegistry) revoked(user string, tokenID string, issuedAt int64) bool {
+	now := time.Now().Unix()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if tokenID != "" {
+		if expireAt, ok := r.tokens[tokenID]; ok && (expireAt == 0 || expireAt > now) {
+			return true
+		}
+	}
+	if user != "" && r.global.active(now) && issuedAt < r.global.issuedBefore {
+		return true
+	}
+	if cutoff, ok := r.users[user]; ok && cutoff.active(now) && issuedAt < cutoff.issuedBefore {
+		return true
+	}
+	return false
+}
+
+var (
+	errTokenIDRequired = errors.New("token ID required")
+	errUserIDRequired  = errors.New("user ID required")
+)
+
+// RevokeToken revokes token with provided ID (i.e. jti claim of JWT) on all nodes. Token ID
+// of connection must be passed to Centrifuge over Credentials.TokenID (and RefreshReply.TokenID
+// upon refresh). Connections authenticated with revoked token are disconnected with
+// DisconnectInvalidToken, attempts to connect or refresh with revoked token are rejected.
+// Revocation is kept in memory of nodes till expireAt Unix time (seconds), usually this
+// is an expiration time of token. Zero expireAt means keeping revocation till node restart.
+func (n *Node) RevokeToken(tokenID string, expireAt int64) error {
+	if tokenID == "" {
+		return errTokenIDRequired
+	}
+	return n.revoke(&controlpb.Revoke{TokenId: tokenID, ExpireAt: expireAt})
+}
+
+// RevokeUserTokens revokes all tokens of user issued before issuedBefore Unix time (seconds)
+// on all nodes. Zero issuedBefore means current time. Empty userID is not allowed, use
+// RevokeAllTokens to revoke tokens of all users. Issue time of connection token must be
+// passed to Centrifuge over Credentials.IssuedAt (and RefreshReply.IssuedAt upon refresh) –
+// connections without issue time are considered issued before any cutoff. Connections with
+// revoked tokens are disconnected with DisconnectInvalidToken, attempts to connect or refresh
+// with revoked token are rejected. Revocation is kept in memory of nodes till expireAt Unix
+// time (seconds), usually this is a max lifetime of tokens. Zero expireAt means keeping
+// revocation till node restart. Note, revocations are not persisted – newly started nodes
+// know nothing about previous revocations, so application may want to re-apply active
+// revocations in StartHandler.
+func (n *Node) RevokeUserTokens(userID string, issuedBefore int64, expireAt int64) error {
+	if userID == "" {
+		return errUserIDRequired
+	}
+	if issuedBefore == 0 {
+		issuedBefore = time.Now().Unix()
+	}
+	return n.revoke(&controlpb.Revoke{User: userID, IssuedBefore: issuedBefore, ExpireAt: expireAt})
+}
+
+// RevokeAllTokens revokes tokens of all users issued before issuedBefore Unix time (seconds)
+// on all nodes – for example, when token signing key was compromised. Zero issuedBefore means
+// current time. Anonymous connections are not affected. Otherwise, works the same way as
+// RevokeUserTokens – so all authenticated connections without Credentials.IssuedAt are
+// disconnected.
+func (n *Node) RevokeAllTokens(issuedBefore int64, expireAt int64) error {
+	if issuedBefore == 0 {
+		issuedBefore = time.Now().Unix()
+	}
+	return n.revoke(&controlpb.Revoke{IssuedBefore: issuedBefore, ExpireAt: expireAt})
+}
+
+func (n *Node) revoke(rev *controlpb.Revoke) error {
+	n.metrics.incActionCount("revoke")
+	n.handleRevoke(rev)
+	cmd := &controlpb.Command{
+		Uid:    n.uid,
+		Revoke: rev,
+	}
+	return n.publishControl(cmd, "")
+}
+
+// handleRevoke saves revocation and disconnects local connections with revoked tokens.
+func (n *Node) handleRevoke(rev *controlpb.Revoke) {
+	n.revocations.add(rev)
+	var connections map[string]*Client
+	if rev.TokenId == "" && rev.User != "" {
+		connections = n.hub.UserConnections(rev.User)
+	} else {
+		connections = n.hub.Connections()
+	}
+	for _, c := range connections {
+		if c.tokenRevoked() {
+			go func(c *Client) { _ = c.close(DisconnectInvalidToken) }(c)
+		}
+	}
+}
+
+// tokenRevoked checks whether connection token credentials are revoked.
+func (c *Client) tokenRevoked() bool {
+	c.mu.RLock()
+	user, tokenID, issuedAt := c.user, c.tokenID, c.issuedAt
+	c.mu.RUnlock()
+	return c.node.revocations.revoked(user, tokenID, issuedAt)
+}
+
+// applyRefreshToken updates token credentials of connection from RefreshReply.
+func (c *Client) applyRefreshToken(reply RefreshReply) {
+	if reply.TokenID == "" && reply.IssuedAt == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.tokenID = reply.TokenID
+	c.issuedAt = reply.IssuedAt
+	c.mu.Unlock()
+}
@@ -0,0 +1,175 @@
+package centrifuge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge/internal/controlpb"
+	"github.com/centrifugal/centrifuge/internal/controlproto"
+
+	"github.com/centrifugal/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationRegistry(t *testing.T) {
+	r := newRevocationRegistry()
+	now := time.Now().Unix()
+
+	require.False(t, r.revoked("42", "jti", now))
+
+	r.add(&controlpb.Revoke{TokenId: "jti", ExpireAt: now + 60})
+	require.True(t, r.revoked("42", "jti", now))
+	require.False(t, r.revoked("42", "other", now))
+
+	r.add(&controlpb.Revoke{TokenId: "expired", ExpireAt: now - 1})
+	require.False(t, r.revoked("42", "expired", now))
+
+	r.add(&controlpb.Revoke{User: "42", IssuedBefore: now})
+	require.True(t, r.revoked("42", "", now-1))
+	require.True(t, r.revoked("42", "", 0))
+	require.False(t, r.revoked("42", "", now))
+	require.False(t, r.revoked("43", "", now-1))
+
+	// Cutoff can only move forward.
+	r.add(&controlpb.Revoke{User: "42", IssuedBefore: now - 10})
+	require.True(t, r.revoked("42", "", now-1))
+
+	r.add(&controlpb.Revoke{IssuedBefore: now - 5, ExpireAt: now + 60})
+	require.True(t, r.revoked("43", "", now-6))
+	require.False(t, r.revoked("43", "", now-5))
+
+	// Expired entries removed on next addition.
+	r.add(&controlpb.Revoke{User: "44", IssuedBefore: now, ExpireAt: now - 1})
+	r.add(&controlpb.Revoke{TokenId: "another"})
+	require.NotContains(t, r.tokens, "expired")
+	require.NotContains(t, r.users, "44")
+}
+
+func newTestTokenClient(t *testing.T, node *Node, cred *Credentials) (*Client, *testTransport) {
+	transport := newTestTransport(func() {})
+	transport.setProtocolVersion(ProtocolVersion2)
+	client, err := newClient(SetCredentials(context.Background(), cred), node, transport)
+	require.NoError(t, err)
+	return client, transport
+}
+
+func waitTransportDisconnect(t *testing.T, transport *testTransport, disconnect Disconnect) {
+	t.Helper()
+	select {
+	case <-transport.closeCh:
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		require.Equal(t, disconnect.Code, transport.disconnect.Code)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting for disconnect")
+	}
+}
+
+func TestNode_RevokeToken(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	require.ErrorIs(t, node.RevokeToken("", 0), errTokenIDRequired)
+
+	client, transport := newTestTokenClient(t, node, &Credentials{UserID: "42", TokenID: "jti"})
+	connectClientV2(t, client)
+	other, otherTransport := newTestTokenClient(t, node, &Credentials{UserID: "42", TokenID: "other"})
+	connectClientV2(t, other)
+
+	require.NoError(t, node.RevokeToken("jti", time.Now().Unix()+60))
+	waitTransportDisconnect(t, transport, DisconnectInvalidToken)
+	otherTransport.mu.Lock()
+	require.False(t, otherTransport.closed)
+	otherTransport.mu.Unlock()
+
+	client, _ = newTestTokenClient(t, node, &Credentials{UserID: "42", TokenID: "jti"})
+	_, err := client.connectCmd(&protocol.ConnectRequest{}, &protocol.Command{}, time.Now(), testReplyWriterWrapper().rw)
+	require.Equal(t, DisconnectInvalidToken, err)
+}
+
+func TestNode_RevokeUserTokens(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	now := time.Now().Unix()
+	client, transport := newTestTokenClient(t, node, &Credentials{UserID: "42", IssuedAt: now - 10})
+	connectClientV2(t, client)
+
+	require.NoError(t, node.RevokeUserTokens("42", now, 0))
+	waitTransportDisconnect(t, transport, DisconnectInvalidToken)
+
+	// Token issued after cutoff is still valid.
+	client, _ = newTestTokenClient(t, node, &Credentials{UserID: "42", IssuedAt: now})
+	connectClientV2(t, client)
+
+	// Other users not affected.
+	client, _ = newTestTokenClient(t, node, &Credentials{UserID: "43", IssuedAt: now - 10})
+	connectClientV2(t, client)
+
+	require.ErrorIs(t, node.RevokeUserTokens("", now, 0), errUserIDRequired)
+}
+
+func TestNode_RevokeAllTokens(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	now := time.Now().Unix()
+	client, transport := newTestTokenClient(t, node, &Credentials{UserID: "42"})
+	connectClientV2(t, client)
+
+	require.NoError(t, node.RevokeAllTokens(now, 0))
+	waitTransportDisconnect(t, transport, DisconnectInvalidToken)
+
+	// Token issued after cutoff is still valid.
+	client, _ = newTestTokenClient(t, node, &Credentials{UserID: "43", IssuedAt: now})
+	connectClientV2(t, client)
+
+	// Anonymous connections not affected.
+	require.False(t, node.revocations.revoked("", "", 0))
+}
+
+func TestNode_RevokeControl(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client, transport := newTestTokenClient(t, node, &Credentials{UserID: "42", TokenID: "jti"})
+	connectClientV2(t, client)
+
+	enc := controlproto.NewProtobufEncoder()
+	cmdBytes, err := enc.EncodeCommand(&controlpb.Command{
+		Uid:    "other",
+		Revoke: &controlpb.Revoke{TokenId: "jti"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.handleControl(cmdBytes))
+	waitTransportDisconnect(t, transport, DisconnectInvalidToken)
+}
+
+func TestClientSideRefresh_RevokedToken(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	node.OnConnecting(func(ctx context.Context, event ConnectEvent) (ConnectReply, error) {
+		return ConnectReply{ClientSideRefresh: true}, nil
+	})
+	node.OnConnect(func(client *Client) {
+		client.OnRefresh(func(e RefreshEvent, cb RefreshCallback) {
+			cb(RefreshReply{ExpireAt: time.Now().Unix() + 60, TokenID: e.Token}, nil)
+		})
+	})
+
+	client, transport := newTestTokenClient(t, node, &Credentials{UserID: "42", ExpireAt: time.Now().Unix() + 60, TokenID: "jti1"})
+	connectClientV2(t, client)
+	require.NoError(t, node.RevokeToken("jti2", 0))
+
+	rwWrapper := testReplyWriterWrapper()
+	err := client.handleRefresh(&protocol.RefreshRequest{Token: "jti3"}, &protocol.Command{}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	require.Nil(t, rwWrapper.replies[0].Error)
+	require.Equal(t, "jti3", client.tokenID)
+
+	err = client.handleRefresh(&protocol.RefreshRequest{Token: "jti2"}, &protocol.Command{}, time.Now(), rwWrapper.rw)
+	require.NoError(t, err)
+	waitTransportDisconnect(t, transport, DisconnectInvalidToken)
+}
@@ -0,0 +1,191 @@
+package centrifuge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// ErrInvalidResumptionToken returned by Node.ResumeSession when resumption token is
+// malformed, has wrong signature, expired or connection token it was issued for is
+// revoked.
+var ErrInvalidResumptionToken = errors.New("invalid resumption token")
+
+// defaultSessionResumptionTTL is used when Config.SessionResumptionTTL not set.
+const defaultSessionResumptionTTL = time.Minute
+
+// resumptionToken is a payload of session resumption token.
+type resumptionToken struct {
+	User     string                          `json:"u,omitempty"`
+	Info     []byte                          `json:"i,omitempty"`
+	ExpireAt int64                           `json:"ce,omitempty"` // Connection credentials expiration.
+	TokenID  string                          `json:"ti,omitempty"` // Connection token ID.
+	IssuedAt int64                           `json:"ia,omitempty"` // Connection token issue time.
+	Exp      int64                           `json:"e"`            // Token expiration.
+	Channels map[string]resumptionTokenState `json:"c,omitempty"`
+}
+
+type resumptionTokenState struct {
+	Info     []byte `json:"i,omitempty"`
+	Flags    uint8  `json:"f,omitempty"`
+	Offset   uint64 `json:"o,omitempty"`
+	Epoch    string `json:"ep,omitempty"`
+	ExpireAt int64  `json:"e,omitempty"`
+}
+
+func (n *Node) sessionResumptionTTL() time.Duration {
+	if n.config.SessionResumptionTTL > 0 {
+		return n.config.SessionResumptionTTL
+	}
+	return defaultSessionResumptionTTL
+}
+
+func signResumptionPayload(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// resumptionToken issues session resumption token for authenticated client.
+func (c *Client) resumptionToken() (string, error) {
+	c.mu.RLock()
+	token := resumptionToken{
+		User:     c.user,
+		Info:     c.info,
+		ExpireAt: c.exp,
+		TokenID:  c.tokenID,
+		IssuedAt: c.issuedAt,
+		Exp:      time.Now().Add(c.node.sessionResumptionTTL()).Unix(),
+		Channels: make(map[string]resumptionTokenState, len(c.channels)),
+	}
+	for ch, ctx := range c.channels {
+		if !channelHasFlag(ctx.flags, flagSubscribed) {
+			continue
+		}
+		token.Channels[ch] = resumptionTokenState{
+			Info:     ctx.info,
+			Flags:    ctx.flags,
+			Offset:   ctx.streamPosition.Offset,
+			Epoch:    ctx.streamPosition.Epoch,
+			ExpireAt: ctx.expireAt,
+		}
+	}
+	c.mu.RUnlock()
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + signResumptionPayload(c.node.config.SessionResumptionSecret, payload), nil
+}
+
+// sendResumptionToken sends resumption token to a client in async message. Called
+// on graceful Node shutdown right before disconnecting client.
+func (c *Client) sendResumptionToken() {
+	c.mu.RLock()
+	authenticated := c.authenticated
+	c.mu.RUnlock()
+	if !authenticated {
+		return
+	}
+	token, err := c.resumptionToken()
+	if err != nil {
+		c.node.logger.log(newLogEntry(LogLevelError, "error issuing resumption token", map[string]any{"client": c.uid, "user": c.user, "error": err.Error()}))
+		return
+	}
+	var data []byte
+	if c.node.config.SessionResumptionMessage != nil {
+		data = c.node.config.SessionResumptionMessage(token)
+	} else {
+		data = []byte(`{"resumption_token":` + strconv.Quote(token) + `}`)
+	}
+	_ = c.Send(data)
+}
+
+// ResumedSession is a connection state restored from session resumption token.
+type ResumedSession struct {
+	// ConnectReply restores Credentials and server-side subscriptions of connection.
+	ConnectReply
+	// ClientSubscriptions contains stream positions of channels client was subscribed
+	// to from client side (zero StreamPosition for channels without positioning). Such
+	// subscriptions are not restored by server – client SDK re-subscribes to them on its
+	// own, positions may be used as recovery hints by application.
+	ClientSubscriptions map[string]StreamPosition
+}
+
+// ResumeSession verifies session resumption token issued by a node on graceful
+// shutdown (see Config.SessionResumptionSecret) and returns ResumedSession which
+// restores connection state: Credentials and server-side subscriptions to channels
+// client was subscribed to. Server-side channels with positioning are subscribed with
+// recovery from the last stream position client received. Supposed to be called from
+// ConnectingHandler when client provides resumption token (for example, in connect
+// request data), connect reply may be modified by application after that.
+// Returns ErrInvalidResumptionToken if token can't be used.
+func (n *Node) ResumeSession(token string) (ResumedSession, error) {
+	secret := n.config.SessionResumptionSecret
+	if len(secret) == 0 {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signResumptionPayload(secret, payload))) {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	var t resumptionToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	now := time.Now().Unix()
+	if t.Exp < now || (t.ExpireAt > 0 && t.ExpireAt < now) {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	if n.revocations.revoked(t.User, t.TokenID, t.IssuedAt) {
+		return ResumedSession{}, ErrInvalidResumptionToken
+	}
+	session := ResumedSession{
+		ConnectReply: ConnectReply{
+			Credentials: &Credentials{
+				UserID:   t.User,
+				Info:     t.Info,
+				ExpireAt: t.ExpireAt,
+				TokenID:  t.TokenID,
+				IssuedAt: t.IssuedAt,
+			},
+		},
+	}
+	for ch, state := range t.Channels {
+		if !channelHasFlag(state.Flags, flagServerSide) {
+			if session.ClientSubscriptions == nil {
+				session.ClientSubscriptions = make(map[string]StreamPosition)
+			}
+			session.ClientSubscriptions[ch] = StreamPosition{Offset: state.Offset, Epoch: state.Epoch}
+			continue
+		}
+		opts := SubscribeOptions{
+			ChannelInfo:       state.Info,
+			ExpireAt:          state.ExpireAt,
+			EmitPresence:      channelHasFlag(state.Flags, flagEmitPresence),
+			EmitJoinLeave:     channelHasFlag(state.Flags, flagEmitJoinLeave),
+			PushJoinLeave:     channelHasFlag(state.Flags, flagPushJoinLeave),
+			EnablePositioning: channelHasFlag(state.Flags, flagPositioning),
+		}
+		if opts.EnablePositioning && state.Epoch != "" {
+			opts.EnableRecovery = true
+			opts.RecoverSince = &StreamPosition{Offset: state.Offset, Epoch: state.Epoch}
+		}
+		if session.Subscriptions == nil {
+			session.Subscriptions = make(map[string]SubscribeOptions)
+		}
+		session.Subscriptions[ch] = opts
+	}
+	return session, nil
+}
@@ -0,0 +1,134 @@
+package centrifuge
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/protocol"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionResumptionNode(t *testing.T, secret string) *Node {
+	node, err := New(Config{
+		LogLevel:                LogLevelError,
+		SessionResumptionSecret: []byte(secret),
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	return node
+}
+
+func TestSessionResumption(t *testing.T) {
+	node := newTestSessionResumptionNode(t, "secret")
+
+	_, err := node.Publish("positioned", []byte(`{}`), WithHistory(10, time.Minute))
+	require.NoError(t, err)
+
+	transport := newTestTransport(func() {})
+	transport.sink = make(chan []byte, 100)
+	client, err := newClient(SetCredentials(context.Background(), &Credentials{UserID: "42", Info: []byte(`{"a":1}`), TokenID: "token", IssuedAt: 100}), node, transport)
+	require.NoError(t, err)
+	connectClientV2(t, client)
+
+	require.NoError(t, client.Subscribe("positioned", WithPositioning(true), WithEmitPresence(true)))
+	require.NoError(t, client.Subscribe("plain"))
+	subCtx := client.subscribeCmd(&protocol.SubscribeRequest{Channel: "client_side"}, SubscribeReply{
+		Options: SubscribeOptions{EnablePositioning: true},
+	}, &protocol.Command{}, false, time.Now(), testReplyWriterWrapper().rw)
+	require.Nil(t, subCtx.disconnect)
+
+	require.NoError(t, node.Shutdown(context.Background()))
+
+	var token string
+	for token == "" {
+		select {
+		case data := <-transport.sink:
+			if !strings.Contains(string(data), "resumption_token") {
+				continue
+			}
+			var reply protocol.Reply
+			require.NoError(t, json.Unmarshal(data, &reply))
+			var msg struct {
+				Token string `json:"resumption_token"`
+			}
+			require.NoError(t, json.Unmarshal(reply.Push.Message.Data, &msg))
+			token = msg.Token
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for resumption token")
+		}
+	}
+
+	otherNode := newTestSessionResumptionNode(t, "secret")
+	defer func() { _ = otherNode.Shutdown(context.Background()) }()
+
+	reply, err := otherNode.ResumeSession(token)
+	require.NoError(t, err)
+	require.Equal(t, "42", reply.Credentials.UserID)
+	require.Equal(t, []byte(`{"a":1}`), reply.Credentials.Info)
+	require.Equal(t, "token", reply.Credentials.TokenID)
+	require.Equal(t, int64(100), reply.Credentials.IssuedAt)
+	// Client-side subscription is not restored on server side.
+	require.Len(t, reply.Subscriptions, 2)
+	require.Len(t, reply.ClientSubscriptions, 1)
+	require.Contains(t, reply.ClientSubscriptions, "client_side")
+	require.NotEmpty(t, reply.ClientSubscriptions["client_side"].Epoch)
+
+	positioned := reply.Subscriptions["positioned"]
+	require.True(t, positioned.EnablePositioning)
+	require.True(t, positioned.EnableRecovery)
+	require.True(t, positioned.EmitPresence)
+	require.NotNil(t, positioned.RecoverSince)
+	require.Equal(t, uint64(1), positioned.RecoverSince.Offset)
+	require.NotEmpty(t, positioned.RecoverSince.Epoch)
+
+	plain := reply.Subscriptions["plain"]
+	require.False(t, plain.EnableRecovery)
+	require.Nil(t, plain.RecoverSince)
+}
+
+func TestResumeSessionInvalid(t *testing.T) {
+	node := newTestSessionResumptionNode(t, "secret")
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client := newTestClientV2(t, node, "42")
+	connectClientV2(t, client)
+	token, err := client.resumptionToken()
+	require.NoError(t, err)
+	_, err = node.ResumeSession(token)
+	require.NoError(t, err)
+
+	_, err = node.ResumeSession("x" + token)
+	require.ErrorIs(t, err, ErrInvalidResumptionToken)
+	_, err = node.ResumeSession("malformed")
+	require.ErrorIs(t, err, ErrInvalidResumptionToken)
+
+	otherNode := newTestSessionResumptionNode(t, "other")
+	defer func() { _ = otherNode.Shutdown(context.Background()) }()
+	_, err = otherNode.ResumeSession(token)
+	require.ErrorIs(t, err, ErrInvalidResumptionToken)
+
+	// Token of connection with revoked credentials.
+	client = newTestClientV2(t, node, "43")
+	connectClientV2(t, client)
+	client.mu.Lock()
+	client.tokenID = "revoked"
+	client.mu.Unlock()
+	token, err = client.resumptionToken()
+	require.NoError(t, err)
+	_, err = node.ResumeSession(token)
+	require.NoError(t, err)
+	require.NoError(t, node.RevokeToken("revoked", 0))
+	_, err = node.ResumeSession(token)
+	require.ErrorIs(t, err, ErrInvalidResumptionToken)
+
+	// Expired token.
+	data, err := json.Marshal(resumptionToken{User: "42", Exp: time.Now().Unix() - 1})
+	require.NoError(t, err)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	_, err = node.ResumeSession(payload + "." + signResumptionPayload([]byte("secret"), payload))
+	require.ErrorIs(t, err, ErrInvalidResumptionToken)
+}
@@ -0,0 +1,153 @@
+package centrifuge
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// writeTestNodeCert writes certificate signed by CA with provided serial into dir.
+func writeTestNodeCert(t *testing.T, ca testCA, serial int64, dir string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	modTime := time.Now().Add(time.Duration(serial) * time.Second)
+	for name, data := range map[string][]byte{
+		"node.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		"node.key": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		"ca.crt":   ca.pem,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	writeTestNodeCert(t, ca, 2, dir)
+
+	m, err := NewMutualTLS(MutualTLSConfig{
+		CertFile:       filepath.Join(dir, "node.crt"),
+		KeyFile:        filepath.Join(dir, "node.key"),
+		CAFile:         filepath.Join(dir, "ca.crt"),
+		ReloadInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", m.ServerConfig())
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	handshake := func(config *tls.Config) (*x509.Certificate, error) {
+		conn, err := tls.Dial("tcp", ln.Addr().String(), config)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = conn.Close() }()
+		if err := conn.Handshake(); err != nil {
+			return nil, err
+		}
+		// Read to make sure server accepted client certificate.
+		_, err = conn.Read(make([]byte, 1))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return conn.ConnectionState().PeerCertificates[0], nil
+	}
+
+	peerCert, err := handshake(m.ClientConfig())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), peerCert.SerialNumber.Int64())
+
+	// Client without certificate rejected.
+	_, err = handshake(&tls.Config{RootCAs: m.ClientConfig().RootCAs, ServerName: "127.0.0.1"})
+	require.Error(t, err)
+
+	// Certificate reloaded after files changed.
+	writeTestNodeCert(t, ca, 3, dir)
+	peerCert, err = handshake(m.ClientConfig())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), peerCert.SerialNumber.Int64())
+
+	// Server certificate signed by another CA rejected by client.
+	otherDir := t.TempDir()
+	writeTestNodeCert(t, newTestCA(t), 4, otherDir)
+	other, err := NewMutualTLS(MutualTLSConfig{
+		CertFile: filepath.Join(otherDir, "node.crt"),
+		KeyFile:  filepath.Join(otherDir, "node.key"),
+		CAFile:   filepath.Join(otherDir, "ca.crt"),
+	})
+	require.NoError(t, err)
+	_, err = handshake(other.ClientConfig())
+	require.Error(t, err)
+}
+
+func TestNewMutualTLS_Errors(t *testing.T) {
+	_, err := NewMutualTLS(MutualTLSConfig{})
+	require.Error(t, err)
+	_, err = NewMutualTLS(MutualTLSConfig{CertFile: "no.crt", KeyFile: "no.key", CAFile: "no.crt"})
+	require.Error(t, err)
+}
@@ -24,6 +24,14 @@ func (d Unsubscribe) String() string {
 	return fmt.Sprintf("code: %d, reason: %s", d.Code, d.Reason)
 }
 
+// ShouldResubscribe reports whether client connection is advised to resubscribe
+// to a channel upon receiving this Unsubscribe. Codes in range [2000, 2499] mean
+// permanent removal from a channel, codes in range [2500, 2999] result into
+// automatic resubscribe attempt on a client side.
+func (d Unsubscribe) ShouldResubscribe() bool {
+	return d.Code >= 2500 && d.Code < 3000
+}
+
 var (
 	unsubscribeClient = Unsubscribe{
 		Code:   UnsubscribeCodeClient,
@@ -10,3 +10,14 @@ func TestUnsubscribe_String(t *testing.T) {
 	t.Parallel()
 	require.Equal(t, `code: 0, reason: client unsubscribed`, unsubscribeClient.String())
 }
+
+func TestUnsubscribe_ShouldResubscribe(t *testing.T) {
+	t.Parallel()
+	require.False(t, unsubscribeClient.ShouldResubscribe())
+	require.False(t, unsubscribeServer.ShouldResubscribe())
+	require.True(t, unsubscribeInsufficientState.ShouldResubscribe())
+	require.True(t, unsubscribeExpired.ShouldResubscribe())
+	require.False(t, Unsubscribe{Code: 2100}.ShouldResubscribe())
+	require.True(t, Unsubscribe{Code: 2600}.ShouldResubscribe())
+	require.False(t, Unsubscribe{Code: 3000}.ShouldResubscribe())
+}
@@ -0,0 +1,360 @@
+package centrifuge
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// WebhookEventType is a type of event sent by WebhookEmitter.
+type WebhookEventType string
+
+// Known webhook event types.
+const (
+	WebhookEventConnect        WebhookEventType = "connect"
+	WebhookEventDisconnect     WebhookEventType = "disconnect"
+	WebhookEventSubscribe      WebhookEventType = "subscribe"
+	WebhookEventUnsubscribe    WebhookEventType = "unsubscribe"
+	WebhookEventPublishFailure WebhookEventType = "publish_failure"
+)
+
+// WebhookEvent is a JSON payload POSTed to webhook endpoint.
+type WebhookEvent struct {
+	Type    WebhookEventType `json:"type"`
+	Time    int64            `json:"time"`
+	Node    string           `json:"node"`
+	User    string           `json:"user,omitempty"`
+	Client  string           `json:"client,omitempty"`
+	Channel string           `json:"channel,omitempty"`
+	// Code is set for disconnect and unsubscribe events.
+	Code   uint32 `json:"code,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// Data is an optional application-specific JSON payload.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// WebhookConfig is a config for WebhookEmitter.
+type WebhookConfig struct {
+	// Endpoint is a URL to POST events to. Must be set.
+	Endpoint string
+	// Name of webhook used as endpoint label in metrics. By default, host of Endpoint
+	// is used – set Name to distinguish several webhooks with the same host.
+	Name string
+	// Secret used to sign requests with HMAC-SHA256. Signed payload is a request
+	// timestamp (Unix seconds sent in X-Centrifuge-Timestamp header), a dot and request
+	// body – so endpoint can reject replayed requests. Signature is sent in hex encoding
+	// in X-Centrifuge-Signature header. If empty then requests are not signed.
+	Secret string
+	// PreviousSecrets are secrets still accepted by endpoint during rotation. Requests are
+	// additionally signed with each of them and all signatures are sent comma-separated
+	// in X-Centrifuge-Signature header (signature with Secret goes first). So endpoint
+	// may switch to the new secret at any moment – see VerifyWebhookSignature.
+	PreviousSecrets []string
+	// Header allows setting custom headers to requests.
+	Header http.Header
+	// Client is HTTP client to use. By default, http.Client with 5 seconds timeout used.
+	Client *http.Client
+	// QueueSize is a maximum number of events waiting to be sent. When queue is full
+	// WebhookEmitter.Emit returns ErrWebhookQueueFull. Zero value means 1024.
+	QueueSize int
+	// NumWorkers is a number of goroutines sending events. Zero value means 1.
+	NumWorkers int
+	// MaxRetries is a maximum number of retries to send an event after the first
+	// failed attempt. Zero value means no retries.
+	MaxRetries int
+	// RetryBackoff is an initial delay between retries, delay is doubled on every
+	// next attempt. Zero value means 100ms.
+	RetryBackoff time.Duration
+	// Events are types of events Node emits automatically after WebhookEmitter is set
+	// with Node.SetWebhookEmitter. Zero value means all known event types.
+	Events []WebhookEventType
+}
+
+// ErrWebhookQueueFull returned by WebhookEmitter.Emit when queue is full.
+var ErrWebhookQueueFull = errors.New("webhook queue full")
+
+// Headers of webhook requests.
+const (
+	WebhookSignatureHeader = "X-Centrifuge-Signature"
+	WebhookTimestampHeader = "X-Centrifuge-Timestamp"
+)
+
+// DefaultWebhookSignatureTolerance is a maximum age of webhook request timestamp
+// accepted by VerifyWebhookSignature when tolerance is not set.
+const DefaultWebhookSignatureTolerance = 5 * time.Minute
+
+// webhookQueueFullLogInterval is a minimal interval between logs about events
+// dropped due to full queue.
+const webhookQueueFullLogInterval = 10 * time.Second
+
+// WebhookEmitter sends events to application endpoint over HTTP asynchronously.
+// Set it to Node with Node.SetWebhookEmitter to deliver connect, disconnect,
+// subscribe, unsubscribe and publish failure events (see WebhookConfig.Events).
+// WebhookEmitter.Emit may be used to send custom events.
+type WebhookEmitter struct {
+	node      *Node
+	config    WebhookConfig
+	events    map[WebhookEventType]struct{}
+	queue     chan WebhookEvent
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	wg        sync.WaitGroup
+	secrets   atomic.Pointer[[]string]
+	// queueFullSampler limits logs about node events dropped due to full queue.
+	queueFullSampler *logSampler
+}
+
+// NewWebhookEmitter creates WebhookEmitter and starts its workers.
+func NewWebhookEmitter(n *Node, config WebhookConfig) (*WebhookEmitter, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("webhook: endpoint required")
+	}
+	if config.Name == "" {
+		u, err := url.Parse(config.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: %w", err)
+		}
+		config.Name = u.Host
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = 1024
+	}
+	if config.NumWorkers == 0 {
+		config.NumWorkers = 1
+	}
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = 100 * time.Millisecond
+	}
+	e := &WebhookEmitter{
+		node:    n,
+		config:  config,
+		queue:   make(chan WebhookEvent, config.QueueSize),
+		closeCh: make(chan struct{}),
+
+		queueFullSampler: newLogSampler(webhookQueueFullLogInterval),
+	}
+	if len(config.Events) > 0 {
+		e.events = make(map[WebhookEventType]struct{}, len(config.Events))
+		for _, eventType := range config.Events {
+			e.events[eventType] = struct{}{}
+		}
+	}
+	e.SetSecrets(config.Secret, config.PreviousSecrets...)
+	for i := 0; i < config.NumWorkers; i++ {
+		e.wg.Add(1)
+		go e.runWorker()
+	}
+	return e, nil
+}
+
+// Emit adds event to a sending queue. Event Time and Node fields are set
+// automatically if empty. Emit does not block and returns ErrWebhookQueueFull
+// if queue is full.
+func (e *WebhookEmitter) Emit(event WebhookEvent) error {
+	if event.Time == 0 {
+		event.Time = time.Now().UnixMilli()
+	}
+	if event.Node == "" {
+		event.Node = e.node.ID()
+	}
+	select {
+	case <-e.closeCh:
+		return errors.New("webhook: emitter closed")
+	default:
+	}
+	select {
+	case e.queue <- event:
+		return nil
+	default:
+		return ErrWebhookQueueFull
+	}
+}
+
+// emitNodeEvent emits event of Node lifecycle if event type is enabled in
+// WebhookConfig.Events.
+func (e *WebhookEmitter) emitNodeEvent(event WebhookEvent) {
+	if e.events != nil {
+		if _, ok := e.events[event.Type]; !ok {
+			return
+		}
+	}
+	if err := e.Emit(event); err != nil {
+		if errors.Is(err, ErrWebhookQueueFull) {
+			// Queue is full under load or when endpoint is slow – avoid flooding logs.
+			if ok, suppressed := e.queueFullSampler.allow(); ok {
+				e.node.logger.log(newLogEntry(LogLevelError, "error emitting webhook event", map[string]any{"type": string(event.Type), "error": err.Error(), "suppressed": suppressed}))
+			}
+			return
+		}
+		e.node.logger.log(newLogEntry(LogLevelError, "error emitting webhook event", map[string]any{"type": string(event.Type), "error": err.Error()}))
+	}
+}
+
+// Close stops accepting new events and waits for queued events to be sent
+// until context is done.
+func (e *WebhookEmitter) Close(ctx context.Context) error {
+	e.closeOnce.Do(func() {
+		close(e.closeCh)
+	})
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *WebhookEmitter) runWorker() {
+	defer e.wg.Done()
+	for {
+		select {
+		case event := <-e.queue:
+			e.handleEvent(event)
+		case <-e.closeCh:
+			// Drain queued events before exit.
+			for {
+				select {
+				case event := <-e.queue:
+					e.handleEvent(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *WebhookEmitter) handleEvent(event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		e.node.logger.log(newLogEntry(LogLevelError, "error marshaling webhook event", map[string]any{"error": err.Error()}))
+		return
+	}
+	backoff := e.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err = e.send(event.Type, body)
+		if err == nil {
+			return
+		}
+		if attempt >= e.config.MaxRetries {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-e.closeCh:
+			// Do not delay Close with retries.
+			timer.Stop()
+			e.node.logger.log(newLogEntry(LogLevelError, "error sending webhook event, emitter closed", map[string]any{"type": string(event.Type), "error": err.Error()}))
+			return
+		}
+		backoff *= 2
+	}
+	e.node.logger.log(newLogEntry(LogLevelError, "error sending webhook event", map[string]any{"type": string(event.Type), "error": err.Error()}))
+}
+
+func (e *WebhookEmitter) send(eventType WebhookEventType, body []byte) (err error) {
+	done := e.node.metrics.observeWebhookRequest(e.config.Name, string(eventType))
+	defer func() { done(err) }()
+	req, err := http.NewRequest(http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range e.config.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secrets := *e.secrets.Load(); len(secrets) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		signatures := make([]string, 0, len(secrets))
+		for _, secret := range secrets {
+			signatures = append(signatures, webhookSignature(secret, timestamp, body))
+		}
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, strings.Join(signatures, ","))
+	}
+	resp, err := e.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook response status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SetSecrets replaces secret and previous secrets used to sign requests at runtime,
+// so secrets can be rotated without restarting the process. Empty secret with no
+// previous secrets disables signing.
+func (e *WebhookEmitter) SetSecrets(secret string, previous ...string) {
+	secrets := make([]string, 0, len(previous)+1)
+	for _, s := range append([]string{secret}, previous...) {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	e.secrets.Store(&secrets)
+}
+
+// VerifyWebhookSignature may be used by webhook endpoint to check request body with
+// values of X-Centrifuge-Timestamp and X-Centrifuge-Signature headers. Returns true if
+// timestamp differs from current time not more than tolerance (zero value means
+// DefaultWebhookSignatureTolerance) and any signature in header matches any of provided
+// secrets – so endpoint may accept both old and new secrets during rotation. Endpoint
+// which needs strict replay protection should additionally remember received events
+// during tolerance window.
+func VerifyWebhookSignature(body []byte, timestamp string, header string, tolerance time.Duration, secrets ...string) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultWebhookSignatureTolerance
+	}
+	age := time.Since(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return false
+	}
+	var match int
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		expected := []byte(webhookSignature(secret, timestamp, body))
+		for _, signature := range strings.Split(header, ",") {
+			match |= subtle.ConstantTimeCompare([]byte(strings.TrimSpace(signature)), expected)
+		}
+	}
+	return match == 1
+}
+
+func webhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte{'.'})
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,345 @@
+package centrifuge
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/encoding/json"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookEmitter(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	received := make(chan WebhookEvent, 1)
+	var numRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		require.Equal(t, webhookSignature("secret", timestamp, body), r.Header.Get(WebhookSignatureHeader))
+		require.True(t, VerifyWebhookSignature(body, timestamp, r.Header.Get(WebhookSignatureHeader), 0, "secret"))
+		require.Equal(t, "value", r.Header.Get("X-Custom"))
+		if atomic.AddInt32(&numRequests, 1) == 1 {
+			// First attempt fails to check retries.
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var event WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		received <- event
+	}))
+	defer server.Close()
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:     server.URL,
+		Secret:       "secret",
+		Header:       http.Header{"X-Custom": []string{"value"}},
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer func() { _ = emitter.Close(context.Background()) }()
+
+	err = emitter.Emit(WebhookEvent{Type: WebhookEventConnect, User: "42", Client: "client"})
+	require.NoError(t, err)
+
+	select {
+	case event := <-received:
+		require.Equal(t, WebhookEventConnect, event.Type)
+		require.Equal(t, "42", event.User)
+		require.Equal(t, node.ID(), event.Node)
+		require.NotZero(t, event.Time)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting webhook")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&numRequests))
+}
+
+func TestWebhookEmitter_QueueFull(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:  server.URL,
+		QueueSize: 1,
+	})
+	require.NoError(t, err)
+
+	var queueFull bool
+	for i := 0; i < 3; i++ {
+		if err := emitter.Emit(WebhookEvent{Type: WebhookEventSubscribe}); err == ErrWebhookQueueFull {
+			queueFull = true
+		}
+	}
+	require.True(t, queueFull)
+}
+
+func TestWebhookEmitter_QueueFullLogSampled(t *testing.T) {
+	var numLogs int32
+	node, err := New(Config{
+		LogLevel: LogLevelError,
+		LogHandler: func(entry LogEntry) {
+			if entry.Message == "error emitting webhook event" {
+				atomic.AddInt32(&numLogs, 1)
+			}
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:  server.URL,
+		QueueSize: 1,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		emitter.emitNodeEvent(WebhookEvent{Type: WebhookEventSubscribe})
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&numLogs))
+}
+
+func TestNewWebhookEmitter_NoEndpoint(t *testing.T) {
+	_, err := NewWebhookEmitter(nil, WebhookConfig{})
+	require.Error(t, err)
+}
+
+func TestWebhookEmitter_SecretRotation(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	signatures := make(chan string, 2)
+	timestamps := make(chan string, 2)
+	bodies := make(chan []byte, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies <- body
+		timestamps <- r.Header.Get(WebhookTimestampHeader)
+		signatures <- r.Header.Get(WebhookSignatureHeader)
+	}))
+	defer server.Close()
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:        server.URL,
+		Secret:          "new",
+		PreviousSecrets: []string{"old"},
+	})
+	require.NoError(t, err)
+	defer func() { _ = emitter.Close(context.Background()) }()
+
+	require.NoError(t, emitter.Emit(WebhookEvent{Type: WebhookEventConnect}))
+	body, timestamp, signature := <-bodies, <-timestamps, <-signatures
+	require.Equal(t, webhookSignature("new", timestamp, body)+","+webhookSignature("old", timestamp, body), signature)
+	require.True(t, VerifyWebhookSignature(body, timestamp, signature, 0, "old"))
+	require.True(t, VerifyWebhookSignature(body, timestamp, signature, 0, "new"))
+	require.False(t, VerifyWebhookSignature(body, timestamp, signature, 0, "other"))
+
+	emitter.SetSecrets("new")
+	require.NoError(t, emitter.Emit(WebhookEvent{Type: WebhookEventConnect}))
+	body, timestamp, signature = <-bodies, <-timestamps, <-signatures
+	require.True(t, VerifyWebhookSignature(body, timestamp, signature, 0, "new"))
+	require.False(t, VerifyWebhookSignature(body, timestamp, signature, 0, "old"))
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"type":"connect"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	require.True(t, VerifyWebhookSignature(body, now, webhookSignature("secret", now, body), 0, "secret"))
+	// Timestamp is signed together with body.
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	require.False(t, VerifyWebhookSignature(body, now, webhookSignature("secret", old, body), 0, "secret"))
+	// Replayed request is rejected after tolerance window.
+	require.False(t, VerifyWebhookSignature(body, old, webhookSignature("secret", old, body), 0, "secret"))
+	require.True(t, VerifyWebhookSignature(body, old, webhookSignature("secret", old, body), 2*time.Hour, "secret"))
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	require.False(t, VerifyWebhookSignature(body, future, webhookSignature("secret", future, body), 0, "secret"))
+	require.False(t, VerifyWebhookSignature(body, "", webhookSignature("secret", "", body), 0, "secret"))
+}
+
+func TestWebhookEmitter_Metrics(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	var numRequests int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&numRequests, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		close(done)
+	}))
+	defer server.Close()
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:     server.URL,
+		Name:         "metrics-test",
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.NoError(t, emitter.Emit(WebhookEvent{Type: WebhookEventSubscribe}))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting webhook")
+	}
+	require.NoError(t, emitter.Close(context.Background()))
+
+	labels := []string{"metrics-test", string(WebhookEventSubscribe)}
+	require.Equal(t, float64(1), testutil.ToFloat64(node.metrics.webhookErrorCount.WithLabelValues(labels...)))
+	require.Equal(t, float64(0), testutil.ToFloat64(node.metrics.webhookInflightGauge.WithLabelValues(labels...)))
+	require.Equal(t, 1, testutil.CollectAndCount(node.metrics.webhookDurationHistogram))
+}
+
+func TestNewWebhookEmitter_DefaultName(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{Endpoint: "http://example.com:8000/hook?secret=1"})
+	require.NoError(t, err)
+	defer func() { _ = emitter.Close(context.Background()) }()
+	require.Equal(t, "example.com:8000", emitter.config.Name)
+}
+
+func TestWebhookEmitter_NodeEvents(t *testing.T) {
+	received := make(chan WebhookEvent, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	broker := NewTestBroker()
+	node, err := New(Config{})
+	require.NoError(t, err)
+	node.SetBroker(broker)
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{Endpoint: server.URL})
+	require.NoError(t, err)
+	defer func() { _ = emitter.Close(context.Background()) }()
+	node.SetWebhookEmitter(emitter)
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(e SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	client := newTestSubscribedClientV2(t, node, "42", "test")
+	client.Unsubscribe("test")
+	client.Disconnect(DisconnectForceNoReconnect)
+	broker.errorOnPublish = true
+	_, err = node.Publish("test", []byte(`{}`))
+	require.Error(t, err)
+
+	// Events are emitted from different goroutines, so order is not checked.
+	expected := map[WebhookEventType]struct{}{
+		WebhookEventConnect:        {},
+		WebhookEventSubscribe:      {},
+		WebhookEventUnsubscribe:    {},
+		WebhookEventDisconnect:     {},
+		WebhookEventPublishFailure: {},
+	}
+	for len(expected) > 0 {
+		select {
+		case event := <-received:
+			require.Contains(t, expected, event.Type)
+			delete(expected, event.Type)
+			if event.Type == WebhookEventPublishFailure {
+				require.Equal(t, "test", event.Channel)
+				continue
+			}
+			require.Equal(t, "42", event.User)
+			require.Equal(t, client.ID(), event.Client)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting webhook")
+		}
+	}
+}
+
+func TestWebhookEmitter_EventsFilter(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	received := make(chan WebhookEvent, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event WebhookEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint: server.URL,
+		Events:   []WebhookEventType{WebhookEventPublishFailure},
+	})
+	require.NoError(t, err)
+	defer func() { _ = emitter.Close(context.Background()) }()
+
+	// Single worker sends events in order, so connect event would come first if
+	// it was not filtered.
+	emitter.emitNodeEvent(WebhookEvent{Type: WebhookEventConnect})
+	emitter.emitNodeEvent(WebhookEvent{Type: WebhookEventPublishFailure})
+	select {
+	case event := <-received:
+		require.Equal(t, WebhookEventPublishFailure, event.Type)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting webhook")
+	}
+}
+
+func TestWebhookEmitter_CloseDuringRetry(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	requests := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	emitter, err := NewWebhookEmitter(node, WebhookConfig{
+		Endpoint:     server.URL,
+		MaxRetries:   3,
+		RetryBackoff: time.Minute,
+	})
+	require.NoError(t, err)
+	require.NoError(t, emitter.Emit(WebhookEvent{Type: WebhookEventConnect}))
+	select {
+	case <-requests:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timeout waiting webhook")
+	}
+
+	// Close must not wait for retry backoff.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, emitter.Close(ctx))
+}
@@ -0,0 +1,108 @@
+package centrifuge
+
+import (
+	"strings"
+)
+
+// WildcardChannelTag is a tag of publication delivered over wildcard subscription
+// which contains channel publication was published into. See Config.WildcardSubscriptions.
+const WildcardChannelTag = "_channel"
+
+// PatternSubscriber is an optional Broker interface required for wildcard
+// subscriptions. Broker must deliver publications of channels matching pattern
+// to BrokerEventHandler.HandlePublication with pattern as channel, and the original
+// channel in WildcardChannelTag – use WildcardPublication for that. Stream position
+// of publications must not be passed since pattern is not a stream.
+type PatternSubscriber interface {
+	// SubscribePattern subscribes Node to channels matching pattern.
+	SubscribePattern(pattern string) error
+	// UnsubscribePattern unsubscribes Node from pattern.
+	UnsubscribePattern(pattern string) error
+}
+
+// MatchWildcard reports whether channel matches wildcard pattern, where "*"
+// matches any sequence of characters (including empty).
+func MatchWildcard(pattern, channel string) bool {
+	prefix, rest, found := strings.Cut(pattern, "*")
+	if !found {
+		return pattern == channel
+	}
+	if !strings.HasPrefix(channel, prefix) {
+		return false
+	}
+	channel = channel[len(prefix):]
+	parts := strings.Split(rest, "*")
+	last := parts[len(parts)-1]
+	for _, part := range parts[:len(parts)-1] {
+		i := strings.Index(channel, part)
+		if i < 0 {
+			return false
+		}
+		channel = channel[i+len(part):]
+	}
+	return len(channel) >= len(last) && strings.HasSuffix(channel, last)
+}
+
+// WildcardPublication returns a copy of publication to deliver over wildcard
+// subscription with WildcardChannelTag set to channel.
+func WildcardPublication(pub *Publication, channel string) *Publication {
+	tags := make(map[string]string, len(pub.Tags)+1)
+	for k, v := range pub.Tags {
+		tags[k] = v
+	}
+	tags[WildcardChannelTag] = channel
+	return &Publication{
+		Data:     pub.Data,
+		Info:     pub.Info,
+		Tags:     tags,
+		Time:     pub.Time,
+		delivery: pub.delivery,
+	}
+}
+
+// wildcardDelivery checks whether publication received over pattern subscription
+// may be delivered to pattern subscribers. Options of pattern subscriptions were
+// resolved for the namespace of pattern, so publications of channels from other
+// namespaces are not delivered. Publications of user-limited channels are only
+// delivered to users allowed in channel – same as for direct subscriptions.
+func (n *Node) wildcardDelivery(pattern string, pub *Publication) (*Publication, bool) {
+	ch := pub.Tags[WildcardChannelTag]
+	if namespaces := n.reloadableConfig().namespaces; namespaces != nil && namespaces.name(ch) != namespaces.name(pattern) {
+		return nil, false
+	}
+	if n.userAllowedInChannel(ch, "") {
+		// Not a user-limited channel.
+		return pub, true
+	}
+	pubCopy := *pub
+	pubCopy.delivery.userAllowed = func(user string) bool {
+		return n.userAllowedInChannel(ch, user)
+	}
+	return &pubCopy, true
+}
+
+func (n *Node) isWildcardChannel(ch string) bool {
+	return n.config.WildcardSubscriptions && strings.Contains(ch, "*")
+}
+
+func (n *Node) brokerSubscribe(ch string) error {
+	if !n.isWildcardChannel(ch) {
+		return n.broker.Subscribe(ch)
+	}
+	ps, ok := n.broker.(PatternSubscriber)
+	if !ok {
+		return ErrorNotAvailable
+	}
+	return ps.SubscribePattern(ch)
+}
+
+func (n *Node) brokerUnsubscribe(ch string) error {
+	if !n.isWildcardChannel(ch) {
+		return n.broker.Unsubscribe(ch)
+	}
+	ps, ok := n.broker.(PatternSubscriber)
+	if !ok {
+		return nil
+	}
+	return ps.UnsubscribePattern(ch)
+}
@@ -0,0 +1,147 @@
+package centrifuge
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		channel string
+		match   bool
+	}{
+		{"stocks.*", "stocks.AAPL", true},
+		{"stocks.*", "stocks.", true},
+		{"stocks.*", "stock.AAPL", false},
+		{"*.AAPL", "stocks.AAPL", true},
+		{"*.AAPL", "stocks.GOOG", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "a1b2c", true},
+		{"a*b*c", "a1c2b", false},
+		{"a*bc", "abcbc", true},
+		{"ab*ba", "aba", false},
+		{"*", "anything", true},
+		{"exact", "exact", true},
+		{"exact", "exact1", false},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.match, MatchWildcard(tc.pattern, tc.channel), tc.pattern+" "+tc.channel)
+	}
+}
+
+func TestRedisBroker_RedisPattern(t *testing.T) {
+	b := &RedisBroker{messagePrefix: "centrifuge.client."}
+	pattern := b.redisPattern("stocks.[a]?.*")
+	require.Equal(t, `centrifuge.client.stocks.\[a\]\?.*`, pattern)
+	require.Equal(t, "stocks.[a]?.*", b.extractPattern(pattern))
+}
+
+func TestWildcardSubscription(t *testing.T) {
+	node, err := New(Config{
+		LogLevel:              LogLevelTrace,
+		LogHandler:            func(entry LogEntry) {},
+		WildcardSubscriptions: true,
+	})
+	require.NoError(t, err)
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(event SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{Options: SubscribeOptions{EnableRecovery: true}}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	transport := newTestTransport(cancelFn)
+	transport.sink = make(chan []byte, 100)
+	client := newTestSubscribedClientWithTransport(t, ctx, node, transport, "42", "stocks.*")
+	chCtx := client.channels["stocks.*"]
+	require.False(t, channelHasFlag(chCtx.flags, flagPositioning))
+
+	_, err = node.Publish("stocks.*", []byte(`{}`))
+	require.ErrorIs(t, err, ErrorBadRequest)
+	_, err = node.Publish("news.AAPL", []byte(`{"n":0}`))
+	require.NoError(t, err)
+	_, err = node.Publish("stocks.AAPL", []byte(`{"n":1}`), WithHistory(10, time.Minute), WithTags(map[string]string{"k": "v"}))
+	require.NoError(t, err)
+
+	for {
+		select {
+		case data := <-transport.sink:
+			msg := string(data)
+			if !strings.Contains(msg, `"pub"`) {
+				continue
+			}
+			require.True(t, strings.Contains(msg, `"channel":"stocks.*"`), msg)
+			require.True(t, strings.Contains(msg, `"_channel":"stocks.AAPL"`), msg)
+			require.True(t, strings.Contains(msg, `"k":"v"`), msg)
+			require.True(t, strings.Contains(msg, `{"n":1}`), msg)
+			require.False(t, strings.Contains(msg, `"offset"`), msg)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for publication")
+		}
+	}
+}
+
+func TestWildcardSubscription_Disabled(t *testing.T) {
+	node := defaultNodeNoHandlers()
+	defer func() { _ = node.Shutdown(context.Background()) }()
+	require.False(t, node.isWildcardChannel("stocks.*"))
+	_, err := node.Publish("stocks.*", []byte(`{}`))
+	require.NoError(t, err)
+}
+
+func TestWildcardSubscription_UserLimitedAndNamespaces(t *testing.T) {
+	node, err := New(Config{
+		LogLevel:              LogLevelTrace,
+		LogHandler:            func(entry LogEntry) {},
+		WildcardSubscriptions: true,
+		UserLimitedChannels:   true,
+		Namespaces:            []ChannelNamespace{{Name: ""}, {Name: "private"}},
+	})
+	require.NoError(t, err)
+	node.OnConnect(func(client *Client) {
+		client.OnSubscribe(func(event SubscribeEvent, cb SubscribeCallback) {
+			cb(SubscribeReply{}, nil)
+		})
+	})
+	require.NoError(t, node.Run())
+	defer func() { _ = node.Shutdown(context.Background()) }()
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	transport := newTestTransport(cancelFn)
+	transport.sink = make(chan []byte, 100)
+	newTestSubscribedClientWithTransport(t, ctx, node, transport, "42", "*")
+
+	// Pattern must not give access to user-limited channels of other users and
+	// to channels of other namespaces.
+	for _, ch := range []string{"dialog#43,44", "private:secret", "dialog#42,43", "news"} {
+		_, err = node.Publish(ch, []byte(`{}`))
+		require.NoError(t, err)
+	}
+
+	var received []string
+	for len(received) < 2 {
+		select {
+		case data := <-transport.sink:
+			msg := string(data)
+			if !strings.Contains(msg, `"pub"`) {
+				continue
+			}
+			for _, ch := range []string{"dialog#43,44", "private:secret", "dialog#42,43", "news"} {
+				if strings.Contains(msg, `"_channel":"`+ch+`"`) {
+					received = append(received, ch)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for publication")
+		}
+	}
+	require.Equal(t, []string{"dialog#42,43", "news"}, received)
+}
@@ -2,6 +2,7 @@ package centrifuge
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/centrifugal/centrifuge/internal/queue"
@@ -12,15 +13,19 @@ type writerConfig struct {
 	WriteManyFn  func(...queue.Item) error
 	WriteFn      func(item queue.Item) error
 	MaxQueueSize int
+	// MaxFrameSize limits total size of messages collected into a single frame.
+	// Zero means no limit.
+	MaxFrameSize int
 }
 
 // writer helps to manage per-connection message byte queue.
 type writer struct {
-	mu       sync.Mutex
-	config   writerConfig
-	messages *queue.Queue
-	closed   bool
-	closeCh  chan struct{}
+	mu           sync.Mutex
+	config       writerConfig
+	maxQueueSize atomic.Int64
+	messages     *queue.Queue
+	closed       bool
+	closeCh      chan struct{}
 }
 
 func newWriter(config writerConfig, queueInitialCap int) *writer {
@@ -32,6 +37,7 @@ func newWriter(config writerConfig, queueInitialCap int) *writer {
 		messages: queue.New(queueInitialCap),
 		closeCh:  make(chan struct{}),
 	}
+	w.maxQueueSize.Store(int64(config.MaxQueueSize))
 	return w
 }
 
@@ -39,6 +45,31 @@ const (
 	defaultMaxMessagesInFrame = 16
 )
 
+// itemsPool reuses slices to collect queue items sent in a single frame.
+// Slice is owned by writer goroutine and returned to pool after WriteManyFn
+// returns, so WriteManyFn must not retain items.
+var itemsPool sync.Pool
+
+func acquireItems(capacity int) *[]queue.Item {
+	v := itemsPool.Get()
+	if v == nil {
+		items := make([]queue.Item, 0, capacity)
+		return &items
+	}
+	items := v.(*[]queue.Item)
+	if cap(*items) < capacity {
+		*items = make([]queue.Item, 0, capacity)
+	}
+	return items
+}
+
+func releaseItems(items *[]queue.Item) {
+	// Clear items to not keep message data referenced from pool.
+	clear(*items)
+	*items = (*items)[:0]
+	itemsPool.Put(items)
+}
+
 func (w *writer) waitSendMessage(maxMessagesInFrame int, writeDelay time.Duration) bool {
 	// Wait for message from the queue.
 	ok := w.messages.Wait()
@@ -62,7 +93,13 @@ func (w *writer) waitSendMessage(maxMessagesInFrame int, writeDelay time.Duratio
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	now := time.Now().UnixNano()
+
 	msg, ok := w.messages.Remove()
+	for ok && msg.Expired(now) {
+		// Silently drop stale messages.
+		msg, ok = w.messages.Remove()
+	}
 	if !ok {
 		return !w.messages.Closed()
 	}
@@ -81,17 +118,28 @@ func (w *writer) waitSendMessage(maxMessagesInFrame int, writeDelay time.Duratio
 			messagesCap = maxMessagesInFrame
 		}
 
-		messages := make([]queue.Item, 0, messagesCap)
-		messages = append(messages, msg)
+		messagesPtr := acquireItems(messagesCap)
+		defer releaseItems(messagesPtr)
+		messages := append(*messagesPtr, msg)
+		frameSize := len(msg.Data)
 
 		for messageCount > 0 {
 			messageCount--
 			if maxMessagesInFrame > -1 && len(messages) >= maxMessagesInFrame {
 				break
 			}
+			if w.config.MaxFrameSize > 0 {
+				if next, ok := w.messages.Peek(); ok && frameSize+len(next.Data) > w.config.MaxFrameSize {
+					break
+				}
+			}
 			m, ok := w.messages.Remove()
 			if ok {
+				if m.Expired(now) {
+					continue
+				}
 				messages = append(messages, m)
+				frameSize += len(m.Data)
 			} else {
 				if w.messages.Closed() {
 					return false
@@ -99,6 +147,7 @@ func (w *writer) waitSendMessage(maxMessagesInFrame int, writeDelay time.Duratio
 				break
 			}
 		}
+		*messagesPtr = messages
 		if len(messages) == 1 {
 			writeErr = w.config.WriteFn(messages[0])
 		} else {
@@ -128,13 +177,21 @@ func (w *writer) run(writeDelay time.Duration, maxMessagesInFrame int) {
 	}
 }
 
+// setMaxQueueSize changes max queue size of writer, zero means no limit.
+func (w *writer) setMaxQueueSize(size int) {
+	w.maxQueueSize.Store(int64(size))
+}
+
 func (w *writer) enqueue(item queue.Item) *Disconnect {
 	ok := w.messages.Add(item)
 	if !ok {
 		return &DisconnectConnectionClosed
 	}
-	if w.config.MaxQueueSize > 0 && w.messages.Size() > w.config.MaxQueueSize {
-		return &DisconnectSlow
+	if maxQueueSize := int(w.maxQueueSize.Load()); maxQueueSize > 0 && w.messages.Size() > maxQueueSize {
+		// Try to free space by dropping low priority messages first.
+		if w.messages.DropLow() == 0 || w.messages.Size() > maxQueueSize {
+			return &DisconnectSlow
+		}
 	}
 	return nil
 }
@@ -149,6 +206,15 @@ func (w *writer) close(flushRemaining bool) error {
 
 	if flushRemaining {
 		remaining := w.messages.CloseRemaining()
+		now := time.Now().UnixNano()
+		n := 0
+		for _, item := range remaining {
+			if !item.Expired(now) {
+				remaining[n] = item
+				n++
+			}
+		}
+		remaining = remaining[:n]
 		if len(remaining) > 0 {
 			// TODO: make it respect MaxMessagesInFrame option.
 			_ = w.config.WriteManyFn(remaining...)
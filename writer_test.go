@@ -258,6 +258,53 @@ func TestWriterDisconnectSlow(t *testing.T) {
 	require.Equal(t, DisconnectSlow.Code, disconnect.Code)
 }
 
+func TestWriterDropLowPriority(t *testing.T) {
+	transport := newFakeTransport(nil)
+
+	w := newWriter(writerConfig{
+		MaxQueueSize: 4,
+		WriteFn:      transport.write,
+		WriteManyFn:  transport.writeMany,
+	}, 0)
+	defer func() { _ = w.close(false) }()
+
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("12"), Priority: queue.PriorityLow}))
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("34")}))
+	// Low priority message dropped to free space.
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("5"), Priority: queue.PriorityHigh}))
+	require.Equal(t, 2, w.messages.Len())
+	// Nothing to drop anymore.
+	disconnect := w.enqueue(queue.Item{Data: []byte("678")})
+	require.Equal(t, DisconnectSlow.Code, disconnect.Code)
+}
+
+func TestWriterDropExpired(t *testing.T) {
+	transport := newFakeTransport(nil)
+
+	w := newWriter(writerConfig{
+		MaxQueueSize: 10 * 1024,
+		WriteFn:      transport.write,
+		WriteManyFn:  transport.writeMany,
+	}, 0)
+
+	expired := time.Now().Add(-time.Second).UnixNano()
+	fresh := time.Now().Add(time.Minute).UnixNano()
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("1"), ExpireAt: expired}))
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("2"), ExpireAt: fresh}))
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("3"), ExpireAt: expired}))
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("4")}))
+
+	go w.run(0, 0)
+	<-transport.ch
+	<-transport.ch
+	require.Equal(t, 2, transport.count)
+
+	// Expired messages are not flushed upon close.
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("5"), ExpireAt: expired}))
+	require.NoError(t, w.close(true))
+	require.Equal(t, 2, transport.count)
+}
+
 func TestWriterDisconnectNormalOnClosedQueue(t *testing.T) {
 	transport := newFakeTransport(nil)
 
@@ -311,3 +358,71 @@ func TestWriterWriteError(t *testing.T) {
 		t.Fatal("timeout waiting for write routine close")
 	}
 }
+
+func TestWriterItemsPool(t *testing.T) {
+	items := acquireItems(2)
+	require.Empty(t, *items)
+	require.GreaterOrEqual(t, cap(*items), 2)
+	*items = append(*items, queue.Item{Data: []byte("test")})
+	releaseItems(items)
+
+	// Slice must not be accessed after release since it may be reused by writer
+	// goroutine, so only check freshly acquired one.
+	items = acquireItems(16)
+	defer releaseItems(items)
+	require.Empty(t, *items)
+	require.GreaterOrEqual(t, cap(*items), 16)
+	require.Nil(t, (*items)[:1][0].Data)
+}
+
+func TestWriterMaxFrameSize(t *testing.T) {
+	transport := newFakeTransport(nil)
+
+	w := newWriter(writerConfig{
+		MaxQueueSize: 10 * 1024,
+		MaxFrameSize: 8,
+		WriteFn:      transport.write,
+		WriteManyFn:  transport.writeMany,
+	}, 0)
+
+	numMessages := 16
+	for i := 0; i < numMessages; i++ {
+		disconnect := w.enqueue(queue.Item{Data: []byte("test")})
+		require.Nil(t, disconnect)
+	}
+
+	doneCh := make(chan struct{})
+
+	go func() {
+		defer close(doneCh)
+		w.run(10*time.Millisecond, 4)
+	}()
+
+	for i := 0; i < numMessages; i++ {
+		<-transport.ch
+	}
+
+	require.Equal(t, transport.count, numMessages)
+	// Only two 4-byte messages fit into 8-byte frame.
+	require.Equal(t, numMessages/2, transport.writeManyCalls)
+	err := w.close(true)
+	require.NoError(t, err)
+
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for write routine close")
+	}
+}
+
+func TestWriterSetMaxQueueSize(t *testing.T) {
+	w := newWriter(writerConfig{
+		MaxQueueSize: 0,
+		WriteFn:      func(item queue.Item) error { return nil },
+		WriteManyFn:  func(items ...queue.Item) error { return nil },
+	}, 0)
+	defer func() { _ = w.close(false) }()
+	require.Nil(t, w.enqueue(queue.Item{Data: []byte("1234")}))
+	w.setMaxQueueSize(2)
+	require.NotNil(t, w.enqueue(queue.Item{Data: []byte("1234")}))
+}